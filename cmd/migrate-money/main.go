@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/Over-knight/Lujay-assesment/internal/config"
+	"github.com/Over-knight/Lujay-assesment/internal/migrations"
+	"github.com/Over-knight/Lujay-assesment/internal/storage"
+)
+
+// migrate-money converts amounts stored as plain numbers to exact {amount: Decimal128, currency} documents
+// Run it once before starting a server that reads amounts as Money; it is safe to run again
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report the documents that would change without writing them")
+	timeout := flag.Duration("timeout", 30*time.Minute, "maximum time the migration may run")
+	flag.Parse()
+
+	cfg := config.Load()
+
+	mongoDB, err := storage.NewMongoDB(storage.MongoConfig{
+		URI:      cfg.MongoDB.URI,
+		Database: cfg.MongoDB.Database,
+		Timeout:  10 * time.Second,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	defer func() {
+		if err := mongoDB.Close(context.Background()); err != nil {
+			log.Printf("Error closing MongoDB connection: %v", err)
+		}
+	}()
+
+	results, err := migrations.MigrateMoney(ctx, mongoDB.Database, *dryRun)
+	failed, changed := 0, int64(0)
+	for _, result := range results {
+		verb := "migrated"
+		if *dryRun {
+			verb = "would migrate"
+		}
+		log.Printf("%s: %s %d documents", result.Collection, verb, result.Migrated)
+		if result.Changed > 0 {
+			log.Printf("%s: skipped %d documents written to during the migration", result.Collection, result.Changed)
+			changed += result.Changed
+		}
+		for id, docErr := range result.Failed {
+			log.Printf("%s: skipped %s: %v", result.Collection, id.Hex(), docErr)
+			failed++
+		}
+	}
+	if err != nil {
+		log.Fatalf("Money migration failed: %v", err)
+	}
+	if failed > 0 {
+		log.Fatalf("Money migration left %d documents unconverted; fix them and run it again", failed)
+	}
+	if changed > 0 {
+		log.Fatalf("Money migration skipped %d documents that changed while it ran; run it again to convert them", changed)
+	}
+	log.Println("Money migration complete")
+}
@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -15,7 +18,10 @@ import (
 	"github.com/Over-knight/Lujay-assesment/internal/cache"
 	"github.com/Over-knight/Lujay-assesment/internal/config"
 	"github.com/Over-knight/Lujay-assesment/internal/handlers"
+	"github.com/Over-knight/Lujay-assesment/internal/middleware"
+	"github.com/Over-knight/Lujay-assesment/internal/payments"
 	"github.com/Over-knight/Lujay-assesment/internal/routes"
+	"github.com/Over-knight/Lujay-assesment/internal/seal"
 	"github.com/Over-knight/Lujay-assesment/internal/service"
 	"github.com/Over-knight/Lujay-assesment/internal/storage"
 	"github.com/Over-knight/Lujay-assesment/internal/upload"
@@ -92,24 +98,249 @@ func main() {
 		log.Println("Cloudinary credentials not configured. File upload will not be available.")
 	}
 
+	// Load inspection scoring weights
+	scoringConfig, err := service.LoadScoringConfig(cfg.Inspection.ScoringConfigPath)
+	if err != nil {
+		log.Fatalf("Invalid inspection scoring config: %v", err)
+	}
+
+	// Load the exchange rates used until an admin uploads a table
+	fileRates, err := service.LoadExchangeRatesFile(cfg.Pricing.ExchangeRatesPath)
+	if err != nil {
+		log.Fatalf("Invalid exchange rates file: %v", err)
+	}
+
+	// Load the fees, sales tax and commission charged on sales
+	pricingConfig, err := service.LoadPricingRulesConfig(cfg.Pricing.RulesPath)
+	if err != nil {
+		log.Fatalf("Invalid pricing rules: %v", err)
+	}
+
+	// Load the key that seals completed inspections
+	var inspectionSigner *seal.Signer
+	if cfg.Inspection.SigningKey != "" {
+		inspectionSigner, err = seal.ParseSigner(cfg.Inspection.SigningKey)
+		if err != nil {
+			log.Fatalf("Invalid inspection signing key: %v", err)
+		}
+	} else {
+		inspectionSigner, err = seal.GenerateSigner()
+		if err != nil {
+			log.Fatalf("Failed to generate inspection signing key: %v", err)
+		}
+		log.Println("Warning: INSPECTION_SIGNING_KEY not set. Using a temporary key; inspection seals will not verify after a restart.")
+	}
+
+	// Parse escrow timing
+	disputeWindow, err := time.ParseDuration(cfg.Escrow.DisputeWindow)
+	if err != nil {
+		log.Fatalf("Invalid escrow dispute window format: %v", err)
+	}
+	releaseInterval, err := time.ParseDuration(cfg.Escrow.ReleaseInterval)
+	if err != nil || releaseInterval <= 0 {
+		log.Fatalf("Invalid escrow release interval: %q", cfg.Escrow.ReleaseInterval)
+	}
+
+	// Parse installment policy
+	gracePeriod, err := time.ParseDuration(cfg.Installments.GracePeriod)
+	if err != nil {
+		log.Fatalf("Invalid installment grace period format: %v", err)
+	}
+	missedAfter, err := time.ParseDuration(cfg.Installments.MissedAfter)
+	if err != nil {
+		log.Fatalf("Invalid installment missed-after format: %v", err)
+	}
+	lateFeePercent, err := strconv.ParseFloat(cfg.Installments.LateFeePercent, 64)
+	if err != nil || lateFeePercent < 0 {
+		log.Fatalf("Invalid installment late fee percent: %q", cfg.Installments.LateFeePercent)
+	}
+	overdueInterval, err := time.ParseDuration(cfg.Installments.OverdueInterval)
+	if err != nil || overdueInterval <= 0 {
+		log.Fatalf("Invalid installment overdue interval: %q", cfg.Installments.OverdueInterval)
+	}
+
+	// Parse offer timing
+	offerTTL, err := time.ParseDuration(cfg.Offers.TTL)
+	if err != nil || offerTTL <= 0 {
+		log.Fatalf("Invalid offer TTL: %q", cfg.Offers.TTL)
+	}
+	offerExpiryInterval, err := time.ParseDuration(cfg.Offers.ExpiryInterval)
+	if err != nil || offerExpiryInterval <= 0 {
+		log.Fatalf("Invalid offer expiry interval: %q", cfg.Offers.ExpiryInterval)
+	}
+
+	// Parse auction timing
+	auctionCloseInterval, err := time.ParseDuration(cfg.Auctions.CloseInterval)
+	if err != nil || auctionCloseInterval <= 0 {
+		log.Fatalf("Invalid auction close interval: %q", cfg.Auctions.CloseInterval)
+	}
+
+	// Parse reservation hold timing
+	holdTTL, err := time.ParseDuration(cfg.Reservations.HoldTTL)
+	if err != nil || holdTTL <= 0 {
+		log.Fatalf("Invalid reservation hold TTL: %q", cfg.Reservations.HoldTTL)
+	}
+	depositHoldTTL, err := time.ParseDuration(cfg.Reservations.DepositHoldTTL)
+	if err != nil || depositHoldTTL <= 0 {
+		log.Fatalf("Invalid reservation deposit hold TTL: %q", cfg.Reservations.DepositHoldTTL)
+	}
+	holdExpiryInterval, err := time.ParseDuration(cfg.Reservations.ExpiryInterval)
+	if err != nil || holdExpiryInterval <= 0 {
+		log.Fatalf("Invalid reservation expiry interval: %q", cfg.Reservations.ExpiryInterval)
+	}
+
+	// Initialize payment providers
+	webhookSecret := cfg.Payments.MockWebhookSecret
+	if webhookSecret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalf("Failed to generate payment webhook secret: %v", err)
+		}
+		webhookSecret = hex.EncodeToString(secret)
+		log.Println("Warning: PAYMENTS_MOCK_WEBHOOK_SECRET not set. Using a temporary secret for payment webhooks.")
+	}
+	// Intents live in MongoDB so escrow holds and deposits survive restarts and are shared between instances
+	paymentProviders := payments.NewRegistry(payments.NewMockProvider(webhookSecret, payments.NewMongoIntentStore(mongoDB.Collection("payment_intents"))))
+
 	// Initialize services
 	userService := service.NewUserService(mongoDB.Collection("users"), jwtManager)
-	vehicleService := service.NewVehicleService(mongoDB.Collection("vehicles"))
-	inspectionService := service.NewInspectionService(mongoDB.Database)
-	transactionService := service.NewTransactionService(mongoDB.Database)
+	var fallbackRates service.ExchangeRateProvider
+	if fileRates != nil {
+		fallbackRates = fileRates
+	}
+	exchangeRateService := service.NewExchangeRateService(mongoDB.Database, fallbackRates)
+	vehicleService := service.NewVehicleService(mongoDB.Collection("vehicles"), exchangeRateService)
+	availabilityService := service.NewAvailabilityService(mongoDB.Database)
+	templateService := service.NewInspectionTemplateService(mongoDB.Database)
+	var inspectionMedia service.MediaStore
+	if cloudinaryUploader != nil {
+		inspectionMedia = cloudinaryUploader
+	}
+	inspectionService := service.NewInspectionService(mongoDB.Database, availabilityService, templateService, service.NewScoringEngine(scoringConfig), inspectionSigner, inspectionMedia)
+	reportService := service.NewInspectionReportService(mongoDB.Database, inspectionService, redisCache)
+	transactionService := service.NewTransactionService(mongoDB.Database, paymentProviders, disputeWindow, exchangeRateService, service.ReservationPolicy{
+		HoldTTL:        holdTTL,
+		DepositHoldTTL: depositHoldTTL,
+	}, service.NewTablePricingRules(pricingConfig, exchangeRateService))
+	offerService := service.NewOfferService(mongoDB.Database, transactionService, offerTTL)
+	notificationService := service.NewNotificationService(mongoDB.Database)
+	auctionService := service.NewAuctionService(mongoDB.Database, transactionService, notificationService)
+	disputeService := service.NewDisputeService(mongoDB.Database, transactionService, notificationService)
+	ledgerService := service.NewLedgerService(mongoDB.Database)
+	installmentService := service.NewInstallmentService(mongoDB.Database, service.InstallmentPolicy{
+		GracePeriod:    gracePeriod,
+		LateFeePercent: lateFeePercent,
+		MissedAfter:    missedAfter,
+	})
+
+	// Idempotency keys live in Redis when available, otherwise in MongoDB
+	var idempotencyStore middleware.IdempotencyStore
+	var mongoIdempotencyStore *middleware.MongoIdempotencyStore
+	if redisCache != nil {
+		idempotencyStore = middleware.NewRedisIdempotencyStore(redisCache)
+	} else {
+		mongoIdempotencyStore = middleware.NewMongoIdempotencyStore(mongoDB.Collection("idempotency_keys"))
+		idempotencyStore = mongoIdempotencyStore
+	}
+
+	// Slot reservations, template versions, verification codes, webhook events, installments, open offers, open auctions and pending transactions rely on unique indexes
+	indexCtx, indexCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := availabilityService.EnsureIndexes(indexCtx); err != nil {
+		log.Fatalf("Failed to create inspection slot indexes: %v", err)
+	}
+	if err := templateService.EnsureIndexes(indexCtx); err != nil {
+		log.Fatalf("Failed to create inspection template indexes: %v", err)
+	}
+	if err := inspectionService.EnsureIndexes(indexCtx); err != nil {
+		log.Fatalf("Failed to create inspection indexes: %v", err)
+	}
+	if err := transactionService.EnsureIndexes(indexCtx); err != nil {
+		log.Fatalf("Failed to create transaction indexes: %v", err)
+	}
+	if err := installmentService.EnsureIndexes(indexCtx); err != nil {
+		log.Fatalf("Failed to create installment indexes: %v", err)
+	}
+	if err := exchangeRateService.EnsureIndexes(indexCtx); err != nil {
+		log.Fatalf("Failed to create exchange rate indexes: %v", err)
+	}
+	if err := offerService.EnsureIndexes(indexCtx); err != nil {
+		log.Fatalf("Failed to create offer indexes: %v", err)
+	}
+	if err := auctionService.EnsureIndexes(indexCtx); err != nil {
+		log.Fatalf("Failed to create auction indexes: %v", err)
+	}
+	if err := notificationService.EnsureIndexes(indexCtx); err != nil {
+		log.Fatalf("Failed to create notification indexes: %v", err)
+	}
+	if err := disputeService.EnsureIndexes(indexCtx); err != nil {
+		log.Fatalf("Failed to create dispute indexes: %v", err)
+	}
+	if err := ledgerService.EnsureIndexes(indexCtx); err != nil {
+		log.Fatalf("Failed to create ledger indexes: %v", err)
+	}
+	if mongoIdempotencyStore != nil {
+		if err := mongoIdempotencyStore.EnsureIndexes(indexCtx); err != nil {
+			log.Fatalf("Failed to create idempotency key indexes: %v", err)
+		}
+	}
+	indexCancel()
+
+	// Release escrow funds once the buyer's dispute window lapses
+	releaserCtx, stopReleaser := context.WithCancel(context.Background())
+	defer stopReleaser()
+	go service.NewEscrowReleaser(transactionService, releaseInterval).Run(releaserCtx, func(err error) {
+		log.Printf("Error releasing escrow funds: %v", err)
+	})
+
+	// Release vehicles whose reservation hold expired before the sale went through
+	holdExpiryCtx, stopHoldExpiry := context.WithCancel(context.Background())
+	defer stopHoldExpiry()
+	go service.NewHoldExpiryJob(transactionService, holdExpiryInterval).Run(holdExpiryCtx, func(err error) {
+		log.Printf("Error expiring reservation holds: %v", err)
+	})
+
+	// Charge late fees and flag missed installments
+	overdueCtx, stopOverdue := context.WithCancel(context.Background())
+	defer stopOverdue()
+	go service.NewInstallmentOverdueJob(installmentService, overdueInterval).Run(overdueCtx, func(err error) {
+		log.Printf("Error marking overdue installments: %v", err)
+	})
+
+	// Expire offers nobody responded to in time
+	offerExpiryCtx, stopOfferExpiry := context.WithCancel(context.Background())
+	defer stopOfferExpiry()
+	go service.NewOfferExpiryJob(offerService, offerExpiryInterval).Run(offerExpiryCtx, func(err error) {
+		log.Printf("Error expiring offers: %v", err)
+	})
+
+	// Close auctions whose end time has passed
+	auctionCloserCtx, stopAuctionCloser := context.WithCancel(context.Background())
+	defer stopAuctionCloser()
+	go service.NewAuctionCloser(auctionService, auctionCloseInterval).Run(auctionCloserCtx, func(err error) {
+		log.Printf("Error closing auctions: %v", err)
+	})
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(userService)
 	vehicleHandler := handlers.NewVehicleHandler(vehicleService)
-	inspectionHandler := handlers.NewInspectionHandler(inspectionService)
+	inspectionHandler := handlers.NewInspectionHandler(inspectionService, availabilityService, reportService)
+	templateHandler := handlers.NewInspectionTemplateHandler(templateService)
 	transactionHandler := handlers.NewTransactionHandler(transactionService)
-	uploadHandler := handlers.NewUploadHandler(cloudinaryUploader, vehicleService)
+	uploadHandler := handlers.NewUploadHandler(cloudinaryUploader, vehicleService, inspectionService, disputeService)
+	webhookHandler := handlers.NewPaymentWebhookHandler(paymentProviders, transactionService)
+	installmentHandler := handlers.NewInstallmentHandler(installmentService)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(exchangeRateService)
+	offerHandler := handlers.NewOfferHandler(offerService)
+	auctionHandler := handlers.NewAuctionHandler(auctionService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	disputeHandler := handlers.NewDisputeHandler(disputeService)
+	ledgerHandler := handlers.NewLedgerHandler(ledgerService)
 
 	// Initialize Gin router with default middleware (logger and recovery)
 	router := gin.Default()
 
 	// Set up routes with Redis cache
-	routes.SetupRoutes(router, mongoDB, redisCache, authHandler, vehicleHandler, inspectionHandler, transactionHandler, uploadHandler, jwtManager)
+	routes.SetupRoutes(router, mongoDB, redisCache, authHandler, vehicleHandler, inspectionHandler, templateHandler, transactionHandler, installmentHandler, uploadHandler, webhookHandler, exchangeRateHandler, offerHandler, auctionHandler, notificationHandler, disputeHandler, ledgerHandler, idempotencyStore, jwtManager)
 
 	// Create server with graceful shutdown support
 	srv := setupServer(router, cfg.Server.Port)
@@ -128,6 +359,11 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopReleaser()
+	stopHoldExpiry()
+	stopOverdue()
+	stopOfferExpiry()
+	stopAuctionCloser()
 
 	// Graceful shutdown with 5 second timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
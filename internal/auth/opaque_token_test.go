@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"testing"
+)
+
+// TestGenerateOpaqueToken tests that tokens are random and hex encoded
+func TestGenerateOpaqueToken(t *testing.T) {
+	first, err := GenerateOpaqueToken()
+	if err != nil {
+		t.Fatalf("GenerateOpaqueToken() error = %v", err)
+	}
+	second, err := GenerateOpaqueToken()
+	if err != nil {
+		t.Fatalf("GenerateOpaqueToken() error = %v", err)
+	}
+
+	if len(first) != 64 {
+		t.Errorf("GenerateOpaqueToken() length = %d, want 64", len(first))
+	}
+	if first == second {
+		t.Error("GenerateOpaqueToken() returned the same token twice")
+	}
+}
+
+// TestHashOpaqueToken tests that hashing is deterministic and hides the token
+func TestHashOpaqueToken(t *testing.T) {
+	token := "calendar-token"
+
+	if HashOpaqueToken(token) != HashOpaqueToken(token) {
+		t.Error("HashOpaqueToken() is not deterministic")
+	}
+	if HashOpaqueToken(token) == token {
+		t.Error("HashOpaqueToken() returned the token unchanged")
+	}
+	if HashOpaqueToken(token) == HashOpaqueToken("other-token") {
+		t.Error("HashOpaqueToken() collided for different tokens")
+	}
+}
@@ -0,0 +1,126 @@
+package calendar
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event status values defined by RFC 5545
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// maxLineOctets is the longest content line allowed before folding (RFC 5545 section 3.1)
+const maxLineOctets = 75
+
+// utcFormat is the RFC 5545 DATE-TIME form for UTC values
+const utcFormat = "20060102T150405Z"
+
+// Calendar is an iCalendar object published as a feed
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Event is a single VEVENT
+// UID must stay stable across feed refreshes so calendar clients update the event in place
+type Event struct {
+	UID          string
+	Start        time.Time
+	End          time.Time
+	Summary      string
+	Location     string
+	Description  string
+	Status       string // TENTATIVE, CONFIRMED or CANCELLED
+	Sequence     int
+	LastModified time.Time
+}
+
+// Encode renders the calendar as an RFC 5545 document
+// now is used as the DTSTAMP of every event
+func (c *Calendar) Encode(now time.Time) []byte {
+	var buf bytes.Buffer
+
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:"+c.ProdID)
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&buf, "X-WR-CALNAME:"+EscapeText(c.Name))
+	}
+
+	for _, event := range c.Events {
+		event.encode(&buf, now)
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// encode writes the VEVENT component
+func (e *Event) encode(buf *bytes.Buffer, now time.Time) {
+	writeLine(buf, "BEGIN:VEVENT")
+	writeLine(buf, "UID:"+e.UID)
+	writeLine(buf, "DTSTAMP:"+formatUTC(now))
+	writeLine(buf, "DTSTART:"+formatUTC(e.Start))
+	writeLine(buf, "DTEND:"+formatUTC(e.End))
+	writeLine(buf, "SUMMARY:"+EscapeText(e.Summary))
+	if e.Location != "" {
+		writeLine(buf, "LOCATION:"+EscapeText(e.Location))
+	}
+	if e.Description != "" {
+		writeLine(buf, "DESCRIPTION:"+EscapeText(e.Description))
+	}
+	if e.Status != "" {
+		writeLine(buf, "STATUS:"+e.Status)
+	}
+	writeLine(buf, "SEQUENCE:"+strconv.Itoa(e.Sequence))
+	if !e.LastModified.IsZero() {
+		writeLine(buf, "LAST-MODIFIED:"+formatUTC(e.LastModified))
+	}
+	writeLine(buf, "END:VEVENT")
+}
+
+// EscapeText escapes a TEXT property value (RFC 5545 section 3.3.11)
+func EscapeText(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return replacer.Replace(value)
+}
+
+// formatUTC formats a time as an RFC 5545 UTC DATE-TIME
+func formatUTC(t time.Time) string {
+	return t.UTC().Format(utcFormat)
+}
+
+// writeLine writes a content line terminated by CRLF, folding it at 75 octets
+// Continuation lines start with a single space and never split a UTF-8 character
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts toward the next line's length
+		limit = maxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
@@ -0,0 +1,61 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendar_Encode(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.FixedZone("WAT", 3600))
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cal := Calendar{
+		ProdID: "-//LUJAY//Inspections//EN",
+		Name:   "Inspections",
+		Events: []Event{
+			{
+				UID:      "inspection-1@lujay",
+				Start:    start,
+				End:      start.Add(time.Hour),
+				Summary:  "Inspection: 2020 Toyota Camry",
+				Location: "Ikeja, Lagos, Nigeria",
+				Status:   StatusCancelled,
+				Sequence: 3,
+			},
+		},
+	}
+
+	out := string(cal.Encode(now))
+
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Contains(t, out, "UID:inspection-1@lujay\r\n")
+	assert.Contains(t, out, "DTSTAMP:20300101T120000Z\r\n")
+	assert.Contains(t, out, "DTSTART:20300107T090000Z\r\n")
+	assert.Contains(t, out, "DTEND:20300107T100000Z\r\n")
+	assert.Contains(t, out, `LOCATION:Ikeja\, Lagos\, Nigeria`+"\r\n")
+	assert.Contains(t, out, "STATUS:CANCELLED\r\n")
+	assert.Contains(t, out, "SEQUENCE:3\r\n")
+	assert.NotContains(t, strings.ReplaceAll(out, "\r\n", ""), "\n")
+}
+
+func TestEscapeText(t *testing.T) {
+	assert.Equal(t, `a\\b\;c\,d\ne`, EscapeText("a\\b;c,d\ne"))
+}
+
+func TestWriteLine_Folds(t *testing.T) {
+	cal := Calendar{ProdID: "x", Name: strings.Repeat("é", 100)}
+	out := string(cal.Encode(time.Now()))
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets)
+		assert.True(t, utf8.ValidString(line), "folded line split a UTF-8 character: %q", line)
+	}
+
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	assert.Contains(t, unfolded, "X-WR-CALNAME:"+strings.Repeat("é", 100)+"\r\n")
+}
@@ -6,11 +6,19 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	Server     ServerConfig
-	MongoDB    MongoDBConfig
-	Redis      RedisConfig
-	JWT        JWTConfig
-	Cloudinary CloudinaryConfig
+	Server       ServerConfig
+	MongoDB      MongoDBConfig
+	Redis        RedisConfig
+	JWT          JWTConfig
+	Cloudinary   CloudinaryConfig
+	Inspection   InspectionConfig
+	Escrow       EscrowConfig
+	Payments     PaymentsConfig
+	Installments InstallmentsConfig
+	Pricing      PricingConfig
+	Offers       OffersConfig
+	Auctions     AuctionsConfig
+	Reservations ReservationsConfig
 }
 
 // ServerConfig holds server-specific configuration
@@ -48,6 +56,55 @@ type CloudinaryConfig struct {
 	Folder    string
 }
 
+// InspectionConfig holds inspection scoring and sealing configuration
+type InspectionConfig struct {
+	ScoringConfigPath string // JSON file overriding the default scoring weights
+	SigningKey        string // base64 Ed25519 seed used to seal completed inspections
+}
+
+// EscrowConfig holds escrow release timing configuration
+type EscrowConfig struct {
+	DisputeWindow   string // how long after delivery the buyer can dispute before funds are released
+	ReleaseInterval string // how often lapsed dispute windows are checked
+}
+
+// PaymentsConfig holds payment provider configuration
+type PaymentsConfig struct {
+	MockWebhookSecret string // HMAC secret the mock provider signs webhooks with
+}
+
+// InstallmentsConfig holds installment late fee and overdue tracking configuration
+type InstallmentsConfig struct {
+	GracePeriod     string // how long after the due date an unpaid installment becomes late
+	LateFeePercent  string // late fee as a percentage of the scheduled payment
+	MissedAfter     string // how long after the due date a late installment is considered missed
+	OverdueInterval string // how often overdue installments are checked
+}
+
+// PricingConfig holds multi-currency pricing configuration
+type PricingConfig struct {
+	ExchangeRatesPath string // JSON rate table used until an admin uploads one
+	RulesPath         string // JSON file with the fees, sales tax and commission charged on sales
+}
+
+// OffersConfig holds offer negotiation timing configuration
+type OffersConfig struct {
+	TTL            string // how long the other party has to respond to an offer or counter-offer
+	ExpiryInterval string // how often lapsed offers are expired
+}
+
+// AuctionsConfig holds auction closing configuration
+type AuctionsConfig struct {
+	CloseInterval string // how often ended auctions are closed
+}
+
+// ReservationsConfig holds vehicle reservation hold configuration
+type ReservationsConfig struct {
+	HoldTTL        string // how long a new transaction keeps its vehicle reserved
+	DepositHoldTTL string // how long the hold lasts once the buyer pays a deposit
+	ExpiryInterval string // how often expired holds are released
+}
+
 // Load reads configuration from environment variables
 // Returns a Config struct with all application settings
 func Load() *Config {
@@ -77,6 +134,39 @@ func Load() *Config {
 			APISecret: getEnv("CLOUDINARY_API_SECRET", ""),
 			Folder:    getEnv("CLOUDINARY_FOLDER", "lujay/vehicles"),
 		},
+		Inspection: InspectionConfig{
+			ScoringConfigPath: getEnv("INSPECTION_SCORING_CONFIG", ""),
+			SigningKey:        getEnv("INSPECTION_SIGNING_KEY", ""),
+		},
+		Escrow: EscrowConfig{
+			DisputeWindow:   getEnv("ESCROW_DISPUTE_WINDOW", "72h"),
+			ReleaseInterval: getEnv("ESCROW_RELEASE_INTERVAL", "5m"),
+		},
+		Payments: PaymentsConfig{
+			MockWebhookSecret: getEnv("PAYMENTS_MOCK_WEBHOOK_SECRET", ""),
+		},
+		Installments: InstallmentsConfig{
+			GracePeriod:     getEnv("INSTALLMENT_GRACE_PERIOD", "120h"),
+			LateFeePercent:  getEnv("INSTALLMENT_LATE_FEE_PERCENT", "5"),
+			MissedAfter:     getEnv("INSTALLMENT_MISSED_AFTER", "720h"),
+			OverdueInterval: getEnv("INSTALLMENT_OVERDUE_INTERVAL", "24h"),
+		},
+		Pricing: PricingConfig{
+			ExchangeRatesPath: getEnv("EXCHANGE_RATES_PATH", ""),
+			RulesPath:         getEnv("PRICING_RULES_PATH", ""),
+		},
+		Offers: OffersConfig{
+			TTL:            getEnv("OFFER_TTL", "48h"),
+			ExpiryInterval: getEnv("OFFER_EXPIRY_INTERVAL", "15m"),
+		},
+		Auctions: AuctionsConfig{
+			CloseInterval: getEnv("AUCTION_CLOSE_INTERVAL", "1m"),
+		},
+		Reservations: ReservationsConfig{
+			HoldTTL:        getEnv("RESERVATION_HOLD_TTL", "48h"),
+			DepositHoldTTL: getEnv("RESERVATION_DEPOSIT_HOLD_TTL", "168h"),
+			ExpiryInterval: getEnv("RESERVATION_EXPIRY_INTERVAL", "5m"),
+		},
 	}
 }
 
@@ -53,6 +53,8 @@ const (
 	ErrCodeInvalidCredentials      = "INVALID_CREDENTIALS"
 	ErrCodeResourceExists          = "RESOURCE_EXISTS"
 	ErrCodeInsufficientPermissions = "INSUFFICIENT_PERMISSIONS"
+	ErrCodeInvalidStateTransition  = "INVALID_STATE_TRANSITION"
+	ErrCodePaymentFailed           = "PAYMENT_FAILED"
 )
 
 // Predefined errors
@@ -151,6 +153,24 @@ func NewConflictError(message string) *AppError {
 	}
 }
 
+// NewInvalidStateTransitionError creates an error for a rejected status transition
+func NewInvalidStateTransitionError(message string) *AppError {
+	return &AppError{
+		Code:       ErrCodeInvalidStateTransition,
+		Message:    message,
+		StatusCode: http.StatusConflict,
+	}
+}
+
+// NewPaymentError creates an error for a payment the provider rejected or failed to process
+func NewPaymentError(message string) *AppError {
+	return &AppError{
+		Code:       ErrCodePaymentFailed,
+		Message:    message,
+		StatusCode: http.StatusPaymentRequired,
+	}
+}
+
 // NewDatabaseError creates a database error
 func NewDatabaseError(message string) *AppError {
 	return &AppError{
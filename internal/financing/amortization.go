@@ -0,0 +1,251 @@
+package financing
+
+import (
+	"errors"
+	"math"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// Compounding conventions for the nominal annual interest rate
+const (
+	CompoundingMonthly    = "monthly"
+	CompoundingDaily      = "daily"
+	CompoundingQuarterly  = "quarterly"
+	CompoundingSemiAnnual = "semiannual"
+	CompoundingAnnual     = "annual"
+	CompoundingContinuous = "continuous"
+)
+
+// PaymentsPerYear is the number of installments in a year; payments are always monthly
+const PaymentsPerYear = 12
+
+// compoundingPerYear maps each discrete convention to its compounding periods per year
+var compoundingPerYear = map[string]float64{
+	CompoundingMonthly:    12,
+	CompoundingDaily:      365,
+	CompoundingQuarterly:  4,
+	CompoundingSemiAnnual: 2,
+	CompoundingAnnual:     1,
+}
+
+// Terms describe a loan to amortize
+type Terms struct {
+	Principal   money.Money // amount financed
+	AnnualRate  float64     // nominal annual interest rate in percent
+	Months      float64     // term in months; a fractional part becomes a shorter final period
+	Balloon     money.Money // lump sum due with the final installment
+	Compounding string      // compounding convention for AnnualRate, monthly when empty
+	Fees        money.Money // finance charges paid at signing, which raise the APR
+}
+
+// Period is one installment of the schedule
+type Period struct {
+	Number    int         `json:"number"`
+	Payment   money.Money `json:"payment"`
+	Principal money.Money `json:"principal"`
+	Interest  money.Money `json:"interest"`
+	Balance   money.Money `json:"balance"` // outstanding after this payment
+}
+
+// Schedule is a full amortization plan
+// Every amount is in whole minor units, so the periods always add up to the totals
+type Schedule struct {
+	Principal     money.Money `json:"principal"`
+	AnnualRate    float64     `json:"annualRate"`
+	Months        float64     `json:"months"`
+	Compounding   string      `json:"compounding"`
+	Balloon       money.Money `json:"balloon,omitzero"`
+	Payment       money.Money `json:"payment"`      // regular monthly installment
+	FinalPayment  money.Money `json:"finalPayment"` // last installment, including any balloon
+	TotalPaid     money.Money `json:"totalPaid"`
+	TotalInterest money.Money `json:"totalInterest"`
+	APR           float64     `json:"apr"` // annual percentage rate, including fees
+	Periods       []Period    `json:"periods"`
+}
+
+// IsValidCompounding checks if the given compounding convention is supported
+func IsValidCompounding(compounding string) bool {
+	if compounding == CompoundingContinuous {
+		return true
+	}
+	_, ok := compoundingPerYear[compounding]
+	return ok
+}
+
+// Validate checks that the terms describe a loan that can be amortized
+func (t Terms) Validate() error {
+	if !t.Principal.IsPositive() {
+		return errors.New("financed amount must be greater than 0")
+	}
+	if t.Principal.Currency() == "" {
+		return errors.New("financed amount must have a currency")
+	}
+	if t.AnnualRate < 0 {
+		return errors.New("interest rate cannot be negative")
+	}
+	if t.Months < 1 {
+		return errors.New("term must be at least one month")
+	}
+	if t.Balloon.IsNegative() {
+		return errors.New("balloon payment cannot be negative")
+	}
+	if cmp, err := t.Balloon.Cmp(t.Principal); err != nil {
+		return err
+	} else if cmp >= 0 {
+		return errors.New("balloon payment must be less than the financed amount")
+	}
+	if t.Fees.IsNegative() {
+		return errors.New("fees cannot be negative")
+	}
+	if cmp, err := t.Fees.Cmp(t.Principal); err != nil {
+		return err
+	} else if cmp >= 0 {
+		return errors.New("fees must be less than the financed amount")
+	}
+	if t.Compounding != "" && !IsValidCompounding(t.Compounding) {
+		return errors.New("compounding must be one of: monthly, daily, quarterly, semiannual, annual, continuous")
+	}
+	return nil
+}
+
+// PeriodicRate converts the nominal annual rate to the equivalent rate per monthly payment period
+func (t Terms) PeriodicRate() float64 {
+	rate := t.AnnualRate / 100
+	if rate == 0 {
+		return 0
+	}
+	if t.Compounding == CompoundingContinuous {
+		return math.Expm1(rate / PaymentsPerYear)
+	}
+
+	perYear, ok := compoundingPerYear[t.Compounding]
+	if !ok {
+		perYear = compoundingPerYear[CompoundingMonthly]
+	}
+	return math.Pow(1+rate/perYear, perYear/PaymentsPerYear) - 1
+}
+
+// Amortize builds the payment schedule for the terms
+// Every installment is the same except the last, which absorbs rounding, any shorter final period and the balloon
+// Interest is rounded half up to the minor unit each period, and balances are tracked exactly in minor units
+func Amortize(t Terms) (*Schedule, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	if t.Compounding == "" {
+		t.Compounding = CompoundingMonthly
+	}
+
+	currency := t.Principal.Currency()
+	rate := t.PeriodicRate()
+	count, final := splitTerm(t.Months)
+	payment, err := money.FromFloat(levelPayment(t.Principal.Float64(), t.Balloon.Float64(), rate, count, final), currency, money.RoundHalfUp)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &Schedule{
+		Principal:   t.Principal,
+		AnnualRate:  t.AnnualRate,
+		Months:      t.Months,
+		Compounding: t.Compounding,
+		Balloon:     t.Balloon,
+		Payment:     payment,
+		Periods:     make([]Period, 0, count),
+	}
+
+	balance := t.Principal.Minor()
+	var totalPaid, totalInterest int64
+	for n := 1; n <= count; n++ {
+		outstanding := money.New(balance, currency)
+		factor := rate
+		if n == count {
+			// The last period may be shorter than a month, and it pays off whatever remains
+			factor = math.Pow(1+rate, final) - 1
+		}
+		accrued, err := outstanding.MulFloat(factor, money.RoundHalfUp)
+		if err != nil {
+			return nil, err
+		}
+		interest, paid := accrued.Minor(), payment.Minor()
+		if n == count {
+			paid = balance + interest
+		}
+		balance -= paid - interest
+
+		schedule.Periods = append(schedule.Periods, Period{
+			Number:    n,
+			Payment:   money.New(paid, currency),
+			Principal: money.New(paid-interest, currency),
+			Interest:  money.New(interest, currency),
+			Balance:   money.New(balance, currency),
+		})
+		totalPaid += paid
+		totalInterest += interest
+	}
+
+	schedule.FinalPayment = schedule.Periods[count-1].Payment
+	schedule.TotalPaid = money.New(totalPaid, currency)
+	schedule.TotalInterest = money.New(totalInterest, currency)
+	schedule.APR = annualPercentageRate(t.Principal.Float64()-t.Fees.Float64(), schedule.Periods, final)
+
+	return schedule, nil
+}
+
+// splitTerm returns the number of installments and the length of the last one in periods
+// 30 months is 30 full periods; 15.6 months is 16 installments, the last covering 0.6 of a month
+func splitTerm(months float64) (int, float64) {
+	// Tolerate floating point noise from year to month conversions, such as 2.6 * 12
+	whole := math.Round(months)
+	if math.Abs(months-whole) < 1e-9 {
+		return int(whole), 1
+	}
+	count := math.Ceil(months)
+	return int(count), months - (count - 1)
+}
+
+// levelPayment solves for the installment that repays principal down to the balloon
+// The final installment is discounted over its shorter period, then the balloon is paid with it
+func levelPayment(principal, balloon, rate float64, count int, final float64) float64 {
+	if rate == 0 {
+		return (principal - balloon) / (float64(count-1) + final)
+	}
+
+	v := 1 / (1 + rate)
+	lastDiscount := math.Pow(v, float64(count-1)+final)
+	annuity := (1-math.Pow(v, float64(count-1)))/rate + lastDiscount
+	return (principal - balloon*lastDiscount) / annuity
+}
+
+// annualPercentageRate finds the yearly rate at which the payments repay the amount received
+// It is the periodic internal rate of return times the payments per year, in percent
+func annualPercentageRate(received float64, periods []Period, final float64) float64 {
+	presentValue := func(rate float64) float64 {
+		pv := 0.0
+		for i, p := range periods {
+			t := float64(i + 1)
+			if i == len(periods)-1 {
+				t = float64(i) + final
+			}
+			pv += p.Payment.Float64() / math.Pow(1+rate, t)
+		}
+		return pv
+	}
+
+	// Present value falls as the rate rises, so bisect until it matches what the buyer received
+	low, high := 0.0, 1.0
+	if presentValue(low) <= received {
+		return 0
+	}
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		if presentValue(mid) > received {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	return math.Round((low+high)/2*PaymentsPerYear*100*1000) / 1000
+}
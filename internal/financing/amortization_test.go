@@ -0,0 +1,148 @@
+package financing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// usd parses a dollar amount
+func usd(amount string) money.Money {
+	return money.MustParse(amount, "USD")
+}
+
+func TestAmortize_StandardLoan(t *testing.T) {
+	schedule, err := Amortize(Terms{Principal: usd("20000"), AnnualRate: 6, Months: 60})
+	require.NoError(t, err)
+
+	assert.Equal(t, usd("386.66"), schedule.Payment)
+	assert.Len(t, schedule.Periods, 60)
+	assert.Equal(t, CompoundingMonthly, schedule.Compounding)
+	assert.Equal(t, 6.0, schedule.APR)
+
+	first := schedule.Periods[0]
+	assert.Equal(t, usd("100.00"), first.Interest)
+	assert.Equal(t, usd("286.66"), first.Principal)
+	assert.Equal(t, usd("19713.34"), first.Balance)
+
+	last := schedule.Periods[59]
+	assert.True(t, last.Balance.IsZero())
+	assert.InDelta(t, 386.66, last.Payment.Float64(), 1)
+	assertReconciles(t, schedule)
+}
+
+func TestAmortize_ZeroRate(t *testing.T) {
+	schedule, err := Amortize(Terms{Principal: usd("12000"), Months: 12})
+	require.NoError(t, err)
+
+	assert.Equal(t, usd("1000"), schedule.Payment)
+	assert.True(t, schedule.TotalInterest.IsZero())
+	assert.Equal(t, usd("12000"), schedule.TotalPaid)
+	assert.Equal(t, 0.0, schedule.APR)
+}
+
+func TestAmortize_FractionalTerm(t *testing.T) {
+	// 2.5 years is exactly 30 monthly payments
+	whole, err := Amortize(Terms{Principal: usd("15000"), AnnualRate: 5, Months: 2.5 * 12})
+	require.NoError(t, err)
+	assert.Len(t, whole.Periods, 30)
+
+	// 15.5 months is 15 full payments and a half-month stub
+	stub, err := Amortize(Terms{Principal: usd("15000"), AnnualRate: 5, Months: 15.5})
+	require.NoError(t, err)
+	require.Len(t, stub.Periods, 16)
+
+	last := stub.Periods[15]
+	assert.True(t, last.Balance.IsZero())
+	assert.Less(t, last.Payment.Minor(), stub.Payment.Minor())
+	assert.LessOrEqual(t, last.Interest.Minor(), stub.Periods[14].Interest.Minor()/2+1)
+	assertReconciles(t, stub)
+}
+
+func TestAmortize_Balloon(t *testing.T) {
+	plain, err := Amortize(Terms{Principal: usd("20000"), AnnualRate: 6, Months: 60})
+	require.NoError(t, err)
+	balloon, err := Amortize(Terms{Principal: usd("20000"), AnnualRate: 6, Months: 60, Balloon: usd("5000")})
+	require.NoError(t, err)
+
+	assert.Less(t, balloon.Payment.Minor(), plain.Payment.Minor())
+	assert.InDelta(t, balloon.Payment.Float64()+5000, balloon.FinalPayment.Float64(), 1)
+	assert.True(t, balloon.Periods[59].Balance.IsZero())
+	assert.Greater(t, balloon.TotalInterest.Minor(), plain.TotalInterest.Minor())
+	assertReconciles(t, balloon)
+}
+
+func TestAmortize_Compounding(t *testing.T) {
+	payments := map[string]int64{}
+	for _, compounding := range []string{CompoundingAnnual, CompoundingMonthly, CompoundingDaily, CompoundingContinuous} {
+		schedule, err := Amortize(Terms{Principal: usd("20000"), AnnualRate: 6, Months: 60, Compounding: compounding})
+		require.NoError(t, err)
+		payments[compounding] = schedule.Payment.Minor()
+	}
+
+	// More frequent compounding costs more for the same nominal rate
+	assert.Less(t, payments[CompoundingAnnual], payments[CompoundingMonthly])
+	assert.Less(t, payments[CompoundingMonthly], payments[CompoundingDaily])
+	assert.LessOrEqual(t, payments[CompoundingDaily], payments[CompoundingContinuous])
+}
+
+func TestAmortize_FeesRaiseAPR(t *testing.T) {
+	schedule, err := Amortize(Terms{Principal: usd("20000"), AnnualRate: 6, Months: 60, Fees: usd("500")})
+	require.NoError(t, err)
+
+	assert.Equal(t, usd("386.66"), schedule.Payment)
+	assert.Greater(t, schedule.APR, 6.9)
+	assert.Less(t, schedule.APR, 7.1)
+}
+
+func TestAmortize_ZeroDecimalCurrency(t *testing.T) {
+	schedule, err := Amortize(Terms{Principal: money.MustParse("2000000", "JPY"), AnnualRate: 3, Months: 24})
+	require.NoError(t, err)
+
+	assert.Equal(t, "JPY", schedule.Payment.Currency())
+	assertReconciles(t, schedule)
+}
+
+// assertReconciles checks that the periods add up to the principal and the totals to the cent
+func assertReconciles(t *testing.T, schedule *Schedule) {
+	t.Helper()
+
+	var principal, interest, paid int64
+	for _, period := range schedule.Periods {
+		assert.Equal(t, period.Payment.Minor(), period.Principal.Minor()+period.Interest.Minor())
+		principal += period.Principal.Minor()
+		interest += period.Interest.Minor()
+		paid += period.Payment.Minor()
+	}
+
+	assert.Equal(t, schedule.Principal.Minor(), principal)
+	assert.Equal(t, schedule.TotalInterest.Minor(), interest)
+	assert.Equal(t, schedule.TotalPaid.Minor(), paid)
+	assert.Equal(t, schedule.TotalPaid.Minor(), schedule.Principal.Minor()+schedule.TotalInterest.Minor())
+}
+
+func TestAmortize_InvalidTerms(t *testing.T) {
+	tests := []struct {
+		name   string
+		terms  Terms
+		errMsg string
+	}{
+		{"no principal", Terms{Months: 12}, "financed amount must be greater than 0"},
+		{"no currency", Terms{Principal: money.MustParse("1000", ""), Months: 12}, "financed amount must have a currency"},
+		{"negative rate", Terms{Principal: usd("1000"), AnnualRate: -1, Months: 12}, "interest rate cannot be negative"},
+		{"term under a month", Terms{Principal: usd("1000"), Months: 0.5}, "term must be at least one month"},
+		{"balloon too large", Terms{Principal: usd("1000"), Months: 12, Balloon: usd("1000")}, "balloon payment must be less than the financed amount"},
+		{"unknown compounding", Terms{Principal: usd("1000"), Months: 12, Compounding: "weekly"}, "compounding must be one of: monthly, daily, quarterly, semiannual, annual, continuous"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Amortize(tt.terms)
+			require.Error(t, err)
+			assert.Equal(t, tt.errMsg, err.Error())
+		})
+	}
+}
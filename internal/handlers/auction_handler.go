@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/service"
+)
+
+// AuctionHandler handles auction HTTP requests
+type AuctionHandler struct {
+	service *service.AuctionService
+}
+
+// NewAuctionHandler creates a new auction handler
+func NewAuctionHandler(service *service.AuctionService) *AuctionHandler {
+	return &AuctionHandler{
+		service: service,
+	}
+}
+
+// CreateAuction handles POST /vehicles/:id/auction
+func (h *AuctionHandler) CreateAuction(c *gin.Context) {
+	var req models.CreateAuctionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	auction, err := h.service.CreateAuction(c.Request.Context(), c.Param("id"), &req, userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, auction)
+}
+
+// ListAuctions handles GET /auctions
+// Lists open auctions, ending soonest first
+func (h *AuctionHandler) ListAuctions(c *gin.Context) {
+	auctions, err := h.service.ListOpenAuctions(c.Request.Context())
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"auctions": auctions,
+		"count":    len(auctions),
+	})
+}
+
+// GetAuction handles GET /auctions/:id
+func (h *AuctionHandler) GetAuction(c *gin.Context) {
+	auction, err := h.service.GetAuction(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"auction":    auction,
+		"minimumBid": auction.MinimumBid(),
+	})
+}
+
+// GetBids handles GET /auctions/:id/bids
+func (h *AuctionHandler) GetBids(c *gin.Context) {
+	bids, err := h.service.GetBids(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bids":  bids,
+		"count": len(bids),
+	})
+}
+
+// PlaceBid handles POST /auctions/:id/bids
+func (h *AuctionHandler) PlaceBid(c *gin.Context) {
+	var req models.PlaceBidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	auction, bid, err := h.service.PlaceBid(c.Request.Context(), c.Param("id"), &req, userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"auction": auction,
+		"bid":     bid,
+	})
+}
+
+// CancelAuction handles POST /auctions/:id/cancel
+func (h *AuctionHandler) CancelAuction(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	auction, err := h.service.CancelAuction(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, auction)
+}
+
+// respondWithError maps auction errors to HTTP responses
+func (h *AuctionHandler) respondWithError(c *gin.Context, err error) {
+	if respondWithAppError(c, err) {
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -81,6 +81,38 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, authResponse)
 }
 
+// RotateCalendarToken handles requests to issue a new calendar feed token
+// POST /api/v1/auth/calendar-token
+// Requires authentication middleware
+func (h *AuthHandler) RotateCalendarToken(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	token, err := h.userService.RotateCalendarToken(c.Request.Context(), userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to issue calendar token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.CalendarTokenResponse{
+		Token:   token,
+		FeedURL: "/api/v1/inspections/calendar.ics?token=" + token,
+	})
+}
+
 // GetProfile handles requests to get the current user's profile
 // GET /api/v1/auth/profile
 // Requires authentication middleware
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/service"
+)
+
+// DisputeHandler handles transaction dispute HTTP requests
+type DisputeHandler struct {
+	service *service.DisputeService
+}
+
+// NewDisputeHandler creates a new dispute handler
+func NewDisputeHandler(service *service.DisputeService) *DisputeHandler {
+	return &DisputeHandler{
+		service: service,
+	}
+}
+
+// OpenDispute handles POST /transactions/:id/disputes
+func (h *DisputeHandler) OpenDispute(c *gin.Context) {
+	var req models.OpenDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	dispute, err := h.service.OpenDispute(c.Request.Context(), c.Param("id"), &req, userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dispute)
+}
+
+// GetTransactionDisputes handles GET /transactions/:id/disputes
+func (h *DisputeHandler) GetTransactionDisputes(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	disputes, err := h.service.GetTransactionDisputes(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"disputes": disputes,
+		"count":    len(disputes),
+	})
+}
+
+// GetDispute handles GET /disputes/:id
+func (h *DisputeHandler) GetDispute(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	dispute, err := h.service.GetDispute(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+// AddMessage handles POST /disputes/:id/messages
+func (h *DisputeHandler) AddMessage(c *gin.Context) {
+	var req models.DisputeMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	dispute, err := h.service.AddMessage(c.Request.Context(), c.Param("id"), &req, userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+// ListDisputes handles GET /admin/disputes
+// Lists disputes oldest first, optionally filtered by ?status=
+func (h *DisputeHandler) ListDisputes(c *gin.Context) {
+	disputes, err := h.service.ListDisputes(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"disputes": disputes,
+		"count":    len(disputes),
+	})
+}
+
+// StartReview handles POST /admin/disputes/:id/review
+func (h *DisputeHandler) StartReview(c *gin.Context) {
+	adminID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	dispute, err := h.service.StartReview(c.Request.Context(), c.Param("id"), adminID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+// ResolveDispute handles POST /admin/disputes/:id/resolve
+func (h *DisputeHandler) ResolveDispute(c *gin.Context) {
+	var req models.ResolveDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	dispute, transaction, err := h.service.ResolveDispute(c.Request.Context(), c.Param("id"), &req, adminID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dispute":     dispute,
+		"transaction": transaction,
+	})
+}
+
+// respondWithError maps dispute errors to HTTP responses
+func (h *DisputeHandler) respondWithError(c *gin.Context, err error) {
+	if respondWithAppError(c, err) {
+		return
+	}
+	switch err.Error() {
+	case "invalid transaction ID":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case "transaction not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/middleware"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/service"
+)
+
+// ExchangeRateHandler handles exchange rate HTTP requests
+type ExchangeRateHandler struct {
+	service *service.ExchangeRateService
+}
+
+// NewExchangeRateHandler creates a new exchange rate handler
+func NewExchangeRateHandler(service *service.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		service: service,
+	}
+}
+
+// GetRates handles GET /exchange-rates
+// Returns the rate table currently used to convert prices
+func (h *ExchangeRateHandler) GetRates(c *gin.Context) {
+	table, err := h.service.Rates(c.Request.Context())
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve exchange rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, table)
+}
+
+// UploadRates handles PUT /admin/exchange-rates
+// Stores a new rate table that replaces the current one once it takes effect
+func (h *ExchangeRateHandler) UploadRates(c *gin.Context) {
+	var req models.UploadExchangeRatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, err := primitive.ObjectIDFromHex(middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	table, err := h.service.UploadRates(c.Request.Context(), &req, adminID)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save exchange rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Exchange rates saved successfully",
+		"exchangeRates": table,
+	})
+}
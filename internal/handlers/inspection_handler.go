@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -14,13 +16,17 @@ import (
 
 // InspectionHandler handles inspection-related HTTP requests
 type InspectionHandler struct {
-	service *service.InspectionService
+	service      *service.InspectionService
+	availability *service.AvailabilityService
+	reports      *service.InspectionReportService
 }
 
 // NewInspectionHandler creates a new inspection handler
-func NewInspectionHandler(service *service.InspectionService) *InspectionHandler {
+func NewInspectionHandler(service *service.InspectionService, availability *service.AvailabilityService, reports *service.InspectionReportService) *InspectionHandler {
 	return &InspectionHandler{
-		service: service,
+		service:      service,
+		availability: availability,
+		reports:      reports,
 	}
 }
 
@@ -37,21 +43,28 @@ func (h *InspectionHandler) CreateInspection(c *gin.Context) {
 		return
 	}
 
-	// Get inspector ID from context
+	// The caller requests the inspection; an inspector is assigned separately
 	userIDStr := middleware.GetUserID(c)
 	if userIDStr == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	inspectorID, err := primitive.ObjectIDFromHex(userIDStr)
+	requesterID, err := primitive.ObjectIDFromHex(userIDStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
 		return
 	}
 
-	inspection, err := h.service.CreateInspection(c.Request.Context(), &req, inspectorID)
+	inspection, err := h.service.CreateInspection(c.Request.Context(), &req, requesterID)
 	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		if err.Error() == "vehicle not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -76,6 +89,44 @@ func (h *InspectionHandler) GetInspection(c *gin.Context) {
 	c.JSON(http.StatusOK, inspection)
 }
 
+// GetInspectionReport handles GET /inspections/:id/report.pdf
+func (h *InspectionHandler) GetInspectionReport(c *gin.Context) {
+	id := c.Param("id")
+
+	data, err := h.reports.RenderReport(c.Request.Context(), id)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		switch err.Error() {
+		case "inspection not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "invalid inspection ID":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="inspection-%s.pdf"`, id))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// VerifyInspection handles GET /verify/inspections/:code
+func (h *InspectionHandler) VerifyInspection(c *gin.Context) {
+	verification, err := h.service.VerifyInspection(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
 // GetInspectionsByVehicle handles GET /vehicles/:id/inspections
 func (h *InspectionHandler) GetInspectionsByVehicle(c *gin.Context) {
 	vehicleID := c.Param("id")
@@ -118,6 +169,206 @@ func (h *InspectionHandler) GetMyInspections(c *gin.Context) {
 	})
 }
 
+// GetCalendarFeed handles GET /inspections/calendar.ics?token=
+func (h *InspectionHandler) GetCalendarFeed(c *gin.Context) {
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	feed, err := h.service.CalendarFeed(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", feed.Encode(time.Now()))
+}
+
+// GetInspectorQueue handles GET /inspectors/me/queue
+func (h *InspectionHandler) GetInspectorQueue(c *gin.Context) {
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	inspectorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	inspections, err := h.service.GetInspectorQueue(c.Request.Context(), inspectorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"inspections": inspections,
+		"count":       len(inspections),
+	})
+}
+
+// ListAvailableSlots handles GET /inspections/slots?vehicleId=&from=&to=
+func (h *InspectionHandler) ListAvailableSlots(c *gin.Context) {
+	vehicleID := c.Query("vehicleId")
+	if vehicleID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "vehicleId is required"})
+		return
+	}
+
+	var from, to time.Time
+	var err error
+	if value := c.Query("from"); value != "" {
+		if from, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 timestamp"})
+			return
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 timestamp"})
+			return
+		}
+	}
+
+	slots, err := h.service.AvailableSlots(c.Request.Context(), vehicleID, from, to)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		switch err.Error() {
+		case "invalid vehicleId":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "vehicle not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"slots": slots,
+		"count": len(slots),
+	})
+}
+
+// GetMyAvailability handles GET /inspectors/me/availability
+func (h *InspectionHandler) GetMyAvailability(c *gin.Context) {
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	inspectorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	availability, err := h.availability.GetAvailability(c.Request.Context(), inspectorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+// SetMyAvailability handles PUT /inspectors/me/availability
+func (h *InspectionHandler) SetMyAvailability(c *gin.Context) {
+	var req models.SetAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	inspectorID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	availability, err := h.availability.SetAvailability(c.Request.Context(), inspectorID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+// AssignInspector handles PUT /admin/inspections/:id/inspector
+func (h *InspectionHandler) AssignInspector(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.AssignInspectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adminID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	inspection, err := h.service.AssignInspector(c.Request.Context(), id, &req, adminID)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		switch err.Error() {
+		case "inspection not found", "inspector not found", "vehicle not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "invalid inspectorId", "user is not an inspector":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "no inspector available for this location":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, inspection)
+}
+
 // UpdateInspection handles PUT /inspections/:id
 func (h *InspectionHandler) UpdateInspection(c *gin.Context) {
 	id := c.Param("id")
@@ -133,12 +384,31 @@ func (h *InspectionHandler) UpdateInspection(c *gin.Context) {
 		return
 	}
 
-	inspection, err := h.service.UpdateInspection(c.Request.Context(), id, &req)
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	inspection, err := h.service.UpdateInspection(c.Request.Context(), id, &req, userID)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
 		if err.Error() == "inspection not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if err.Error() == "you are not authorized to modify this inspection" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -161,12 +431,114 @@ func (h *InspectionHandler) CompleteInspection(c *gin.Context) {
 		return
 	}
 
-	inspection, err := h.service.CompleteInspection(c.Request.Context(), id, &req)
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	inspection, err := h.service.CompleteInspection(c.Request.Context(), id, &req, userID)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		if err.Error() == "inspection not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "you are not authorized to modify this inspection" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, inspection)
+}
+
+// StartInspection handles POST /inspections/:id/start
+func (h *InspectionHandler) StartInspection(c *gin.Context) {
+	id := c.Param("id")
+
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	inspection, err := h.service.StartInspection(c.Request.Context(), id, userID)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		if err.Error() == "inspection not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "you are not authorized to modify this inspection" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, inspection)
+}
+
+// RescheduleInspection handles POST /inspections/:id/reschedule
+func (h *InspectionHandler) RescheduleInspection(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.RescheduleInspectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	inspection, err := h.service.RescheduleInspection(c.Request.Context(), id, &req, userID)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
 		if err.Error() == "inspection not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if err.Error() == "you are not authorized to modify this inspection" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -188,12 +560,31 @@ func (h *InspectionHandler) CancelInspection(c *gin.Context) {
 		return
 	}
 
-	inspection, err := h.service.CancelInspection(c.Request.Context(), id, req.Notes)
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	inspection, err := h.service.CancelInspection(c.Request.Context(), id, req.Notes, userID)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
 		if err.Error() == "inspection not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if err.Error() == "you are not authorized to modify this inspection" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/middleware"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/service"
+)
+
+// InspectionTemplateHandler handles inspection checklist template HTTP requests
+type InspectionTemplateHandler struct {
+	service *service.InspectionTemplateService
+}
+
+// NewInspectionTemplateHandler creates a new inspection template handler
+func NewInspectionTemplateHandler(service *service.InspectionTemplateService) *InspectionTemplateHandler {
+	return &InspectionTemplateHandler{
+		service: service,
+	}
+}
+
+// SaveTemplate handles POST /admin/inspection-templates
+// Creates the template, or publishes a new version when the key already exists
+func (h *InspectionTemplateHandler) SaveTemplate(c *gin.Context) {
+	var req models.SaveInspectionTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adminID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	template, err := h.service.SaveTemplate(c.Request.Context(), &req, adminID)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// RetireTemplate handles DELETE /admin/inspection-templates/:key
+func (h *InspectionTemplateHandler) RetireTemplate(c *gin.Context) {
+	key := c.Param("key")
+
+	if err := h.service.RetireTemplate(c.Request.Context(), key); err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Inspection template retired successfully"})
+}
+
+// ListTemplates handles GET /inspection-templates
+// Pass ?all=true to include retired versions
+func (h *InspectionTemplateHandler) ListTemplates(c *gin.Context) {
+	includeRetired := c.Query("all") == "true"
+
+	templates, err := h.service.ListTemplates(c.Request.Context(), includeRetired)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+// GetTemplate handles GET /inspection-templates/:id
+func (h *InspectionTemplateHandler) GetTemplate(c *gin.Context) {
+	id := c.Param("id")
+
+	template, err := h.service.GetTemplateByID(c.Request.Context(), id)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		if err.Error() == "invalid template ID" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/middleware"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/service"
+)
+
+// InstallmentHandler handles installment-related HTTP requests
+type InstallmentHandler struct {
+	service *service.InstallmentService
+}
+
+// NewInstallmentHandler creates a new installment handler
+func NewInstallmentHandler(service *service.InstallmentService) *InstallmentHandler {
+	return &InstallmentHandler{
+		service: service,
+	}
+}
+
+// GetTransactionInstallments handles GET /transactions/:id/installments
+func (h *InstallmentHandler) GetTransactionInstallments(c *gin.Context) {
+	id := c.Param("id")
+
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	installments, err := h.service.GetTransactionInstallments(c.Request.Context(), id, userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"installments": installments,
+		"count":        len(installments),
+	})
+}
+
+// RecordPayment handles POST /installments/:id/payments
+func (h *InstallmentHandler) RecordPayment(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.RecordInstallmentPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	installment, err := h.service.RecordPayment(c.Request.Context(), id, &req, userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, installment)
+}
+
+// GetReceivablesSummary handles GET /installments/receivables
+// Dealers see their own receivables; admins pass ?dealerId= to see any dealer's
+func (h *InstallmentHandler) GetReceivablesSummary(c *gin.Context) {
+	dealerIDStr := middleware.GetUserID(c)
+	if dealerIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if middleware.GetUserRole(c) == models.RoleAdmin && c.Query("dealerId") != "" {
+		dealerIDStr = c.Query("dealerId")
+	}
+
+	dealerID, err := primitive.ObjectIDFromHex(dealerIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dealer ID"})
+		return
+	}
+
+	summary, err := h.service.GetReceivablesSummary(c.Request.Context(), dealerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// respondWithError maps installment errors to HTTP responses
+func (h *InstallmentHandler) respondWithError(c *gin.Context, err error) {
+	if respondWithAppError(c, err) {
+		return
+	}
+	switch err.Error() {
+	case "installment not found", "transaction not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "invalid installment ID", "invalid transaction ID":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case "you are not authorized to view this transaction", "you are not authorized to record payments for this installment":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
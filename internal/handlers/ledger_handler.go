@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Over-knight/Lujay-assesment/internal/service"
+)
+
+// LedgerHandler handles ledger report HTTP requests
+type LedgerHandler struct {
+	service *service.LedgerService
+}
+
+// NewLedgerHandler creates a new ledger handler
+func NewLedgerHandler(service *service.LedgerService) *LedgerHandler {
+	return &LedgerHandler{
+		service: service,
+	}
+}
+
+// GetTrialBalance handles GET /admin/ledger/trial-balance?asOf=
+// Totals every account's debits and credits for entries posted before asOf
+func (h *LedgerHandler) GetTrialBalance(c *gin.Context) {
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.TrialBalance(c.Request.Context(), asOf)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build trial balance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetAccountBalance handles GET /admin/ledger/accounts/:account?asOf=
+// Returns the account's balance in each currency it has entries in
+func (h *LedgerHandler) GetAccountBalance(c *gin.Context) {
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	balances, err := h.service.AccountBalances(c.Request.Context(), c.Param("account"), asOf)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve account balance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"account":  c.Param("account"),
+		"asOf":     asOf,
+		"balances": balances,
+	})
+}
+
+// parseAsOf reads the asOf query parameter, defaulting to now
+// A date such as 2026-09-30 covers the whole day, so month-end reports include the last day's entries
+func parseAsOf(c *gin.Context) (time.Time, bool) {
+	value := c.Query("asOf")
+	if value == "" {
+		return time.Now().UTC(), true
+	}
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date.AddDate(0, 0, 1), true
+	}
+	asOf, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "asOf must be a date (YYYY-MM-DD) or an RFC 3339 timestamp"})
+		return time.Time{}, false
+	}
+	return asOf, true
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/service"
+)
+
+// OfferHandler handles offer and counter-offer HTTP requests
+type OfferHandler struct {
+	service *service.OfferService
+}
+
+// NewOfferHandler creates a new offer handler
+func NewOfferHandler(service *service.OfferService) *OfferHandler {
+	return &OfferHandler{
+		service: service,
+	}
+}
+
+// MakeOffer handles POST /vehicles/:id/offers
+func (h *OfferHandler) MakeOffer(c *gin.Context) {
+	var req models.MakeOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	offer, err := h.service.MakeOffer(c.Request.Context(), c.Param("id"), &req, userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, offer)
+}
+
+// GetVehicleOffers handles GET /vehicles/:id/offers
+// Only the vehicle's owner can list its offers
+func (h *OfferHandler) GetVehicleOffers(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	offers, err := h.service.GetVehicleOffers(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"offers": offers,
+		"count":  len(offers),
+	})
+}
+
+// GetMyOffers handles GET /offers/my
+func (h *OfferHandler) GetMyOffers(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	offers, err := h.service.GetMyOffers(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"offers": offers,
+		"count":  len(offers),
+	})
+}
+
+// GetOffer handles GET /offers/:id
+func (h *OfferHandler) GetOffer(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	offer, err := h.service.GetOffer(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
+
+// CounterOffer handles POST /offers/:id/counter
+func (h *OfferHandler) CounterOffer(c *gin.Context) {
+	var req models.CounterOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	offer, err := h.service.CounterOffer(c.Request.Context(), c.Param("id"), &req, userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
+
+// AcceptOffer handles POST /offers/:id/accept
+// Returns the accepted offer and the pending transaction created for it
+func (h *OfferHandler) AcceptOffer(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	offer, transaction, err := h.service.AcceptOffer(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"offer":       offer,
+		"transaction": transaction,
+	})
+}
+
+// RejectOffer handles POST /offers/:id/reject
+func (h *OfferHandler) RejectOffer(c *gin.Context) {
+	var req models.RejectOfferRequest
+	// The reason is optional, so an empty body is allowed
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	offer, err := h.service.RejectOffer(c.Request.Context(), c.Param("id"), &req, userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
+
+// respondWithError maps offer errors to HTTP responses
+// Accepting an offer can also fail with the transaction service's own errors
+func (h *OfferHandler) respondWithError(c *gin.Context, err error) {
+	if respondWithAppError(c, err) {
+		return
+	}
+	switch err.Error() {
+	case "vehicle not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "vehicle is not available for sale", "cannot create transaction with yourself":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case "you are not the owner of this vehicle":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Over-knight/Lujay-assesment/internal/payments"
+	"github.com/Over-knight/Lujay-assesment/internal/service"
+)
+
+// maxWebhookBytes bounds the size of a webhook payload
+const maxWebhookBytes = 1 << 20 // 1 MB
+
+// PaymentWebhookHandler handles webhooks sent by payment providers
+type PaymentWebhookHandler struct {
+	providers *payments.Registry
+	service   *service.TransactionService
+}
+
+// NewPaymentWebhookHandler creates a new payment webhook handler
+func NewPaymentWebhookHandler(providers *payments.Registry, service *service.TransactionService) *PaymentWebhookHandler {
+	return &PaymentWebhookHandler{
+		providers: providers,
+		service:   service,
+	}
+}
+
+// HandleWebhook handles POST /webhooks/payments/:provider
+// Any 2xx tells the provider to stop retrying, so only failures worth retrying return an error status
+func (h *PaymentWebhookHandler) HandleWebhook(c *gin.Context) {
+	provider, err := h.providers.Get(c.Param("provider"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The signature covers the exact bytes sent, so the body must be read raw
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read webhook payload"})
+		return
+	}
+	if len(payload) > maxWebhookBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "webhook payload too large"})
+		return
+	}
+
+	event, err := provider.ParseWebhook(payload, c.GetHeader(payments.SignatureHeader))
+	if err != nil {
+		if errors.Is(err, payments.ErrInvalidSignature) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	outcome, err := h.service.HandlePaymentEvent(c.Request.Context(), provider.Name(), event)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"received": true,
+		"outcome":  outcome,
+	})
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/middleware"
+)
+
+// respondWithAppError writes typed service errors using their own status code and error code
+// Returns true when the error was an AppError and a response has been sent
+func respondWithAppError(c *gin.Context, err error) bool {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		apperrors.RespondWithError(c, appErr)
+		return true
+	}
+	return false
+}
+
+// currentUser returns the authenticated user's ID, writing an error response if there is none
+func currentUser(c *gin.Context) (primitive.ObjectID, bool) {
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return primitive.NilObjectID, false
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return primitive.NilObjectID, false
+	}
+	return userID, true
+}
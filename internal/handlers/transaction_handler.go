@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -60,6 +61,9 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if respondWithAppError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -155,6 +159,9 @@ func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
 
 	transaction, err := h.service.UpdateTransaction(c.Request.Context(), id, &req, userID)
 	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
 		if err.Error() == "transaction not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -199,6 +206,9 @@ func (h *TransactionHandler) CompleteTransaction(c *gin.Context) {
 
 	transaction, err := h.service.CompleteTransaction(c.Request.Context(), id, &req, userID)
 	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
 		if err.Error() == "transaction not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -207,10 +217,6 @@ func (h *TransactionHandler) CompleteTransaction(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
-		if err.Error() == "transaction is not pending" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -246,6 +252,9 @@ func (h *TransactionHandler) CancelTransaction(c *gin.Context) {
 
 	transaction, err := h.service.CancelTransaction(c.Request.Context(), id, req.Notes, userID)
 	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
 		if err.Error() == "transaction not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -254,17 +263,355 @@ func (h *TransactionHandler) CancelTransaction(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
-		if err.Error() == "only pending transactions can be cancelled" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// RefundTransaction handles POST /admin/transactions/:id/refund
+func (h *TransactionHandler) RefundTransaction(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.RefundTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adminID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	transaction, err := h.service.RefundTransaction(c.Request.Context(), id, &req, adminID)
+	if err != nil {
+		h.respondWithEscrowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// CreatePaymentIntent handles POST /transactions/:id/payment-intent
+func (h *TransactionHandler) CreatePaymentIntent(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.CreatePaymentIntentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	transaction, intent, err := h.service.CreatePaymentIntent(c.Request.Context(), id, &req, userID)
+	if err != nil {
+		if err.Error() == "only the buyer can pay for this transaction" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.respondWithEscrowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transaction": transaction,
+		"intent":      intent,
+	})
+}
+
+// FundTransaction handles POST /transactions/:id/fund
+func (h *TransactionHandler) FundTransaction(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.FundTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	transaction, err := h.service.FundTransaction(c.Request.Context(), id, &req, userID)
+	if err != nil {
+		h.respondWithEscrowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// PayDeposit handles POST /transactions/:id/deposit
+func (h *TransactionHandler) PayDeposit(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.PayDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	transaction, err := h.service.PayDeposit(c.Request.Context(), id, &req, userID)
+	if err != nil {
+		if err.Error() == "only the buyer can pay the deposit" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		h.respondWithEscrowError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, transaction)
 }
 
+// MarkDelivered handles POST /transactions/:id/deliver
+func (h *TransactionHandler) MarkDelivered(c *gin.Context) {
+	id := c.Param("id")
+
+	type DeliverRequest struct {
+		Notes string `json:"notes"`
+	}
+
+	var req DeliverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	transaction, err := h.service.MarkDelivered(c.Request.Context(), id, req.Notes, userID)
+	if err != nil {
+		h.respondWithEscrowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// ConfirmDelivery handles POST /transactions/:id/confirm-delivery
+func (h *TransactionHandler) ConfirmDelivery(c *gin.Context) {
+	id := c.Param("id")
+
+	type ConfirmRequest struct {
+		Notes string `json:"notes"`
+	}
+
+	var req ConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	transaction, err := h.service.ConfirmDelivery(c.Request.Context(), id, req.Notes, userID)
+	if err != nil {
+		h.respondWithEscrowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// QuoteTransaction handles POST /transactions/quote
+func (h *TransactionHandler) QuoteTransaction(c *gin.Context) {
+	var req models.TransactionQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := h.service.QuoteTransaction(c.Request.Context(), &req)
+	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
+		if err.Error() == "vehicle not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+// QuoteFinancing handles POST /financing/quote
+func (h *TransactionHandler) QuoteFinancing(c *gin.Context) {
+	var req models.FinancingQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule, err := h.service.QuoteFinancing(&req)
+	if err != nil {
+		h.respondWithEscrowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// GetAmortization handles GET /transactions/:id/amortization
+func (h *TransactionHandler) GetAmortization(c *gin.Context) {
+	id := c.Param("id")
+
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	schedule, err := h.service.GetAmortizationSchedule(c.Request.Context(), id, userID)
+	if err != nil {
+		h.respondWithEscrowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// GetInvoice handles GET /transactions/:id/invoice.pdf
+func (h *TransactionHandler) GetInvoice(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	invoice, data, err := h.service.RenderInvoice(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		h.respondWithEscrowError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="invoice-%s.pdf"`, invoice.Number))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// GetBillOfSale handles GET /transactions/:id/bill-of-sale.pdf
+func (h *TransactionHandler) GetBillOfSale(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	invoice, data, err := h.service.RenderBillOfSale(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		h.respondWithEscrowError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="bill-of-sale-%s.pdf"`, invoice.Number))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// respondWithEscrowError maps escrow and payment flow errors to HTTP responses
+func (h *TransactionHandler) respondWithEscrowError(c *gin.Context, err error) {
+	if respondWithAppError(c, err) {
+		return
+	}
+	switch err.Error() {
+	case "transaction not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "invalid transaction ID":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case "you are not authorized to update this transaction", "you are not authorized to view this transaction":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // ListTransactions handles GET /transactions
 func (h *TransactionHandler) ListTransactions(c *gin.Context) {
 	// Parse query parameters
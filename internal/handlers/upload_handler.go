@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -18,15 +19,19 @@ import (
 
 // UploadHandler handles file upload operations
 type UploadHandler struct {
-	uploader       *upload.CloudinaryUploader
-	vehicleService *service.VehicleService
+	uploader          *upload.CloudinaryUploader
+	vehicleService    *service.VehicleService
+	inspectionService *service.InspectionService
+	disputeService    *service.DisputeService
 }
 
 // NewUploadHandler creates a new upload handler
-func NewUploadHandler(uploader *upload.CloudinaryUploader, vehicleService *service.VehicleService) *UploadHandler {
+func NewUploadHandler(uploader *upload.CloudinaryUploader, vehicleService *service.VehicleService, inspectionService *service.InspectionService, disputeService *service.DisputeService) *UploadHandler {
 	return &UploadHandler{
-		uploader:       uploader,
-		vehicleService: vehicleService,
+		uploader:          uploader,
+		vehicleService:    vehicleService,
+		inspectionService: inspectionService,
+		disputeService:    disputeService,
 	}
 }
 
@@ -361,6 +366,235 @@ func (h *UploadHandler) SetPrimaryImage(c *gin.Context) {
 	})
 }
 
+// UploadIssueMedia handles attaching evidence to an inspection issue
+// @Summary Upload inspection issue media
+// @Description Attach photos or screenshots to an issue on the inspection report (max 10 files per issue, 10MB per file)
+// @Tags uploads
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Inspection ID"
+// @Param index path int true "Issue index in the report"
+// @Param media formData file true "Image files (jpg, jpeg, png, gif, webp)"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/inspections/{id}/issues/{index}/media [post]
+func (h *UploadHandler) UploadIssueMedia(c *gin.Context) {
+	if h.uploader == nil {
+		errors.HandleError(c, errors.NewAppError(errors.ErrCodeInternalServer, "file upload is not available", http.StatusServiceUnavailable))
+		return
+	}
+
+	// Get inspection ID and issue index
+	inspectionID := c.Param("id")
+	if _, err := primitive.ObjectIDFromHex(inspectionID); err != nil {
+		errors.HandleError(c, errors.NewValidationError("invalid inspection ID"))
+		return
+	}
+
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		errors.HandleError(c, errors.NewValidationError("invalid issue index"))
+		return
+	}
+
+	// Get user ID from context
+	userID, err := primitive.ObjectIDFromHex(middleware.GetUserID(c))
+	if err != nil {
+		errors.HandleError(c, errors.ErrUnauthorized)
+		return
+	}
+
+	// Parse multipart form
+	form, err := c.MultipartForm()
+	if err != nil {
+		errors.HandleError(c, errors.NewValidationError("failed to parse form"))
+		return
+	}
+
+	files := form.File["media"]
+	if len(files) == 0 {
+		errors.HandleError(c, errors.NewValidationError("no media provided"))
+		return
+	}
+
+	// Check permissions and limits before uploading anything
+	if err := h.inspectionService.CheckIssueMediaUpload(c.Request.Context(), inspectionID, index, len(files), userID); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	uploadedMedia := make([]models.IssueMedia, 0, len(files))
+	uploadedPublicIDs := make([]string, 0, len(files))
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	for _, fileHeader := range files {
+		file, err := fileHeader.Open()
+		if err != nil {
+			h.rollbackUploads(ctx, uploadedPublicIDs)
+			errors.HandleError(c, errors.NewValidationError(fmt.Sprintf("failed to open file: %s", fileHeader.Filename)))
+			return
+		}
+		defer file.Close()
+
+		if err := upload.ValidateImageFile(file, fileHeader); err != nil {
+			h.rollbackUploads(ctx, uploadedPublicIDs)
+			errors.HandleError(c, errors.NewValidationError(fmt.Sprintf("%s: %s", fileHeader.Filename, err.Error())))
+			return
+		}
+
+		result, err := h.uploader.UploadImage(ctx, file, fileHeader.Filename, fmt.Sprintf("inspection_%s/issue_%d", inspectionID, index))
+		if err != nil {
+			h.rollbackUploads(ctx, uploadedPublicIDs)
+			errors.HandleError(c, errors.NewDatabaseError("failed to upload media"))
+			return
+		}
+
+		uploadedMedia = append(uploadedMedia, models.IssueMedia{
+			URL:        result.URL,
+			PublicID:   result.PublicID,
+			Format:     result.Format,
+			Width:      result.Width,
+			Height:     result.Height,
+			Bytes:      result.Bytes,
+			UploadedBy: userID,
+			UploadedAt: time.Now(),
+		})
+		uploadedPublicIDs = append(uploadedPublicIDs, result.PublicID)
+	}
+
+	inspection, err := h.inspectionService.AddIssueMedia(c.Request.Context(), inspectionID, index, uploadedMedia, userID)
+	if err != nil {
+		h.rollbackUploads(ctx, uploadedPublicIDs)
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "media uploaded successfully",
+		"media_added":    len(uploadedMedia),
+		"uploaded_media": uploadedMedia,
+		"issue":          inspection.Report.Issues[index],
+	})
+}
+
+// UploadDisputeEvidence handles attaching evidence to a dispute
+// @Summary Upload dispute evidence
+// @Description Attach photos supporting either side of an unresolved dispute (max 20 files per dispute, 10MB per file)
+// @Tags uploads
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Dispute ID"
+// @Param evidence formData file true "Image files (jpg, jpeg, png, gif, webp)"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/disputes/{id}/evidence [post]
+func (h *UploadHandler) UploadDisputeEvidence(c *gin.Context) {
+	if h.uploader == nil {
+		errors.HandleError(c, errors.NewAppError(errors.ErrCodeInternalServer, "file upload is not available", http.StatusServiceUnavailable))
+		return
+	}
+
+	// Get dispute ID
+	disputeID := c.Param("id")
+	if _, err := primitive.ObjectIDFromHex(disputeID); err != nil {
+		errors.HandleError(c, errors.NewValidationError("invalid dispute ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID, err := primitive.ObjectIDFromHex(middleware.GetUserID(c))
+	if err != nil {
+		errors.HandleError(c, errors.ErrUnauthorized)
+		return
+	}
+
+	// Parse multipart form
+	form, err := c.MultipartForm()
+	if err != nil {
+		errors.HandleError(c, errors.NewValidationError("failed to parse form"))
+		return
+	}
+
+	files := form.File["evidence"]
+	if len(files) == 0 {
+		errors.HandleError(c, errors.NewValidationError("no evidence provided"))
+		return
+	}
+
+	// Check permissions and limits before uploading anything
+	if err := h.disputeService.CheckEvidenceUpload(c.Request.Context(), disputeID, len(files), userID); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	uploadedEvidence := make([]models.DisputeEvidence, 0, len(files))
+	uploadedPublicIDs := make([]string, 0, len(files))
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	for _, fileHeader := range files {
+		file, err := fileHeader.Open()
+		if err != nil {
+			h.rollbackUploads(ctx, uploadedPublicIDs)
+			errors.HandleError(c, errors.NewValidationError(fmt.Sprintf("failed to open file: %s", fileHeader.Filename)))
+			return
+		}
+		defer file.Close()
+
+		if err := upload.ValidateImageFile(file, fileHeader); err != nil {
+			h.rollbackUploads(ctx, uploadedPublicIDs)
+			errors.HandleError(c, errors.NewValidationError(fmt.Sprintf("%s: %s", fileHeader.Filename, err.Error())))
+			return
+		}
+
+		result, err := h.uploader.UploadImage(ctx, file, fileHeader.Filename, fmt.Sprintf("dispute_%s", disputeID))
+		if err != nil {
+			h.rollbackUploads(ctx, uploadedPublicIDs)
+			errors.HandleError(c, errors.NewDatabaseError("failed to upload evidence"))
+			return
+		}
+
+		uploadedEvidence = append(uploadedEvidence, models.DisputeEvidence{
+			URL:        result.URL,
+			PublicID:   result.PublicID,
+			Format:     result.Format,
+			Width:      result.Width,
+			Height:     result.Height,
+			Bytes:      result.Bytes,
+			UploadedBy: userID,
+			UploadedAt: time.Now(),
+		})
+		uploadedPublicIDs = append(uploadedPublicIDs, result.PublicID)
+	}
+
+	dispute, err := h.disputeService.AddEvidence(c.Request.Context(), disputeID, uploadedEvidence, userID)
+	if err != nil {
+		h.rollbackUploads(ctx, uploadedPublicIDs)
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "evidence uploaded successfully",
+		"evidence_added":    len(uploadedEvidence),
+		"uploaded_evidence": uploadedEvidence,
+		"dispute":           dispute,
+	})
+}
+
 // rollbackUploads deletes uploaded images from Cloudinary
 func (h *UploadHandler) rollbackUploads(ctx context.Context, publicIDs []string) {
 	for _, publicID := range publicIDs {
@@ -3,11 +3,13 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/Over-knight/Lujay-assesment/internal/middleware"
 	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
 	"github.com/Over-knight/Lujay-assesment/internal/service"
 )
 
@@ -81,6 +83,15 @@ func (h *VehicleHandler) ListVehicles(c *gin.Context) {
 	minYear, _ := strconv.Atoi(c.Query("minYear"))
 	maxYear, _ := strconv.Atoi(c.Query("maxYear"))
 
+	// Prices are shown and filtered in the requested currency
+	currency := strings.ToUpper(strings.TrimSpace(c.Query("currency")))
+	if currency != "" && !money.IsValidCurrency(currency) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "currency must be a supported ISO 4217 code",
+		})
+		return
+	}
+
 	// Build query
 	query := service.VehicleListQuery{
 		Page:      page,
@@ -94,11 +105,15 @@ func (h *VehicleHandler) ListVehicles(c *gin.Context) {
 		Status:    c.DefaultQuery("status", "active"),
 		SortBy:    c.DefaultQuery("sortBy", "createdAt"),
 		SortOrder: c.DefaultQuery("sortOrder", "desc"),
+		Currency:  currency,
 	}
 
 	// Get vehicles from database
 	response, err := h.vehicleService.ListVehicles(c.Request.Context(), query)
 	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve vehicles",
 		})
@@ -213,6 +228,9 @@ func (h *VehicleHandler) UpdateVehicle(c *gin.Context) {
 	// Update vehicle
 	vehicle, err := h.vehicleService.UpdateVehicle(c.Request.Context(), vehicleID, userID, req)
 	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
 		if err.Error() == "vehicle not found or unauthorized" {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": err.Error(),
@@ -257,6 +275,9 @@ func (h *VehicleHandler) DeleteVehicle(c *gin.Context) {
 	// Delete vehicle
 	err := h.vehicleService.DeleteVehicle(c.Request.Context(), vehicleID, userID)
 	if err != nil {
+		if respondWithAppError(c, err) {
+			return
+		}
 		if err.Error() == "vehicle not found or unauthorized" {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": err.Error(),
@@ -1,12 +1,17 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/Over-knight/Lujay-assesment/internal/auth"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
 )
 
 // AuthMiddleware creates a middleware that validates JWT tokens
@@ -77,3 +82,45 @@ func GetUserEmail(c *gin.Context) string {
 	}
 	return email.(string)
 }
+
+// CalendarTokenMiddleware creates a middleware that authenticates calendar feed requests
+// Calendar clients cannot send Authorization headers, so the per-user token travels in the ?token= query parameter
+// userCollection: MongoDB users collection to look up the token's owner
+// Returns a Gin middleware handler function
+func CalendarTokenMiddleware(userCollection *mongo.Collection) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if token == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Calendar token is required",
+			})
+			c.Abort()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		var user models.User
+		err := userCollection.FindOne(ctx, bson.M{"calendarTokenHash": auth.HashOpaqueToken(token)}).Decode(&user)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": "Invalid calendar token",
+				})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to verify calendar token",
+				})
+			}
+			c.Abort()
+			return
+		}
+
+		// Store user information in context for use in handlers
+		c.Set("userID", user.ID.Hex())
+		c.Set("email", user.Email)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,343 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Over-knight/Lujay-assesment/internal/auth"
+	"github.com/Over-knight/Lujay-assesment/internal/cache"
+)
+
+// IdempotencyKeyHeader is the request header clients send to make a retry safe
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const (
+	maxIdempotencyKeyLength = 255
+	idempotencyLockTTL      = 30 * time.Second       // longest a request may hold a key before another retry can take over
+	idempotencyPollInterval = 100 * time.Millisecond // how often a concurrent duplicate checks for the first response
+	idempotencyWaitTimeout  = 10 * time.Second       // how long a concurrent duplicate waits before giving up
+)
+
+// IdempotencyRecord is the stored outcome of a request made with an Idempotency-Key
+type IdempotencyRecord struct {
+	RequestHash string         `json:"request_hash" bson:"requestHash"`
+	Response    CachedResponse `json:"response" bson:"response"`
+}
+
+// IdempotencyStore keeps idempotency records and the locks that serialize concurrent duplicates
+type IdempotencyStore interface {
+	// Acquire takes the lock for a key, returning false if another request holds it
+	Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Release drops the lock for a key
+	Release(ctx context.Context, key string) error
+	// Load returns the record for a key, or nil if there is none
+	Load(ctx context.Context, key string) (*IdempotencyRecord, error)
+	// Save stores the record for a key
+	Save(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error
+}
+
+// IdempotencyMiddleware replays the stored response when a mutating request is retried with the same Idempotency-Key
+// Keys are scoped to the user, so two users cannot collide; reusing a key with a different request returns 422
+// jwtManager: identifies the user from the bearer token, since this runs before route authentication
+// ttl: how long a key is remembered
+func IdempotencyMiddleware(store IdempotencyStore, jwtManager *auth.JWTManager, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Idempotency-Key must be at most 255 characters",
+			})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Failed to read request body",
+			})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		storeKey := idempotencyStoreKey(idempotencyScope(c, jwtManager), key)
+		requestHash := hashRequest(c.Request.Method, c.Request.URL.Path, body)
+		ctx := c.Request.Context()
+
+		// Wait for the lock so concurrent duplicates run one at a time
+		acquired := false
+		defer func() {
+			if acquired {
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+				defer cancel()
+				_ = store.Release(releaseCtx, storeKey)
+			}
+		}()
+
+		deadline := time.Now().Add(idempotencyWaitTimeout)
+		for {
+			record, err := store.Load(ctx, storeKey)
+			if err != nil {
+				// Fail open like the rate limiter: an unavailable store must not block writes
+				c.Next()
+				return
+			}
+			if record != nil {
+				replayIdempotentResponse(c, record, requestHash)
+				return
+			}
+			if acquired {
+				break
+			}
+
+			acquired, err = store.Acquire(ctx, storeKey, idempotencyLockTTL)
+			if err != nil {
+				acquired = false
+				c.Next()
+				return
+			}
+			// Loop once more after acquiring: the holder may have saved its response just before releasing
+			if acquired {
+				continue
+			}
+
+			if time.Now().After(deadline) {
+				c.JSON(http.StatusConflict, gin.H{
+					"error": "A request with this Idempotency-Key is still being processed",
+				})
+				c.Abort()
+				return
+			}
+			select {
+			case <-ctx.Done():
+				c.Abort()
+				return
+			case <-time.After(idempotencyPollInterval):
+			}
+		}
+
+		// Capture the response so it can be replayed
+		responseWriter := &responseBodyWriter{
+			ResponseWriter: c.Writer,
+			body:           &bytes.Buffer{},
+		}
+		c.Writer = responseWriter
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if !isReplayableStatus(status) {
+			return
+		}
+
+		record := &IdempotencyRecord{
+			RequestHash: requestHash,
+			Response: CachedResponse{
+				StatusCode:  status,
+				ContentType: c.Writer.Header().Get("Content-Type"),
+				Headers:     c.Writer.Header().Clone(),
+				Body:        responseWriter.body.Bytes(),
+			},
+		}
+
+		saveCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = store.Save(saveCtx, storeKey, record, ttl)
+	}
+}
+
+// replayIdempotentResponse writes a stored response, or 422 if the retry is a different request
+func replayIdempotentResponse(c *gin.Context, record *IdempotencyRecord, requestHash string) {
+	if record.RequestHash != requestHash {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "Idempotency-Key was already used with a different request",
+		})
+		c.Abort()
+		return
+	}
+
+	for key, values := range record.Response.Headers {
+		for _, value := range values {
+			c.Header(key, value)
+		}
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(record.Response.StatusCode, record.Response.ContentType, record.Response.Body)
+	c.Abort()
+}
+
+// isMutatingMethod reports whether requests with the method change state
+func isMutatingMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut ||
+		method == http.MethodPatch || method == http.MethodDelete
+}
+
+// isReplayableStatus reports whether a response is the final outcome of the request
+// Server errors, auth failures, conflicts and rate limits are left unstored so the client can retry them
+func isReplayableStatus(status int) bool {
+	if status >= 500 {
+		return false
+	}
+	switch status {
+	case http.StatusUnauthorized, http.StatusConflict, http.StatusTooManyRequests:
+		return false
+	}
+	return true
+}
+
+// idempotencyScope identifies who sent the request: the token's user, or the client IP when unauthenticated
+func idempotencyScope(c *gin.Context, jwtManager *auth.JWTManager) string {
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		if claims, err := jwtManager.ValidateToken(token); err == nil {
+			return "user:" + claims.UserID
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// idempotencyStoreKey combines the scope and client key into a fixed-length store key
+func idempotencyStoreKey(scope, key string) string {
+	hash := sha256.Sum256([]byte(scope + ":" + key))
+	return hex.EncodeToString(hash[:])
+}
+
+// hashRequest fingerprints a request so a reused key can be told apart from a retry
+func hashRequest(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RedisIdempotencyStore keeps idempotency records in Redis
+type RedisIdempotencyStore struct {
+	cache *cache.RedisCache
+}
+
+// NewRedisIdempotencyStore creates a Redis-backed idempotency store
+func NewRedisIdempotencyStore(redisCache *cache.RedisCache) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{cache: redisCache}
+}
+
+// Acquire takes the lock with SETNX
+func (s *RedisIdempotencyStore) Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return s.cache.SetNX(ctx, "idempotency:lock:"+key, 1, ttl)
+}
+
+// Release deletes the lock
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.cache.Delete(ctx, "idempotency:lock:"+key)
+}
+
+// Load returns the stored record, or nil if there is none
+func (s *RedisIdempotencyStore) Load(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	exists, err := s.cache.Exists(ctx, "idempotency:"+key)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	var record IdempotencyRecord
+	if err := s.cache.Get(ctx, "idempotency:"+key, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Save stores the record with a TTL
+func (s *RedisIdempotencyStore) Save(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	return s.cache.Set(ctx, "idempotency:"+key, record, ttl)
+}
+
+// MongoIdempotencyStore keeps idempotency records in MongoDB, for deployments without Redis
+// The lock is the document itself: inserting it fails while another request holds the key
+type MongoIdempotencyStore struct {
+	collection *mongo.Collection
+}
+
+// mongoIdempotencyDocument is a lock or a completed record
+type mongoIdempotencyDocument struct {
+	Key       string             `bson:"_id"`
+	Record    *IdempotencyRecord `bson:"record,omitempty"`
+	ExpiresAt time.Time          `bson:"expiresAt"` // lock expiry while in progress, record expiry once saved
+}
+
+// NewMongoIdempotencyStore creates a MongoDB-backed idempotency store
+func NewMongoIdempotencyStore(collection *mongo.Collection) *MongoIdempotencyStore {
+	return &MongoIdempotencyStore{collection: collection}
+}
+
+// EnsureIndexes creates the TTL index that removes expired keys
+func (s *MongoIdempotencyStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0).SetName("idx_idempotency_keys_expires_ttl"),
+	})
+	return err
+}
+
+// Acquire inserts the lock document, taking over a lock whose holder has expired
+func (s *MongoIdempotencyStore) Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	_, err := s.collection.InsertOne(ctx, mongoIdempotencyDocument{Key: key, ExpiresAt: now.Add(ttl)})
+	if err == nil {
+		return true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return false, err
+	}
+
+	// The TTL monitor only runs every minute, so expired locks and records are taken over here
+	filter := bson.M{"_id": key, "expiresAt": bson.M{"$lt": now}}
+	update := bson.M{
+		"$set":   bson.M{"expiresAt": now.Add(ttl)},
+		"$unset": bson.M{"record": ""},
+	}
+	result, err := s.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// Release deletes the lock document unless a record was saved in it
+func (s *MongoIdempotencyStore) Release(ctx context.Context, key string) error {
+	_, err := s.collection.DeleteOne(ctx, bson.M{"_id": key, "record": bson.M{"$exists": false}})
+	return err
+}
+
+// Load returns the stored record, or nil if there is none or it has expired
+func (s *MongoIdempotencyStore) Load(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	var doc mongoIdempotencyDocument
+	filter := bson.M{"_id": key, "record": bson.M{"$exists": true}, "expiresAt": bson.M{"$gt": time.Now()}}
+	err := s.collection.FindOne(ctx, filter).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return doc.Record, nil
+}
+
+// Save stores the record in the lock document
+func (s *MongoIdempotencyStore) Save(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	update := bson.M{"$set": bson.M{"record": record, "expiresAt": time.Now().Add(ttl)}}
+	_, err := s.collection.UpdateOne(ctx, bson.M{"_id": key}, update, options.Update().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Over-knight/Lujay-assesment/internal/auth"
+)
+
+// memoryIdempotencyStore is an in-process IdempotencyStore for tests
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	locks   map[string]bool
+	records map[string]*IdempotencyRecord
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{locks: map[string]bool{}, records: map[string]*IdempotencyRecord{}}
+}
+
+func (s *memoryIdempotencyStore) Acquire(_ context.Context, key string, _ time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locks[key] {
+		return false, nil
+	}
+	s.locks[key] = true
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.locks, key)
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Load(_ context.Context, key string) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records[key], nil
+}
+
+func (s *memoryIdempotencyStore) Save(_ context.Context, key string, record *IdempotencyRecord, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = record
+	return nil
+}
+
+// newIdempotencyRouter serves POST /items, counting how often the handler runs
+func newIdempotencyRouter(store IdempotencyStore, status int, delay time.Duration, calls *int32) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(IdempotencyMiddleware(store, auth.NewJWTManager("test-secret", time.Hour), time.Hour))
+	router.POST("/items", func(c *gin.Context) {
+		n := atomic.AddInt32(calls, 1)
+		time.Sleep(delay)
+		c.JSON(status, gin.H{"call": n})
+	})
+	return router
+}
+
+func sendIdempotent(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware_ReplaysRetry(t *testing.T) {
+	var calls int32
+	router := newIdempotencyRouter(newMemoryIdempotencyStore(), http.StatusCreated, 0, &calls)
+
+	first := sendIdempotent(router, "key-1", `{"name":"camry"}`)
+	retry := sendIdempotent(router, "key-1", `{"name":"camry"}`)
+
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestIdempotencyMiddleware_RejectsDifferentBody(t *testing.T) {
+	var calls int32
+	router := newIdempotencyRouter(newMemoryIdempotencyStore(), http.StatusCreated, 0, &calls)
+
+	sendIdempotent(router, "key-1", `{"name":"camry"}`)
+	reused := sendIdempotent(router, "key-1", `{"name":"corolla"}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestIdempotencyMiddleware_WithoutKey(t *testing.T) {
+	var calls int32
+	router := newIdempotencyRouter(newMemoryIdempotencyStore(), http.StatusCreated, 0, &calls)
+
+	sendIdempotent(router, "", `{"name":"camry"}`)
+	sendIdempotent(router, "", `{"name":"camry"}`)
+
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestIdempotencyMiddleware_DoesNotStoreServerErrors(t *testing.T) {
+	var calls int32
+	router := newIdempotencyRouter(newMemoryIdempotencyStore(), http.StatusInternalServerError, 0, &calls)
+
+	sendIdempotent(router, "key-1", `{}`)
+	sendIdempotent(router, "key-1", `{}`)
+
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestIdempotencyMiddleware_SerializesConcurrentDuplicates(t *testing.T) {
+	var calls int32
+	router := newIdempotencyRouter(newMemoryIdempotencyStore(), http.StatusCreated, 50*time.Millisecond, &calls)
+
+	var wg sync.WaitGroup
+	codes := make([]int, 5)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = sendIdempotent(router, "key-1", `{"name":"camry"}`).Code
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls)
+	for _, code := range codes {
+		assert.Equal(t, http.StatusCreated, code)
+	}
+}
+
+func TestIdempotencyMiddleware_ScopesKeysToUser(t *testing.T) {
+	var calls int32
+	jwtManager := auth.NewJWTManager("test-secret", time.Hour)
+	router := newIdempotencyRouter(newMemoryIdempotencyStore(), http.StatusCreated, 0, &calls)
+
+	for _, userID := range []string{"user-a", "user-b"} {
+		token, err := jwtManager.GenerateToken(userID, userID+"@example.com")
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(IdempotencyKeyHeader, "shared-key")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+	}
+
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestIsReplayableStatus(t *testing.T) {
+	assert.True(t, isReplayableStatus(http.StatusCreated))
+	assert.True(t, isReplayableStatus(http.StatusBadRequest))
+	assert.False(t, isReplayableStatus(http.StatusUnauthorized))
+	assert.False(t, isReplayableStatus(http.StatusConflict))
+	assert.False(t, isReplayableStatus(http.StatusTooManyRequests))
+	assert.False(t, isReplayableStatus(http.StatusServiceUnavailable))
+}
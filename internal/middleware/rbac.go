@@ -104,3 +104,13 @@ func RequireAdmin(userCollection *mongo.Collection) gin.HandlerFunc {
 func RequireAdminOrDealer(userCollection *mongo.Collection) gin.HandlerFunc {
 	return RBACMiddleware(userCollection, models.RoleAdmin, models.RoleDealer)
 }
+
+// RequireInspector middleware that only allows inspector users
+func RequireInspector(userCollection *mongo.Collection) gin.HandlerFunc {
+	return RBACMiddleware(userCollection, models.RoleInspector)
+}
+
+// RequireInspectorOrAdmin middleware that allows inspector or admin users
+func RequireInspectorOrAdmin(userCollection *mongo.Collection) gin.HandlerFunc {
+	return RBACMiddleware(userCollection, models.RoleInspector, models.RoleAdmin)
+}
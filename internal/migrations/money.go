@@ -0,0 +1,231 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// MoneyCollection lists the amount fields of a collection that the money migration converts
+type MoneyCollection struct {
+	Name          string
+	Paths         []string // dotted paths; a path through an array converts the field in every element
+	CurrencyField string   // top-level field holding the document's currency, "" to use money.DefaultCurrency
+}
+
+// MoneyCollections are the collections that stored amounts as plain numbers before the Money type
+var MoneyCollections = []MoneyCollection{
+	{Name: "vehicles", Paths: []string{"price"}},
+	{Name: "inspections", Paths: []string{"report.estimatedRepairs"}},
+	{
+		Name: "transactions",
+		Paths: []string{
+			"amount",
+			"paymentDetails.downPayment",
+			"paymentDetails.financedAmount",
+			"paymentDetails.monthlyPayment",
+			"paymentDetails.balloonPayment",
+			"paymentDetails.financingFees",
+			"paymentDetails.totalInterest",
+		},
+		CurrencyField: "currency",
+	},
+	{
+		Name:          "installments",
+		Paths:         []string{"amount", "principal", "interest", "lateFee", "amountPaid", "payments.amount"},
+		CurrencyField: "currency",
+	},
+}
+
+// MoneyResult reports what the migration did to one collection
+type MoneyResult struct {
+	Collection string
+	Migrated   int64
+	Changed    int64                        // documents written to between being read and converted; run again to convert them
+	Failed     map[primitive.ObjectID]error // documents left unchanged, such as ones with an unknown currency
+}
+
+// MigrateMoney rewrites plain-number amounts as {amount: Decimal128, currency} documents
+// Amounts are rounded half to even to the currency's minor unit. Fields that are already converted are
+// left alone, so the migration can be re-run safely after a failure or a deploy that still wrote numbers
+func MigrateMoney(ctx context.Context, db *mongo.Database, dryRun bool) ([]MoneyResult, error) {
+	results := make([]MoneyResult, 0, len(MoneyCollections))
+	for _, spec := range MoneyCollections {
+		result, err := migrateMoneyCollection(ctx, db.Collection(spec.Name), spec, dryRun)
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", spec.Name, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// migrateMoneyCollection converts the documents of one collection that still hold a numeric amount
+func migrateMoneyCollection(ctx context.Context, collection *mongo.Collection, spec MoneyCollection, dryRun bool) (MoneyResult, error) {
+	result := MoneyResult{Collection: spec.Name, Failed: map[primitive.ObjectID]error{}}
+
+	pending := bson.A{}
+	for _, path := range spec.Paths {
+		pending = append(pending, bson.M{path: bson.M{"$type": "number"}})
+	}
+	cursor, err := collection.Find(ctx, bson.M{"$or": pending})
+	if err != nil {
+		return result, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return result, err
+		}
+		id, _ := doc["_id"].(primitive.ObjectID)
+
+		set, err := convertMoneyFields(doc, spec)
+		if err != nil {
+			result.Failed[id] = err
+			continue
+		}
+		if len(set) == 0 {
+			continue
+		}
+
+		if !dryRun {
+			updated, err := collection.UpdateOne(ctx, unchangedFilter(cursor.Current, set, spec), bson.M{"$set": set})
+			if err != nil {
+				return result, err
+			}
+			if updated.MatchedCount == 0 {
+				result.Changed++
+				continue
+			}
+		}
+		result.Migrated++
+	}
+
+	return result, cursor.Err()
+}
+
+// unchangedFilter matches the document only while the fields being replaced, and its currency, still
+// hold the values that were read, so a concurrent write is not overwritten with stale converted values
+// The raw values keep sub-document field order, which equality matches on embedded documents depend on
+func unchangedFilter(doc bson.Raw, set bson.M, spec MoneyCollection) bson.D {
+	filter := bson.D{{Key: "_id", Value: doc.Lookup("_id")}}
+	fields := make([]string, 0, len(set)+1)
+	for root := range set {
+		fields = append(fields, root)
+	}
+	sort.Strings(fields)
+	if spec.CurrencyField != "" {
+		fields = append(fields, spec.CurrencyField)
+	}
+
+	for _, field := range fields {
+		value, err := doc.LookupErr(field)
+		if err != nil {
+			filter = append(filter, bson.E{Key: field, Value: bson.M{"$exists": false}})
+			continue
+		}
+		filter = append(filter, bson.E{Key: field, Value: value})
+	}
+	return filter
+}
+
+// convertMoneyFields returns the $set that converts a document's numeric amounts, keyed by top-level field
+// Whole top-level fields are replaced so amounts inside arrays are converted in place
+func convertMoneyFields(doc bson.M, spec MoneyCollection) (bson.M, error) {
+	currency := money.DefaultCurrency
+	if spec.CurrencyField != "" {
+		if value, ok := doc[spec.CurrencyField].(string); ok && value != "" {
+			currency = value
+		}
+	}
+	if !money.IsValidCurrency(currency) {
+		return nil, fmt.Errorf("%w: %q", money.ErrUnknownCurrency, currency)
+	}
+
+	set := bson.M{}
+	for _, path := range spec.Paths {
+		parts := strings.Split(path, ".")
+		root := parts[0]
+		current, ok := set[root]
+		if !ok {
+			current = doc[root]
+		}
+
+		converted, changed, err := convertAmount(current, parts[1:], currency)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if changed {
+			set[root] = converted
+		}
+	}
+	return set, nil
+}
+
+// convertAmount converts the number at path below value, descending into sub-documents and arrays
+// It reports whether anything was converted
+func convertAmount(value interface{}, path []string, currency string) (interface{}, bool, error) {
+	if len(path) == 0 {
+		amount, ok := numberValue(value)
+		if !ok {
+			return value, false, nil
+		}
+		converted, err := money.FromFloat(amount, currency, money.RoundHalfEven)
+		if err != nil {
+			return nil, false, err
+		}
+		return converted, true, nil
+	}
+
+	switch v := value.(type) {
+	case bson.M:
+		child, changed, err := convertAmount(v[path[0]], path[1:], currency)
+		if err != nil || !changed {
+			return value, false, err
+		}
+		copied := bson.M{}
+		for key, field := range v {
+			copied[key] = field
+		}
+		copied[path[0]] = child
+		return copied, true, nil
+	case primitive.A:
+		copied := make(primitive.A, len(v))
+		anyChanged := false
+		for i, element := range v {
+			converted, changed, err := convertAmount(element, path, currency)
+			if err != nil {
+				return nil, false, fmt.Errorf("element %d: %w", i, err)
+			}
+			copied[i] = converted
+			anyChanged = anyChanged || changed
+		}
+		if !anyChanged {
+			return value, false, nil
+		}
+		return copied, true, nil
+	}
+	return value, false, nil
+}
+
+// numberValue returns a BSON number as a float; amounts were always stored as doubles or integers
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,129 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// roundTrip decodes a document the way the migration reads it from MongoDB
+func roundTrip(t *testing.T, doc interface{}) bson.M {
+	t.Helper()
+	raw, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	var decoded bson.M
+	require.NoError(t, bson.Unmarshal(raw, &decoded))
+	return decoded
+}
+
+// spec returns the migration's field list for a collection
+func spec(t *testing.T, name string) MoneyCollection {
+	t.Helper()
+	for _, collection := range MoneyCollections {
+		if collection.Name == name {
+			return collection
+		}
+	}
+	t.Fatalf("no money fields listed for %s", name)
+	return MoneyCollection{}
+}
+
+func TestConvertMoneyFields_Transaction(t *testing.T) {
+	doc := roundTrip(t, bson.M{
+		"amount":   30000.0,
+		"currency": "NGN",
+		"paymentDetails": bson.M{
+			"downPayment":    10000.005,
+			"monthlyPayment": 608.44,
+			"bankName":       "First Bank",
+		},
+	})
+
+	set, err := convertMoneyFields(doc, spec(t, "transactions"))
+	require.NoError(t, err)
+
+	assert.Equal(t, money.MustParse("30000", "NGN"), set["amount"])
+	details := set["paymentDetails"].(bson.M)
+	assert.Equal(t, money.MustParse("10000.00", "NGN"), details["downPayment"], "halves round to even")
+	assert.Equal(t, money.MustParse("608.44", "NGN"), details["monthlyPayment"])
+	assert.Equal(t, "First Bank", details["bankName"])
+	assert.NotContains(t, details, "balloonPayment")
+}
+
+func TestConvertMoneyFields_ArraysAndDefaultCurrency(t *testing.T) {
+	installment := roundTrip(t, bson.M{
+		"amount":     int32(500),
+		"currency":   "USD",
+		"lateFee":    bson.M{"amount": money.MustParse("25", "USD").Decimal128(), "currency": "USD"},
+		"amountPaid": 0.0,
+		"payments": bson.A{
+			bson.M{"amount": 200.5, "reference": "TRF-1"},
+			bson.M{"amount": 99.5},
+		},
+	})
+
+	set, err := convertMoneyFields(installment, spec(t, "installments"))
+	require.NoError(t, err)
+	assert.Equal(t, money.MustParse("500", "USD"), set["amount"])
+	assert.NotContains(t, set, "lateFee", "already converted fields are left alone")
+	payments := set["payments"].(primitive.A)
+	assert.Equal(t, money.MustParse("200.50", "USD"), payments[0].(bson.M)["amount"])
+	assert.Equal(t, "TRF-1", payments[0].(bson.M)["reference"])
+	assert.Equal(t, money.MustParse("99.50", "USD"), payments[1].(bson.M)["amount"])
+
+	vehicle := roundTrip(t, bson.M{"price": 25000.99})
+	set, err = convertMoneyFields(vehicle, spec(t, "vehicles"))
+	require.NoError(t, err)
+	assert.Equal(t, money.MustParse("25000.99", money.DefaultCurrency), set["price"])
+}
+
+func TestConvertMoneyFields_Idempotent(t *testing.T) {
+	doc := roundTrip(t, bson.M{"amount": 100.0, "currency": "USD"})
+	set, err := convertMoneyFields(doc, spec(t, "transactions"))
+	require.NoError(t, err)
+
+	// Apply the update and convert again; nothing is left to change
+	doc["amount"] = set["amount"]
+	set, err = convertMoneyFields(roundTrip(t, doc), spec(t, "transactions"))
+	require.NoError(t, err)
+	assert.Empty(t, set)
+}
+
+func TestConvertMoneyFields_UnknownCurrency(t *testing.T) {
+	doc := roundTrip(t, bson.M{"amount": 100.0, "currency": "usd"})
+	_, err := convertMoneyFields(doc, spec(t, "transactions"))
+	assert.ErrorIs(t, err, money.ErrUnknownCurrency)
+}
+
+func TestUnchangedFilter(t *testing.T) {
+	id := primitive.NewObjectID()
+	raw, err := bson.Marshal(bson.D{
+		{Key: "_id", Value: id},
+		{Key: "amount", Value: 30000.0},
+		{Key: "paymentDetails", Value: bson.D{{Key: "downPayment", Value: 10000.0}, {Key: "bankName", Value: "First Bank"}}},
+		{Key: "status", Value: "pending"},
+	})
+	require.NoError(t, err)
+	var doc bson.M
+	require.NoError(t, bson.Unmarshal(raw, &doc))
+
+	set, err := convertMoneyFields(doc, spec(t, "transactions"))
+	require.NoError(t, err)
+	filter := unchangedFilter(raw, set, spec(t, "transactions"))
+
+	keys := make([]string, len(filter))
+	for i, e := range filter {
+		keys[i] = e.Key
+	}
+	assert.Equal(t, []string{"_id", "amount", "paymentDetails", "currency"}, keys, "fields the update replaces and the currency it used")
+
+	details := filter[2].Value.(bson.RawValue)
+	assert.Equal(t, bson.TypeEmbeddedDocument, details.Type)
+	assert.Equal(t, "downPayment", details.Document().Index(0).Key(), "embedded documents keep their stored field order")
+	assert.Equal(t, bson.M{"$exists": false}, filter[3].Value, "a document without a currency must still have none")
+}
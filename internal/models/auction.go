@@ -0,0 +1,172 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// Listing type constants
+const (
+	ListingTypeFixedPrice = "fixed_price" // sold at its price, or through offers
+	ListingTypeAuction    = "auction"     // sold to the highest bidder when its auction closes
+)
+
+// Auction status constants
+const (
+	AuctionStatusOpen      = "open"      // accepting bids until it ends
+	AuctionStatusClosed    = "closed"    // ended; see the outcome
+	AuctionStatusCancelled = "cancelled" // withdrawn by the seller before any bids
+	AuctionStatusFailed    = "failed"    // sold, but the winner's transaction could not be created; see closeError
+)
+
+// Auction outcome constants
+const (
+	AuctionOutcomeSold          = "sold"            // the reserve was met; a pending transaction was created for the winner
+	AuctionOutcomeReserveNotMet = "reserve_not_met" // bids never reached the reserve price
+	AuctionOutcomeNoBids        = "no_bids"
+)
+
+// Default anti-sniping settings
+const (
+	DefaultAuctionExtendWithin = 120 // seconds before the end within which a bid extends the auction
+	DefaultAuctionExtendBy     = 120 // seconds a late bid leaves on the clock
+	MaxAuctionDuration         = 30 * 24 * time.Hour
+)
+
+// Auction is a timed sale of a vehicle to the highest bidder
+// Bidders place proxy bids: the maximum they are willing to pay. The current price only rises as far as
+// needed to keep the highest maximum in the lead, so LeaderMax is never shown to other users
+type Auction struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	VehicleID primitive.ObjectID `bson:"vehicleId" json:"vehicleId"`
+	SellerID  primitive.ObjectID `bson:"sellerId" json:"sellerId"`
+	Status    string             `bson:"status" json:"status"`
+
+	Currency      string      `bson:"currency" json:"currency"`
+	StartPrice    money.Money `bson:"startPrice" json:"startPrice"`
+	ReservePrice  money.Money `bson:"reservePrice,omitempty" json:"-"` // kept private; only whether it was met is shown
+	BidIncrement  money.Money `bson:"bidIncrement" json:"bidIncrement"`
+	PaymentMethod string      `bson:"paymentMethod" json:"paymentMethod"`
+	Escrow        bool        `bson:"escrow" json:"escrow"`
+
+	StartsAt     time.Time `bson:"startsAt" json:"startsAt"`
+	EndsAt       time.Time `bson:"endsAt" json:"endsAt"`             // pushed back by bids placed within ExtendWithin of the end
+	ExtendWithin int       `bson:"extendWithin" json:"extendWithin"` // seconds
+	ExtendBy     int       `bson:"extendBy" json:"extendBy"`         // seconds
+
+	CurrentPrice money.Money         `bson:"currentPrice,omitempty" json:"currentPrice,omitzero"`
+	LeaderID     *primitive.ObjectID `bson:"leaderId,omitempty" json:"leaderId,omitempty"`
+	LeaderMax    money.Money         `bson:"leaderMax,omitempty" json:"-"`
+	ReserveMet   bool                `bson:"reserveMet" json:"reserveMet"`
+	BidCount     int                 `bson:"bidCount" json:"bidCount"`
+	Version      int64               `bson:"version" json:"-"` // incremented by every bid so concurrent bids cannot both apply
+
+	Outcome       string              `bson:"outcome,omitempty" json:"outcome,omitempty"`
+	WinnerID      *primitive.ObjectID `bson:"winnerId,omitempty" json:"winnerId,omitempty"`
+	TransactionID *primitive.ObjectID `bson:"transactionId,omitempty" json:"transactionId,omitempty"`
+	CloseError    string              `bson:"closeError,omitempty" json:"closeError,omitempty"` // why the winner's transaction could not be created
+	ClosedAt      *time.Time          `bson:"closedAt,omitempty" json:"closedAt,omitempty"`
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// AuctionBid records one proxy bid on an auction
+type AuctionBid struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	AuctionID primitive.ObjectID `bson:"auctionId" json:"auctionId"`
+	BidderID  primitive.ObjectID `bson:"bidderId" json:"bidderId"`
+	MaxAmount money.Money        `bson:"maxAmount" json:"-"`
+	Price     money.Money        `bson:"price" json:"price"`     // the auction's current price once the bid was resolved
+	Leading   bool               `bson:"leading" json:"leading"` // whether the bidder led once the bid was resolved
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// MinimumBid returns the lowest maximum a new bidder can place
+func (a *Auction) MinimumBid() money.Money {
+	if a.LeaderID == nil {
+		return a.StartPrice
+	}
+	minimum, err := a.CurrentPrice.Add(a.BidIncrement)
+	if err != nil {
+		return a.CurrentPrice
+	}
+	return minimum
+}
+
+// CreateAuctionRequest represents the request body for listing a vehicle by auction
+type CreateAuctionRequest struct {
+	StartPrice    money.Money `json:"startPrice"`   // in the vehicle's listing currency
+	ReservePrice  money.Money `json:"reservePrice"` // optional; the vehicle is not sold below it
+	BidIncrement  money.Money `json:"bidIncrement"`
+	StartsAt      *time.Time  `json:"startsAt"` // now when omitted
+	EndsAt        time.Time   `json:"endsAt" binding:"required"`
+	ExtendWithin  int         `json:"extendWithin"` // seconds; DefaultAuctionExtendWithin when omitted
+	ExtendBy      int         `json:"extendBy"`     // seconds; DefaultAuctionExtendBy when omitted
+	PaymentMethod string      `json:"paymentMethod" binding:"required"`
+	Escrow        bool        `json:"escrow"`
+}
+
+// PlaceBidRequest represents the request body for placing a proxy bid
+type PlaceBidRequest struct {
+	MaxAmount money.Money `json:"maxAmount"` // the most the bidder is willing to pay, in the auction's currency
+}
+
+// Validate validates the CreateAuctionRequest against the time it is made
+func (r *CreateAuctionRequest) Validate(now time.Time) error {
+	if !r.StartPrice.IsPositive() {
+		return errors.New("startPrice must be greater than 0")
+	}
+	if !r.BidIncrement.IsPositive() {
+		return errors.New("bidIncrement must be greater than 0")
+	}
+	if r.ReservePrice.IsNegative() {
+		return errors.New("reservePrice must be non-negative")
+	}
+	if !r.ReservePrice.IsZero() {
+		if cmp, err := r.ReservePrice.Cmp(r.StartPrice); err == nil && cmp < 0 {
+			return errors.New("reservePrice must be at least startPrice")
+		}
+	}
+
+	startsAt := now
+	if r.StartsAt != nil {
+		if r.StartsAt.Before(now.Add(-time.Minute)) {
+			return errors.New("startsAt must not be in the past")
+		}
+		startsAt = *r.StartsAt
+	}
+	if !r.EndsAt.After(startsAt) {
+		return errors.New("endsAt must be after the auction starts")
+	}
+	if r.EndsAt.Sub(startsAt) > MaxAuctionDuration {
+		return errors.New("auctions can run for at most 30 days")
+	}
+	if r.ExtendWithin < 0 || r.ExtendBy < 0 {
+		return errors.New("extendWithin and extendBy must be non-negative")
+	}
+
+	if !IsValidPaymentMethod(r.PaymentMethod) {
+		return errors.New("invalid paymentMethod value")
+	}
+	// Financing terms depend on the winning price, so they are arranged after the auction closes
+	if r.PaymentMethod == PaymentMethodFinancing {
+		return errors.New("auctions cannot be paid with financing")
+	}
+	if r.Escrow && r.PaymentMethod != PaymentMethodCard && r.PaymentMethod != PaymentMethodBankTransfer {
+		return errors.New("escrow is only available for card and bank_transfer payments")
+	}
+	return nil
+}
+
+// Validate validates the PlaceBidRequest
+func (r *PlaceBidRequest) Validate() error {
+	if !r.MaxAmount.IsPositive() {
+		return errors.New("maxAmount must be greater than 0")
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func TestCreateAuctionRequest_Validate(t *testing.T) {
+	now := time.Now()
+	valid := func() CreateAuctionRequest {
+		return CreateAuctionRequest{
+			StartPrice:    money.MustParse("10000", ""),
+			ReservePrice:  money.MustParse("15000", ""),
+			BidIncrement:  money.MustParse("250", ""),
+			EndsAt:        now.Add(7 * 24 * time.Hour),
+			PaymentMethod: PaymentMethodBankTransfer,
+			Escrow:        true,
+		}
+	}
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		modify  func(*CreateAuctionRequest)
+		wantErr string
+	}{
+		{name: "valid auction", modify: func(r *CreateAuctionRequest) {}},
+		{name: "no reserve", modify: func(r *CreateAuctionRequest) { r.ReservePrice = money.Money{} }},
+		{name: "zero start price", modify: func(r *CreateAuctionRequest) { r.StartPrice = money.MustParse("0", "") }, wantErr: "startPrice must be greater than 0"},
+		{name: "zero increment", modify: func(r *CreateAuctionRequest) { r.BidIncrement = money.MustParse("0", "") }, wantErr: "bidIncrement must be greater than 0"},
+		{name: "reserve below start price", modify: func(r *CreateAuctionRequest) { r.ReservePrice = money.MustParse("5000", "") }, wantErr: "reservePrice must be at least startPrice"},
+		{name: "starts in the past", modify: func(r *CreateAuctionRequest) { r.StartsAt = &past }, wantErr: "startsAt must not be in the past"},
+		{name: "ends before it starts", modify: func(r *CreateAuctionRequest) { r.EndsAt = now.Add(-time.Minute) }, wantErr: "endsAt must be after the auction starts"},
+		{name: "runs too long", modify: func(r *CreateAuctionRequest) { r.EndsAt = now.Add(31 * 24 * time.Hour) }, wantErr: "auctions can run for at most 30 days"},
+		{name: "negative extension", modify: func(r *CreateAuctionRequest) { r.ExtendBy = -1 }, wantErr: "extendWithin and extendBy must be non-negative"},
+		{name: "financing", modify: func(r *CreateAuctionRequest) { r.PaymentMethod = PaymentMethodFinancing; r.Escrow = false }, wantErr: "auctions cannot be paid with financing"},
+		{name: "escrow with cash", modify: func(r *CreateAuctionRequest) { r.PaymentMethod = PaymentMethodCash }, wantErr: "escrow is only available for card and bank_transfer payments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(&req)
+			err := req.Validate(now)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAuction_MinimumBid(t *testing.T) {
+	auction := Auction{
+		StartPrice:   money.MustParse("10000", "USD"),
+		BidIncrement: money.MustParse("250", "USD"),
+	}
+	assert.Equal(t, "10000.00", auction.MinimumBid().Decimal(), "the first bid only has to meet the start price")
+
+	leader := primitive.NewObjectID()
+	auction.LeaderID = &leader
+	auction.CurrentPrice = money.MustParse("12000", "USD")
+	assert.Equal(t, "12250.00", auction.MinimumBid().Decimal())
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Default availability used for inspectors who have not configured a calendar
+const (
+	DefaultSlotMinutes  = 60
+	DefaultWorkingStart = "09:00"
+	DefaultWorkingEnd   = "17:00"
+	DefaultTimeZone     = "UTC"
+)
+
+// InspectorAvailability describes when an inspector can take bookings
+type InspectorAvailability struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	InspectorID   primitive.ObjectID `bson:"inspectorId" json:"inspectorId"`
+	TimeZone      string             `bson:"timeZone" json:"timeZone"` // IANA name, e.g. Africa/Lagos
+	WorkingHours  []WorkingHours     `bson:"workingHours" json:"workingHours"`
+	BlackoutDates []BlackoutPeriod   `bson:"blackoutDates,omitempty" json:"blackoutDates,omitempty"`
+	SlotMinutes   int                `bson:"slotMinutes" json:"slotMinutes"`
+	CreatedAt     time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt     time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// WorkingHours is a daily working window in the inspector's time zone
+type WorkingHours struct {
+	Weekday time.Weekday `bson:"weekday" json:"weekday"` // 0 = Sunday
+	Start   string       `bson:"start" json:"start"`     // HH:MM
+	End     string       `bson:"end" json:"end"`         // HH:MM
+}
+
+// BlackoutPeriod is a span during which the inspector takes no bookings
+type BlackoutPeriod struct {
+	Start  time.Time `bson:"start" json:"start"`
+	End    time.Time `bson:"end" json:"end"`
+	Reason string    `bson:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// SlotReservation claims one inspector slot for an inspection
+// A unique index on (inspectorId, start) prevents double-booking
+type SlotReservation struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	InspectorID  primitive.ObjectID `bson:"inspectorId" json:"inspectorId"`
+	InspectionID primitive.ObjectID `bson:"inspectionId" json:"inspectionId"`
+	Start        time.Time          `bson:"start" json:"start"`
+	End          time.Time          `bson:"end" json:"end"`
+	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// InspectionSlot is a bookable time window with a specific inspector
+type InspectionSlot struct {
+	InspectorID primitive.ObjectID `json:"inspectorId"`
+	Start       time.Time          `json:"start"`
+	End         time.Time          `json:"end"`
+}
+
+// SetAvailabilityRequest represents the request to replace an inspector's availability
+type SetAvailabilityRequest struct {
+	TimeZone      string           `json:"timeZone"`
+	WorkingHours  []WorkingHours   `json:"workingHours" binding:"required"`
+	BlackoutDates []BlackoutPeriod `json:"blackoutDates"`
+	SlotMinutes   int              `json:"slotMinutes" binding:"required"`
+}
+
+// DefaultInspectorAvailability returns a Monday to Friday, nine to five calendar
+func DefaultInspectorAvailability(inspectorID primitive.ObjectID) *InspectorAvailability {
+	hours := make([]WorkingHours, 0, 5)
+	for day := time.Monday; day <= time.Friday; day++ {
+		hours = append(hours, WorkingHours{Weekday: day, Start: DefaultWorkingStart, End: DefaultWorkingEnd})
+	}
+
+	return &InspectorAvailability{
+		InspectorID:  inspectorID,
+		TimeZone:     DefaultTimeZone,
+		WorkingHours: hours,
+		SlotMinutes:  DefaultSlotMinutes,
+	}
+}
+
+// Validate validates the SetAvailabilityRequest
+func (r *SetAvailabilityRequest) Validate() error {
+	if r.TimeZone != "" {
+		if _, err := time.LoadLocation(r.TimeZone); err != nil {
+			return errors.New("invalid timeZone")
+		}
+	}
+
+	if r.SlotMinutes < 15 || r.SlotMinutes > 480 {
+		return errors.New("slotMinutes must be between 15 and 480")
+	}
+
+	if len(r.WorkingHours) == 0 {
+		return errors.New("at least one working hours entry is required")
+	}
+
+	for i, wh := range r.WorkingHours {
+		if wh.Weekday < time.Sunday || wh.Weekday > time.Saturday {
+			return fmt.Errorf("invalid weekday at index %d", i)
+		}
+		start, err := ParseClock(wh.Start)
+		if err != nil {
+			return fmt.Errorf("invalid start time at index %d", i)
+		}
+		end, err := ParseClock(wh.End)
+		if err != nil {
+			return fmt.Errorf("invalid end time at index %d", i)
+		}
+		if end-start < time.Duration(r.SlotMinutes)*time.Minute {
+			return fmt.Errorf("working hours at index %d must fit at least one slot", i)
+		}
+	}
+
+	for i, blackout := range r.BlackoutDates {
+		if !blackout.End.After(blackout.Start) {
+			return fmt.Errorf("blackout end must be after start at index %d", i)
+		}
+	}
+
+	return nil
+}
+
+// ParseClock parses an HH:MM time of day into an offset from midnight
+func ParseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAvailabilityRequest_Validate(t *testing.T) {
+	weekday := []WorkingHours{{Weekday: time.Monday, Start: "09:00", End: "17:00"}}
+	blackoutStart := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		request SetAvailabilityRequest
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid request",
+			request: SetAvailabilityRequest{TimeZone: "Africa/Lagos", WorkingHours: weekday, SlotMinutes: 60},
+			wantErr: false,
+		},
+		{
+			name:    "unknown time zone",
+			request: SetAvailabilityRequest{TimeZone: "Mars/Olympus", WorkingHours: weekday, SlotMinutes: 60},
+			wantErr: true,
+			errMsg:  "invalid timeZone",
+		},
+		{
+			name:    "slot too short",
+			request: SetAvailabilityRequest{WorkingHours: weekday, SlotMinutes: 5},
+			wantErr: true,
+			errMsg:  "slotMinutes must be between 15 and 480",
+		},
+		{
+			name:    "no working hours",
+			request: SetAvailabilityRequest{SlotMinutes: 60},
+			wantErr: true,
+			errMsg:  "at least one working hours entry is required",
+		},
+		{
+			name: "malformed start time",
+			request: SetAvailabilityRequest{
+				WorkingHours: []WorkingHours{{Weekday: time.Monday, Start: "9am", End: "17:00"}},
+				SlotMinutes:  60,
+			},
+			wantErr: true,
+			errMsg:  "invalid start time at index 0",
+		},
+		{
+			name: "window shorter than a slot",
+			request: SetAvailabilityRequest{
+				WorkingHours: []WorkingHours{{Weekday: time.Monday, Start: "09:00", End: "09:30"}},
+				SlotMinutes:  60,
+			},
+			wantErr: true,
+			errMsg:  "working hours at index 0 must fit at least one slot",
+		},
+		{
+			name: "blackout ends before it starts",
+			request: SetAvailabilityRequest{
+				WorkingHours:  weekday,
+				BlackoutDates: []BlackoutPeriod{{Start: blackoutStart, End: blackoutStart.Add(-time.Hour)}},
+				SlotMinutes:   60,
+			},
+			wantErr: true,
+			errMsg:  "blackout end must be after start at index 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.errMsg, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	offset, err := ParseClock("13:45")
+	assert.NoError(t, err)
+	assert.Equal(t, 13*time.Hour+45*time.Minute, offset)
+
+	_, err = ParseClock("25:00")
+	assert.Error(t, err)
+}
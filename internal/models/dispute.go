@@ -0,0 +1,189 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// Dispute status constants
+const (
+	DisputeStatusOpen           = "open"            // raised by the buyer; the transaction is frozen
+	DisputeStatusUnderReview    = "under_review"    // an admin is investigating
+	DisputeStatusResolvedBuyer  = "resolved_buyer"  // decided for the buyer; the sale was cancelled or refunded
+	DisputeStatusResolvedSeller = "resolved_seller" // decided for the seller; the sale carries on and held funds are released
+)
+
+// Dispute reason constants
+const (
+	DisputeReasonNotAsDescribed     = "not_as_described"    // the vehicle does not match its listing
+	DisputeReasonInspectionMismatch = "inspection_mismatch" // the vehicle does not match its inspection report
+	DisputeReasonUndisclosedDamage  = "undisclosed_damage"  // damage or faults that were not disclosed
+	DisputeReasonNotDelivered       = "not_delivered"       // the seller never handed the vehicle over
+	DisputeReasonDocumentation      = "documentation"       // title, registration or ownership papers are missing or wrong
+	DisputeReasonOther              = "other"
+)
+
+// Dispute party role constants, recorded on each message
+const (
+	DisputeRoleBuyer  = "buyer"
+	DisputeRoleSeller = "seller"
+	DisputeRoleAdmin  = "admin"
+)
+
+// Dispute limits
+const (
+	MaxDisputeEvidence      = 20
+	MaxDisputeMessageLength = 2000
+)
+
+// Dispute is a buyer's complaint that a sale does not match its listing or inspection
+// While a dispute is unresolved its transaction is frozen: it cannot be completed or cancelled
+// until an admin resolves the dispute for one of the parties
+type Dispute struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TransactionID primitive.ObjectID `bson:"transactionId" json:"transactionId"`
+	VehicleID     primitive.ObjectID `bson:"vehicleId" json:"vehicleId"`
+	BuyerID       primitive.ObjectID `bson:"buyerId" json:"buyerId"`
+	SellerID      primitive.ObjectID `bson:"sellerId" json:"sellerId"`
+
+	Status      string `bson:"status" json:"status"`
+	Reason      string `bson:"reason" json:"reason"`
+	Description string `bson:"description" json:"description"`
+
+	Evidence []DisputeEvidence `bson:"evidence" json:"evidence"`
+	Messages []DisputeMessage  `bson:"messages" json:"messages"`
+
+	// Admin investigating the dispute, set once it is under review
+	ReviewerID *primitive.ObjectID `bson:"reviewerId,omitempty" json:"reviewerId,omitempty"`
+	ReviewedAt *time.Time          `bson:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+
+	Resolution *DisputeResolution `bson:"resolution,omitempty" json:"resolution,omitempty"`
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// DisputeEvidence is a photo uploaded by a party to support their side of a dispute
+type DisputeEvidence struct {
+	URL        string             `bson:"url" json:"url"`
+	PublicID   string             `bson:"publicId" json:"publicId"`
+	Format     string             `bson:"format,omitempty" json:"format,omitempty"`
+	Width      int                `bson:"width,omitempty" json:"width,omitempty"`
+	Height     int                `bson:"height,omitempty" json:"height,omitempty"`
+	Bytes      int                `bson:"bytes,omitempty" json:"bytes,omitempty"`
+	UploadedBy primitive.ObjectID `bson:"uploadedBy" json:"uploadedBy"`
+	UploadedAt time.Time          `bson:"uploadedAt" json:"uploadedAt"`
+}
+
+// DisputeMessage is one entry in the thread between the buyer, the seller and admins
+type DisputeMessage struct {
+	AuthorID   primitive.ObjectID `bson:"authorId" json:"authorId"`
+	AuthorRole string             `bson:"authorRole" json:"authorRole"`
+	Body       string             `bson:"body" json:"body"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// DisputeResolution records how an admin decided a dispute
+// The transaction's status history and refunds show what the decision did to the sale
+type DisputeResolution struct {
+	Outcome    string              `bson:"outcome" json:"outcome"` // resolved_buyer or resolved_seller
+	Notes      string              `bson:"notes" json:"notes"`
+	RefundID   *primitive.ObjectID `bson:"refundId,omitempty" json:"refundId,omitempty"` // the refund issued on a completed sale resolved for the buyer
+	ResolvedBy primitive.ObjectID  `bson:"resolvedBy" json:"resolvedBy"`
+	ResolvedAt time.Time           `bson:"resolvedAt" json:"resolvedAt"`
+}
+
+// IsValidDisputeStatus checks if the dispute status is valid
+func IsValidDisputeStatus(status string) bool {
+	switch status {
+	case DisputeStatusOpen, DisputeStatusUnderReview, DisputeStatusResolvedBuyer, DisputeStatusResolvedSeller:
+		return true
+	}
+	return false
+}
+
+// IsValidDisputeReason checks if the dispute reason is valid
+func IsValidDisputeReason(reason string) bool {
+	switch reason {
+	case DisputeReasonNotAsDescribed, DisputeReasonInspectionMismatch, DisputeReasonUndisclosedDamage,
+		DisputeReasonNotDelivered, DisputeReasonDocumentation, DisputeReasonOther:
+		return true
+	}
+	return false
+}
+
+// IsResolved reports whether an admin has decided the dispute
+func (d *Dispute) IsResolved() bool {
+	return d.Status == DisputeStatusResolvedBuyer || d.Status == DisputeStatusResolvedSeller
+}
+
+// OpenDisputeRequest represents the buyer's request to dispute a transaction
+type OpenDisputeRequest struct {
+	Reason      string `json:"reason" binding:"required"`
+	Description string `json:"description" binding:"required"`
+}
+
+// DisputeMessageRequest represents a message posted to a dispute's thread
+type DisputeMessageRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// ResolveDisputeRequest represents an admin's decision on a dispute
+// Refund fields only apply when a completed sale is resolved for the buyer; unfinished sales are
+// cancelled, which returns the whole payment
+type ResolveDisputeRequest struct {
+	Outcome         string      `json:"outcome" binding:"required"` // resolved_buyer or resolved_seller
+	Notes           string      `json:"notes" binding:"required"`
+	RefundAmount    money.Money `json:"refundAmount"`    // the whole refundable amount when omitted
+	Reference       string      `json:"reference"`       // payout reference, required unless the payment provider refunds the payment
+	RevertOwnership bool        `json:"revertOwnership"` // give the vehicle back to the seller; full refunds only
+}
+
+// Validate validates the OpenDisputeRequest
+func (r *OpenDisputeRequest) Validate() error {
+	if !IsValidDisputeReason(r.Reason) {
+		return errors.New("invalid reason value")
+	}
+	if r.Description == "" {
+		return errors.New("description is required")
+	}
+	if len(r.Description) > MaxDisputeMessageLength {
+		return errors.New("description must be at most 2000 characters")
+	}
+	return nil
+}
+
+// Validate validates the DisputeMessageRequest
+func (r *DisputeMessageRequest) Validate() error {
+	if r.Body == "" {
+		return errors.New("body is required")
+	}
+	if len(r.Body) > MaxDisputeMessageLength {
+		return errors.New("body must be at most 2000 characters")
+	}
+	return nil
+}
+
+// Validate validates the ResolveDisputeRequest
+func (r *ResolveDisputeRequest) Validate() error {
+	if r.Outcome != DisputeStatusResolvedBuyer && r.Outcome != DisputeStatusResolvedSeller {
+		return errors.New("outcome must be resolved_buyer or resolved_seller")
+	}
+	if r.Notes == "" {
+		return errors.New("notes are required")
+	}
+	if len(r.Notes) > MaxDisputeMessageLength {
+		return errors.New("notes must be at most 2000 characters")
+	}
+	if r.RefundAmount.IsNegative() {
+		return errors.New("refundAmount must be greater than 0")
+	}
+	if r.Outcome == DisputeStatusResolvedSeller && (!r.RefundAmount.IsZero() || r.Reference != "" || r.RevertOwnership) {
+		return errors.New("refunds only apply when the dispute is resolved for the buyer")
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func TestOpenDisputeRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     OpenDisputeRequest
+		wantErr string
+	}{
+		{name: "valid dispute", req: OpenDisputeRequest{Reason: DisputeReasonInspectionMismatch, Description: "The report shows no rust but the sills are rotten"}},
+		{name: "unknown reason", req: OpenDisputeRequest{Reason: "buyers_remorse", Description: "Changed my mind"}, wantErr: "invalid reason value"},
+		{name: "missing description", req: OpenDisputeRequest{Reason: DisputeReasonOther}, wantErr: "description is required"},
+		{name: "description too long", req: OpenDisputeRequest{Reason: DisputeReasonOther, Description: strings.Repeat("a", MaxDisputeMessageLength+1)}, wantErr: "description must be at most 2000 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDisputeMessageRequest_Validate(t *testing.T) {
+	req := DisputeMessageRequest{Body: "Photos of the odometer attached"}
+	assert.NoError(t, req.Validate())
+
+	req.Body = ""
+	assert.EqualError(t, req.Validate(), "body is required")
+
+	req.Body = strings.Repeat("a", MaxDisputeMessageLength+1)
+	assert.EqualError(t, req.Validate(), "body must be at most 2000 characters")
+}
+
+func TestResolveDisputeRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     ResolveDisputeRequest
+		wantErr string
+	}{
+		{name: "resolved for buyer", req: ResolveDisputeRequest{Outcome: DisputeStatusResolvedBuyer, Notes: "Inspection missed the flood damage"}},
+		{name: "partial refund for buyer", req: ResolveDisputeRequest{Outcome: DisputeStatusResolvedBuyer, Notes: "Repair costs", RefundAmount: money.MustParse("1500", ""), Reference: "PAYOUT-1"}},
+		{name: "resolved for seller", req: ResolveDisputeRequest{Outcome: DisputeStatusResolvedSeller, Notes: "Damage was disclosed in the listing"}},
+		{name: "unknown outcome", req: ResolveDisputeRequest{Outcome: DisputeStatusUnderReview, Notes: "x"}, wantErr: "outcome must be resolved_buyer or resolved_seller"},
+		{name: "missing notes", req: ResolveDisputeRequest{Outcome: DisputeStatusResolvedSeller}, wantErr: "notes are required"},
+		{name: "negative refund", req: ResolveDisputeRequest{Outcome: DisputeStatusResolvedBuyer, Notes: "x", RefundAmount: money.MustParse("-1", "")}, wantErr: "refundAmount must be greater than 0"},
+		{name: "refund for seller", req: ResolveDisputeRequest{Outcome: DisputeStatusResolvedSeller, Notes: "x", RefundAmount: money.MustParse("100", "")}, wantErr: "refunds only apply when the dispute is resolved for the buyer"},
+		{name: "revert for seller", req: ResolveDisputeRequest{Outcome: DisputeStatusResolvedSeller, Notes: "x", RevertOwnership: true}, wantErr: "refunds only apply when the dispute is resolved for the buyer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDispute_IsResolved(t *testing.T) {
+	for status, want := range map[string]bool{
+		DisputeStatusOpen:           false,
+		DisputeStatusUnderReview:    false,
+		DisputeStatusResolvedBuyer:  true,
+		DisputeStatusResolvedSeller: true,
+	} {
+		dispute := Dispute{Status: status}
+		assert.Equal(t, want, dispute.IsResolved(), status)
+		assert.True(t, IsValidDisputeStatus(status))
+	}
+	assert.False(t, IsValidDisputeStatus("closed"))
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// ExchangeRateTable lists the value of one unit of a base currency in other currencies
+// Rates between two quoted currencies are crossed through the base
+type ExchangeRateTable struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Base        string             `bson:"base" json:"base"`
+	Rates       map[string]float64 `bson:"rates" json:"rates"` // units of each currency per unit of base
+	Source      string             `bson:"source" json:"source"`
+	EffectiveAt time.Time          `bson:"effectiveAt" json:"effectiveAt"`
+	UploadedBy  primitive.ObjectID `bson:"uploadedBy,omitempty" json:"uploadedBy,omitempty"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// ExchangeRate is the rate used to convert an amount from one currency to another
+type ExchangeRate struct {
+	From   string    `bson:"from" json:"from"`
+	To     string    `bson:"to" json:"to"`
+	Rate   float64   `bson:"rate" json:"rate"` // units of To per unit of From
+	Source string    `bson:"source" json:"source"`
+	AsOf   time.Time `bson:"asOf" json:"asOf"`
+}
+
+// ExchangeRateSnapshot records how a transaction's amount related to the vehicle's listed price when it was created
+type ExchangeRateSnapshot struct {
+	ExchangeRate `bson:",inline"`
+	ListingPrice money.Money `bson:"listingPrice" json:"listingPrice"` // the vehicle's price in its own currency
+	Converted    money.Money `bson:"converted" json:"converted"`       // the listing price in the transaction's currency
+	CapturedAt   time.Time   `bson:"capturedAt" json:"capturedAt"`
+}
+
+// UploadExchangeRatesRequest represents the request body for uploading an exchange rate table
+type UploadExchangeRatesRequest struct {
+	Base        string             `json:"base" binding:"required"`
+	Rates       map[string]float64 `json:"rates" binding:"required"`
+	Source      string             `json:"source"`
+	EffectiveAt *time.Time         `json:"effectiveAt"` // now when omitted
+}
+
+// Validate validates the UploadExchangeRatesRequest
+func (r *UploadExchangeRatesRequest) Validate() error {
+	table := ExchangeRateTable{Base: r.Base, Rates: r.Rates}
+	return table.Validate()
+}
+
+// Validate checks that every currency is a supported ISO 4217 code with a positive rate
+func (t *ExchangeRateTable) Validate() error {
+	if !money.IsValidCurrency(t.Base) {
+		return fmt.Errorf("base currency %q is not a supported ISO 4217 code", t.Base)
+	}
+	if len(t.Rates) == 0 {
+		return errors.New("at least one rate is required")
+	}
+	for currency, rate := range t.Rates {
+		if !money.IsValidCurrency(currency) {
+			return fmt.Errorf("currency %q is not a supported ISO 4217 code", currency)
+		}
+		if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+			return fmt.Errorf("rate for %s must be greater than 0", currency)
+		}
+	}
+	if rate, ok := t.Rates[t.Base]; ok && rate != 1 {
+		return fmt.Errorf("rate for the base currency %s must be 1", t.Base)
+	}
+	return nil
+}
+
+// Currencies returns every currency the table can convert between, including the base
+func (t *ExchangeRateTable) Currencies() []string {
+	currencies := []string{t.Base}
+	for currency := range t.Rates {
+		if currency != t.Base {
+			currencies = append(currencies, currency)
+		}
+	}
+	return currencies
+}
+
+// Rate returns the rate that converts from one currency to another
+func (t *ExchangeRateTable) Rate(from, to string) (ExchangeRate, error) {
+	fromRate, ok := t.unitsPerBase(from)
+	if !ok {
+		return ExchangeRate{}, fmt.Errorf("no exchange rate for %s", from)
+	}
+	toRate, ok := t.unitsPerBase(to)
+	if !ok {
+		return ExchangeRate{}, fmt.Errorf("no exchange rate for %s", to)
+	}
+
+	return ExchangeRate{
+		From:   from,
+		To:     to,
+		Rate:   toRate / fromRate,
+		Source: t.Source,
+		AsOf:   t.EffectiveAt,
+	}, nil
+}
+
+// unitsPerBase returns how many units of currency one unit of the base is worth
+func (t *ExchangeRateTable) unitsPerBase(currency string) (float64, bool) {
+	if currency == t.Base {
+		return 1, true
+	}
+	rate, ok := t.Rates[currency]
+	return rate, ok
+}
+
+// Convert converts an amount with the rate, rounding half to even to the target currency's minor unit
+func (r ExchangeRate) Convert(amount money.Money) (money.Money, error) {
+	if amount.Currency() != r.From {
+		return money.Money{}, fmt.Errorf("%w: cannot convert %s with a %s rate", money.ErrCurrencyMismatch, amount.Currency(), r.From)
+	}
+	return amount.Convert(r.To, r.Rate, money.RoundHalfEven)
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func TestExchangeRateTable_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   ExchangeRateTable
+		wantErr string
+	}{
+		{name: "valid table", table: ExchangeRateTable{Base: "USD", Rates: map[string]float64{"NGN": 1520.25, "EUR": 0.92, "USD": 1}}},
+		{name: "unknown base", table: ExchangeRateTable{Base: "usd", Rates: map[string]float64{"NGN": 1520}}, wantErr: `base currency "usd" is not a supported ISO 4217 code`},
+		{name: "no rates", table: ExchangeRateTable{Base: "USD"}, wantErr: "at least one rate is required"},
+		{name: "unknown currency", table: ExchangeRateTable{Base: "USD", Rates: map[string]float64{"XYZ": 2}}, wantErr: `currency "XYZ" is not a supported ISO 4217 code`},
+		{name: "non-positive rate", table: ExchangeRateTable{Base: "USD", Rates: map[string]float64{"NGN": 0}}, wantErr: "rate for NGN must be greater than 0"},
+		{name: "base rate other than one", table: ExchangeRateTable{Base: "USD", Rates: map[string]float64{"USD": 1.1}}, wantErr: "rate for the base currency USD must be 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.table.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestExchangeRateTable_Rate(t *testing.T) {
+	table := ExchangeRateTable{Base: "USD", Rates: map[string]float64{"NGN": 1500, "EUR": 0.8}, Source: "treasury"}
+
+	rate, err := table.Rate("USD", "NGN")
+	require.NoError(t, err)
+	assert.Equal(t, 1500.0, rate.Rate)
+	assert.Equal(t, "treasury", rate.Source)
+
+	rate, err = table.Rate("NGN", "USD")
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0/1500, rate.Rate, 1e-12)
+
+	// Two quoted currencies cross through the base
+	rate, err = table.Rate("EUR", "NGN")
+	require.NoError(t, err)
+	assert.InDelta(t, 1875.0, rate.Rate, 1e-9)
+
+	_, err = table.Rate("USD", "GBP")
+	assert.EqualError(t, err, "no exchange rate for GBP")
+}
+
+func TestExchangeRate_Convert(t *testing.T) {
+	rate := ExchangeRate{From: "EUR", To: "NGN", Rate: 1875}
+
+	converted, err := rate.Convert(money.MustParse("20000.50", "EUR"))
+	require.NoError(t, err)
+	assert.Equal(t, money.MustParse("37500937.50", "NGN"), converted)
+
+	_, err = rate.Convert(money.MustParse("100", "USD"))
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Over-knight/Lujay-assesment/internal/financing"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// FinancingQuoteRequest represents a request to price a financed purchase before creating a transaction
+type FinancingQuoteRequest struct {
+	Amount         money.Money `json:"amount"`
+	Currency       string      `json:"currency"` // defaults to money.DefaultCurrency
+	DownPayment    money.Money `json:"downPayment"`
+	InterestRate   float64     `json:"interestRate"`
+	FinancingTerms int         `json:"financingTerms"` // in months
+	FinancingYears float64     `json:"financingYears"` // fractional-year term, overrides financingTerms
+	BalloonPayment money.Money `json:"balloonPayment"`
+	Compounding    string      `json:"compounding"`
+	FinancingFees  money.Money `json:"financingFees"`
+}
+
+// Validate validates the FinancingQuoteRequest
+func (r *FinancingQuoteRequest) Validate() error {
+	if !r.Amount.IsPositive() {
+		return errors.New("amount must be greater than 0")
+	}
+
+	if r.Currency != "" && !money.IsValidCurrency(r.Currency) {
+		return errors.New("currency must be a supported ISO 4217 code")
+	}
+
+	if r.DownPayment.IsNegative() {
+		return errors.New("downPayment cannot be negative")
+	}
+
+	if cmp, err := r.DownPayment.Cmp(r.Amount); err == nil && cmp >= 0 {
+		return errors.New("downPayment must be less than total amount")
+	}
+
+	if r.FinancingTerms <= 0 && r.FinancingYears <= 0 {
+		return errors.New("financingTerms or financingYears is required")
+	}
+
+	terms, err := r.Terms()
+	if err != nil {
+		return err
+	}
+	return terms.Validate()
+}
+
+// Terms returns the loan terms of the quote with the request's currency attached to every amount
+func (r *FinancingQuoteRequest) Terms() (financing.Terms, error) {
+	currency := r.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	amount, err := r.Amount.WithCurrency(currency)
+	if err != nil {
+		return financing.Terms{}, fmt.Errorf("amount: %w", err)
+	}
+	details, err := r.PaymentDetails().WithCurrency(currency)
+	if err != nil {
+		return financing.Terms{}, err
+	}
+	return details.FinancingTermsFor(amount)
+}
+
+// PaymentDetails returns the financing fields as they would be stored on a transaction
+func (r *FinancingQuoteRequest) PaymentDetails() PaymentDetails {
+	return PaymentDetails{
+		DownPayment:    r.DownPayment,
+		InterestRate:   r.InterestRate,
+		FinancingTerms: r.FinancingTerms,
+		FinancingYears: r.FinancingYears,
+		BalloonPayment: r.BalloonPayment,
+		Compounding:    r.Compounding,
+		FinancingFees:  r.FinancingFees,
+	}
+}
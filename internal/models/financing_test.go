@@ -0,0 +1,71 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func TestFinancingQuoteRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request FinancingQuoteRequest
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid quote in months",
+			request: FinancingQuoteRequest{Amount: money.MustParse("25000", ""), DownPayment: money.MustParse("5000", ""), InterestRate: 6, FinancingTerms: 60},
+			wantErr: false,
+		},
+		{
+			name:    "valid quote in fractional years without down payment",
+			request: FinancingQuoteRequest{Amount: money.MustParse("25000", ""), InterestRate: 6, FinancingYears: 3.5, Compounding: "continuous"},
+			wantErr: false,
+		},
+		{
+			name:    "missing amount",
+			request: FinancingQuoteRequest{InterestRate: 6, FinancingTerms: 60},
+			wantErr: true,
+			errMsg:  "amount must be greater than 0",
+		},
+		{
+			name:    "down payment covers the price",
+			request: FinancingQuoteRequest{Amount: money.MustParse("25000", ""), DownPayment: money.MustParse("25000", ""), FinancingTerms: 60},
+			wantErr: true,
+			errMsg:  "downPayment must be less than total amount",
+		},
+		{
+			name:    "missing term",
+			request: FinancingQuoteRequest{Amount: money.MustParse("25000", ""), InterestRate: 6},
+			wantErr: true,
+			errMsg:  "financingTerms or financingYears is required",
+		},
+		{
+			name:    "term shorter than a month",
+			request: FinancingQuoteRequest{Amount: money.MustParse("25000", ""), InterestRate: 6, FinancingYears: 0.05},
+			wantErr: true,
+			errMsg:  "term must be at least one month",
+		},
+		{
+			name:    "negative fees",
+			request: FinancingQuoteRequest{Amount: money.MustParse("25000", ""), InterestRate: 6, FinancingTerms: 60, FinancingFees: money.MustParse("-10", "")},
+			wantErr: true,
+			errMsg:  "fees cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.errMsg, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
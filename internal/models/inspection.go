@@ -2,39 +2,71 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
 )
 
 // Inspection status constants
 const (
-	InspectionStatusPending   = "pending"
-	InspectionStatusScheduled = "scheduled"
-	InspectionStatusCompleted = "completed"
-	InspectionStatusCancelled = "cancelled"
+	InspectionStatusPending    = "pending"
+	InspectionStatusScheduled  = "scheduled"
+	InspectionStatusInProgress = "in_progress"
+	InspectionStatusCompleted  = "completed"
+	InspectionStatusCancelled  = "cancelled"
 )
 
 // Inspection represents a vehicle inspection record
 type Inspection struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	VehicleID   primitive.ObjectID `bson:"vehicleId" json:"vehicleId"`
-	InspectorID primitive.ObjectID `bson:"inspectorId" json:"inspectorId"`
+	RequestedBy primitive.ObjectID `bson:"requestedBy,omitempty" json:"requestedBy,omitempty"`
+	InspectorID primitive.ObjectID `bson:"inspectorId,omitempty" json:"inspectorId,omitempty"` // empty until assigned
 	Status      string             `bson:"status" json:"status"`
 
+	// Assignment details
+	AssignedAt       *time.Time `bson:"assignedAt,omitempty" json:"assignedAt,omitempty"`
+	AssignmentMethod string     `bson:"assignmentMethod,omitempty" json:"assignmentMethod,omitempty"` // auto, manual
+
 	// Inspection details
 	ScheduledAt time.Time  `bson:"scheduledAt,omitempty" json:"scheduledAt,omitempty"`
 	CompletedAt *time.Time `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
 
+	// Checklist template version the report is completed against, pinned when the inspection is scheduled
+	Template *InspectionTemplateRef `bson:"template,omitempty" json:"template,omitempty"`
+
 	// Inspection report
 	Report InspectionReport `bson:"report" json:"report"`
 
+	// Tamper-evident signature applied on completion
+	Seal *InspectionSeal `bson:"seal,omitempty" json:"seal,omitempty"`
+
+	// Public IDs of every file uploaded as issue evidence, kept so they can be removed with the inspection
+	MediaPublicIDs []string `bson:"mediaPublicIds,omitempty" json:"-"`
+
+	// Lifecycle log of every applied status transition and reschedule
+	Transitions []InspectionTransition `bson:"transitions,omitempty" json:"transitions,omitempty"`
+
 	// Additional info
 	Notes     string    `bson:"notes,omitempty" json:"notes,omitempty"`
 	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
 	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
 }
 
+// InspectionTransition records a single applied lifecycle transition
+type InspectionTransition struct {
+	From        string             `bson:"from,omitempty" json:"from,omitempty"`
+	To          string             `bson:"to" json:"to"`
+	ActorID     primitive.ObjectID `bson:"actorId" json:"actorId"`
+	ActorRole   string             `bson:"actorRole" json:"actorRole"` // inspector, requester, admin, system
+	Reason      string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	ScheduledAt *time.Time         `bson:"scheduledAt,omitempty" json:"scheduledAt,omitempty"` // set when (re)scheduling
+	ChangedAt   time.Time          `bson:"changedAt" json:"changedAt"`
+}
+
 // InspectionReport contains the details of an inspection
 type InspectionReport struct {
 	OverallCondition string            `bson:"overallCondition" json:"overallCondition"` // excellent, good, fair, poor
@@ -43,21 +75,64 @@ type InspectionReport struct {
 	InteriorScore    int               `bson:"interiorScore" json:"interiorScore"`       // 0-100
 	Issues           []InspectionIssue `bson:"issues,omitempty" json:"issues,omitempty"`
 	Recommendations  []string          `bson:"recommendations,omitempty" json:"recommendations,omitempty"`
-	EstimatedRepairs float64           `bson:"estimatedRepairs" json:"estimatedRepairs"`
+	EstimatedRepairs money.Money       `bson:"estimatedRepairs" json:"estimatedRepairs"`       // in money.DefaultCurrency
+	Checklist        []ChecklistAnswer `bson:"checklist,omitempty" json:"checklist,omitempty"` // answers to the inspection's template
+
+	// Server-computed scores, set on completion; the fields above keep the inspector's submitted values
+	Computed *ComputedScores `bson:"computed,omitempty" json:"computed,omitempty"`
+}
+
+// ComputedScores are the scores derived from a report's weighted issues
+type ComputedScores struct {
+	OverallCondition string          `bson:"overallCondition" json:"overallCondition"`
+	OverallScore     int             `bson:"overallScore" json:"overallScore"`
+	MechanicalScore  int             `bson:"mechanicalScore" json:"mechanicalScore"`
+	ExteriorScore    int             `bson:"exteriorScore" json:"exteriorScore"`
+	InteriorScore    int             `bson:"interiorScore" json:"interiorScore"`
+	HasMismatch      bool            `bson:"hasMismatch" json:"hasMismatch"`
+	Mismatches       []ScoreMismatch `bson:"mismatches,omitempty" json:"mismatches,omitempty"`
+	ComputedAt       time.Time       `bson:"computedAt" json:"computedAt"`
 }
 
+// ScoreMismatch records a submitted value that disagrees with the computed one
+type ScoreMismatch struct {
+	Field     string `bson:"field" json:"field"`
+	Submitted string `bson:"submitted" json:"submitted"`
+	Computed  string `bson:"computed" json:"computed"`
+}
+
+// Inspector assignment method constants
+const (
+	AssignmentMethodAuto   = "auto"
+	AssignmentMethodManual = "manual"
+)
+
 // InspectionIssue represents a specific issue found during inspection
 type InspectionIssue struct {
-	Category    string `bson:"category" json:"category"` // mechanical, electrical, body, interior, etc.
-	Severity    string `bson:"severity" json:"severity"` // critical, major, minor
-	Description string `bson:"description" json:"description"`
-	Location    string `bson:"location,omitempty" json:"location,omitempty"`
+	Category    string       `bson:"category" json:"category"` // mechanical, electrical, body, interior, etc.
+	Severity    string       `bson:"severity" json:"severity"` // critical, major, minor
+	Description string       `bson:"description" json:"description"`
+	Location    string       `bson:"location,omitempty" json:"location,omitempty"`
+	Media       []IssueMedia `bson:"media,omitempty" json:"media,omitempty"` // photos and screenshots evidencing the issue
+}
+
+// IssueMedia references an uploaded photo or screenshot attached to an issue
+type IssueMedia struct {
+	URL        string             `bson:"url" json:"url"`
+	PublicID   string             `bson:"publicId" json:"publicId"`
+	Format     string             `bson:"format,omitempty" json:"format,omitempty"`
+	Width      int                `bson:"width,omitempty" json:"width,omitempty"`
+	Height     int                `bson:"height,omitempty" json:"height,omitempty"`
+	Bytes      int                `bson:"bytes,omitempty" json:"bytes,omitempty"`
+	UploadedBy primitive.ObjectID `bson:"uploadedBy" json:"uploadedBy"`
+	UploadedAt time.Time          `bson:"uploadedAt" json:"uploadedAt"`
 }
 
 // CreateInspectionRequest represents the request to create an inspection
 type CreateInspectionRequest struct {
 	VehicleID   string    `json:"vehicleId" binding:"required"`
 	ScheduledAt time.Time `json:"scheduledAt" binding:"required"`
+	TemplateKey string    `json:"templateKey"` // optional checklist template
 	Notes       string    `json:"notes"`
 }
 
@@ -65,6 +140,7 @@ type CreateInspectionRequest struct {
 type UpdateInspectionRequest struct {
 	Status      string            `json:"status"`
 	ScheduledAt *time.Time        `json:"scheduledAt"`
+	TemplateKey string            `json:"templateKey"`
 	Report      *InspectionReport `json:"report"`
 	Notes       string            `json:"notes"`
 }
@@ -75,6 +151,30 @@ type CompleteInspectionRequest struct {
 	Notes  string           `json:"notes"`
 }
 
+// RescheduleInspectionRequest represents the request to move an inspection to a new time
+type RescheduleInspectionRequest struct {
+	ScheduledAt time.Time `json:"scheduledAt" binding:"required"`
+	Reason      string    `json:"reason"`
+}
+
+// AssignInspectorRequest represents an admin request to assign or reassign an inspection
+// Leaving InspectorID empty lets the auto-assigner pick an inspector
+type AssignInspectorRequest struct {
+	InspectorID string `json:"inspectorId"`
+	Reason      string `json:"reason"`
+}
+
+// Validate validates the AssignInspectorRequest
+func (r *AssignInspectorRequest) Validate() error {
+	if r.InspectorID != "" {
+		if _, err := primitive.ObjectIDFromHex(r.InspectorID); err != nil {
+			return errors.New("invalid inspectorId format")
+		}
+	}
+
+	return nil
+}
+
 // Validate validates the CreateInspectionRequest
 func (r *CreateInspectionRequest) Validate() error {
 	if r.VehicleID == "" {
@@ -115,6 +215,19 @@ func (r *UpdateInspectionRequest) Validate() error {
 	return nil
 }
 
+// Validate validates the RescheduleInspectionRequest
+func (r *RescheduleInspectionRequest) Validate() error {
+	if r.ScheduledAt.IsZero() {
+		return errors.New("scheduledAt is required")
+	}
+
+	if r.ScheduledAt.Before(time.Now()) {
+		return errors.New("scheduledAt cannot be in the past")
+	}
+
+	return nil
+}
+
 // Validate validates the CompleteInspectionRequest
 func (r *CompleteInspectionRequest) Validate() error {
 	return r.Report.Validate()
@@ -150,10 +263,14 @@ func (r *InspectionReport) Validate() error {
 		return errors.New("interiorScore must be between 0 and 100")
 	}
 
-	if r.EstimatedRepairs < 0 {
+	if r.EstimatedRepairs.IsNegative() {
 		return errors.New("estimatedRepairs cannot be negative")
 	}
 
+	if _, err := r.EstimatedRepairs.WithCurrency(money.DefaultCurrency); err != nil {
+		return fmt.Errorf("estimatedRepairs: %w", err)
+	}
+
 	// Validate issues
 	for i, issue := range r.Issues {
 		if issue.Category == "" {
@@ -186,6 +303,7 @@ func IsValidInspectionStatus(status string) bool {
 	validStatuses := []string{
 		InspectionStatusPending,
 		InspectionStatusScheduled,
+		InspectionStatusInProgress,
 		InspectionStatusCompleted,
 		InspectionStatusCancelled,
 	}
@@ -0,0 +1,35 @@
+package models
+
+// InspectionSeal is the server's signature over a completed inspection
+type InspectionSeal struct {
+	Code      string                `bson:"code" json:"code"` // short public verification code
+	Payload   InspectionSealPayload `bson:"payload" json:"payload"`
+	Signature string                `bson:"signature" json:"signature"` // base64 Ed25519 over the canonical JSON of Payload
+	KeyID     string                `bson:"keyId" json:"keyId"`
+}
+
+// InspectionSealPayload is the signed summary of a completed inspection
+// Times are RFC 3339 UTC strings with millisecond precision, matching what MongoDB stores
+type InspectionSealPayload struct {
+	Code             string `bson:"code" json:"code"`
+	InspectionID     string `bson:"inspectionId" json:"inspectionId"`
+	VehicleID        string `bson:"vehicleId" json:"vehicleId"`
+	Vehicle          string `bson:"vehicle,omitempty" json:"vehicle,omitempty"` // e.g. "2018 Toyota Corolla" at signing time
+	InspectorID      string `bson:"inspectorId,omitempty" json:"inspectorId,omitempty"`
+	CompletedAt      string `bson:"completedAt" json:"completedAt"`
+	OverallCondition string `bson:"overallCondition" json:"overallCondition"`
+	OverallScore     int    `bson:"overallScore" json:"overallScore"`
+	ReportDigest     string `bson:"reportDigest" json:"reportDigest"` // SHA-256 of the canonical report JSON
+	SignedAt         string `bson:"signedAt" json:"signedAt"`
+}
+
+// InspectionVerification is the public result of checking an inspection seal
+type InspectionVerification struct {
+	Valid     bool                  `json:"valid"`
+	Reason    string                `json:"reason,omitempty"` // why the seal no longer holds
+	Summary   InspectionSealPayload `json:"summary"`
+	Signature string                `json:"signature"`
+	Algorithm string                `json:"algorithm"`
+	KeyID     string                `json:"keyId"`
+	PublicKey string                `json:"publicKey"` // base64, for verifying the signature offline
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InspectionTemplate is a versioned checklist that inspectors fill in when completing an inspection
+// Every edit creates a new version under the same key; old versions are kept so past reports stay interpretable
+type InspectionTemplate struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Key          string             `bson:"key" json:"key"` // stable across versions, e.g. "ev-standard"
+	Version      int                `bson:"version" json:"version"`
+	Name         string             `bson:"name" json:"name"`
+	Description  string             `bson:"description,omitempty" json:"description,omitempty"`
+	VehicleTypes []string           `bson:"vehicleTypes,omitempty" json:"vehicleTypes,omitempty"` // e.g. car, ev, motorcycle, truck
+	Sections     []TemplateSection  `bson:"sections" json:"sections"`
+	Active       bool               `bson:"active" json:"active"` // only the latest version of a key is active
+	CreatedBy    primitive.ObjectID `bson:"createdBy" json:"createdBy"`
+	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// TemplateSection groups related check items
+type TemplateSection struct {
+	Key   string         `bson:"key" json:"key"`
+	Title string         `bson:"title" json:"title"`
+	Items []TemplateItem `bson:"items" json:"items"`
+}
+
+// TemplateItem is a single check the inspector must answer
+type TemplateItem struct {
+	Key            string   `bson:"key" json:"key"`
+	Label          string   `bson:"label" json:"label"`
+	Weight         float64  `bson:"weight" json:"weight"`                                     // relative importance within the template
+	AllowedAnswers []string `bson:"allowedAnswers,omitempty" json:"allowedAnswers,omitempty"` // empty accepts free text
+	RequiredPhotos int      `bson:"requiredPhotos,omitempty" json:"requiredPhotos,omitempty"`
+	Optional       bool     `bson:"optional,omitempty" json:"optional,omitempty"`
+}
+
+// ChecklistAnswer is the inspector's answer to one template item
+type ChecklistAnswer struct {
+	SectionKey string   `bson:"sectionKey" json:"sectionKey"`
+	ItemKey    string   `bson:"itemKey" json:"itemKey"`
+	Answer     string   `bson:"answer" json:"answer"`
+	Photos     []string `bson:"photos,omitempty" json:"photos,omitempty"` // photo URLs
+	Notes      string   `bson:"notes,omitempty" json:"notes,omitempty"`
+}
+
+// InspectionTemplateRef pins the template version an inspection is completed against
+type InspectionTemplateRef struct {
+	ID      primitive.ObjectID `bson:"id" json:"id"`
+	Key     string             `bson:"key" json:"key"`
+	Version int                `bson:"version" json:"version"`
+}
+
+// SaveInspectionTemplateRequest represents the request to create a template or publish a new version of one
+type SaveInspectionTemplateRequest struct {
+	Key          string            `json:"key" binding:"required"`
+	Name         string            `json:"name" binding:"required"`
+	Description  string            `json:"description"`
+	VehicleTypes []string          `json:"vehicleTypes"`
+	Sections     []TemplateSection `json:"sections" binding:"required"`
+}
+
+// Validate validates the SaveInspectionTemplateRequest
+func (r *SaveInspectionTemplateRequest) Validate() error {
+	if r.Key == "" {
+		return errors.New("key is required")
+	}
+
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if len(r.Sections) == 0 {
+		return errors.New("at least one section is required")
+	}
+
+	sectionKeys := map[string]bool{}
+	for i, section := range r.Sections {
+		if section.Key == "" {
+			return fmt.Errorf("section key is required at index %d", i)
+		}
+		if sectionKeys[section.Key] {
+			return fmt.Errorf("duplicate section key %q", section.Key)
+		}
+		sectionKeys[section.Key] = true
+
+		if len(section.Items) == 0 {
+			return fmt.Errorf("section %q must have at least one item", section.Key)
+		}
+
+		itemKeys := map[string]bool{}
+		for j, item := range section.Items {
+			if item.Key == "" {
+				return fmt.Errorf("item key is required at index %d of section %q", j, section.Key)
+			}
+			if itemKeys[item.Key] {
+				return fmt.Errorf("duplicate item key %q in section %q", item.Key, section.Key)
+			}
+			itemKeys[item.Key] = true
+
+			if item.Label == "" {
+				return fmt.Errorf("item %q in section %q needs a label", item.Key, section.Key)
+			}
+			if item.Weight < 0 {
+				return fmt.Errorf("item %q in section %q cannot have a negative weight", item.Key, section.Key)
+			}
+			if item.RequiredPhotos < 0 {
+				return fmt.Errorf("item %q in section %q cannot require a negative number of photos", item.Key, section.Key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Ref returns the reference stored on inspections that use this template
+func (t *InspectionTemplate) Ref() *InspectionTemplateRef {
+	return &InspectionTemplateRef{ID: t.ID, Key: t.Key, Version: t.Version}
+}
+
+// ValidateAnswers checks a completed checklist against the template
+// Every required item must be answered exactly once with an allowed answer and enough photos
+func (t *InspectionTemplate) ValidateAnswers(answers []ChecklistAnswer) error {
+	items := map[string]TemplateItem{}
+	for _, section := range t.Sections {
+		for _, item := range section.Items {
+			items[section.Key+"/"+item.Key] = item
+		}
+	}
+
+	answered := map[string]bool{}
+	for i, answer := range answers {
+		id := answer.SectionKey + "/" + answer.ItemKey
+		item, ok := items[id]
+		if !ok {
+			return fmt.Errorf("checklist answer at index %d refers to unknown item %q", i, id)
+		}
+		if answered[id] {
+			return fmt.Errorf("item %q is answered more than once", id)
+		}
+		answered[id] = true
+
+		if answer.Answer == "" {
+			return fmt.Errorf("item %q needs an answer", id)
+		}
+		if len(item.AllowedAnswers) > 0 && !containsAnswer(item.AllowedAnswers, answer.Answer) {
+			return fmt.Errorf("answer %q is not allowed for item %q", answer.Answer, id)
+		}
+		if len(answer.Photos) < item.RequiredPhotos {
+			return fmt.Errorf("item %q requires at least %d photos", id, item.RequiredPhotos)
+		}
+	}
+
+	for _, section := range t.Sections {
+		for _, item := range section.Items {
+			id := section.Key + "/" + item.Key
+			if !item.Optional && !answered[id] {
+				return fmt.Errorf("item %q must be answered", id)
+			}
+		}
+	}
+
+	return nil
+}
+
+// containsAnswer checks if an answer is in the allowed list
+func containsAnswer(allowed []string, answer string) bool {
+	for _, a := range allowed {
+		if a == answer {
+			return true
+		}
+	}
+	return false
+}
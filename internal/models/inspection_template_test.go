@@ -0,0 +1,132 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func evTemplate() *InspectionTemplate {
+	return &InspectionTemplate{
+		Key:     "ev-standard",
+		Version: 2,
+		Sections: []TemplateSection{
+			{
+				Key:   "battery",
+				Title: "Battery",
+				Items: []TemplateItem{
+					{Key: "soh", Label: "State of health", Weight: 3, AllowedAnswers: []string{"good", "degraded", "failed"}},
+					{Key: "charge-port", Label: "Charge port", Weight: 1, RequiredPhotos: 1},
+				},
+			},
+			{
+				Key:   "body",
+				Title: "Body",
+				Items: []TemplateItem{
+					{Key: "underbody", Label: "Underbody damage", Weight: 1, Optional: true},
+				},
+			},
+		},
+	}
+}
+
+func TestSaveInspectionTemplateRequest_Validate(t *testing.T) {
+	sections := evTemplate().Sections
+
+	tests := []struct {
+		name    string
+		request SaveInspectionTemplateRequest
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid request",
+			request: SaveInspectionTemplateRequest{Key: "ev-standard", Name: "EV standard", Sections: sections},
+			wantErr: false,
+		},
+		{
+			name:    "missing key",
+			request: SaveInspectionTemplateRequest{Name: "EV standard", Sections: sections},
+			wantErr: true,
+			errMsg:  "key is required",
+		},
+		{
+			name:    "no sections",
+			request: SaveInspectionTemplateRequest{Key: "ev-standard", Name: "EV standard"},
+			wantErr: true,
+			errMsg:  "at least one section is required",
+		},
+		{
+			name: "duplicate section key",
+			request: SaveInspectionTemplateRequest{
+				Key:      "ev-standard",
+				Name:     "EV standard",
+				Sections: []TemplateSection{sections[0], sections[0]},
+			},
+			wantErr: true,
+			errMsg:  `duplicate section key "battery"`,
+		},
+		{
+			name: "negative weight",
+			request: SaveInspectionTemplateRequest{
+				Key:  "ev-standard",
+				Name: "EV standard",
+				Sections: []TemplateSection{
+					{Key: "battery", Items: []TemplateItem{{Key: "soh", Label: "State of health", Weight: -1}}},
+				},
+			},
+			wantErr: true,
+			errMsg:  `item "soh" in section "battery" cannot have a negative weight`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.errMsg, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInspectionTemplate_ValidateAnswers(t *testing.T) {
+	template := evTemplate()
+	soh := ChecklistAnswer{SectionKey: "battery", ItemKey: "soh", Answer: "good"}
+	port := ChecklistAnswer{SectionKey: "battery", ItemKey: "charge-port", Answer: "intact", Photos: []string{"https://example.com/port.jpg"}}
+
+	tests := []struct {
+		name    string
+		answers []ChecklistAnswer
+		errMsg  string
+	}{
+		{"all required items answered", []ChecklistAnswer{soh, port}, ""},
+		{"missing required item", []ChecklistAnswer{soh}, `item "battery/charge-port" must be answered`},
+		{"unknown item", []ChecklistAnswer{soh, port, {SectionKey: "tyres", ItemKey: "tread", Answer: "ok"}}, `checklist answer at index 2 refers to unknown item "tyres/tread"`},
+		{"answer not allowed", []ChecklistAnswer{{SectionKey: "battery", ItemKey: "soh", Answer: "excellent"}, port}, `answer "excellent" is not allowed for item "battery/soh"`},
+		{"missing photo", []ChecklistAnswer{soh, {SectionKey: "battery", ItemKey: "charge-port", Answer: "intact"}}, `item "battery/charge-port" requires at least 1 photos`},
+		{"duplicate answer", []ChecklistAnswer{soh, soh, port}, `item "battery/soh" is answered more than once`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := template.ValidateAnswers(tt.answers)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestInspectionTemplate_Ref(t *testing.T) {
+	template := evTemplate()
+	ref := template.Ref()
+
+	assert.Equal(t, "ev-standard", ref.Key)
+	assert.Equal(t, 2, ref.Version)
+}
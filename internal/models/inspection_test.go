@@ -6,6 +6,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
 )
 
 func TestCreateInspectionRequest_Validate(t *testing.T) {
@@ -89,7 +91,7 @@ func TestUpdateInspectionRequest_Validate(t *testing.T) {
 		MechanicalScore:  85,
 		ExteriorScore:    90,
 		InteriorScore:    88,
-		EstimatedRepairs: 500.0,
+		EstimatedRepairs: money.MustParse("500", ""),
 	}
 
 	tests := []struct {
@@ -179,7 +181,7 @@ func TestInspectionReport_Validate(t *testing.T) {
 				MechanicalScore:  85,
 				ExteriorScore:    90,
 				InteriorScore:    88,
-				EstimatedRepairs: 500.0,
+				EstimatedRepairs: money.MustParse("500", ""),
 			},
 			wantErr: false,
 		},
@@ -190,7 +192,7 @@ func TestInspectionReport_Validate(t *testing.T) {
 				MechanicalScore:  70,
 				ExteriorScore:    75,
 				InteriorScore:    80,
-				EstimatedRepairs: 1500.0,
+				EstimatedRepairs: money.MustParse("1500", ""),
 				Issues: []InspectionIssue{
 					{
 						Category:    "mechanical",
@@ -209,7 +211,7 @@ func TestInspectionReport_Validate(t *testing.T) {
 				MechanicalScore:  85,
 				ExteriorScore:    90,
 				InteriorScore:    88,
-				EstimatedRepairs: 500.0,
+				EstimatedRepairs: money.MustParse("500", ""),
 			},
 			wantErr: true,
 			errMsg:  "overallCondition is required",
@@ -221,7 +223,7 @@ func TestInspectionReport_Validate(t *testing.T) {
 				MechanicalScore:  85,
 				ExteriorScore:    90,
 				InteriorScore:    88,
-				EstimatedRepairs: 500.0,
+				EstimatedRepairs: money.MustParse("500", ""),
 			},
 			wantErr: true,
 			errMsg:  "overallCondition must be one of",
@@ -233,7 +235,7 @@ func TestInspectionReport_Validate(t *testing.T) {
 				MechanicalScore:  150,
 				ExteriorScore:    90,
 				InteriorScore:    88,
-				EstimatedRepairs: 500.0,
+				EstimatedRepairs: money.MustParse("500", ""),
 			},
 			wantErr: true,
 			errMsg:  "mechanicalScore must be between 0 and 100",
@@ -245,7 +247,7 @@ func TestInspectionReport_Validate(t *testing.T) {
 				MechanicalScore:  -10,
 				ExteriorScore:    90,
 				InteriorScore:    88,
-				EstimatedRepairs: 500.0,
+				EstimatedRepairs: money.MustParse("500", ""),
 			},
 			wantErr: true,
 			errMsg:  "mechanicalScore must be between 0 and 100",
@@ -257,7 +259,7 @@ func TestInspectionReport_Validate(t *testing.T) {
 				MechanicalScore:  85,
 				ExteriorScore:    101,
 				InteriorScore:    88,
-				EstimatedRepairs: 500.0,
+				EstimatedRepairs: money.MustParse("500", ""),
 			},
 			wantErr: true,
 			errMsg:  "exteriorScore must be between 0 and 100",
@@ -269,7 +271,7 @@ func TestInspectionReport_Validate(t *testing.T) {
 				MechanicalScore:  85,
 				ExteriorScore:    90,
 				InteriorScore:    -5,
-				EstimatedRepairs: 500.0,
+				EstimatedRepairs: money.MustParse("500", ""),
 			},
 			wantErr: true,
 			errMsg:  "interiorScore must be between 0 and 100",
@@ -281,7 +283,7 @@ func TestInspectionReport_Validate(t *testing.T) {
 				MechanicalScore:  85,
 				ExteriorScore:    90,
 				InteriorScore:    88,
-				EstimatedRepairs: -100.0,
+				EstimatedRepairs: money.MustParse("-100", ""),
 			},
 			wantErr: true,
 			errMsg:  "estimatedRepairs cannot be negative",
@@ -293,7 +295,7 @@ func TestInspectionReport_Validate(t *testing.T) {
 				MechanicalScore:  85,
 				ExteriorScore:    90,
 				InteriorScore:    88,
-				EstimatedRepairs: 500.0,
+				EstimatedRepairs: money.MustParse("500", ""),
 				Issues: []InspectionIssue{
 					{
 						Category:    "",
@@ -312,7 +314,7 @@ func TestInspectionReport_Validate(t *testing.T) {
 				MechanicalScore:  85,
 				ExteriorScore:    90,
 				InteriorScore:    88,
-				EstimatedRepairs: 500.0,
+				EstimatedRepairs: money.MustParse("500", ""),
 				Issues: []InspectionIssue{
 					{
 						Category:    "mechanical",
@@ -331,7 +333,7 @@ func TestInspectionReport_Validate(t *testing.T) {
 				MechanicalScore:  85,
 				ExteriorScore:    90,
 				InteriorScore:    88,
-				EstimatedRepairs: 500.0,
+				EstimatedRepairs: money.MustParse("500", ""),
 				Issues: []InspectionIssue{
 					{
 						Category:    "mechanical",
@@ -350,7 +352,7 @@ func TestInspectionReport_Validate(t *testing.T) {
 				MechanicalScore:  85,
 				ExteriorScore:    90,
 				InteriorScore:    88,
-				EstimatedRepairs: 500.0,
+				EstimatedRepairs: money.MustParse("500", ""),
 				Issues: []InspectionIssue{
 					{
 						Category:    "mechanical",
@@ -387,6 +389,7 @@ func TestIsValidInspectionStatus(t *testing.T) {
 	}{
 		{"pending status", InspectionStatusPending, true},
 		{"scheduled status", InspectionStatusScheduled, true},
+		{"in progress status", InspectionStatusInProgress, true},
 		{"completed status", InspectionStatusCompleted, true},
 		{"cancelled status", InspectionStatusCancelled, true},
 		{"invalid status", "invalid", false},
@@ -415,7 +418,7 @@ func TestCompleteInspectionRequest_Validate(t *testing.T) {
 					MechanicalScore:  85,
 					ExteriorScore:    90,
 					InteriorScore:    88,
-					EstimatedRepairs: 500.0,
+					EstimatedRepairs: money.MustParse("500", ""),
 				},
 				Notes: "Inspection completed successfully",
 			},
@@ -446,3 +449,42 @@ func TestCompleteInspectionRequest_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestRescheduleInspectionRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request RescheduleInspectionRequest
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid reschedule",
+			request: RescheduleInspectionRequest{ScheduledAt: time.Now().Add(48 * time.Hour), Reason: "inspector unavailable"},
+			wantErr: false,
+		},
+		{
+			name:    "missing scheduledAt",
+			request: RescheduleInspectionRequest{},
+			wantErr: true,
+			errMsg:  "scheduledAt is required",
+		},
+		{
+			name:    "past scheduledAt",
+			request: RescheduleInspectionRequest{ScheduledAt: time.Now().Add(-time.Hour)},
+			wantErr: true,
+			errMsg:  "scheduledAt cannot be in the past",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.errMsg, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// Installment status constants
+const (
+	InstallmentStatusDue    = "due"    // not yet paid in full, and not past its grace period
+	InstallmentStatusPaid   = "paid"   // paid in full, including any late fee
+	InstallmentStatusLate   = "late"   // past its grace period; a late fee has been charged
+	InstallmentStatusMissed = "missed" // still unpaid long after its due date
+
+	InstallmentStatusCancelled = "cancelled" // written off because the sale was refunded
+)
+
+// Installment is one monthly payment owed on a completed financing transaction
+type Installment struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TransactionID primitive.ObjectID `bson:"transactionId" json:"transactionId"`
+	VehicleID     primitive.ObjectID `bson:"vehicleId" json:"vehicleId"`
+	DealerID      primitive.ObjectID `bson:"dealerId" json:"dealerId"` // the seller collecting the payments
+	BuyerID       primitive.ObjectID `bson:"buyerId" json:"buyerId"`
+
+	Number    int         `bson:"number" json:"number"` // 1-based position in the schedule
+	DueDate   time.Time   `bson:"dueDate" json:"dueDate"`
+	Amount    money.Money `bson:"amount" json:"amount"` // scheduled payment, excluding late fees
+	Principal money.Money `bson:"principal" json:"principal"`
+	Interest  money.Money `bson:"interest" json:"interest"`
+	Currency  string      `bson:"currency" json:"currency"`
+
+	Status     string               `bson:"status" json:"status"`
+	LateFee    money.Money          `bson:"lateFee" json:"lateFee"`
+	AmountPaid money.Money          `bson:"amountPaid" json:"amountPaid"`
+	Payments   []InstallmentPayment `bson:"payments,omitempty" json:"payments,omitempty"`
+	LateAt     *time.Time           `bson:"lateAt,omitempty" json:"lateAt,omitempty"`
+	MissedAt   *time.Time           `bson:"missedAt,omitempty" json:"missedAt,omitempty"`
+	PaidAt     *time.Time           `bson:"paidAt,omitempty" json:"paidAt,omitempty"`
+
+	// Timestamps
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// InstallmentPayment records money received against an installment
+type InstallmentPayment struct {
+	Amount     money.Money        `bson:"amount" json:"amount"`
+	Reference  string             `bson:"reference,omitempty" json:"reference,omitempty"`
+	Notes      string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	RecordedBy primitive.ObjectID `bson:"recordedBy" json:"recordedBy"`
+	PaidAt     time.Time          `bson:"paidAt" json:"paidAt"`
+}
+
+// RecordInstallmentPaymentRequest represents the request body for recording an installment payment
+type RecordInstallmentPaymentRequest struct {
+	Amount    money.Money `json:"amount"` // in the installment's currency
+	Reference string      `json:"reference"`
+	Notes     string      `json:"notes"`
+	PaidAt    *time.Time  `json:"paidAt"` // when the money was received, now when omitted
+}
+
+// Validate validates the RecordInstallmentPaymentRequest
+func (r *RecordInstallmentPaymentRequest) Validate() error {
+	if !r.Amount.IsPositive() {
+		return errors.New("amount must be greater than 0")
+	}
+
+	if r.PaidAt != nil && r.PaidAt.After(time.Now()) {
+		return errors.New("paidAt cannot be in the future")
+	}
+
+	return nil
+}
+
+// ReceivablesSummary totals what a dealer is owed across their financed sales
+type ReceivablesSummary struct {
+	DealerID        primitive.ObjectID  `json:"dealerId"`
+	Totals          []ReceivablesTotals `json:"totals"`       // one entry per currency the dealer sells in
+	Transactions    int                 `json:"transactions"` // financed sales with open installments
+	StatusCounts    map[string]int      `json:"statusCounts"` // installments per status
+	NextDueDate     *time.Time          `json:"nextDueDate,omitempty"`
+	OldestOverdueAt *time.Time          `json:"oldestOverdueAt,omitempty"` // due date of the oldest unpaid overdue installment
+}
+
+// ReceivablesTotals holds a dealer's receivables in one currency
+type ReceivablesTotals struct {
+	Currency    string      `json:"currency"`
+	Outstanding money.Money `json:"outstanding"` // unpaid amounts and late fees on open installments
+	Overdue     money.Money `json:"overdue"`     // the part of outstanding on late and missed installments
+	LateFees    money.Money `json:"lateFees"`    // late fees charged, paid or not
+	Collected   money.Money `json:"collected"`   // everything received so far
+}
+
+// Balance returns how much is still owed on the installment, including late fees
+func (i *Installment) Balance() money.Money {
+	owed, err := i.Amount.Add(i.LateFee)
+	if err != nil {
+		return money.Zero(i.Currency)
+	}
+	balance, err := owed.Sub(i.AmountPaid)
+	if err != nil || balance.IsNegative() {
+		return money.Zero(i.Currency)
+	}
+	return balance
+}
+
+// IsOpen reports whether the installment can still receive payments
+func (i *Installment) IsOpen() bool {
+	return i.Status != InstallmentStatusPaid && i.Status != InstallmentStatusCancelled
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func TestRecordInstallmentPaymentRequest_Validate(t *testing.T) {
+	yesterday := time.Now().Add(-24 * time.Hour)
+	tomorrow := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		request RecordInstallmentPaymentRequest
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid payment",
+			request: RecordInstallmentPaymentRequest{Amount: money.MustParse("386.66", ""), Reference: "TRF-1001"},
+			wantErr: false,
+		},
+		{
+			name:    "valid backdated payment",
+			request: RecordInstallmentPaymentRequest{Amount: money.MustParse("100", ""), PaidAt: &yesterday},
+			wantErr: false,
+		},
+		{
+			name:    "zero amount",
+			request: RecordInstallmentPaymentRequest{},
+			wantErr: true,
+			errMsg:  "amount must be greater than 0",
+		},
+		{
+			name:    "paid in the future",
+			request: RecordInstallmentPaymentRequest{Amount: money.MustParse("100", ""), PaidAt: &tomorrow},
+			wantErr: true,
+			errMsg:  "paidAt cannot be in the future",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.errMsg, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInstallment_Balance(t *testing.T) {
+	installment := Installment{
+		Amount:     money.MustParse("500", "USD"),
+		LateFee:    money.MustParse("25", "USD"),
+		AmountPaid: money.MustParse("200", "USD"),
+		Currency:   "USD",
+	}
+	assert.Equal(t, money.MustParse("325", "USD"), installment.Balance())
+
+	installment.AmountPaid = money.MustParse("600", "USD")
+	assert.Equal(t, money.Zero("USD"), installment.Balance())
+}
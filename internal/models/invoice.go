@@ -0,0 +1,80 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// InvoiceNumberPrefix starts every invoice number
+const InvoiceNumberPrefix = "INV"
+
+// Invoice is the numbered record of a completed sale, issued once per transaction
+// Both the invoice and the bill of sale are rendered from its snapshot, which is never changed after
+// issue, so a document downloaded again is identical to the first copy
+type Invoice struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TransactionID primitive.ObjectID `bson:"transactionId" json:"transactionId"`
+	SellerID      primitive.ObjectID `bson:"sellerId" json:"sellerId"`
+	Sequence      int64              `bson:"sequence" json:"sequence"` // gap-free per seller, starting at 1
+	Number        string             `bson:"number" json:"number"`
+	Snapshot      SaleSnapshot       `bson:"snapshot" json:"snapshot"`
+	IssuedAt      time.Time          `bson:"issuedAt" json:"issuedAt"`
+}
+
+// SaleSnapshot captures the sale as it stood when the invoice was issued
+type SaleSnapshot struct {
+	Seller  SaleParty   `bson:"seller" json:"seller"`
+	Buyer   SaleParty   `bson:"buyer" json:"buyer"`
+	Vehicle SaleVehicle `bson:"vehicle" json:"vehicle"`
+
+	Amount           money.Money `bson:"amount" json:"amount"`
+	Lines            []PriceLine `bson:"lines,omitempty" json:"lines,omitempty"` // what the amount is made of, when recorded
+	Currency         string      `bson:"currency" json:"currency"`
+	PaymentMethod    string      `bson:"paymentMethod" json:"paymentMethod"`
+	PaymentReference string      `bson:"paymentReference,omitempty" json:"paymentReference,omitempty"`
+	Escrow           bool        `bson:"escrow" json:"escrow"`
+
+	// Financed sales show what was paid at signing and what remains on the loan
+	DownPayment    money.Money `bson:"downPayment,omitempty" json:"downPayment,omitzero"`
+	FinancedAmount money.Money `bson:"financedAmount,omitempty" json:"financedAmount,omitzero"`
+	MonthlyPayment money.Money `bson:"monthlyPayment,omitempty" json:"monthlyPayment,omitzero"`
+	FinancingTerms int         `bson:"financingTerms,omitempty" json:"financingTerms,omitempty"`
+
+	InspectionID *primitive.ObjectID `bson:"inspectionId,omitempty" json:"inspectionId,omitempty"`
+	CompletedAt  time.Time           `bson:"completedAt" json:"completedAt"`
+}
+
+// SaleParty identifies the seller or the buyer on a sale document
+type SaleParty struct {
+	ID    primitive.ObjectID `bson:"id" json:"id"`
+	Name  string             `bson:"name" json:"name"`
+	Email string             `bson:"email" json:"email"`
+}
+
+// SaleVehicle describes the vehicle sold
+type SaleVehicle struct {
+	ID           primitive.ObjectID `bson:"id" json:"id"`
+	Make         string             `bson:"make" json:"make"`
+	Model        string             `bson:"model" json:"model"`
+	Year         int                `bson:"year" json:"year"`
+	Mileage      float64            `bson:"mileage" json:"mileage"`
+	Color        string             `bson:"color,omitempty" json:"color,omitempty"`
+	Transmission string             `bson:"transmission,omitempty" json:"transmission,omitempty"`
+	FuelType     string             `bson:"fuelType,omitempty" json:"fuelType,omitempty"`
+	Location     string             `bson:"location,omitempty" json:"location,omitempty"`
+}
+
+// InvoiceCounter holds the last invoice sequence issued for a seller
+type InvoiceCounter struct {
+	SellerID primitive.ObjectID `bson:"_id" json:"sellerId"`
+	Sequence int64              `bson:"sequence" json:"sequence"`
+}
+
+// FormatInvoiceNumber formats a seller's invoice sequence as an invoice number, e.g. INV-000042
+func FormatInvoiceNumber(sequence int64) string {
+	return fmt.Sprintf("%s-%06d", InvoiceNumberPrefix, sequence)
+}
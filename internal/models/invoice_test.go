@@ -0,0 +1,13 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatInvoiceNumber(t *testing.T) {
+	assert.Equal(t, "INV-000001", FormatInvoiceNumber(1))
+	assert.Equal(t, "INV-000042", FormatInvoiceNumber(42))
+	assert.Equal(t, "INV-1234567", FormatInvoiceNumber(1234567))
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// Ledger account constants
+const (
+	LedgerAccountCash              = "cash"               // money the platform has received for sales
+	LedgerAccountEscrow            = "escrow"             // buyer money held by the payment provider in escrow and deposit holds
+	LedgerAccountBuyerFunds        = "buyer_funds"        // held money owed back to buyers until their sale goes through
+	LedgerAccountSellerPayable     = "seller_payable"     // sale proceeds owed to sellers
+	LedgerAccountSalesTaxPayable   = "sales_tax_payable"  // sales tax collected on behalf of tax authorities
+	LedgerAccountFeeRevenue        = "fee_revenue"        // documentation and registration fees
+	LedgerAccountCommissionRevenue = "commission_revenue" // platform commission on sales
+	LedgerAccountRefunds           = "refunds"            // money returned to buyers after their sale completed
+)
+
+// Ledger account type constants
+const (
+	LedgerAccountTypeAsset     = "asset"
+	LedgerAccountTypeLiability = "liability"
+	LedgerAccountTypeRevenue   = "revenue"
+	LedgerAccountTypeContra    = "contra_revenue"
+)
+
+// Posting side constants
+const (
+	LedgerDebit  = "debit"
+	LedgerCredit = "credit"
+)
+
+// Ledger event constants
+const (
+	LedgerEventEscrowFunded     = "escrow_funded"     // the buyer's payment is held in escrow
+	LedgerEventEscrowRefunded   = "escrow_refunded"   // a cancelled sale's escrow hold went back to the buyer
+	LedgerEventDepositHeld      = "deposit_held"      // the buyer paid a reservation deposit
+	LedgerEventDepositRefunded  = "deposit_refunded"  // the deposit went back to the buyer
+	LedgerEventDepositForfeited = "deposit_forfeited" // the buyer walked away and the deposit was paid to the seller
+	LedgerEventSaleCompleted    = "sale_completed"    // the sale went through and its price was split between the seller and the platform
+	LedgerEventRefund           = "refund"            // an admin refunded all or part of a completed sale
+)
+
+// LedgerAccounts is the chart of accounts in the order reports list it
+var LedgerAccounts = []string{
+	LedgerAccountCash,
+	LedgerAccountEscrow,
+	LedgerAccountBuyerFunds,
+	LedgerAccountSellerPayable,
+	LedgerAccountSalesTaxPayable,
+	LedgerAccountFeeRevenue,
+	LedgerAccountCommissionRevenue,
+	LedgerAccountRefunds,
+}
+
+// ledgerAccountTypes gives the type of every account in the chart of accounts
+var ledgerAccountTypes = map[string]string{
+	LedgerAccountCash:              LedgerAccountTypeAsset,
+	LedgerAccountEscrow:            LedgerAccountTypeAsset,
+	LedgerAccountBuyerFunds:        LedgerAccountTypeLiability,
+	LedgerAccountSellerPayable:     LedgerAccountTypeLiability,
+	LedgerAccountSalesTaxPayable:   LedgerAccountTypeLiability,
+	LedgerAccountFeeRevenue:        LedgerAccountTypeRevenue,
+	LedgerAccountCommissionRevenue: LedgerAccountTypeRevenue,
+	LedgerAccountRefunds:           LedgerAccountTypeContra,
+}
+
+// LedgerAccountType returns the account's type, or false if the account is not in the chart of accounts
+func LedgerAccountType(account string) (string, bool) {
+	accountType, ok := ledgerAccountTypes[account]
+	return accountType, ok
+}
+
+// LedgerDebitNormal reports whether the account's balance grows with debits
+// Assets and contra-revenue do; liabilities and revenue grow with credits
+func LedgerDebitNormal(account string) bool {
+	accountType := ledgerAccountTypes[account]
+	return accountType == LedgerAccountTypeAsset || accountType == LedgerAccountTypeContra
+}
+
+// LedgerEntry is one balanced set of postings recording a money movement
+// Entries are only ever inserted; a mistake is corrected by posting another entry
+type LedgerEntry struct {
+	ID            primitive.ObjectID `bson:"_id" json:"id"`
+	Key           string             `bson:"key" json:"key"` // unique per movement, so a retried write is not posted twice
+	TransactionID primitive.ObjectID `bson:"transactionId" json:"transactionId"`
+	Event         string             `bson:"event" json:"event"`
+	Currency      string             `bson:"currency" json:"currency"`
+	Postings      []LedgerPosting    `bson:"postings" json:"postings"`
+	Memo          string             `bson:"memo,omitempty" json:"memo,omitempty"`
+	CreatedAt     time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// LedgerPosting debits or credits one account
+type LedgerPosting struct {
+	Account string      `bson:"account" json:"account"`
+	Side    string      `bson:"side" json:"side"` // debit or credit
+	Amount  money.Money `bson:"amount" json:"amount"`
+}
+
+// Validate checks the entry's postings are positive, in the entry's currency and balance
+func (e *LedgerEntry) Validate() error {
+	if e.Key == "" {
+		return errors.New("ledger entry key is required")
+	}
+	if len(e.Postings) < 2 {
+		return errors.New("ledger entry needs at least two postings")
+	}
+
+	debits, credits := money.Zero(e.Currency), money.Zero(e.Currency)
+	for _, posting := range e.Postings {
+		if _, ok := ledgerAccountTypes[posting.Account]; !ok {
+			return fmt.Errorf("unknown ledger account %q", posting.Account)
+		}
+		if !posting.Amount.IsPositive() {
+			return fmt.Errorf("%s posting to %s must be greater than 0", posting.Side, posting.Account)
+		}
+		if posting.Amount.Currency() != e.Currency {
+			return fmt.Errorf("%s posting to %s is in %s, not the entry's %s", posting.Side, posting.Account, posting.Amount.Currency(), e.Currency)
+		}
+
+		var err error
+		switch posting.Side {
+		case LedgerDebit:
+			debits, err = debits.Add(posting.Amount)
+		case LedgerCredit:
+			credits, err = credits.Add(posting.Amount)
+		default:
+			return fmt.Errorf("posting side must be %s or %s", LedgerDebit, LedgerCredit)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if cmp, err := debits.Cmp(credits); err != nil || cmp != 0 {
+		return fmt.Errorf("ledger entry does not balance: debits %s, credits %s", debits, credits)
+	}
+	return nil
+}
+
+// LedgerAccountBalance is an account's activity in one currency
+type LedgerAccountBalance struct {
+	Account  string      `json:"account"`
+	Type     string      `json:"type"`
+	Currency string      `json:"currency"`
+	Debits   money.Money `json:"debits"`
+	Credits  money.Money `json:"credits"`
+	Balance  money.Money `json:"balance"` // on the account's normal side; negative when it is overdrawn
+}
+
+// TrialBalance lists every account's balance as of a point in time
+type TrialBalance struct {
+	AsOf     time.Time              `json:"asOf"`
+	Accounts []LedgerAccountBalance `json:"accounts"`
+	Totals   []TrialBalanceTotals   `json:"totals"` // one entry per currency
+	Balanced bool                   `json:"balanced"`
+}
+
+// TrialBalanceTotals sums all accounts in one currency; debits and credits are equal when the ledger balances
+type TrialBalanceTotals struct {
+	Currency string      `json:"currency"`
+	Debits   money.Money `json:"debits"`
+	Credits  money.Money `json:"credits"`
+	Balanced bool        `json:"balanced"`
+}
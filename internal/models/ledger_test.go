@@ -0,0 +1,96 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func TestLedgerEntry_Validate(t *testing.T) {
+	usd := func(amount string) money.Money { return money.MustParse(amount, "USD") }
+	entry := func(postings ...LedgerPosting) LedgerEntry {
+		return LedgerEntry{Key: "txn:sale_completed", Currency: "USD", Postings: postings}
+	}
+
+	tests := []struct {
+		name    string
+		entry   LedgerEntry
+		wantErr string
+	}{
+		{
+			name: "balanced",
+			entry: entry(
+				LedgerPosting{Account: LedgerAccountCash, Side: LedgerDebit, Amount: usd("10250")},
+				LedgerPosting{Account: LedgerAccountSellerPayable, Side: LedgerCredit, Amount: usd("10000")},
+				LedgerPosting{Account: LedgerAccountCommissionRevenue, Side: LedgerCredit, Amount: usd("250")},
+			),
+		},
+		{
+			name: "unbalanced",
+			entry: entry(
+				LedgerPosting{Account: LedgerAccountCash, Side: LedgerDebit, Amount: usd("10250")},
+				LedgerPosting{Account: LedgerAccountSellerPayable, Side: LedgerCredit, Amount: usd("10000")},
+			),
+			wantErr: "ledger entry does not balance: debits 10250.00 USD, credits 10000.00 USD",
+		},
+		{
+			name:    "single posting",
+			entry:   entry(LedgerPosting{Account: LedgerAccountCash, Side: LedgerDebit, Amount: usd("1")}),
+			wantErr: "ledger entry needs at least two postings",
+		},
+		{
+			name: "unknown account",
+			entry: entry(
+				LedgerPosting{Account: "suspense", Side: LedgerDebit, Amount: usd("1")},
+				LedgerPosting{Account: LedgerAccountCash, Side: LedgerCredit, Amount: usd("1")},
+			),
+			wantErr: `unknown ledger account "suspense"`,
+		},
+		{
+			name: "zero amount",
+			entry: entry(
+				LedgerPosting{Account: LedgerAccountEscrow, Side: LedgerDebit, Amount: usd("0")},
+				LedgerPosting{Account: LedgerAccountBuyerFunds, Side: LedgerCredit, Amount: usd("0")},
+			),
+			wantErr: "debit posting to escrow must be greater than 0",
+		},
+		{
+			name: "other currency",
+			entry: entry(
+				LedgerPosting{Account: LedgerAccountEscrow, Side: LedgerDebit, Amount: money.MustParse("1", "EUR")},
+				LedgerPosting{Account: LedgerAccountBuyerFunds, Side: LedgerCredit, Amount: usd("1")},
+			),
+			wantErr: "debit posting to escrow is in EUR, not the entry's USD",
+		},
+		{
+			name:    "missing key",
+			entry:   LedgerEntry{Currency: "USD"},
+			wantErr: "ledger entry key is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.entry.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLedgerDebitNormal(t *testing.T) {
+	assert.True(t, LedgerDebitNormal(LedgerAccountCash))
+	assert.True(t, LedgerDebitNormal(LedgerAccountRefunds))
+	assert.False(t, LedgerDebitNormal(LedgerAccountSellerPayable))
+	assert.False(t, LedgerDebitNormal(LedgerAccountCommissionRevenue))
+
+	for _, account := range LedgerAccounts {
+		_, ok := LedgerAccountType(account)
+		assert.True(t, ok, account)
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notification type constants
+const (
+	NotificationTypeAuctionOutbid = "auction_outbid" // another bidder's maximum beat the user's
+	NotificationTypeAuctionWon    = "auction_won"    // the user won an auction; a pending transaction was created
+	NotificationTypeAuctionLost   = "auction_lost"   // an auction the user bid on closed without them winning
+	NotificationTypeAuctionClosed = "auction_closed" // the seller's auction closed, with or without a sale
+
+	NotificationTypeDisputeOpened   = "dispute_opened"   // the buyer disputed one of the seller's sales
+	NotificationTypeDisputeResolved = "dispute_resolved" // an admin decided a dispute the user is a party to
+)
+
+// Notification is a message delivered to a user's in-app inbox
+type Notification struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
+	Type      string             `bson:"type" json:"type"`
+	Title     string             `bson:"title" json:"title"`
+	Message   string             `bson:"message" json:"message"`
+	Data      map[string]string  `bson:"data,omitempty" json:"data,omitempty"` // IDs of the records the notification is about
+	ReadAt    *time.Time         `bson:"readAt,omitempty" json:"readAt,omitempty"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
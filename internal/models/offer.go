@@ -0,0 +1,134 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// Offer status constants
+const (
+	OfferStatusOpen     = "open"     // waiting for the party that did not name the latest amount
+	OfferStatusAccepted = "accepted" // agreed; a pending transaction was created
+	OfferStatusRejected = "rejected" // declined by either party, or closed because another offer was accepted
+	OfferStatusExpired  = "expired"  // nobody responded before the offer's TTL lapsed
+)
+
+// Offer party constants
+const (
+	OfferPartyBuyer  = "buyer"
+	OfferPartySeller = "seller"
+)
+
+// Offer is a buyer's negotiation with a seller over the price of one vehicle
+// Each counter-offer is appended to Rounds; Amount is the latest amount on the table
+type Offer struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	VehicleID primitive.ObjectID `bson:"vehicleId" json:"vehicleId"`
+	SellerID  primitive.ObjectID `bson:"sellerId" json:"sellerId"`
+	BuyerID   primitive.ObjectID `bson:"buyerId" json:"buyerId"`
+
+	Status        string       `bson:"status" json:"status"`
+	Amount        money.Money  `bson:"amount" json:"amount"`
+	Currency      string       `bson:"currency" json:"currency"`
+	PaymentMethod string       `bson:"paymentMethod" json:"paymentMethod"`
+	Escrow        bool         `bson:"escrow" json:"escrow"`
+	AwaitingParty string       `bson:"awaitingParty,omitempty" json:"awaitingParty,omitempty"` // who has to respond while the offer is open
+	Rounds        []OfferRound `bson:"rounds" json:"rounds"`
+
+	TransactionID *primitive.ObjectID `bson:"transactionId,omitempty" json:"transactionId,omitempty"` // set once accepted
+	Reason        string              `bson:"reason,omitempty" json:"reason,omitempty"`               // why the offer was rejected
+
+	ExpiresAt time.Time `bson:"expiresAt" json:"expiresAt"` // the TTL restarts with every counter-offer
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// OfferRound is one amount named by either party during a negotiation
+type OfferRound struct {
+	Party     string             `bson:"party" json:"party"`
+	ActorID   primitive.ObjectID `bson:"actorId" json:"actorId"`
+	Amount    money.Money        `bson:"amount" json:"amount"`
+	Message   string             `bson:"message,omitempty" json:"message,omitempty"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// PartyOf returns whether userID is the offer's buyer or seller, or "" if neither
+func (o *Offer) PartyOf(userID primitive.ObjectID) string {
+	switch userID {
+	case o.BuyerID:
+		return OfferPartyBuyer
+	case o.SellerID:
+		return OfferPartySeller
+	}
+	return ""
+}
+
+// IsExpired reports whether an open offer's TTL has lapsed at now
+func (o *Offer) IsExpired(now time.Time) bool {
+	return o.Status == OfferStatusOpen && !now.Before(o.ExpiresAt)
+}
+
+// MakeOfferRequest represents the buyer's request to make an offer on a vehicle
+type MakeOfferRequest struct {
+	Amount        money.Money `json:"amount"`
+	Currency      string      `json:"currency"` // the vehicle's listing currency when omitted
+	PaymentMethod string      `json:"paymentMethod" binding:"required"`
+	Escrow        bool        `json:"escrow"` // hold the buyer's payment until delivery is confirmed
+	Message       string      `json:"message"`
+}
+
+// CounterOfferRequest represents either party's request to answer an offer with another amount
+type CounterOfferRequest struct {
+	Amount  money.Money `json:"amount"` // in the offer's currency
+	Message string      `json:"message"`
+}
+
+// RejectOfferRequest represents the request body for rejecting an offer
+type RejectOfferRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Validate validates the MakeOfferRequest
+func (r *MakeOfferRequest) Validate() error {
+	if !r.Amount.IsPositive() {
+		return errors.New("amount must be greater than 0")
+	}
+	if r.Currency != "" && !money.IsValidCurrency(r.Currency) {
+		return errors.New("currency must be a supported ISO 4217 code")
+	}
+	if r.Currency != "" {
+		if _, err := r.Amount.WithCurrency(r.Currency); err != nil {
+			return fmt.Errorf("amount: %w", err)
+		}
+	}
+	if !IsValidPaymentMethod(r.PaymentMethod) {
+		return errors.New("invalid paymentMethod value")
+	}
+	// Financing terms depend on the agreed amount, so they are arranged after the offer is accepted
+	if r.PaymentMethod == PaymentMethodFinancing {
+		return errors.New("offers cannot be paid with financing")
+	}
+	if r.Escrow && r.PaymentMethod != PaymentMethodCard && r.PaymentMethod != PaymentMethodBankTransfer {
+		return errors.New("escrow is only available for card and bank_transfer payments")
+	}
+	if len(r.Message) > 1000 {
+		return errors.New("message must be at most 1000 characters")
+	}
+	return nil
+}
+
+// Validate validates the CounterOfferRequest
+func (r *CounterOfferRequest) Validate() error {
+	if !r.Amount.IsPositive() {
+		return errors.New("amount must be greater than 0")
+	}
+	if len(r.Message) > 1000 {
+		return errors.New("message must be at most 1000 characters")
+	}
+	return nil
+}
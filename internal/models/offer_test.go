@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func TestMakeOfferRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     MakeOfferRequest
+		wantErr string
+	}{
+		{name: "valid offer", req: MakeOfferRequest{Amount: money.MustParse("24000", ""), PaymentMethod: PaymentMethodBankTransfer, Escrow: true}},
+		{name: "valid offer in another currency", req: MakeOfferRequest{Amount: money.MustParse("36000000", ""), Currency: "NGN", PaymentMethod: PaymentMethodCash}},
+		{name: "zero amount", req: MakeOfferRequest{Amount: money.MustParse("0", ""), PaymentMethod: PaymentMethodCash}, wantErr: "amount must be greater than 0"},
+		{name: "unsupported currency", req: MakeOfferRequest{Amount: money.MustParse("100", ""), Currency: "ngn", PaymentMethod: PaymentMethodCash}, wantErr: "currency must be a supported ISO 4217 code"},
+		{name: "unknown payment method", req: MakeOfferRequest{Amount: money.MustParse("100", ""), PaymentMethod: "barter"}, wantErr: "invalid paymentMethod value"},
+		{name: "financing", req: MakeOfferRequest{Amount: money.MustParse("100", ""), PaymentMethod: PaymentMethodFinancing}, wantErr: "offers cannot be paid with financing"},
+		{name: "escrow with cash", req: MakeOfferRequest{Amount: money.MustParse("100", ""), PaymentMethod: PaymentMethodCash, Escrow: true}, wantErr: "escrow is only available for card and bank_transfer payments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCounterOfferRequest_Validate(t *testing.T) {
+	req := CounterOfferRequest{Amount: money.MustParse("25000", "")}
+	assert.NoError(t, req.Validate())
+
+	req.Amount = money.MustParse("-1", "")
+	assert.EqualError(t, req.Validate(), "amount must be greater than 0")
+}
+
+func TestOffer_PartyOfAndExpiry(t *testing.T) {
+	now := time.Now()
+	offer := Offer{
+		BuyerID:   primitive.NewObjectID(),
+		SellerID:  primitive.NewObjectID(),
+		Status:    OfferStatusOpen,
+		ExpiresAt: now.Add(time.Hour),
+	}
+
+	assert.Equal(t, OfferPartyBuyer, offer.PartyOf(offer.BuyerID))
+	assert.Equal(t, OfferPartySeller, offer.PartyOf(offer.SellerID))
+	assert.Empty(t, offer.PartyOf(primitive.NewObjectID()))
+
+	assert.False(t, offer.IsExpired(now))
+	assert.True(t, offer.IsExpired(now.Add(time.Hour)))
+
+	offer.Status = OfferStatusAccepted
+	assert.False(t, offer.IsExpired(now.Add(2*time.Hour)), "only open offers expire")
+}
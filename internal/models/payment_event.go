@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PaymentEvent records a processed payment provider webhook so redeliveries are not applied twice
+type PaymentEvent struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Provider      string              `bson:"provider" json:"provider"`
+	EventID       string              `bson:"eventId" json:"eventId"`
+	Type          string              `bson:"type" json:"type"`
+	IntentID      string              `bson:"intentId" json:"intentId"`
+	Status        string              `bson:"status" json:"status"`
+	TransactionID *primitive.ObjectID `bson:"transactionId,omitempty" json:"transactionId,omitempty"`
+	Outcome       string              `bson:"outcome" json:"outcome"` // processed or ignored
+	ReceivedAt    time.Time           `bson:"receivedAt" json:"receivedAt"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// Price line kind constants
+const (
+	PriceLineVehicle          = "vehicle_price"
+	PriceLineDocumentationFee = "documentation_fee"
+	PriceLineRegistrationFee  = "registration_fee"
+	PriceLineSalesTax         = "sales_tax"
+	PriceLineCommission       = "platform_commission"
+)
+
+// PriceLine is one item of what the buyer pays for a sale
+type PriceLine struct {
+	Kind         string      `bson:"kind" json:"kind"`
+	Description  string      `bson:"description" json:"description"`
+	Amount       money.Money `bson:"amount" json:"amount"`
+	Percent      float64     `bson:"percent,omitempty" json:"percent,omitempty"`           // rate applied to the vehicle price, for tax and commission lines
+	Jurisdiction string      `bson:"jurisdiction,omitempty" json:"jurisdiction,omitempty"` // where the sales tax is due, such as "Lagos, Nigeria"
+}
+
+// TransactionQuoteRequest represents a request to preview what a sale of a vehicle would cost
+type TransactionQuoteRequest struct {
+	VehicleID string      `json:"vehicleId" binding:"required"`
+	Amount    money.Money `json:"amount"` // agreed vehicle price; the listed price converted to currency when omitted
+	Currency  string      `json:"currency" binding:"required"`
+}
+
+// TransactionQuote is the line-item breakdown a transaction would be created with
+type TransactionQuote struct {
+	VehicleID primitive.ObjectID `json:"vehicleId"`
+	Currency  string             `json:"currency"`
+	Lines     []PriceLine        `json:"lines"`
+	Total     money.Money        `json:"total"`
+}
+
+// Validate validates the TransactionQuoteRequest
+func (r *TransactionQuoteRequest) Validate() error {
+	if _, err := primitive.ObjectIDFromHex(r.VehicleID); err != nil {
+		return errors.New("invalid vehicleId format")
+	}
+	if !money.IsValidCurrency(r.Currency) {
+		return errors.New("currency must be a supported ISO 4217 code")
+	}
+	if r.Amount.IsNegative() {
+		return errors.New("amount must be greater than 0")
+	}
+	if _, err := r.Amount.WithCurrency(r.Currency); err != nil {
+		return errors.New("amount: " + err.Error())
+	}
+	return nil
+}
+
+// SumPriceLines totals a breakdown in currency
+func SumPriceLines(lines []PriceLine, currency string) (money.Money, error) {
+	total := money.Zero(currency)
+	for _, line := range lines {
+		var err error
+		if total, err = total.Add(line.Amount); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total, nil
+}
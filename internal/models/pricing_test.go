@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func TestTransactionQuoteRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     TransactionQuoteRequest
+		wantErr string
+	}{
+		{name: "listed price", req: TransactionQuoteRequest{VehicleID: "507f1f77bcf86cd799439011", Currency: "USD"}},
+		{name: "agreed price", req: TransactionQuoteRequest{VehicleID: "507f1f77bcf86cd799439011", Currency: "USD", Amount: money.MustParse("9500", "")}},
+		{name: "invalid vehicle", req: TransactionQuoteRequest{VehicleID: "nope", Currency: "USD"}, wantErr: "invalid vehicleId format"},
+		{name: "invalid currency", req: TransactionQuoteRequest{VehicleID: "507f1f77bcf86cd799439011", Currency: "usd"}, wantErr: "currency must be a supported ISO 4217 code"},
+		{name: "negative amount", req: TransactionQuoteRequest{VehicleID: "507f1f77bcf86cd799439011", Currency: "USD", Amount: money.MustParse("-1", "")}, wantErr: "amount must be greater than 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestSumPriceLines(t *testing.T) {
+	total, err := SumPriceLines([]PriceLine{
+		{Kind: PriceLineVehicle, Amount: money.MustParse("10000", "USD")},
+		{Kind: PriceLineSalesTax, Amount: money.MustParse("750.25", "USD")},
+	}, "USD")
+	require.NoError(t, err)
+	assert.Equal(t, money.MustParse("10750.25", "USD"), total)
+
+	total, err = SumPriceLines(nil, "USD")
+	require.NoError(t, err)
+	assert.Equal(t, money.Zero("USD"), total)
+
+	_, err = SumPriceLines([]PriceLine{{Amount: money.MustParse("1", "EUR")}}, "USD")
+	assert.Error(t, err)
+}
@@ -2,19 +2,49 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/financing"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
 )
 
 // Transaction status constants
 const (
 	TransactionStatusPending   = "pending"
+	TransactionStatusFunded    = "funded"    // escrow: buyer's funds are held
+	TransactionStatusDelivered = "delivered" // escrow: vehicle handed over, dispute window running
 	TransactionStatusCompleted = "completed"
+	TransactionStatusRefunded  = "refunded" // completed, then refunded in full by an admin
 	TransactionStatusCancelled = "cancelled"
 	TransactionStatusFailed    = "failed"
 )
 
+// Escrow fund status constants
+const (
+	EscrowStatusAwaitingFunds = "awaiting_funds"
+	EscrowStatusHeld          = "held"
+	EscrowStatusReleased      = "released"
+	EscrowStatusRefundPending = "refund_pending" // the sale was cancelled and the hold is being returned to the buyer
+	EscrowStatusRefunded      = "refunded"
+)
+
+// Refund status constants
+const (
+	RefundStatusPending   = "pending"   // claimed, waiting for the payment provider to return the money
+	RefundStatusCompleted = "completed" // the money was returned; refunds recorded without a status are completed
+)
+
+// Reservation deposit status constants
+const (
+	DepositStatusAwaitingPayment = "awaiting_payment"
+	DepositStatusHeld            = "held"
+	DepositStatusRefunded        = "refunded"  // returned to the buyer when the sale completes or the seller calls it off
+	DepositStatusForfeited       = "forfeited" // paid out to the seller when the buyer cancels or lets the hold expire
+)
+
 // Transaction type constants
 const (
 	TransactionTypePurchase = "purchase"
@@ -39,23 +69,53 @@ type Transaction struct {
 	BuyerID  primitive.ObjectID `bson:"buyerId" json:"buyerId"`
 
 	// Transaction details
-	Type          string  `bson:"type" json:"type"`
-	Status        string  `bson:"status" json:"status"`
-	Amount        float64 `bson:"amount" json:"amount"`
-	Currency      string  `bson:"currency" json:"currency"`
-	PaymentMethod string  `bson:"paymentMethod" json:"paymentMethod"`
+	Type          string      `bson:"type" json:"type"`
+	Status        string      `bson:"status" json:"status"`
+	Amount        money.Money `bson:"amount" json:"amount"`
+	Currency      string      `bson:"currency" json:"currency"` // ISO 4217 code every amount of the transaction is in
+	PaymentMethod string      `bson:"paymentMethod" json:"paymentMethod"`
+
+	// What the amount is made of: the vehicle price, fees, sales tax and commission; Amount is always their sum
+	// Transactions created before breakdowns were recorded have none
+	PriceLines []PriceLine `bson:"priceLines,omitempty" json:"priceLines,omitempty"`
+
+	// Rate between the vehicle's listed currency and the transaction's, captured when the transaction was created
+	ExchangeRate *ExchangeRateSnapshot `bson:"exchangeRate,omitempty" json:"exchangeRate,omitempty"`
 
 	// Payment details
 	PaymentDetails PaymentDetails `bson:"paymentDetails" json:"paymentDetails"`
 
+	// Escrow details, set when the buyer's payment is held until delivery is confirmed
+	Escrow *EscrowDetails `bson:"escrow,omitempty" json:"escrow,omitempty"`
+
+	// Provider payment, set once the buyer starts a card or bank transfer payment
+	Payment *ProviderPayment `bson:"payment,omitempty" json:"payment,omitempty"`
+
+	// Reservation hold on the vehicle, with the buyer's optional deposit
+	Hold *ReservationHold `bson:"hold,omitempty" json:"hold,omitempty"`
+
+	// Refunds issued after the sale completed; partial refunds leave the transaction completed
+	Refunds        []TransactionRefund `bson:"refunds,omitempty" json:"refunds,omitempty"`
+	RefundedAmount money.Money         `bson:"refundedAmount,omitempty" json:"refundedAmount,omitzero"`
+	RefundedAt     *time.Time          `bson:"refundedAt,omitempty" json:"refundedAt,omitempty"` // set once refunded in full
+
+	// Unresolved dispute freezing the transaction; it cannot be completed or cancelled until an admin resolves it
+	OpenDisputeID *primitive.ObjectID `bson:"openDisputeId,omitempty" json:"openDisputeId,omitempty"`
+
 	// Inspection reference (optional)
 	InspectionID *primitive.ObjectID `bson:"inspectionId,omitempty" json:"inspectionId,omitempty"`
 
+	// Auction the buyer won the vehicle in; only its close can sell a vehicle that is being auctioned
+	AuctionID *primitive.ObjectID `bson:"auctionId,omitempty" json:"auctionId,omitempty"`
+
 	// Additional info
 	Notes       string     `bson:"notes,omitempty" json:"notes,omitempty"`
 	CompletedAt *time.Time `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
 	CancelledAt *time.Time `bson:"cancelledAt,omitempty" json:"cancelledAt,omitempty"`
 
+	// Audit trail of every applied status transition
+	StatusHistory []TransactionStatusChange `bson:"statusHistory,omitempty" json:"statusHistory,omitempty"`
+
 	// Timestamps
 	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
 	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
@@ -67,11 +127,17 @@ type PaymentDetails struct {
 	PaidAt               *time.Time `bson:"paidAt,omitempty" json:"paidAt,omitempty"`
 
 	// For financing
-	DownPayment    float64 `bson:"downPayment,omitempty" json:"downPayment,omitempty"`
-	FinancedAmount float64 `bson:"financedAmount,omitempty" json:"financedAmount,omitempty"`
-	MonthlyPayment float64 `bson:"monthlyPayment,omitempty" json:"monthlyPayment,omitempty"`
-	FinancingTerms int     `bson:"financingTerms,omitempty" json:"financingTerms,omitempty"` // in months
-	InterestRate   float64 `bson:"interestRate,omitempty" json:"interestRate,omitempty"`
+	DownPayment    money.Money `bson:"downPayment,omitempty" json:"downPayment,omitzero"`
+	FinancedAmount money.Money `bson:"financedAmount,omitempty" json:"financedAmount,omitzero"`
+	MonthlyPayment money.Money `bson:"monthlyPayment,omitempty" json:"monthlyPayment,omitzero"`
+	FinancingTerms int         `bson:"financingTerms,omitempty" json:"financingTerms,omitempty"` // in months
+	InterestRate   float64     `bson:"interestRate,omitempty" json:"interestRate,omitempty"`
+	FinancingYears float64     `bson:"financingYears,omitempty" json:"financingYears,omitempty"` // fractional-year term, overrides financingTerms
+	BalloonPayment money.Money `bson:"balloonPayment,omitempty" json:"balloonPayment,omitzero"`
+	Compounding    string      `bson:"compounding,omitempty" json:"compounding,omitempty"`    // monthly when empty
+	FinancingFees  money.Money `bson:"financingFees,omitempty" json:"financingFees,omitzero"` // finance charges paid at signing
+	APR            float64     `bson:"apr,omitempty" json:"apr,omitempty"`
+	TotalInterest  money.Money `bson:"totalInterest,omitempty" json:"totalInterest,omitzero"`
 
 	// Bank details for transfer
 	BankName      string `bson:"bankName,omitempty" json:"bankName,omitempty"`
@@ -82,15 +148,97 @@ type PaymentDetails struct {
 	CardBrand string `bson:"cardBrand,omitempty" json:"cardBrand,omitempty"`
 }
 
+// EscrowDetails tracks funds held by the payment provider for an escrow transaction
+type EscrowDetails struct {
+	Status        string     `bson:"status" json:"status"`                                   // awaiting_funds, held, released, refund_pending, refunded
+	Provider      string     `bson:"provider,omitempty" json:"provider,omitempty"`           // payment provider holding the funds
+	HoldReference string     `bson:"holdReference,omitempty" json:"holdReference,omitempty"` // provider's reference for the hold
+	FundedAt      *time.Time `bson:"fundedAt,omitempty" json:"fundedAt,omitempty"`
+	DeliveredAt   *time.Time `bson:"deliveredAt,omitempty" json:"deliveredAt,omitempty"`
+	ReleaseAt     *time.Time `bson:"releaseAt,omitempty" json:"releaseAt,omitempty"` // funds are released automatically after this time
+	ReleasedAt    *time.Time `bson:"releasedAt,omitempty" json:"releasedAt,omitempty"`
+	RefundedAt    *time.Time `bson:"refundedAt,omitempty" json:"refundedAt,omitempty"`
+}
+
+// ProviderPayment tracks a card or bank transfer payment at the payment provider
+// Status is only ever updated from the provider, never from client-supplied data
+type ProviderPayment struct {
+	Provider    string     `bson:"provider" json:"provider"`
+	IntentID    string     `bson:"intentId" json:"intentId"`
+	Status      string     `bson:"status" json:"status"`                             // requires_capture, processing, succeeded, failed, refunded
+	Attempt     int        `bson:"attempt" json:"attempt"`                           // incremented each time a failed payment is retried
+	RefundDue   bool       `bson:"refundDue,omitempty" json:"refundDue,omitempty"`   // the sale was cancelled and the payment is being refunded
+	CaptureDue  bool       `bson:"captureDue,omitempty" json:"captureDue,omitempty"` // the sale completed and the authorized payment is being captured
+	ConfirmedAt *time.Time `bson:"confirmedAt,omitempty" json:"confirmedAt,omitempty"`
+	UpdatedAt   time.Time  `bson:"updatedAt" json:"updatedAt"`
+}
+
+// ReservationHold keeps the vehicle reserved for the transaction until ExpiresAt
+// Pending and failed transactions are cancelled by the system once their hold expires; funded ones keep it
+type ReservationHold struct {
+	ExpiresAt        time.Time   `bson:"expiresAt" json:"expiresAt"` // pushed back once the deposit is paid
+	Deposit          money.Money `bson:"deposit,omitempty" json:"deposit,omitzero"`
+	DepositStatus    string      `bson:"depositStatus,omitempty" json:"depositStatus,omitempty"`
+	DepositProvider  string      `bson:"depositProvider,omitempty" json:"depositProvider,omitempty"`
+	DepositReference string      `bson:"depositReference,omitempty" json:"depositReference,omitempty"` // provider's reference for the held deposit
+	DepositPaidAt    *time.Time  `bson:"depositPaidAt,omitempty" json:"depositPaidAt,omitempty"`
+	ForfeitDeposit   bool        `bson:"forfeitDeposit,omitempty" json:"-"`                // decided when the transaction is cancelled
+	ReleasedAt       *time.Time  `bson:"releasedAt,omitempty" json:"releasedAt,omitempty"` // when the vehicle and deposit were settled
+}
+
+// TransactionRefund is one refund line item of a completed transaction
+type TransactionRefund struct {
+	ID                primitive.ObjectID  `bson:"_id" json:"id"`
+	Amount            money.Money         `bson:"amount" json:"amount"`
+	Reason            string              `bson:"reason" json:"reason"`
+	Reference         string              `bson:"reference" json:"reference"`                   // provider refund or payout reference
+	Status            string              `bson:"status,omitempty" json:"status,omitempty"`     // pending or completed
+	Provider          string              `bson:"provider,omitempty" json:"provider,omitempty"` // set when the payment provider returned the money
+	RevertedOwnership bool                `bson:"revertedOwnership" json:"revertedOwnership"`
+	DisputeID         *primitive.ObjectID `bson:"disputeId,omitempty" json:"disputeId,omitempty"` // the dispute whose resolution issued the refund
+	RefundedBy        primitive.ObjectID  `bson:"refundedBy" json:"refundedBy"`
+	CreatedAt         time.Time           `bson:"createdAt" json:"createdAt"`
+}
+
+// RefundableAmount returns how much of the transaction has not been refunded yet
+func (t *Transaction) RefundableAmount() (money.Money, error) {
+	if t.RefundedAmount.IsZero() {
+		return t.Amount, nil
+	}
+	return t.Amount.Sub(t.RefundedAmount)
+}
+
+// PendingRefund returns the refund waiting for the payment provider, or nil if there is none
+func (t *Transaction) PendingRefund() *TransactionRefund {
+	for i := range t.Refunds {
+		if t.Refunds[i].Status == RefundStatusPending {
+			return &t.Refunds[i]
+		}
+	}
+	return nil
+}
+
+// TransactionStatusChange records a single applied status transition
+type TransactionStatusChange struct {
+	From      string             `bson:"from,omitempty" json:"from,omitempty"`
+	To        string             `bson:"to" json:"to"`
+	ActorID   primitive.ObjectID `bson:"actorId" json:"actorId"`
+	ActorRole string             `bson:"actorRole" json:"actorRole"` // seller, buyer, admin, system
+	Reason    string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	ChangedAt time.Time          `bson:"changedAt" json:"changedAt"`
+}
+
 // CreateTransactionRequest represents the request to create a transaction
 type CreateTransactionRequest struct {
-	VehicleID     string  `json:"vehicleId" binding:"required"`
-	BuyerID       string  `json:"buyerId" binding:"required"`
-	Amount        float64 `json:"amount" binding:"required"`
-	Currency      string  `json:"currency" binding:"required"`
-	PaymentMethod string  `json:"paymentMethod" binding:"required"`
-	InspectionID  string  `json:"inspectionId"`
-	Notes         string  `json:"notes"`
+	VehicleID     string      `json:"vehicleId" binding:"required"`
+	BuyerID       string      `json:"buyerId" binding:"required"`
+	Amount        money.Money `json:"amount"` // a decimal in the request's currency, such as 25000 or "25000.00"
+	Currency      string      `json:"currency" binding:"required"`
+	PaymentMethod string      `json:"paymentMethod" binding:"required"`
+	InspectionID  string      `json:"inspectionId"`
+	Notes         string      `json:"notes"`
+	Escrow        bool        `json:"escrow"`  // hold the buyer's payment until delivery is confirmed
+	Deposit       money.Money `json:"deposit"` // optional deposit the buyer pays to keep the vehicle reserved for longer
 
 	// Payment details
 	PaymentDetails PaymentDetails `json:"paymentDetails"`
@@ -109,6 +257,30 @@ type CompleteTransactionRequest struct {
 	Notes                string `json:"notes"`
 }
 
+// FundTransactionRequest represents the buyer's request to fund an escrow transaction
+type FundTransactionRequest struct {
+	PaymentSource string `json:"paymentSource" binding:"required"` // provider token for the buyer's card or bank account
+	Notes         string `json:"notes"`
+}
+
+// RefundTransactionRequest represents an admin's request to refund a completed transaction
+type RefundTransactionRequest struct {
+	Amount          money.Money `json:"amount"` // in the transaction's currency; the whole refundable amount when omitted
+	Reason          string      `json:"reason" binding:"required"`
+	Reference       string      `json:"reference"`       // payout reference, required unless the payment provider refunds the payment
+	RevertOwnership bool        `json:"revertOwnership"` // give the vehicle back to the seller and list it again; full refunds only
+}
+
+// PayDepositRequest represents the buyer's request to pay a reservation deposit
+type PayDepositRequest struct {
+	PaymentSource string `json:"paymentSource" binding:"required"` // provider token for the buyer's card or bank account
+}
+
+// CreatePaymentIntentRequest represents the buyer's request to start paying for a transaction
+type CreatePaymentIntentRequest struct {
+	PaymentSource string `json:"paymentSource" binding:"required"` // provider token for the buyer's card or bank account
+}
+
 // Validate validates the CreateTransactionRequest
 func (r *CreateTransactionRequest) Validate() error {
 	if r.VehicleID == "" {
@@ -127,7 +299,7 @@ func (r *CreateTransactionRequest) Validate() error {
 		return errors.New("invalid buyerId format")
 	}
 
-	if r.Amount <= 0 {
+	if !r.Amount.IsPositive() {
 		return errors.New("amount must be greater than 0")
 	}
 
@@ -135,6 +307,15 @@ func (r *CreateTransactionRequest) Validate() error {
 		return errors.New("currency is required")
 	}
 
+	if !money.IsValidCurrency(r.Currency) {
+		return errors.New("currency must be a supported ISO 4217 code")
+	}
+
+	amount, details, err := r.Amounts()
+	if err != nil {
+		return err
+	}
+
 	if r.PaymentMethod == "" {
 		return errors.New("paymentMethod is required")
 	}
@@ -150,32 +331,70 @@ func (r *CreateTransactionRequest) Validate() error {
 		}
 	}
 
+	// Escrow holds funds with the payment provider, which only handles cards and bank transfers
+	if r.Escrow && r.PaymentMethod != PaymentMethodCard && r.PaymentMethod != PaymentMethodBankTransfer {
+		return errors.New("escrow is only available for card and bank_transfer payments")
+	}
+
+	if r.Deposit.IsNegative() {
+		return errors.New("deposit must be non-negative")
+	}
+	if !r.Deposit.IsZero() {
+		deposit, err := r.Deposit.WithCurrency(r.Currency)
+		if err != nil {
+			return fmt.Errorf("deposit: %w", err)
+		}
+		if cmp, _ := deposit.Cmp(amount); cmp >= 0 {
+			return errors.New("deposit must be less than amount")
+		}
+	}
+
 	// Validate payment details based on payment method
-	if err := r.validatePaymentDetails(); err != nil {
+	if err := r.validatePaymentDetails(amount, details); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Amounts returns the amount and payment details with the request's currency attached
+func (r *CreateTransactionRequest) Amounts() (money.Money, PaymentDetails, error) {
+	amount, err := r.Amount.WithCurrency(r.Currency)
+	if err != nil {
+		return money.Money{}, PaymentDetails{}, fmt.Errorf("amount: %w", err)
+	}
+	details, err := r.PaymentDetails.WithCurrency(r.Currency)
+	if err != nil {
+		return money.Money{}, PaymentDetails{}, err
+	}
+	return amount, details, nil
+}
+
 // validatePaymentDetails validates payment details based on payment method
-func (r *CreateTransactionRequest) validatePaymentDetails() error {
+func (r *CreateTransactionRequest) validatePaymentDetails(amount money.Money, details PaymentDetails) error {
 	switch r.PaymentMethod {
 	case PaymentMethodFinancing:
-		if r.PaymentDetails.DownPayment <= 0 {
+		if !details.DownPayment.IsPositive() {
 			return errors.New("downPayment is required for financing")
 		}
-		if r.PaymentDetails.DownPayment >= r.Amount {
+		if cmp, _ := details.DownPayment.Cmp(amount); cmp >= 0 {
 			return errors.New("downPayment must be less than total amount")
 		}
-		if r.PaymentDetails.FinancingTerms <= 0 {
+		if details.FinancingTerms <= 0 && details.FinancingYears <= 0 {
 			return errors.New("financingTerms is required for financing")
 		}
-		if r.PaymentDetails.InterestRate < 0 {
+		if details.InterestRate < 0 {
 			return errors.New("interestRate cannot be negative")
 		}
+		terms, err := details.FinancingTermsFor(amount)
+		if err != nil {
+			return err
+		}
+		if err := terms.Validate(); err != nil {
+			return err
+		}
 	case PaymentMethodBankTransfer:
-		if r.PaymentDetails.BankName == "" {
+		if details.BankName == "" {
 			return errors.New("bankName is required for bank transfer")
 		}
 	}
@@ -201,11 +420,115 @@ func (r *CompleteTransactionRequest) Validate() error {
 	return nil
 }
 
+// Validate validates the FundTransactionRequest
+func (r *FundTransactionRequest) Validate() error {
+	if r.PaymentSource == "" {
+		return errors.New("paymentSource is required")
+	}
+
+	return nil
+}
+
+// Validate validates the RefundTransactionRequest
+func (r *RefundTransactionRequest) Validate() error {
+	if r.Amount.IsNegative() {
+		return errors.New("amount must be greater than 0")
+	}
+	if r.Reason == "" {
+		return errors.New("reason is required")
+	}
+	if len(r.Reason) > 1000 {
+		return errors.New("reason must be at most 1000 characters")
+	}
+
+	return nil
+}
+
+// Validate validates the PayDepositRequest
+func (r *PayDepositRequest) Validate() error {
+	if r.PaymentSource == "" {
+		return errors.New("paymentSource is required")
+	}
+
+	return nil
+}
+
+// Validate validates the CreatePaymentIntentRequest
+func (r *CreatePaymentIntentRequest) Validate() error {
+	if r.PaymentSource == "" {
+		return errors.New("paymentSource is required")
+	}
+
+	return nil
+}
+
+// RequiresProviderPayment reports whether the payment must be collected and confirmed by the payment provider
+// Escrow transactions are excluded because their funds are held through the escrow flow instead
+func (t *Transaction) RequiresProviderPayment() bool {
+	if t.Escrow != nil {
+		return false
+	}
+	return t.PaymentMethod == PaymentMethodCard || t.PaymentMethod == PaymentMethodBankTransfer
+}
+
+// FinancingTermsFor returns the loan terms for financing the given price with these details
+// The quote endpoint and stored transactions both amortize through this, so they always agree
+func (d PaymentDetails) FinancingTermsFor(amount money.Money) (financing.Terms, error) {
+	months := float64(d.FinancingTerms)
+	if d.FinancingYears > 0 {
+		months = d.FinancingYears * financing.PaymentsPerYear
+	}
+
+	principal, err := amount.Sub(d.DownPayment)
+	if err != nil {
+		return financing.Terms{}, err
+	}
+
+	return financing.Terms{
+		Principal:   principal,
+		AnnualRate:  d.InterestRate,
+		Months:      months,
+		Balloon:     d.BalloonPayment,
+		Compounding: d.Compounding,
+		Fees:        d.FinancingFees,
+	}, nil
+}
+
+// WithCurrency attaches the transaction's currency to amounts sent without one
+func (d PaymentDetails) WithCurrency(currency string) (PaymentDetails, error) {
+	fields := []struct {
+		name   string
+		amount *money.Money
+	}{
+		{"downPayment", &d.DownPayment},
+		{"financedAmount", &d.FinancedAmount},
+		{"monthlyPayment", &d.MonthlyPayment},
+		{"balloonPayment", &d.BalloonPayment},
+		{"financingFees", &d.FinancingFees},
+		{"totalInterest", &d.TotalInterest},
+	}
+	for _, field := range fields {
+		if field.amount.IsZero() {
+			*field.amount = money.Money{}
+			continue
+		}
+		converted, err := field.amount.WithCurrency(currency)
+		if err != nil {
+			return PaymentDetails{}, fmt.Errorf("%s: %w", field.name, err)
+		}
+		*field.amount = converted
+	}
+	return d, nil
+}
+
 // IsValidTransactionStatus checks if the given status is valid
 func IsValidTransactionStatus(status string) bool {
 	validStatuses := []string{
 		TransactionStatusPending,
+		TransactionStatusFunded,
+		TransactionStatusDelivered,
 		TransactionStatusCompleted,
+		TransactionStatusRefunded,
 		TransactionStatusCancelled,
 		TransactionStatusFailed,
 	}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
 )
 
 func TestCreateTransactionRequest_Validate(t *testing.T) {
@@ -23,7 +25,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        25000.0,
+				Amount:        money.MustParse("25000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodCash,
 				Notes:         "Cash payment",
@@ -35,11 +37,11 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        30000.0,
+				Amount:        money.MustParse("30000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodFinancing,
 				PaymentDetails: PaymentDetails{
-					DownPayment:    10000.0,
+					DownPayment:    money.MustParse("10000", ""),
 					FinancingTerms: 60,
 					InterestRate:   3.5,
 				},
@@ -51,7 +53,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        25000.0,
+				Amount:        money.MustParse("25000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodBankTransfer,
 				PaymentDetails: PaymentDetails{
@@ -60,12 +62,75 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid escrow card transaction",
+			request: CreateTransactionRequest{
+				VehicleID:     validVehicleID,
+				BuyerID:       validBuyerID,
+				Amount:        money.MustParse("25000", ""),
+				Currency:      "USD",
+				PaymentMethod: PaymentMethodCard,
+				Escrow:        true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid transaction with deposit",
+			request: CreateTransactionRequest{
+				VehicleID:     validVehicleID,
+				BuyerID:       validBuyerID,
+				Amount:        money.MustParse("25000", ""),
+				Currency:      "USD",
+				PaymentMethod: PaymentMethodCash,
+				Deposit:       money.MustParse("1000", ""),
+			},
+			wantErr: false,
+		},
+		{
+			name: "deposit not less than amount",
+			request: CreateTransactionRequest{
+				VehicleID:     validVehicleID,
+				BuyerID:       validBuyerID,
+				Amount:        money.MustParse("25000", ""),
+				Currency:      "USD",
+				PaymentMethod: PaymentMethodCash,
+				Deposit:       money.MustParse("25000", ""),
+			},
+			wantErr: true,
+			errMsg:  "deposit must be less than amount",
+		},
+		{
+			name: "negative deposit",
+			request: CreateTransactionRequest{
+				VehicleID:     validVehicleID,
+				BuyerID:       validBuyerID,
+				Amount:        money.MustParse("25000", ""),
+				Currency:      "USD",
+				PaymentMethod: PaymentMethodCash,
+				Deposit:       money.MustParse("-100", ""),
+			},
+			wantErr: true,
+			errMsg:  "deposit must be non-negative",
+		},
+		{
+			name: "escrow not available for cash",
+			request: CreateTransactionRequest{
+				VehicleID:     validVehicleID,
+				BuyerID:       validBuyerID,
+				Amount:        money.MustParse("25000", ""),
+				Currency:      "USD",
+				PaymentMethod: PaymentMethodCash,
+				Escrow:        true,
+			},
+			wantErr: true,
+			errMsg:  "escrow is only available for card and bank_transfer payments",
+		},
 		{
 			name: "valid with inspection",
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        25000.0,
+				Amount:        money.MustParse("25000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodCash,
 				InspectionID:  validInspectionID,
@@ -77,7 +142,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     "",
 				BuyerID:       validBuyerID,
-				Amount:        25000.0,
+				Amount:        money.MustParse("25000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodCash,
 			},
@@ -89,7 +154,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     "invalid-id",
 				BuyerID:       validBuyerID,
-				Amount:        25000.0,
+				Amount:        money.MustParse("25000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodCash,
 			},
@@ -101,7 +166,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       "",
-				Amount:        25000.0,
+				Amount:        money.MustParse("25000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodCash,
 			},
@@ -113,7 +178,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       "invalid-id",
-				Amount:        25000.0,
+				Amount:        money.MustParse("25000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodCash,
 			},
@@ -125,7 +190,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        0,
+				Amount:        money.MustParse("0", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodCash,
 			},
@@ -137,7 +202,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        -1000.0,
+				Amount:        money.MustParse("-1000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodCash,
 			},
@@ -149,19 +214,55 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        25000.0,
+				Amount:        money.MustParse("25000", ""),
 				Currency:      "",
 				PaymentMethod: PaymentMethodCash,
 			},
 			wantErr: true,
 			errMsg:  "currency is required",
 		},
+		{
+			name: "unsupported currency",
+			request: CreateTransactionRequest{
+				VehicleID:     validVehicleID,
+				BuyerID:       validBuyerID,
+				Amount:        money.MustParse("25000", ""),
+				Currency:      "XYZ",
+				PaymentMethod: PaymentMethodCash,
+			},
+			wantErr: true,
+			errMsg:  "currency must be a supported ISO 4217 code",
+		},
+		{
+			name: "fractional amount in a currency without minor units",
+			request: CreateTransactionRequest{
+				VehicleID:     validVehicleID,
+				BuyerID:       validBuyerID,
+				Amount:        money.MustParse("25000.50", ""),
+				Currency:      "JPY",
+				PaymentMethod: PaymentMethodCash,
+			},
+			wantErr: true,
+			errMsg:  "has more than 0 decimal places",
+		},
+		{
+			name: "amount in a different currency",
+			request: CreateTransactionRequest{
+				VehicleID:     validVehicleID,
+				BuyerID:       validBuyerID,
+				Amount:        money.MustParse("25000", "EUR"),
+				Currency:      "USD",
+				PaymentMethod: PaymentMethodCash,
+			},
+			wantErr: true,
+			errMsg:  "amounts are in different currencies",
+		},
 		{
 			name: "missing paymentMethod",
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        25000.0,
+				Amount:        money.MustParse("25000", ""),
 				Currency:      "USD",
 				PaymentMethod: "",
 			},
@@ -173,7 +274,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        25000.0,
+				Amount:        money.MustParse("25000", ""),
 				Currency:      "USD",
 				PaymentMethod: "crypto",
 			},
@@ -185,7 +286,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        25000.0,
+				Amount:        money.MustParse("25000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodCash,
 				InspectionID:  "invalid-id",
@@ -198,7 +299,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        30000.0,
+				Amount:        money.MustParse("30000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodFinancing,
 				PaymentDetails: PaymentDetails{
@@ -214,11 +315,11 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        30000.0,
+				Amount:        money.MustParse("30000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodFinancing,
 				PaymentDetails: PaymentDetails{
-					DownPayment:    30000.0,
+					DownPayment:    money.MustParse("30000", ""),
 					FinancingTerms: 60,
 					InterestRate:   3.5,
 				},
@@ -231,11 +332,11 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        30000.0,
+				Amount:        money.MustParse("30000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodFinancing,
 				PaymentDetails: PaymentDetails{
-					DownPayment:  10000.0,
+					DownPayment:  money.MustParse("10000", ""),
 					InterestRate: 3.5,
 				},
 			},
@@ -247,11 +348,11 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        30000.0,
+				Amount:        money.MustParse("30000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodFinancing,
 				PaymentDetails: PaymentDetails{
-					DownPayment:    10000.0,
+					DownPayment:    money.MustParse("10000", ""),
 					FinancingTerms: 60,
 					InterestRate:   -1.0,
 				},
@@ -259,12 +360,66 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "interestRate cannot be negative",
 		},
+		{
+			name: "valid financing with fractional-year term and balloon",
+			request: CreateTransactionRequest{
+				VehicleID:     validVehicleID,
+				BuyerID:       validBuyerID,
+				Amount:        money.MustParse("30000", ""),
+				Currency:      "USD",
+				PaymentMethod: PaymentMethodFinancing,
+				PaymentDetails: PaymentDetails{
+					DownPayment:    money.MustParse("10000", ""),
+					FinancingYears: 2.5,
+					InterestRate:   4.9,
+					BalloonPayment: money.MustParse("5000", ""),
+					Compounding:    "daily",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "financing with balloon covering the financed amount",
+			request: CreateTransactionRequest{
+				VehicleID:     validVehicleID,
+				BuyerID:       validBuyerID,
+				Amount:        money.MustParse("30000", ""),
+				Currency:      "USD",
+				PaymentMethod: PaymentMethodFinancing,
+				PaymentDetails: PaymentDetails{
+					DownPayment:    money.MustParse("10000", ""),
+					FinancingTerms: 60,
+					InterestRate:   3.5,
+					BalloonPayment: money.MustParse("20000", ""),
+				},
+			},
+			wantErr: true,
+			errMsg:  "balloon payment must be less than the financed amount",
+		},
+		{
+			name: "financing with unknown compounding",
+			request: CreateTransactionRequest{
+				VehicleID:     validVehicleID,
+				BuyerID:       validBuyerID,
+				Amount:        money.MustParse("30000", ""),
+				Currency:      "USD",
+				PaymentMethod: PaymentMethodFinancing,
+				PaymentDetails: PaymentDetails{
+					DownPayment:    money.MustParse("10000", ""),
+					FinancingTerms: 60,
+					InterestRate:   3.5,
+					Compounding:    "weekly",
+				},
+			},
+			wantErr: true,
+			errMsg:  "compounding must be one of",
+		},
 		{
 			name: "bank transfer without bank name",
 			request: CreateTransactionRequest{
 				VehicleID:     validVehicleID,
 				BuyerID:       validBuyerID,
-				Amount:        25000.0,
+				Amount:        money.MustParse("25000", ""),
 				Currency:      "USD",
 				PaymentMethod: PaymentMethodBankTransfer,
 			},
@@ -384,6 +539,39 @@ func TestCompleteTransactionRequest_Validate(t *testing.T) {
 	}
 }
 
+func TestFundTransactionRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request FundTransactionRequest
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid funding request",
+			request: FundTransactionRequest{PaymentSource: "tok_visa"},
+			wantErr: false,
+		},
+		{
+			name:    "missing payment source",
+			request: FundTransactionRequest{Notes: "funding"},
+			wantErr: true,
+			errMsg:  "paymentSource is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.errMsg, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestIsValidTransactionStatus(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -391,7 +579,10 @@ func TestIsValidTransactionStatus(t *testing.T) {
 		want   bool
 	}{
 		{"pending status", TransactionStatusPending, true},
+		{"funded status", TransactionStatusFunded, true},
+		{"delivered status", TransactionStatusDelivered, true},
 		{"completed status", TransactionStatusCompleted, true},
+		{"refunded status", TransactionStatusRefunded, true},
 		{"cancelled status", TransactionStatusCancelled, true},
 		{"failed status", TransactionStatusFailed, true},
 		{"invalid status", "invalid", false},
@@ -447,3 +638,56 @@ func TestIsValidPaymentMethod(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateTransactionRequest_Amounts(t *testing.T) {
+	request := CreateTransactionRequest{
+		Amount:   money.MustParse("30000", ""),
+		Currency: "NGN",
+		PaymentDetails: PaymentDetails{
+			DownPayment:    money.MustParse("10000.5", ""),
+			FinancingTerms: 36,
+		},
+	}
+
+	amount, details, err := request.Amounts()
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("30000", "NGN"), amount)
+	assert.Equal(t, money.MustParse("10000.50", "NGN"), details.DownPayment)
+	assert.True(t, details.BalloonPayment.IsZero())
+	assert.Equal(t, 36, details.FinancingTerms)
+}
+
+func TestRefundTransactionRequest_Validate(t *testing.T) {
+	req := RefundTransactionRequest{Reason: "vehicle was misdescribed"}
+	assert.NoError(t, req.Validate(), "the amount defaults to the refundable balance")
+
+	req.Amount = money.MustParse("500", "")
+	assert.NoError(t, req.Validate())
+
+	req.Amount = money.MustParse("-1", "")
+	assert.EqualError(t, req.Validate(), "amount must be greater than 0")
+
+	req = RefundTransactionRequest{}
+	assert.EqualError(t, req.Validate(), "reason is required")
+}
+
+func TestTransaction_RefundableAmount(t *testing.T) {
+	txn := Transaction{Amount: money.MustParse("25000", "USD")}
+
+	refundable, err := txn.RefundableAmount()
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("25000", "USD"), refundable)
+
+	txn.RefundedAmount = money.MustParse("2500.50", "USD")
+	refundable, err = txn.RefundableAmount()
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("22499.50", "USD"), refundable)
+}
+
+func TestPendingRefund(t *testing.T) {
+	done := TransactionRefund{ID: primitive.NewObjectID(), Amount: money.MustParse("1000", "USD")}
+	pending := TransactionRefund{ID: primitive.NewObjectID(), Amount: money.MustParse("24000", "USD"), Status: RefundStatusPending}
+
+	assert.Nil(t, (&Transaction{Refunds: []TransactionRefund{done}}).PendingRefund(), "refunds recorded without a status are completed")
+	assert.Equal(t, pending.ID, (&Transaction{Refunds: []TransactionRefund{done, pending}}).PendingRefund().ID)
+}
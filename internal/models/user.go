@@ -13,25 +13,38 @@ type User struct {
 	Password  string             `json:"-" bson:"password"` // Never send password in JSON response
 	FirstName string             `json:"firstName" bson:"firstName"`
 	LastName  string             `json:"lastName" bson:"lastName"`
-	Role      string             `json:"role" bson:"role"` // admin, dealer, buyer
+	Role      string             `json:"role" bson:"role"`                             // admin, dealer, buyer, inspector
+	Location  *Location          `json:"location,omitempty" bson:"location,omitempty"` // service area, used to assign inspectors
 	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
 	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+
+	// SHA-256 of the token that authenticates the user's calendar feed URL
+	CalendarTokenHash string `json:"-" bson:"calendarTokenHash,omitempty"`
 }
 
 // User role constants
 const (
-	RoleAdmin  = "admin"
-	RoleDealer = "dealer"
-	RoleBuyer  = "buyer"
+	RoleAdmin     = "admin"
+	RoleDealer    = "dealer"
+	RoleBuyer     = "buyer"
+	RoleInspector = "inspector"
 )
 
 // RegisterRequest represents the registration request payload
 type RegisterRequest struct {
-	Email     string `json:"email" binding:"required,email"`
-	Password  string `json:"password" binding:"required,min=6"`
-	FirstName string `json:"firstName" binding:"required"`
-	LastName  string `json:"lastName" binding:"required"`
-	Role      string `json:"role" binding:"omitempty,oneof=admin dealer buyer"`
+	Email     string    `json:"email" binding:"required,email"`
+	Password  string    `json:"password" binding:"required,min=6"`
+	FirstName string    `json:"firstName" binding:"required"`
+	LastName  string    `json:"lastName" binding:"required"`
+	Role      string    `json:"role" binding:"omitempty,oneof=admin dealer buyer inspector"`
+	Location  *Location `json:"location"`
+}
+
+// CalendarTokenResponse represents a newly issued calendar feed token
+// The token is only returned once; rotating it invalidates the previous feed URL
+type CalendarTokenResponse struct {
+	Token   string `json:"token"`
+	FeedURL string `json:"feedUrl"`
 }
 
 // LoginRequest represents the login request payload
@@ -2,9 +2,12 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
 )
 
 // Vehicle represents a vehicle in the system
@@ -14,7 +17,7 @@ type Vehicle struct {
 	Make      string             `json:"make" bson:"make"`
 	Model     string             `json:"model" bson:"model"`
 	Year      int                `json:"year" bson:"year"`
-	Price     float64            `json:"price" bson:"price"`
+	Price     money.Money        `json:"price" bson:"price"` // in the currency the vehicle is listed in
 	Mileage   float64            `json:"mileage" bson:"mileage"`
 	Status    string             `json:"status" bson:"status"`
 	Location  Location           `json:"location" bson:"location"`
@@ -22,6 +25,16 @@ type Vehicle struct {
 	Meta      VehicleMeta        `json:"meta" bson:"meta"`
 	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
 	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+
+	// ListingType is ListingTypeAuction while the vehicle is being auctioned; empty means ListingTypeFixedPrice
+	ListingType string              `json:"listingType,omitempty" bson:"listingType,omitempty"`
+	AuctionID   *primitive.ObjectID `json:"auctionId,omitempty" bson:"auctionId,omitempty"`
+
+	// Reservation is set while the vehicle is held for a transaction
+	Reservation *VehicleReservation `json:"reservation,omitempty" bson:"reservation,omitempty"`
+
+	// DisplayPrice is the price converted to the currency a listing was requested in; it is never stored
+	DisplayPrice *money.Money `json:"displayPrice,omitempty" bson:"-"`
 }
 
 // Location represents the vehicle location
@@ -45,12 +58,21 @@ type VehicleMeta struct {
 	FuelType     string `json:"fuelType,omitempty" bson:"fuelType,omitempty"`
 }
 
+// VehicleReservation holds a vehicle for one transaction until it completes, is cancelled or the hold expires
+type VehicleReservation struct {
+	TransactionID primitive.ObjectID `json:"transactionId" bson:"transactionId"`
+	BuyerID       primitive.ObjectID `json:"buyerId" bson:"buyerId"`
+	ExpiresAt     time.Time          `json:"expiresAt" bson:"expiresAt"`
+	CreatedAt     time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
 // CreateVehicleRequest represents the request payload for creating a vehicle
 type CreateVehicleRequest struct {
 	Make     string         `json:"make" binding:"required"`
 	Model    string         `json:"model" binding:"required"`
 	Year     int            `json:"year" binding:"required,min=1900,max=2100"`
-	Price    float64        `json:"price" binding:"required,min=0"`
+	Price    money.Money    `json:"price"`
+	Currency string         `json:"currency"` // ISO 4217 code of the price, money.DefaultCurrency when omitted
 	Mileage  float64        `json:"mileage" binding:"required,min=0"`
 	Location Location       `json:"location" binding:"required"`
 	Images   []VehicleImage `json:"images"`
@@ -62,7 +84,8 @@ type UpdateVehicleRequest struct {
 	Make     string         `json:"make"`
 	Model    string         `json:"model"`
 	Year     int            `json:"year" binding:"omitempty,min=1900,max=2100"`
-	Price    float64        `json:"price" binding:"omitempty,min=0"`
+	Price    money.Money    `json:"price"`
+	Currency string         `json:"currency"` // relists the vehicle in another currency; requires a price
 	Mileage  float64        `json:"mileage" binding:"omitempty,min=0"`
 	Status   string         `json:"status"`
 	Location *Location      `json:"location"`
@@ -73,6 +96,7 @@ type UpdateVehicleRequest struct {
 // VehicleStatus constants
 const (
 	VehicleStatusActive   = "active"
+	VehicleStatusReserved = "reserved" // held for a pending transaction; set and cleared by transactions only
 	VehicleStatusSold     = "sold"
 	VehicleStatusArchived = "archived"
 )
@@ -88,9 +112,15 @@ func (req *CreateVehicleRequest) Validate() error {
 	if req.Year < 1900 || req.Year > 2100 {
 		return errors.New("year must be between 1900 and 2100")
 	}
-	if req.Price < 0 {
+	if req.Price.IsNegative() {
 		return errors.New("price must be non-negative")
 	}
+	if req.Currency != "" && !money.IsValidCurrency(req.Currency) {
+		return errors.New("currency must be a supported ISO 4217 code")
+	}
+	if _, err := req.ListedPrice(); err != nil {
+		return err
+	}
 	if req.Mileage < 0 {
 		return errors.New("mileage must be non-negative")
 	}
@@ -100,6 +130,19 @@ func (req *CreateVehicleRequest) Validate() error {
 	return nil
 }
 
+// ListedPrice returns the price in the currency the vehicle is listed in
+func (req *CreateVehicleRequest) ListedPrice() (money.Money, error) {
+	currency := req.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	price, err := req.Price.WithCurrency(currency)
+	if err != nil {
+		return money.Money{}, fmt.Errorf("price: %w", err)
+	}
+	return price, nil
+}
+
 // Validate validates the Location
 func (loc *Location) Validate() error {
 	if loc.City == "" {
@@ -119,15 +162,29 @@ func (req *UpdateVehicleRequest) Validate() error {
 	if req.Year != 0 && (req.Year < 1900 || req.Year > 2100) {
 		return errors.New("year must be between 1900 and 2100")
 	}
-	if req.Price < 0 {
+	if req.Price.IsNegative() {
 		return errors.New("price must be non-negative")
 	}
+	if req.Currency != "" {
+		if !money.IsValidCurrency(req.Currency) {
+			return errors.New("currency must be a supported ISO 4217 code")
+		}
+		if req.Price.IsZero() {
+			return errors.New("price is required when changing currency")
+		}
+		if _, err := req.Price.WithCurrency(req.Currency); err != nil {
+			return fmt.Errorf("price: %w", err)
+		}
+	}
 	if req.Mileage < 0 {
 		return errors.New("mileage must be non-negative")
 	}
 	if req.Status != "" && !IsValidVehicleStatus(req.Status) {
 		return errors.New("invalid vehicle status")
 	}
+	if req.Status == VehicleStatusReserved {
+		return errors.New("vehicles are reserved by creating a transaction")
+	}
 	if req.Location != nil {
 		if err := req.Location.Validate(); err != nil {
 			return err
@@ -139,6 +196,7 @@ func (req *UpdateVehicleRequest) Validate() error {
 // IsValidVehicleStatus checks if the status is valid
 func IsValidVehicleStatus(status string) bool {
 	return status == VehicleStatusActive ||
+		status == VehicleStatusReserved ||
 		status == VehicleStatusSold ||
 		status == VehicleStatusArchived
 }
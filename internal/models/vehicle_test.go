@@ -2,6 +2,8 @@ package models
 
 import (
 	"testing"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
 )
 
 // TestCreateVehicleRequest_Validate tests validation for CreateVehicleRequest
@@ -18,7 +20,7 @@ func TestCreateVehicleRequest_Validate(t *testing.T) {
 				Make:    "Toyota",
 				Model:   "Camry",
 				Year:    2020,
-				Price:   25000,
+				Price:   money.MustParse("25000", ""),
 				Mileage: 15000,
 				Location: Location{
 					City:    "New York",
@@ -33,7 +35,7 @@ func TestCreateVehicleRequest_Validate(t *testing.T) {
 			req: CreateVehicleRequest{
 				Model:   "Camry",
 				Year:    2020,
-				Price:   25000,
+				Price:   money.MustParse("25000", ""),
 				Mileage: 15000,
 				Location: Location{
 					City:    "New York",
@@ -49,7 +51,7 @@ func TestCreateVehicleRequest_Validate(t *testing.T) {
 			req: CreateVehicleRequest{
 				Make:    "Toyota",
 				Year:    2020,
-				Price:   25000,
+				Price:   money.MustParse("25000", ""),
 				Mileage: 15000,
 				Location: Location{
 					City:    "New York",
@@ -66,7 +68,7 @@ func TestCreateVehicleRequest_Validate(t *testing.T) {
 				Make:    "Toyota",
 				Model:   "Camry",
 				Year:    1899,
-				Price:   25000,
+				Price:   money.MustParse("25000", ""),
 				Mileage: 15000,
 				Location: Location{
 					City:    "New York",
@@ -83,7 +85,7 @@ func TestCreateVehicleRequest_Validate(t *testing.T) {
 				Make:    "Toyota",
 				Model:   "Camry",
 				Year:    2101,
-				Price:   25000,
+				Price:   money.MustParse("25000", ""),
 				Mileage: 15000,
 				Location: Location{
 					City:    "New York",
@@ -100,7 +102,7 @@ func TestCreateVehicleRequest_Validate(t *testing.T) {
 				Make:    "Toyota",
 				Model:   "Camry",
 				Year:    2020,
-				Price:   -1000,
+				Price:   money.MustParse("-1000", ""),
 				Mileage: 15000,
 				Location: Location{
 					City:    "New York",
@@ -117,7 +119,7 @@ func TestCreateVehicleRequest_Validate(t *testing.T) {
 				Make:    "Toyota",
 				Model:   "Camry",
 				Year:    2020,
-				Price:   25000,
+				Price:   money.MustParse("25000", ""),
 				Mileage: -1000,
 				Location: Location{
 					City:    "New York",
@@ -128,13 +130,48 @@ func TestCreateVehicleRequest_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "mileage must be non-negative",
 		},
+		{
+			name: "Valid request in another currency",
+			req: CreateVehicleRequest{
+				Make:     "Toyota",
+				Model:    "Camry",
+				Year:     2020,
+				Price:    money.MustParse("38000000", ""),
+				Currency: "NGN",
+				Mileage:  15000,
+				Location: Location{
+					City:    "Lagos",
+					State:   "LA",
+					Country: "Nigeria",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Unsupported currency",
+			req: CreateVehicleRequest{
+				Make:     "Toyota",
+				Model:    "Camry",
+				Year:     2020,
+				Price:    money.MustParse("25000", ""),
+				Currency: "usd",
+				Mileage:  15000,
+				Location: Location{
+					City:    "New York",
+					State:   "NY",
+					Country: "USA",
+				},
+			},
+			wantErr: true,
+			errMsg:  "currency must be a supported ISO 4217 code",
+		},
 		{
 			name: "Zero price and mileage",
 			req: CreateVehicleRequest{
 				Make:    "Toyota",
 				Model:   "Camry",
 				Year:    2020,
-				Price:   0,
+				Price:   money.MustParse("0", ""),
 				Mileage: 0,
 				Location: Location{
 					City:    "New York",
@@ -240,7 +277,7 @@ func TestUpdateVehicleRequest_Validate(t *testing.T) {
 				Make:    "Honda",
 				Model:   "Accord",
 				Year:    2021,
-				Price:   28000,
+				Price:   money.MustParse("28000", ""),
 				Mileage: 5000,
 				Status:  VehicleStatusActive,
 				Location: &Location{
@@ -254,7 +291,7 @@ func TestUpdateVehicleRequest_Validate(t *testing.T) {
 		{
 			name: "Valid partial update",
 			req: UpdateVehicleRequest{
-				Price:  30000,
+				Price:  money.MustParse("30000", ""),
 				Status: VehicleStatusSold,
 			},
 			wantErr: false,
@@ -283,11 +320,36 @@ func TestUpdateVehicleRequest_Validate(t *testing.T) {
 		{
 			name: "Negative price",
 			req: UpdateVehicleRequest{
-				Price: -5000,
+				Price: money.MustParse("-5000", ""),
 			},
 			wantErr: true,
 			errMsg:  "price must be non-negative",
 		},
+		{
+			name: "Relist in another currency",
+			req: UpdateVehicleRequest{
+				Price:    money.MustParse("3500000", ""),
+				Currency: "JPY",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Currency change without price",
+			req: UpdateVehicleRequest{
+				Currency: "EUR",
+			},
+			wantErr: true,
+			errMsg:  "price is required when changing currency",
+		},
+		{
+			name: "Price too precise for currency",
+			req: UpdateVehicleRequest{
+				Price:    money.MustParse("3500000.50", ""),
+				Currency: "JPY",
+			},
+			wantErr: true,
+			errMsg:  "price: invalid amount: 3500000.5000 has more than 0 decimal places",
+		},
 		{
 			name: "Negative mileage",
 			req: UpdateVehicleRequest{
@@ -325,6 +387,14 @@ func TestUpdateVehicleRequest_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Reserved status is set by transactions",
+			req: UpdateVehicleRequest{
+				Status: VehicleStatusReserved,
+			},
+			wantErr: true,
+			errMsg:  "vehicles are reserved by creating a transaction",
+		},
 		{
 			name: "Invalid location - missing city",
 			req: UpdateVehicleRequest{
@@ -374,6 +444,11 @@ func TestIsValidVehicleStatus(t *testing.T) {
 			status: VehicleStatusArchived,
 			want:   true,
 		},
+		{
+			name:   "Valid status - reserved",
+			status: VehicleStatusReserved,
+			want:   true,
+		},
 		{
 			name:   "Invalid status - pending",
 			status: "pending",
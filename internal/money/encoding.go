@@ -0,0 +1,135 @@
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// document is how an amount is stored in MongoDB
+// Decimal128 keeps the value exact while range filters, sorting and $sum still treat it as a number
+type document struct {
+	Amount   primitive.Decimal128 `bson:"amount"`
+	Currency string               `bson:"currency"`
+}
+
+// jsonAmount is how an amount is sent to clients; the value is a string so no client parses it as a float
+type jsonAmount struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency,omitempty"`
+}
+
+// Decimal128 returns the amount in major units as a BSON decimal
+func (m Money) Decimal128() primitive.Decimal128 {
+	d, _ := primitive.ParseDecimal128(m.Decimal())
+	return d
+}
+
+// FromDecimal128 converts a BSON decimal, such as a $sum over stored amounts, back to an exact amount
+func FromDecimal128(d primitive.Decimal128, currency string) (Money, error) {
+	exponent, err := exponentOf(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	if d.IsNaN() || d.IsInf() != 0 {
+		return Money{}, fmt.Errorf("%w: %s", ErrInvalidAmount, d.String())
+	}
+
+	coefficient, exp, err := d.BigInt()
+	if err != nil {
+		return Money{}, err
+	}
+	rat := new(big.Rat).SetInt(coefficient)
+	if exp > 0 {
+		rat.Mul(rat, new(big.Rat).SetInt(pow10(exp)))
+	} else if exp < 0 {
+		rat.Quo(rat, new(big.Rat).SetInt(pow10(-exp)))
+	}
+
+	minor, exact, err := scaleRat(rat, exponent, RoundDown)
+	if err != nil {
+		return Money{}, err
+	}
+	if !exact {
+		return Money{}, fmt.Errorf("%w: %s has more than %d decimal places", ErrInvalidAmount, d.String(), exponent)
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// MarshalBSONValue stores the amount as {amount: Decimal128, currency}
+func (m Money) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	raw, err := bson.Marshal(document{Amount: m.Decimal128(), Currency: m.currency})
+	return bsontype.EmbeddedDocument, raw, err
+}
+
+// UnmarshalBSONValue reads an amount stored by MarshalBSONValue
+// Plain numbers from before the Money migration are rejected rather than guessed at
+func (m *Money) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	switch t {
+	case bsontype.Null, bsontype.Undefined:
+		*m = Money{}
+		return nil
+	case bsontype.EmbeddedDocument:
+	default:
+		return fmt.Errorf("cannot decode BSON %s into Money; run the money migration", t)
+	}
+
+	var doc document
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	decoded, err := FromDecimal128(doc.Amount, doc.Currency)
+	if err != nil {
+		return err
+	}
+	*m = decoded
+	return nil
+}
+
+// MarshalJSON encodes the amount as {"amount": "1250.50", "currency": "USD"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	amount, err := json.Marshal(m.Decimal())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonAmount{Amount: amount, Currency: m.currency})
+}
+
+// UnmarshalJSON accepts {"amount": ..., "currency": ...} as well as a bare number or decimal string
+// Bare amounts have no currency until WithCurrency attaches the one of the request they came in
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*m = Money{}
+		return nil
+	}
+
+	// Decode objects into a fresh value: RawMessage reuses its buffer, which would overwrite data
+	var raw jsonAmount
+	if len(data) > 0 && data[0] == '{' {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	} else {
+		raw.Amount = data
+	}
+
+	amount := string(raw.Amount)
+	if len(raw.Amount) > 0 && raw.Amount[0] == '"' {
+		if err := json.Unmarshal(raw.Amount, &amount); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := Parse(amount, raw.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,351 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency prices amounts that do not belong to a document with its own currency
+const DefaultCurrency = "USD"
+
+// unitlessExponent is the precision kept for amounts decoded without a currency, such as bare JSON numbers
+// WithCurrency rescales them once the currency they are in is known
+const unitlessExponent = 4
+
+// Errors returned by parsing and arithmetic
+var (
+	ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// Money is an exact amount of a currency, held in the currency's minor units (cents for USD)
+// The zero value is zero with no currency
+type Money struct {
+	minor    int64
+	currency string
+}
+
+// New creates an amount from minor units
+func New(minor int64, currency string) Money {
+	return Money{minor: minor, currency: currency}
+}
+
+// Zero returns zero in the currency
+func Zero(currency string) Money {
+	return Money{currency: currency}
+}
+
+// Parse reads a decimal amount such as "1250.50" exactly
+// It fails if the amount has more decimal places than the currency allows
+func Parse(amount, currency string) (Money, error) {
+	exponent, err := exponentOf(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	amount = strings.TrimSpace(amount)
+	if !isDecimal(amount) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	rat, _ := new(big.Rat).SetString(amount)
+	minor, exact, err := scaleRat(rat, exponent, RoundDown)
+	if err != nil {
+		return Money{}, err
+	}
+	if !exact {
+		return Money{}, fmt.Errorf("%w: %s has more than %d decimal places", ErrInvalidAmount, amount, exponent)
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// MustParse is like Parse but panics on error; it is meant for constants and tests
+func MustParse(amount, currency string) Money {
+	m, err := Parse(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// FromFloat converts a floating point amount, rounding to the currency's minor unit with mode
+// The float is read through its shortest decimal form, so 1.005 rounds half up to 1.01 rather than 1.00
+func FromFloat(amount float64, currency string, mode RoundingMode) (Money, error) {
+	exponent, err := exponentOf(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return Money{}, fmt.Errorf("%w: %v", ErrInvalidAmount, amount)
+	}
+
+	rat, _ := new(big.Rat).SetString(strconv.FormatFloat(amount, 'f', -1, 64))
+	minor, _, err := scaleRat(rat, exponent, mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// Minor returns the amount in minor units
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+// Currency returns the ISO 4217 code of the amount, or "" if it has none yet
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// Exponent returns the number of decimal places of the amount's minor unit
+func (m Money) Exponent() int {
+	exponent, _ := exponentOf(m.currency)
+	return exponent
+}
+
+// IsZero reports whether the amount is zero; it also lets `omitempty` and `omitzero` drop zero amounts
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.minor > 0
+}
+
+// IsNegative reports whether the amount is less than zero
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+// WithCurrency attaches a currency to an amount decoded without one
+// Amounts already in the currency are returned unchanged; amounts in another currency are an error
+func (m Money) WithCurrency(currency string) (Money, error) {
+	if m.currency == currency {
+		return m, nil
+	}
+	if m.currency != "" {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, currency)
+	}
+
+	exponent, err := exponentOf(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	rat := new(big.Rat).SetFrac(big.NewInt(m.minor), pow10(unitlessExponent))
+	minor, exact, err := scaleRat(rat, exponent, RoundDown)
+	if err != nil {
+		return Money{}, err
+	}
+	if !exact {
+		return Money{}, fmt.Errorf("%w: %s has more than %d decimal places", ErrInvalidAmount, m.Decimal(), exponent)
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// Add returns m + other
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := m.combine(other)
+	if err != nil {
+		return Money{}, err
+	}
+	sum := m.minor + other.minor
+	if (other.minor > 0 && sum < m.minor) || (other.minor < 0 && sum > m.minor) {
+		return Money{}, fmt.Errorf("%w: %s + %s is out of range", ErrInvalidAmount, m.Decimal(), other.Decimal())
+	}
+	return Money{minor: sum, currency: currency}, nil
+}
+
+// Sub returns m - other
+func (m Money) Sub(other Money) (Money, error) {
+	currency, err := m.combine(other)
+	if err != nil {
+		return Money{}, err
+	}
+	difference := m.minor - other.minor
+	if (other.minor < 0 && difference < m.minor) || (other.minor > 0 && difference > m.minor) {
+		return Money{}, fmt.Errorf("%w: %s - %s is out of range", ErrInvalidAmount, m.Decimal(), other.Decimal())
+	}
+	return Money{minor: difference, currency: currency}, nil
+}
+
+// Cmp compares two amounts, returning -1, 0 or +1
+func (m Money) Cmp(other Money) (int, error) {
+	if _, err := m.combine(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.minor < other.minor:
+		return -1, nil
+	case m.minor > other.minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Neg returns -m
+// The most negative amount has no positive counterpart, so Neg leaves it unchanged
+func (m Money) Neg() Money {
+	if m.minor == math.MinInt64 {
+		return m
+	}
+	return Money{minor: -m.minor, currency: m.currency}
+}
+
+// Abs returns the absolute value of m
+func (m Money) Abs() Money {
+	if m.minor < 0 {
+		return m.Neg()
+	}
+	return m
+}
+
+// Mul multiplies the amount by a whole number exactly
+func (m Money) Mul(n int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.minor), big.NewInt(n))
+	minor, err := toMinor(product)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{minor: minor, currency: m.currency}, nil
+}
+
+// MulFloat multiplies the amount by a factor such as an interest rate, rounding to the minor unit with mode
+func (m Money) MulFloat(factor float64, mode RoundingMode) (Money, error) {
+	rat := new(big.Rat).SetFloat64(factor)
+	if rat == nil {
+		return Money{}, fmt.Errorf("%w: factor %v", ErrInvalidAmount, factor)
+	}
+	rat.Mul(rat, new(big.Rat).SetInt64(m.minor))
+	minor, _, err := scaleRat(rat, 0, mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{minor: minor, currency: m.currency}, nil
+}
+
+// Percent returns percent per cent of the amount, rounding to the minor unit with mode
+func (m Money) Percent(percent float64, mode RoundingMode) (Money, error) {
+	rat := new(big.Rat).SetFloat64(percent)
+	if rat == nil {
+		return Money{}, fmt.Errorf("%w: percentage %v", ErrInvalidAmount, percent)
+	}
+	rat.Mul(rat, new(big.Rat).SetInt64(m.minor))
+	rat.Quo(rat, big.NewRat(100, 1))
+	minor, _, err := scaleRat(rat, 0, mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{minor: minor, currency: m.currency}, nil
+}
+
+// Convert converts the amount to another currency at rate units of that currency per unit of this one
+// The rate is read through its shortest decimal form, as rates are published
+func (m Money) Convert(currency string, rate float64, mode RoundingMode) (Money, error) {
+	exponent, err := exponentOf(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	if rate <= 0 || math.IsNaN(rate) || math.IsInf(rate, 0) {
+		return Money{}, fmt.Errorf("%w: exchange rate %v", ErrInvalidAmount, rate)
+	}
+
+	factor, _ := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	major := new(big.Rat).SetFrac(big.NewInt(m.minor), pow10(m.Exponent()))
+	minor, _, err := scaleRat(major.Mul(major, factor), exponent, mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// Float64 returns the amount in major units as a float, for rate calculations and display only
+func (m Money) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(big.NewInt(m.minor), pow10(m.Exponent())).Float64()
+	return f
+}
+
+// Decimal returns the amount in major units with all of the currency's decimal places, such as "1250.50"
+func (m Money) Decimal() string {
+	return formatMinor(m.minor, m.Exponent())
+}
+
+// String returns the amount and its currency, such as "1250.50 USD"
+func (m Money) String() string {
+	if m.currency == "" {
+		return m.Decimal()
+	}
+	return m.Decimal() + " " + m.currency
+}
+
+// combine returns the currency of a result of combining two amounts
+// The zero value combines with any amount, so optional amounts that were never set need no special casing
+func (m Money) combine(other Money) (string, error) {
+	switch {
+	case m.currency == other.currency:
+		return m.currency, nil
+	case m == Money{}:
+		return other.currency, nil
+	case other == Money{}:
+		return m.currency, nil
+	}
+	return "", fmt.Errorf("%w: %q and %q", ErrCurrencyMismatch, m.currency, other.currency)
+}
+
+// exponentOf returns the minor unit exponent of a currency, or the unitless precision for ""
+func exponentOf(currency string) (int, error) {
+	if currency == "" {
+		return unitlessExponent, nil
+	}
+	exponent, ok := Exponent(currency)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return exponent, nil
+}
+
+// formatMinor writes minor units as a decimal with exponent places
+func formatMinor(minor int64, exponent int) string {
+	sign := ""
+	digits := strconv.FormatUint(uint64(minor), 10)
+	if minor < 0 {
+		sign = "-"
+		digits = strconv.FormatUint(uint64(-minor), 10)
+	}
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// isDecimal reports whether s is a plain decimal number such as "-12.50", without exponents or fractions
+func isDecimal(s string) bool {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	whole, fraction, hasPoint := strings.Cut(s, ".")
+	if whole == "" && fraction == "" {
+		return false
+	}
+	if hasPoint && fraction == "" {
+		return false
+	}
+	for _, part := range []string{whole, fraction} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// pow10 returns 10^n as a big integer
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
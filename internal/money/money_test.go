@@ -0,0 +1,258 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		minor    int64
+		wantErr  bool
+	}{
+		{"1250.50", "USD", 125050, false},
+		{"1250.5", "USD", 125050, false},
+		{"-0.01", "USD", -1, false},
+		{"25000", "JPY", 25000, false},
+		{"1.234", "KWD", 1234, false},
+		{"1.005", "USD", 0, true},
+		{"25000.5", "JPY", 0, true},
+		{"1e3", "USD", 0, true},
+		{"1/2", "USD", 0, true},
+		{"12.", "USD", 0, true},
+		{"", "USD", 0, true},
+		{"10", "XYZ", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount+" "+tt.currency, func(t *testing.T) {
+			m, err := Parse(tt.amount, tt.currency)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.minor, m.Minor())
+			assert.Equal(t, tt.currency, m.Currency())
+		})
+	}
+}
+
+func TestRoundingModes(t *testing.T) {
+	tests := []struct {
+		amount float64
+		mode   RoundingMode
+		minor  int64
+	}{
+		{1.005, RoundHalfUp, 101},
+		{1.005, RoundHalfEven, 100},
+		{1.015, RoundHalfEven, 102},
+		{1.001, RoundUp, 101},
+		{1.009, RoundDown, 100},
+		{-1.005, RoundHalfUp, -101},
+		{-1.009, RoundDown, -100},
+		{2.5, RoundHalfEven, 250},
+	}
+
+	for _, tt := range tests {
+		m, err := FromFloat(tt.amount, "USD", tt.mode)
+		require.NoError(t, err)
+		assert.Equal(t, tt.minor, m.Minor(), "%v with mode %d", tt.amount, tt.mode)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a := MustParse("100.10", "USD")
+	b := MustParse("0.20", "USD")
+
+	sum, err := a.Add(b)
+	require.NoError(t, err)
+	assert.Equal(t, "100.30", sum.Decimal())
+
+	diff, err := b.Sub(a)
+	require.NoError(t, err)
+	assert.Equal(t, "-99.90", diff.Decimal())
+
+	cmp, err := a.Cmp(b)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cmp)
+
+	_, err = a.Add(MustParse("1", "EUR"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	// An unset amount combines with any currency
+	unset, err := Money{}.Sub(a)
+	require.NoError(t, err)
+	assert.Equal(t, MustParse("-100.10", "USD"), unset)
+
+	product, err := a.Mul(3)
+	require.NoError(t, err)
+	assert.Equal(t, "300.30", product.Decimal())
+	interest, err := MustParse("20000", "USD").MulFloat(0.06/12, RoundHalfUp)
+	require.NoError(t, err)
+	assert.Equal(t, "100.00", interest.Decimal())
+	fee, err := MustParse("500.50", "USD").Percent(5, RoundHalfUp)
+	require.NoError(t, err)
+	assert.Equal(t, "25.03", fee.Decimal())
+	fee, err = MustParse("500.50", "USD").Percent(5, RoundHalfEven)
+	require.NoError(t, err)
+	assert.Equal(t, "25.02", fee.Decimal())
+	assert.Equal(t, "1250.50 USD", MustParse("1250.5", "USD").String())
+	assert.Equal(t, "0.05", MustParse("0.05", "USD").Decimal())
+	assert.Equal(t, "7", MustParse("7", "JPY").Decimal())
+	assert.Equal(t, 1250.5, MustParse("1250.50", "USD").Float64())
+}
+
+func TestRange(t *testing.T) {
+	largest := New(math.MaxInt64, "USD")
+	smallest := New(math.MinInt64, "USD")
+
+	// The largest amount in cents still parses; one cent more does not wrap around
+	parsed, err := Parse("92233720368547758.07", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, largest, parsed)
+	_, err = Parse("92233720368547758.08", "USD")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = Parse("100000000000000000000", "USD")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = Parse("-100000000000000000000", "USD")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = FromFloat(1e30, "USD", RoundHalfUp)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	one := New(1, "USD")
+	_, err = largest.Add(one)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = smallest.Sub(one)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = largest.Sub(one.Neg())
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = smallest.Add(one.Neg())
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = largest.Mul(2)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = smallest.Mul(-1)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = largest.MulFloat(1.5, RoundHalfUp)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = largest.Percent(200, RoundHalfUp)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = largest.Convert("NGN", 1500, RoundHalfEven)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	// Results at the limits are fine
+	sum, err := New(math.MaxInt64-1, "USD").Add(one)
+	require.NoError(t, err)
+	assert.Equal(t, largest, sum)
+	difference, err := New(math.MinInt64+1, "USD").Sub(one)
+	require.NoError(t, err)
+	assert.Equal(t, smallest, difference)
+	product, err := New(math.MaxInt64/2, "USD").Mul(2)
+	require.NoError(t, err)
+	assert.Equal(t, New(math.MaxInt64-1, "USD"), product)
+}
+
+func TestConvert(t *testing.T) {
+	ngn, err := MustParse("100.50", "USD").Convert("NGN", 1520.25, RoundHalfEven)
+	require.NoError(t, err)
+	assert.Equal(t, MustParse("152785.12", "NGN"), ngn, "152785.125 rounds half to even")
+
+	jpy, err := MustParse("10.00", "USD").Convert("JPY", 149.55, RoundHalfEven)
+	require.NoError(t, err)
+	assert.Equal(t, MustParse("1496", "JPY"), jpy)
+
+	_, err = MustParse("1", "USD").Convert("NGN", 0, RoundHalfEven)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = MustParse("1", "USD").Convert("XYZ", 1, RoundHalfEven)
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestWithCurrency(t *testing.T) {
+	unitless := MustParse("25000.5", "")
+
+	usd, err := unitless.WithCurrency("USD")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2500050), usd.Minor())
+
+	_, err = unitless.WithCurrency("JPY")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	same, err := usd.WithCurrency("USD")
+	require.NoError(t, err)
+	assert.Equal(t, usd, same)
+
+	_, err = usd.WithCurrency("EUR")
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestJSON(t *testing.T) {
+	encoded, err := json.Marshal(MustParse("1250.5", "USD"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"1250.50","currency":"USD"}`, string(encoded))
+
+	inputs := map[string]Money{
+		`{"amount":"1250.50","currency":"USD"}`: MustParse("1250.50", "USD"),
+		`{"amount":1250.5,"currency":"USD"}`:    MustParse("1250.50", "USD"),
+		`"1250.50"`:                             MustParse("1250.50", ""),
+		`1250.5`:                                MustParse("1250.5", ""),
+		`null`:                                  {},
+	}
+	for input, want := range inputs {
+		var m Money
+		require.NoError(t, json.Unmarshal([]byte(input), &m), input)
+		assert.Equal(t, want, m, input)
+	}
+
+	// Decoding must leave the input untouched
+	input := []byte(`{"price":{"amount":"1250.50","currency":"USD"},"id":"x"}`)
+	original := string(input)
+	var wrapped struct {
+		Price Money  `json:"price"`
+		ID    string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(input, &wrapped))
+	assert.Equal(t, original, string(input))
+
+	var m Money
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":"1.001","currency":"USD"}`), &m))
+	assert.Error(t, json.Unmarshal([]byte(`true`), &m))
+}
+
+func TestBSON(t *testing.T) {
+	type priced struct {
+		Price   Money `bson:"price"`
+		Deposit Money `bson:"deposit,omitempty"`
+	}
+
+	raw, err := bson.Marshal(priced{Price: MustParse("25000.99", "NGN")})
+	require.NoError(t, err)
+
+	var doc bson.M
+	require.NoError(t, bson.Unmarshal(raw, &doc))
+	assert.NotContains(t, doc, "deposit")
+
+	var decoded priced
+	require.NoError(t, bson.Unmarshal(raw, &decoded))
+	assert.Equal(t, MustParse("25000.99", "NGN"), decoded.Price)
+
+	// Documents from before the migration hold plain numbers
+	legacy, err := bson.Marshal(bson.M{"price": 25000.99})
+	require.NoError(t, err)
+	assert.Error(t, bson.Unmarshal(legacy, &decoded))
+}
+
+func TestFromDecimal128(t *testing.T) {
+	sum, err := FromDecimal128(MustParse("10.10", "USD").Decimal128(), "USD")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1010), sum.Minor())
+
+	_, err = FromDecimal128(MustParse("1.234", "KWD").Decimal128(), "USD")
+	assert.Error(t, err)
+}
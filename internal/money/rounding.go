@@ -0,0 +1,69 @@
+package money
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RoundingMode decides which minor unit an amount between two minor units goes to
+type RoundingMode int
+
+// Rounding modes
+const (
+	RoundHalfUp   RoundingMode = iota // to the nearest unit, halves away from zero; customer-facing amounts
+	RoundHalfEven                     // to the nearest unit, halves to the even unit; bulk conversions without drift
+	RoundDown                         // toward zero; never charges more than owed
+	RoundUp                           // away from zero; never collects less than owed
+)
+
+// scaleRat converts a major unit amount to minor units with exponent decimal places
+// It reports whether the conversion was exact, so callers can refuse to round silently
+func scaleRat(amount *big.Rat, exponent int, mode RoundingMode) (int64, bool, error) {
+	scaled := new(big.Rat).Mul(amount, new(big.Rat).SetInt(pow10(exponent)))
+	return roundRat(scaled, mode)
+}
+
+// roundRat rounds a rational number to an integer with mode and reports whether it was already whole
+// Results that do not fit in minor units are an error rather than wrapping around
+func roundRat(r *big.Rat, mode RoundingMode) (int64, bool, error) {
+	quotient, remainder := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if remainder.Sign() == 0 {
+		minor, err := toMinor(quotient)
+		return minor, true, err
+	}
+
+	// QuoRem truncates toward zero; decide whether to step one unit away from zero
+	away := false
+	switch mode {
+	case RoundUp:
+		away = true
+	case RoundDown:
+		away = false
+	case RoundHalfUp, RoundHalfEven:
+		twice := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2))
+		switch twice.Cmp(r.Denom()) {
+		case 1:
+			away = true
+		case 0:
+			away = mode == RoundHalfUp || quotient.Bit(0) == 1
+		}
+	}
+
+	if away {
+		if r.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	minor, err := toMinor(quotient)
+	return minor, false, err
+}
+
+// toMinor converts a whole number of minor units, failing if it is out of range
+func toMinor(units *big.Int) (int64, error) {
+	if !units.IsInt64() {
+		return 0, fmt.Errorf("%w: %s minor units is out of range", ErrInvalidAmount, units)
+	}
+	return units.Int64(), nil
+}
@@ -0,0 +1,174 @@
+package payments
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MockProviderName identifies the mock provider in routes and on stored payments
+const MockProviderName = "mock"
+
+// mockUpdateAttempts bounds how often a status change is retried when another update wins the race
+const mockUpdateAttempts = 3
+
+// MockProvider is a deterministic Provider for tests and local development
+// Intent and event IDs are derived from their inputs, so replaying the same calls yields the same IDs
+// Card payments are authorized and wait for capture, bank transfers succeed immediately,
+// and sources starting with "decline" fail
+type MockProvider struct {
+	secret string
+	now    func() time.Time
+	store  IntentStore
+}
+
+// NewMockProvider creates a mock provider that signs webhooks with secret and keeps its intents in store
+// A nil store keeps them in memory, which only suits tests and a single short-lived process
+func NewMockProvider(secret string, store IntentStore) *MockProvider {
+	if store == nil {
+		store = newMemoryIntentStore()
+	}
+	return &MockProvider{
+		secret: secret,
+		now:    time.Now,
+		store:  store,
+	}
+}
+
+// Name identifies the provider
+func (p *MockProvider) Name() string {
+	return MockProviderName
+}
+
+// CreateIntent creates an intent, or returns the existing one for a repeated idempotency key
+func (p *MockProvider) CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error) {
+	if !req.Amount.IsPositive() {
+		return nil, errors.New("amount must be greater than 0")
+	}
+	key := req.IdempotencyKey
+	if key == "" {
+		key = req.Reference
+	}
+	id := "pi_mock_" + mockDigest(key)
+
+	status := StatusRequiresCapture
+	switch {
+	case strings.HasPrefix(req.Source, "decline"):
+		status = StatusFailed
+	case req.Method == "bank_transfer":
+		status = StatusSucceeded
+	}
+
+	intent, previous, err := p.store.Create(ctx, &Intent{
+		ID:           id,
+		Status:       status,
+		Reference:    req.Reference,
+		Amount:       req.Amount,
+		Method:       req.Method,
+		ClientSecret: id + "_secret_" + mockDigest(p.secret+id),
+		CreatedAt:    p.now().UTC(),
+	}, req)
+	if err != nil {
+		return nil, err
+	}
+	if previous != req {
+		return nil, errors.New("idempotency key reused with different parameters")
+	}
+	return intent, nil
+}
+
+// Capture collects an authorized payment
+func (p *MockProvider) Capture(ctx context.Context, intentID string) (*Intent, error) {
+	return p.update(ctx, intentID, StatusSucceeded)
+}
+
+// Refund returns a payment to the buyer, voiding it if it was never captured
+func (p *MockProvider) Refund(ctx context.Context, intentID string) (*Intent, error) {
+	return p.update(ctx, intentID, StatusRefunded)
+}
+
+// Status returns the current state of an intent
+func (p *MockProvider) Status(ctx context.Context, intentID string) (*Intent, error) {
+	return p.store.Load(ctx, intentID)
+}
+
+// ParseWebhook verifies the signature and decodes the event
+func (p *MockProvider) ParseWebhook(payload []byte, signature string) (*Event, error) {
+	if err := VerifySignature(p.secret, payload, signature, p.now()); err != nil {
+		return nil, err
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	if event.ID == "" || event.IntentID == "" || event.Status == "" {
+		return nil, errors.New("invalid webhook payload: id, intentId and status are required")
+	}
+	return &event, nil
+}
+
+// SignedEvent builds the webhook the provider would send for an intent's current status
+// Returns the payload and its signature header, for tests and simulating webhooks locally
+func (p *MockProvider) SignedEvent(intentID string) ([]byte, string, error) {
+	intent, err := p.Status(context.Background(), intentID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	event := Event{
+		ID:        "evt_mock_" + mockDigest(intent.ID+":"+intent.Status),
+		Type:      "payment_intent." + intent.Status,
+		IntentID:  intent.ID,
+		Status:    intent.Status,
+		Amount:    intent.Amount,
+		CreatedAt: p.now().UTC(),
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, Sign(p.secret, payload, p.now()), nil
+}
+
+// update moves an intent to a new status; repeating a completed update is a no-op
+// The change only applies if the intent is still in the status it was read in, so concurrent
+// captures and refunds cannot both succeed
+func (p *MockProvider) update(ctx context.Context, intentID, status string) (*Intent, error) {
+	for attempt := 0; attempt < mockUpdateAttempts; attempt++ {
+		intent, err := p.store.Load(ctx, intentID)
+		if err != nil {
+			return nil, err
+		}
+		if intent.Status == status {
+			return intent, nil
+		}
+		if status == StatusSucceeded && intent.Status != StatusRequiresCapture {
+			return nil, fmt.Errorf("cannot capture a payment that is %s", intent.Status)
+		}
+		if !CanTransition(intent.Status, status) {
+			return nil, fmt.Errorf("cannot move a payment from %s to %s", intent.Status, status)
+		}
+
+		updated, err := p.store.UpdateStatus(ctx, intentID, intent.Status, status)
+		if err != nil {
+			return nil, err
+		}
+		if updated {
+			intent.Status = status
+			return intent, nil
+		}
+	}
+	return nil, fmt.Errorf("payment %s was modified concurrently", intentID)
+}
+
+// mockDigest derives a short stable identifier from a value
+func mockDigest(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,167 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func TestMockProvider_CardPayment(t *testing.T) {
+	provider := NewMockProvider("whsec_test", nil)
+	ctx := context.Background()
+	req := IntentRequest{Reference: "txn_1", Amount: money.MustParse("25000", "USD"), Method: "card", Source: "tok_visa", IdempotencyKey: "txn_1-1"}
+
+	intent, err := provider.CreateIntent(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRequiresCapture, intent.Status)
+	assert.NotEmpty(t, intent.ClientSecret)
+
+	// The same key returns the same intent
+	again, err := provider.CreateIntent(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, intent.ID, again.ID)
+
+	// Reusing the key for a different payment is rejected
+	changed := req
+	changed.Amount = money.MustParse("1", "USD")
+	_, err = provider.CreateIntent(ctx, changed)
+	assert.Error(t, err)
+
+	captured, err := provider.Capture(ctx, intent.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, captured.Status)
+
+	// Capture and refund are idempotent
+	_, err = provider.Capture(ctx, intent.ID)
+	assert.NoError(t, err)
+	refunded, err := provider.Refund(ctx, intent.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRefunded, refunded.Status)
+	_, err = provider.Refund(ctx, intent.ID)
+	assert.NoError(t, err)
+
+	_, err = provider.Capture(ctx, intent.ID)
+	assert.Error(t, err)
+}
+
+func TestMockProvider_Deterministic(t *testing.T) {
+	req := IntentRequest{Reference: "txn_1", Amount: money.MustParse("100", "USD"), Method: "bank_transfer", IdempotencyKey: "txn_1-1"}
+
+	first, err := NewMockProvider("whsec_test", nil).CreateIntent(context.Background(), req)
+	assert.NoError(t, err)
+	second, err := NewMockProvider("whsec_test", nil).CreateIntent(context.Background(), req)
+	assert.NoError(t, err)
+
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, StatusSucceeded, first.Status)
+}
+
+func TestMockProvider_Declined(t *testing.T) {
+	provider := NewMockProvider("whsec_test", nil)
+	intent, err := provider.CreateIntent(context.Background(), IntentRequest{Reference: "txn_1", Amount: money.MustParse("100", "USD"), Method: "card", Source: "decline_card"})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusFailed, intent.Status)
+
+	_, err = provider.Capture(context.Background(), intent.ID)
+	assert.Error(t, err)
+
+	_, err = provider.Status(context.Background(), "pi_mock_missing")
+	assert.ErrorIs(t, err, ErrIntentNotFound)
+}
+
+func TestMockProvider_Webhook(t *testing.T) {
+	provider := NewMockProvider("whsec_test", nil)
+	intent, err := provider.CreateIntent(context.Background(), IntentRequest{Reference: "txn_1", Amount: money.MustParse("100", "USD"), Method: "card", Source: "tok_visa"})
+	assert.NoError(t, err)
+
+	payload, signature, err := provider.SignedEvent(intent.ID)
+	assert.NoError(t, err)
+
+	event, err := provider.ParseWebhook(payload, signature)
+	assert.NoError(t, err)
+	assert.Equal(t, intent.ID, event.IntentID)
+	assert.Equal(t, StatusRequiresCapture, event.Status)
+	assert.Equal(t, "payment_intent.requires_capture", event.Type)
+
+	// Redelivering the same status carries the same event ID
+	payload2, signature2, err := provider.SignedEvent(intent.ID)
+	assert.NoError(t, err)
+	redelivered, err := provider.ParseWebhook(payload2, signature2)
+	if assert.NoError(t, err) {
+		assert.Equal(t, event.ID, redelivered.ID)
+	}
+
+	// Tampering with the payload breaks the signature
+	var tampered Event
+	assert.NoError(t, json.Unmarshal(payload, &tampered))
+	tampered.Status = StatusSucceeded
+	body, _ := json.Marshal(tampered)
+	_, err = provider.ParseWebhook(body, signature)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = NewMockProvider("whsec_other", nil).ParseWebhook(payload, signature)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestRegistry(t *testing.T) {
+	mock := NewMockProvider("whsec_test", nil)
+	registry := NewRegistry(mock)
+
+	assert.Equal(t, MockProviderName, registry.Default().Name())
+
+	provider, err := registry.Get(MockProviderName)
+	assert.NoError(t, err)
+	assert.Equal(t, mock, provider)
+
+	_, err = registry.Get("stripe")
+	assert.Error(t, err)
+}
+
+func TestMockProvider_SharedStore(t *testing.T) {
+	store := newMemoryIntentStore()
+	ctx := context.Background()
+	req := IntentRequest{Reference: "txn_1", Amount: money.MustParse("500", "USD"), Method: "card", Source: "tok_visa", IdempotencyKey: "txn_1-deposit"}
+
+	// Another instance, or the same one after a restart, sees the intents created before
+	intent, err := NewMockProvider("whsec_test", store).CreateIntent(ctx, req)
+	assert.NoError(t, err)
+	other := NewMockProvider("whsec_test", store)
+
+	again, err := other.CreateIntent(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, intent.ID, again.ID)
+
+	refunded, err := other.Refund(ctx, intent.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRefunded, refunded.Status)
+
+	_, err = NewMockProvider("whsec_test", store).Capture(ctx, intent.ID)
+	assert.EqualError(t, err, "cannot capture a payment that is refunded")
+}
+
+func TestMemoryIntentStore_UpdateStatus(t *testing.T) {
+	store := newMemoryIntentStore()
+	ctx := context.Background()
+	_, _, err := store.Create(ctx, &Intent{ID: "pi_1", Status: StatusRequiresCapture}, IntentRequest{})
+	assert.NoError(t, err)
+
+	updated, err := store.UpdateStatus(ctx, "pi_1", StatusRequiresCapture, StatusSucceeded)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	// A change based on a stale read does not apply
+	updated, err = store.UpdateStatus(ctx, "pi_1", StatusRequiresCapture, StatusRefunded)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+
+	intent, err := store.Load(ctx, "pi_1")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, intent.Status)
+
+	_, err = store.UpdateStatus(ctx, "pi_missing", StatusRequiresCapture, StatusSucceeded)
+	assert.ErrorIs(t, err, ErrIntentNotFound)
+}
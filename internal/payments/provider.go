@@ -0,0 +1,119 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// Payment intent status constants
+const (
+	StatusRequiresCapture = "requires_capture" // authorized, funds not yet taken
+	StatusProcessing      = "processing"       // submitted, waiting for the bank to settle
+	StatusSucceeded       = "succeeded"
+	StatusFailed          = "failed"
+	StatusRefunded        = "refunded"
+)
+
+// ErrIntentNotFound is returned when a provider has no intent with the given ID
+var ErrIntentNotFound = errors.New("payment intent not found")
+
+// Provider collects card and bank transfer payments through an external payment processor
+// Capture and Refund must be idempotent: repeating a call that already succeeded returns the same intent
+type Provider interface {
+	Name() string
+	CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error)
+	Capture(ctx context.Context, intentID string) (*Intent, error)
+	Refund(ctx context.Context, intentID string) (*Intent, error)
+	Status(ctx context.Context, intentID string) (*Intent, error)
+
+	// ParseWebhook verifies a webhook's signature and decodes the event it carries
+	ParseWebhook(payload []byte, signature string) (*Event, error)
+}
+
+// IntentRequest describes a payment to collect
+type IntentRequest struct {
+	Reference      string // merchant reference, the transaction ID
+	Amount         money.Money
+	Method         string // card or bank_transfer
+	Source         string // provider token for the buyer's card or bank account
+	IdempotencyKey string // repeated requests with the same key return the same intent
+}
+
+// Intent is a provider's record of a payment
+type Intent struct {
+	ID           string      `json:"id"`
+	Status       string      `json:"status"`
+	Reference    string      `json:"reference"`
+	Amount       money.Money `json:"amount"`
+	Method       string      `json:"method"`
+	ClientSecret string      `json:"clientSecret,omitempty"` // lets the buyer's client complete the payment
+	CreatedAt    time.Time   `json:"createdAt"`
+}
+
+// Event is a payment status change reported by a provider webhook
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	IntentID  string      `json:"intentId"`
+	Status    string      `json:"status"`
+	Amount    money.Money `json:"amount"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// IsConfirmed reports whether a payment in this status has been authorized or collected
+func IsConfirmed(status string) bool {
+	return status == StatusRequiresCapture || status == StatusSucceeded
+}
+
+// CanTransition reports whether an intent may move from one status to another
+// Webhooks can arrive late or out of order, so stale updates must not move a payment backwards
+func CanTransition(from, to string) bool {
+	switch from {
+	case "":
+		return true
+	case StatusRequiresCapture:
+		return to == StatusSucceeded || to == StatusFailed || to == StatusRefunded
+	case StatusProcessing:
+		return to == StatusSucceeded || to == StatusFailed
+	case StatusSucceeded:
+		return to == StatusRefunded
+	default:
+		return false
+	}
+}
+
+// Registry looks up payment providers by name
+type Registry struct {
+	providers   map[string]Provider
+	defaultName string
+}
+
+// NewRegistry creates a registry; new payments are created with the default provider
+func NewRegistry(defaultProvider Provider, others ...Provider) *Registry {
+	r := &Registry{
+		providers:   map[string]Provider{defaultProvider.Name(): defaultProvider},
+		defaultName: defaultProvider.Name(),
+	}
+	for _, p := range others {
+		r.providers[p.Name()] = p
+	}
+	return r
+}
+
+// Default returns the provider new payments are created with
+func (r *Registry) Default() Provider {
+	return r.providers[r.defaultName]
+}
+
+// Get returns the provider with the given name
+func (r *Registry) Get(name string) (Provider, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown payment provider %q", name)
+	}
+	return p, nil
+}
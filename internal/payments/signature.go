@@ -0,0 +1,80 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the HMAC signature of a webhook payload
+const SignatureHeader = "X-Payment-Signature"
+
+// SignatureTolerance is how far a webhook timestamp may be from now before it is rejected as a replay
+const SignatureTolerance = 5 * time.Minute
+
+// ErrInvalidSignature is returned when a webhook signature is missing, malformed, stale or wrong
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign computes the signature header for a payload: "t=<unix seconds>,v1=<hex HMAC-SHA256>"
+// The timestamp is part of the signed message so a captured webhook cannot be replayed later
+func Sign(secret string, payload []byte, timestamp time.Time) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, computeSignature(secret, ts, payload))
+}
+
+// VerifySignature checks a signature header produced by Sign
+func VerifySignature(secret string, payload []byte, header string, now time.Time) error {
+	if secret == "" {
+		return ErrInvalidSignature
+	}
+
+	var ts string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if ts == "" || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	seconds, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > SignatureTolerance || age < -SignatureTolerance {
+		return ErrInvalidSignature
+	}
+
+	// Several v1 values are allowed so secrets can be rotated without dropping webhooks
+	expected := []byte(computeSignature(secret, ts, payload))
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// computeSignature returns the hex HMAC-SHA256 of "<timestamp>.<payload>"
+func computeSignature(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
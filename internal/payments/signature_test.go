@@ -0,0 +1,74 @@
+package payments
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySignature(t *testing.T) {
+	secret := "whsec_test"
+	payload := []byte(`{"id":"evt_1"}`)
+	now := time.Unix(1700000000, 0)
+	header := Sign(secret, payload, now)
+
+	tests := []struct {
+		name    string
+		secret  string
+		payload []byte
+		header  string
+		now     time.Time
+		wantErr bool
+	}{
+		{"valid signature", secret, payload, header, now, false},
+		{"within tolerance", secret, payload, header, now.Add(4 * time.Minute), false},
+		{"stale timestamp", secret, payload, header, now.Add(6 * time.Minute), true},
+		{"future timestamp", secret, payload, header, now.Add(-6 * time.Minute), true},
+		{"wrong secret", "whsec_other", payload, header, now, true},
+		{"modified payload", secret, []byte(`{"id":"evt_2"}`), header, now, true},
+		{"missing header", secret, payload, "", now, true},
+		{"missing signature", secret, payload, "t=1700000000", now, true},
+		{"malformed timestamp", secret, payload, strings.Replace(header, "t=1700000000", "t=abc", 1), now, true},
+		{"empty secret", "", payload, Sign("", payload, now), now, true},
+		{"rotated secret", secret, payload, header + ",v1=" + strings.Repeat("0", 64), now, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(tt.secret, tt.payload, tt.header, tt.now)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSignature)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		want bool
+	}{
+		{"", StatusRequiresCapture, true},
+		{StatusRequiresCapture, StatusSucceeded, true},
+		{StatusRequiresCapture, StatusRefunded, true},
+		{StatusProcessing, StatusSucceeded, true},
+		{StatusProcessing, StatusFailed, true},
+		{StatusProcessing, StatusRefunded, false},
+		{StatusSucceeded, StatusRefunded, true},
+		{StatusSucceeded, StatusRequiresCapture, false},
+		{StatusSucceeded, StatusFailed, false},
+		{StatusFailed, StatusSucceeded, false},
+		{StatusRefunded, StatusSucceeded, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			assert.Equal(t, tt.want, CanTransition(tt.from, tt.to))
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package payments
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// IntentStore keeps the mock provider's intents
+// A shared store lets every server instance see the same intents, and keeps them across restarts
+type IntentStore interface {
+	// Create stores a new intent with the request that created it, or returns the intent and request
+	// already stored under the same ID
+	Create(ctx context.Context, intent *Intent, req IntentRequest) (*Intent, IntentRequest, error)
+	// Load returns the intent with the ID, or ErrIntentNotFound
+	Load(ctx context.Context, id string) (*Intent, error)
+	// UpdateStatus moves the intent to status if it is still in from, returning false if it was not
+	UpdateStatus(ctx context.Context, id, from, status string) (bool, error)
+}
+
+// memoryIntentStore keeps intents in process memory, for tests and single-process development
+type memoryIntentStore struct {
+	mu       sync.Mutex
+	intents  map[string]Intent
+	requests map[string]IntentRequest
+}
+
+// newMemoryIntentStore creates an empty in-memory store
+func newMemoryIntentStore() *memoryIntentStore {
+	return &memoryIntentStore{intents: map[string]Intent{}, requests: map[string]IntentRequest{}}
+}
+
+// Create stores the intent unless one with its ID exists
+func (s *memoryIntentStore) Create(_ context.Context, intent *Intent, req IntentRequest) (*Intent, IntentRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.intents[intent.ID]; ok {
+		return &existing, s.requests[intent.ID], nil
+	}
+	s.intents[intent.ID] = *intent
+	s.requests[intent.ID] = req
+	stored := *intent
+	return &stored, req, nil
+}
+
+// Load returns a copy of the intent
+func (s *memoryIntentStore) Load(_ context.Context, id string) (*Intent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	intent, ok := s.intents[id]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	return &intent, nil
+}
+
+// UpdateStatus changes the status if it is still from
+func (s *memoryIntentStore) UpdateStatus(_ context.Context, id, from, status string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	intent, ok := s.intents[id]
+	if !ok {
+		return false, ErrIntentNotFound
+	}
+	if intent.Status != from {
+		return false, nil
+	}
+	intent.Status = status
+	s.intents[id] = intent
+	return true, nil
+}
+
+// MongoIntentStore keeps the mock provider's intents in MongoDB
+type MongoIntentStore struct {
+	collection *mongo.Collection
+}
+
+// mongoIntentDocument is an intent and the request that created it
+type mongoIntentDocument struct {
+	ID           string             `bson:"_id"`
+	Status       string             `bson:"status"`
+	Reference    string             `bson:"reference"`
+	Amount       money.Money        `bson:"amount"`
+	Method       string             `bson:"method"`
+	ClientSecret string             `bson:"clientSecret"`
+	CreatedAt    time.Time          `bson:"createdAt"`
+	Request      mongoIntentRequest `bson:"request"`
+}
+
+// mongoIntentRequest is the stored request, compared with repeated requests for the same idempotency key
+type mongoIntentRequest struct {
+	Reference      string      `bson:"reference"`
+	Amount         money.Money `bson:"amount"`
+	Method         string      `bson:"method"`
+	Source         string      `bson:"source"`
+	IdempotencyKey string      `bson:"idempotencyKey"`
+}
+
+// NewMongoIntentStore creates a MongoDB-backed intent store
+func NewMongoIntentStore(collection *mongo.Collection) *MongoIntentStore {
+	return &MongoIntentStore{collection: collection}
+}
+
+// Create inserts the intent; the ID is the document key, so concurrent creates for the same key store one intent
+func (s *MongoIntentStore) Create(ctx context.Context, intent *Intent, req IntentRequest) (*Intent, IntentRequest, error) {
+	doc := mongoIntentDocument{
+		ID:           intent.ID,
+		Status:       intent.Status,
+		Reference:    intent.Reference,
+		Amount:       intent.Amount,
+		Method:       intent.Method,
+		ClientSecret: intent.ClientSecret,
+		CreatedAt:    intent.CreatedAt,
+		Request: mongoIntentRequest{
+			Reference:      req.Reference,
+			Amount:         req.Amount,
+			Method:         req.Method,
+			Source:         req.Source,
+			IdempotencyKey: req.IdempotencyKey,
+		},
+	}
+	_, err := s.collection.InsertOne(ctx, doc)
+	if err == nil {
+		stored := *intent
+		return &stored, req, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, IntentRequest{}, err
+	}
+
+	if err := s.collection.FindOne(ctx, bson.M{"_id": intent.ID}).Decode(&doc); err != nil {
+		return nil, IntentRequest{}, err
+	}
+	return doc.intent(), doc.Request.request(), nil
+}
+
+// Load returns the stored intent
+func (s *MongoIntentStore) Load(ctx context.Context, id string) (*Intent, error) {
+	var doc mongoIntentDocument
+	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrIntentNotFound
+		}
+		return nil, err
+	}
+	return doc.intent(), nil
+}
+
+// UpdateStatus changes the status only while the stored intent is still in from
+func (s *MongoIntentStore) UpdateStatus(ctx context.Context, id, from, status string) (bool, error) {
+	result, err := s.collection.UpdateOne(ctx, bson.M{"_id": id, "status": from}, bson.M{"$set": bson.M{"status": status}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// intent converts the document back to an intent
+func (d *mongoIntentDocument) intent() *Intent {
+	return &Intent{
+		ID:           d.ID,
+		Status:       d.Status,
+		Reference:    d.Reference,
+		Amount:       d.Amount,
+		Method:       d.Method,
+		ClientSecret: d.ClientSecret,
+		CreatedAt:    d.CreatedAt,
+	}
+}
+
+// request converts the stored request back to an intent request
+func (r mongoIntentRequest) request() IntentRequest {
+	return IntentRequest{
+		Reference:      r.Reference,
+		Amount:         r.Amount,
+		Method:         r.Method,
+		Source:         r.Source,
+		IdempotencyKey: r.IdempotencyKey,
+	}
+}
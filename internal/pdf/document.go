@@ -0,0 +1,221 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"strconv"
+	"strings"
+)
+
+// A4 page size in points
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Color is an RGB color with components in the 0-1 range
+type Color struct {
+	R, G, B float64
+}
+
+// Common colors
+var (
+	Black = Color{0, 0, 0}
+	White = Color{1, 1, 1}
+)
+
+// RGB creates a color from 0-255 components
+func RGB(r, g, b uint8) Color {
+	return Color{float64(r) / 255, float64(g) / 255, float64(b) / 255}
+}
+
+// Document is a PDF document built in memory
+// Only the standard Helvetica fonts are used, so no font files are embedded
+type Document struct {
+	pages  []*Page
+	images []*Image
+}
+
+// Image is a JPEG image registered with a document
+type Image struct {
+	name   string
+	data   []byte
+	Width  int
+	Height int
+}
+
+// Page is a single page; coordinates are in points with the origin at the top-left corner
+type Page struct {
+	content bytes.Buffer
+	images  []*Image
+}
+
+// New creates an empty document
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage appends a new A4 page
+func (d *Document) AddPage() *Page {
+	page := &Page{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Pages returns the document's pages in order
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+// AddImage registers an image for drawing; it is stored as an RGB JPEG
+func (d *Document) AddImage(img image.Image) (*Image, error) {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, rgba, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+
+	registered := &Image{
+		name:   "Im" + strconv.Itoa(len(d.images)+1),
+		data:   buf.Bytes(),
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+	}
+	d.images = append(d.images, registered)
+	return registered, nil
+}
+
+// Text draws a single line of text with its top-left corner at (x, y)
+func (p *Page) Text(x, y float64, font Font, size float64, color Color, text string) {
+	// PDF positions text by its baseline, roughly 80% of the font size below the top
+	baseline := PageHeight - y - size*0.8
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s rg %s %s Td (%s) Tj ET\n",
+		font.resource(), num(size), color.operands(), num(x), num(baseline), escape(encodeWinAnsi(text)))
+}
+
+// Rect fills a rectangle whose top-left corner is at (x, y)
+func (p *Page) Rect(x, y, w, h float64, fill Color) {
+	fmt.Fprintf(&p.content, "%s rg %s %s %s %s re f\n",
+		fill.operands(), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// Line strokes a straight line between two points
+func (p *Page) Line(x1, y1, x2, y2, width float64, color Color) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s m %s %s l S\n",
+		color.operands(), num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// Image draws a registered image scaled into the box whose top-left corner is at (x, y)
+func (p *Page) Image(img *Image, x, y, w, h float64) {
+	p.images = append(p.images, img)
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /%s Do Q\n",
+		num(w), num(h), num(x), num(PageHeight-y-h), img.name)
+}
+
+// Bytes serializes the document
+func (d *Document) Bytes() ([]byte, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var out bytes.Buffer
+	offsets := []int{}
+	startObject := func() int {
+		offsets = append(offsets, out.Len())
+		id := len(offsets)
+		fmt.Fprintf(&out, "%d 0 obj\n", id)
+		return id
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-4 are fixed; images follow, then one page and one content stream per page
+	firstImage := 5
+	firstPage := firstImage + len(d.images)
+
+	startObject()
+	out.WriteString("<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+
+	startObject()
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	fmt.Fprintf(&out, "<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(d.pages))
+
+	for _, base := range []string{Helvetica.baseFont(), HelveticaBold.baseFont()} {
+		startObject()
+		fmt.Fprintf(&out, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>\nendobj\n", base)
+	}
+
+	imageIDs := map[*Image]int{}
+	for _, img := range d.images {
+		imageIDs[img] = startObject()
+		fmt.Fprintf(&out, "<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n",
+			img.Width, img.Height, len(img.data))
+		out.Write(img.data)
+		out.WriteString("\nendstream\nendobj\n")
+	}
+
+	for _, page := range d.pages {
+		pageID := startObject()
+
+		xobjects := ""
+		seen := map[*Image]bool{}
+		for _, img := range page.images {
+			if !seen[img] {
+				seen[img] = true
+				xobjects += fmt.Sprintf(" /%s %d 0 R", img.name, imageIDs[img])
+			}
+		}
+
+		fmt.Fprintf(&out, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> /XObject <<%s >> >> /Contents %d 0 R >>\nendobj\n",
+			num(PageWidth), num(PageHeight), xobjects, pageID+1)
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(page.content.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+
+		startObject()
+		fmt.Fprintf(&out, "<< /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+		out.Write(compressed.Bytes())
+		out.WriteString("\nendstream\nendobj\n")
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes(), nil
+}
+
+// operands formats the color as PDF color operands
+func (c Color) operands() string {
+	return num(c.R) + " " + num(c.G) + " " + num(c.B)
+}
+
+// num formats a number compactly for a content stream
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// escape escapes a PDF literal string
+func escape(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`, "\r", `\r`, "\n", `\n`)
+	return replacer.Replace(s)
+}
@@ -0,0 +1,75 @@
+package pdf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_Bytes(t *testing.T) {
+	doc := New()
+	page := doc.AddPage()
+	page.Rect(0, 0, PageWidth, 80, RGB(20, 60, 120))
+	page.Text(40, 30, HelveticaBold, 20, White, "Inspection (certificate)")
+	page.Line(40, 100, 200, 100, 1, Black)
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	registered, err := doc.AddImage(img)
+	require.NoError(t, err)
+	page.Image(registered, 40, 120, 80, 60)
+
+	doc.AddPage()
+
+	out, err := doc.Bytes()
+	require.NoError(t, err)
+
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), "/Count 2")
+	assert.Contains(t, string(out), "/BaseFont /Helvetica-Bold")
+	assert.Contains(t, string(out), "/Subtype /Image /Width 4 /Height 3")
+
+	// Every xref entry must point at the start of its object
+	xrefAt := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	require.NotNil(t, xrefAt)
+	offset, _ := strconv.Atoi(string(xrefAt[1]))
+	assert.True(t, bytes.HasPrefix(out[offset:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out, -1)
+	require.Len(t, entries, 4+1+2*2)
+	for i, entry := range entries {
+		at, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(out[at:], []byte(strconv.Itoa(i+1)+" 0 obj")), "object %d offset", i+1)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\(b\)c\\d`, escape(`a(b)c\d`))
+}
+
+func TestEncodeWinAnsi(t *testing.T) {
+	assert.Equal(t, "caf\xe9 \x95 \x80 ?", encodeWinAnsi("café • € 漢"))
+}
+
+func TestTextWidth(t *testing.T) {
+	// "Hi" in Helvetica is 722 + 222 units
+	assert.InDelta(t, 9.44, TextWidth("Hi", Helvetica, 10), 0.001)
+	assert.Greater(t, TextWidth("Hi", HelveticaBold, 10), TextWidth("Hi", Helvetica, 10))
+}
+
+func TestWrapText(t *testing.T) {
+	lines := WrapText("the quick brown fox jumps over the lazy dog", Helvetica, 10, 60)
+	assert.Greater(t, len(lines), 1)
+	for _, line := range lines {
+		assert.LessOrEqual(t, TextWidth(line, Helvetica, 10), 60.0)
+	}
+
+	assert.Equal(t, []string{"first", "second"}, WrapText("first\nsecond", Helvetica, 10, 500))
+}
@@ -0,0 +1,121 @@
+package pdf
+
+import (
+	"strings"
+)
+
+// Font is one of the standard PDF fonts used by the document
+type Font int
+
+// Supported fonts
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+// helveticaWidths are the Helvetica glyph widths for characters 32-126, in 1/1000 em
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// helveticaBoldWidths are the Helvetica-Bold glyph widths for characters 32-126, in 1/1000 em
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// winAnsiExtras maps common typographic characters to their WinAnsiEncoding bytes
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// resource returns the font's resource name on every page
+func (f Font) resource() string {
+	if f == HelveticaBold {
+		return "F2"
+	}
+	return "F1"
+}
+
+// baseFont returns the standard font name
+func (f Font) baseFont() string {
+	if f == HelveticaBold {
+		return "Helvetica-Bold"
+	}
+	return "Helvetica"
+}
+
+// glyphWidth returns the width of a WinAnsi-encoded byte in 1/1000 em
+func (f Font) glyphWidth(b byte) int {
+	if b >= 32 && b <= 126 {
+		if f == HelveticaBold {
+			return helveticaBoldWidths[b-32]
+		}
+		return helveticaWidths[b-32]
+	}
+	if b == 0x95 {
+		return 350
+	}
+	return 556
+}
+
+// TextWidth returns the rendered width of text in points
+func TextWidth(text string, font Font, size float64) float64 {
+	total := 0
+	for _, b := range []byte(encodeWinAnsi(text)) {
+		total += font.glyphWidth(b)
+	}
+	return float64(total) * size / 1000
+}
+
+// WrapText splits text into lines no wider than maxWidth, breaking on spaces
+// Words longer than a line are placed on their own line
+func WrapText(text string, font Font, size, maxWidth float64) []string {
+	lines := []string{}
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if line != "" && TextWidth(candidate, font, size) > maxWidth {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// encodeWinAnsi converts text to WinAnsiEncoding, replacing unsupported characters with '?'
+func encodeWinAnsi(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r < 128:
+			b.WriteByte(byte(r))
+		case r >= 160 && r <= 255:
+			b.WriteByte(byte(r))
+		default:
+			if mapped, ok := winAnsiExtras[r]; ok {
+				b.WriteByte(mapped)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
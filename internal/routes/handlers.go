@@ -39,11 +39,21 @@ func WelcomeHandler(c *gin.Context) {
 		"message": "Welcome to LUJAY Assessment API",
 		"version": "1.0.0",
 		"endpoints": gin.H{
-			"auth":         "/api/v1/auth",
-			"vehicles":     "/api/v1/vehicles",
-			"inspections":  "/api/v1/inspections",
-			"transactions": "/api/v1/transactions",
-			"health":       "/health",
+			"auth":          "/api/v1/auth",
+			"vehicles":      "/api/v1/vehicles",
+			"inspections":   "/api/v1/inspections",
+			"inspectors":    "/api/v1/inspectors",
+			"templates":     "/api/v1/inspection-templates",
+			"transactions":  "/api/v1/transactions",
+			"offers":        "/api/v1/offers",
+			"auctions":      "/api/v1/auctions",
+			"notifications": "/api/v1/notifications",
+			"disputes":      "/api/v1/disputes",
+			"financing":     "/api/v1/financing",
+			"installments":  "/api/v1/installments",
+			"exchangeRates": "/api/v1/exchange-rates",
+			"verify":        "/api/v1/verify",
+			"health":        "/health",
 		},
 	})
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/Over-knight/Lujay-assesment/internal/cache"
 	"github.com/Over-knight/Lujay-assesment/internal/handlers"
 	"github.com/Over-knight/Lujay-assesment/internal/middleware"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
 	"github.com/Over-knight/Lujay-assesment/internal/storage"
 )
 
@@ -20,8 +21,18 @@ func SetupRoutes(
 	authHandler *handlers.AuthHandler,
 	vehicleHandler *handlers.VehicleHandler,
 	inspectionHandler *handlers.InspectionHandler,
+	templateHandler *handlers.InspectionTemplateHandler,
 	transactionHandler *handlers.TransactionHandler,
+	installmentHandler *handlers.InstallmentHandler,
 	uploadHandler *handlers.UploadHandler,
+	webhookHandler *handlers.PaymentWebhookHandler,
+	exchangeRateHandler *handlers.ExchangeRateHandler,
+	offerHandler *handlers.OfferHandler,
+	auctionHandler *handlers.AuctionHandler,
+	notificationHandler *handlers.NotificationHandler,
+	disputeHandler *handlers.DisputeHandler,
+	ledgerHandler *handlers.LedgerHandler,
+	idempotencyStore middleware.IdempotencyStore,
 	jwtManager *auth.JWTManager,
 ) {
 	// Health check endpoint
@@ -34,6 +45,9 @@ func SetupRoutes(
 
 	// API v1 group
 	v1 := router.Group("/api/v1")
+
+	// Replay responses for retried writes that carry an Idempotency-Key
+	v1.Use(middleware.IdempotencyMiddleware(idempotencyStore, jwtManager, 24*time.Hour))
 	{
 		// Public routes (no authentication required)
 		v1.GET("/", WelcomeHandler)
@@ -45,10 +59,46 @@ func SetupRoutes(
 		setupVehicleRoutes(v1, vehicleHandler, inspectionHandler, transactionHandler, uploadHandler, db, redisCache, jwtManager)
 
 		// Inspection routes
-		setupInspectionRoutes(v1, inspectionHandler, db, redisCache, jwtManager)
+		setupInspectionRoutes(v1, inspectionHandler, uploadHandler, db, redisCache, jwtManager)
+
+		// Public inspection seal verification
+		setupVerificationRoutes(v1, inspectionHandler)
+
+		// Inspection template routes
+		setupInspectionTemplateRoutes(v1, templateHandler, jwtManager)
+
+		// Inspector routes
+		setupInspectorRoutes(v1, inspectionHandler, db, jwtManager)
+
+		// Admin routes
+		setupAdminRoutes(v1, inspectionHandler, templateHandler, transactionHandler, exchangeRateHandler, disputeHandler, ledgerHandler, db, jwtManager)
 
 		// Transaction routes
-		setupTransactionRoutes(v1, transactionHandler, db, jwtManager)
+		setupTransactionRoutes(v1, transactionHandler, installmentHandler, db, jwtManager)
+
+		// Installment routes
+		setupInstallmentRoutes(v1, installmentHandler, db, jwtManager)
+
+		// Offer and counter-offer routes
+		setupOfferRoutes(v1, offerHandler, jwtManager)
+
+		// Auction routes
+		setupAuctionRoutes(v1, auctionHandler, db, jwtManager)
+
+		// In-app notification routes
+		setupNotificationRoutes(v1, notificationHandler, jwtManager)
+
+		// Transaction dispute routes
+		setupDisputeRoutes(v1, disputeHandler, uploadHandler, jwtManager)
+
+		// Public financing calculator
+		setupFinancingRoutes(v1, transactionHandler)
+
+		// Public exchange rates used to convert prices
+		v1.GET("/exchange-rates", exchangeRateHandler.GetRates)
+
+		// Payment provider webhooks (authenticated by signature, not JWT)
+		setupWebhookRoutes(v1, webhookHandler)
 	}
 }
 
@@ -61,6 +111,7 @@ func setupAuthRoutes(v1 *gin.RouterGroup, authHandler *handlers.AuthHandler, jwt
 
 		// Protected routes (authentication required)
 		authRoutes.GET("/profile", middleware.AuthMiddleware(jwtManager), authHandler.GetProfile)
+		authRoutes.POST("/calendar-token", middleware.AuthMiddleware(jwtManager), authHandler.RotateCalendarToken)
 	}
 }
 
@@ -113,9 +164,15 @@ func setupVehicleRoutes(
 }
 
 // setupInspectionRoutes configures inspection routes
-func setupInspectionRoutes(v1 *gin.RouterGroup, inspectionHandler *handlers.InspectionHandler, db *storage.MongoDB, redisCache *cache.RedisCache, jwtManager *auth.JWTManager) {
+func setupInspectionRoutes(v1 *gin.RouterGroup, inspectionHandler *handlers.InspectionHandler, uploadHandler *handlers.UploadHandler, db *storage.MongoDB, redisCache *cache.RedisCache, jwtManager *auth.JWTManager) {
 	inspectionRoutes := v1.Group("/inspections")
 	{
+		// Free slots change with every booking, so they are never cached
+		inspectionRoutes.GET("/slots", inspectionHandler.ListAvailableSlots)
+
+		// Calendar feed authenticated by the per-user token in the query string
+		inspectionRoutes.GET("/calendar.ics", middleware.CalendarTokenMiddleware(db.Collection("users")), inspectionHandler.GetCalendarFeed)
+
 		// Public routes with cache
 		if redisCache != nil {
 			inspectionRoutes.GET("", middleware.CacheMiddleware(redisCache, 3*time.Minute), inspectionHandler.ListInspections)
@@ -126,13 +183,21 @@ func setupInspectionRoutes(v1 *gin.RouterGroup, inspectionHandler *handlers.Insp
 		}
 
 		// Protected routes (authentication required)
-		inspectionRoutes.POST("", middleware.AuthMiddleware(jwtManager), inspectionHandler.CreateInspection)
+		inspectionRoutes.POST("", middleware.AuthMiddleware(jwtManager), middleware.RBACMiddleware(db.Collection("users"), models.RoleAdmin, models.RoleDealer, models.RoleBuyer), inspectionHandler.CreateInspection)
 		inspectionRoutes.GET("/my", middleware.AuthMiddleware(jwtManager), inspectionHandler.GetMyInspections)
+		inspectionRoutes.GET("/:id/report.pdf", middleware.AuthMiddleware(jwtManager), inspectionHandler.GetInspectionReport)
 		inspectionRoutes.PUT("/:id", middleware.AuthMiddleware(jwtManager), inspectionHandler.UpdateInspection)
+		inspectionRoutes.POST("/:id/start", middleware.AuthMiddleware(jwtManager), inspectionHandler.StartInspection)
+		inspectionRoutes.POST("/:id/reschedule", middleware.AuthMiddleware(jwtManager), inspectionHandler.RescheduleInspection)
 		inspectionRoutes.POST("/:id/complete", middleware.AuthMiddleware(jwtManager), inspectionHandler.CompleteInspection)
 		inspectionRoutes.POST("/:id/cancel", middleware.AuthMiddleware(jwtManager), inspectionHandler.CancelInspection)
 		inspectionRoutes.DELETE("/:id", middleware.AuthMiddleware(jwtManager), middleware.RequireAdmin(db.Collection("users")), inspectionHandler.DeleteInspection)
 
+		// Issue evidence upload (assigned inspector only)
+		if uploadHandler != nil {
+			inspectionRoutes.POST("/:id/issues/:index/media", middleware.AuthMiddleware(jwtManager), uploadHandler.UploadIssueMedia)
+		}
+
 		// Apply cache buster for modifying operations
 		if redisCache != nil {
 			inspectionRoutes.Use(middleware.CacheBuster(redisCache))
@@ -140,8 +205,58 @@ func setupInspectionRoutes(v1 *gin.RouterGroup, inspectionHandler *handlers.Insp
 	}
 }
 
+// setupVerificationRoutes configures public verification routes
+func setupVerificationRoutes(v1 *gin.RouterGroup, inspectionHandler *handlers.InspectionHandler) {
+	verifyRoutes := v1.Group("/verify")
+	{
+		verifyRoutes.GET("/inspections/:code", inspectionHandler.VerifyInspection)
+	}
+}
+
+// setupInspectionTemplateRoutes configures read-only checklist template routes
+func setupInspectionTemplateRoutes(v1 *gin.RouterGroup, templateHandler *handlers.InspectionTemplateHandler, jwtManager *auth.JWTManager) {
+	templateRoutes := v1.Group("/inspection-templates")
+	templateRoutes.Use(middleware.AuthMiddleware(jwtManager))
+	{
+		templateRoutes.GET("", templateHandler.ListTemplates)
+		templateRoutes.GET("/:id", templateHandler.GetTemplate)
+	}
+}
+
+// setupInspectorRoutes configures routes for the inspector's own work
+func setupInspectorRoutes(v1 *gin.RouterGroup, inspectionHandler *handlers.InspectionHandler, db *storage.MongoDB, jwtManager *auth.JWTManager) {
+	inspectorRoutes := v1.Group("/inspectors")
+	{
+		inspectorRoutes.GET("/me/queue", middleware.AuthMiddleware(jwtManager), middleware.RequireInspector(db.Collection("users")), inspectionHandler.GetInspectorQueue)
+		inspectorRoutes.GET("/me/availability", middleware.AuthMiddleware(jwtManager), middleware.RequireInspector(db.Collection("users")), inspectionHandler.GetMyAvailability)
+		inspectorRoutes.PUT("/me/availability", middleware.AuthMiddleware(jwtManager), middleware.RequireInspector(db.Collection("users")), inspectionHandler.SetMyAvailability)
+	}
+}
+
+// setupAdminRoutes configures admin-only routes
+func setupAdminRoutes(v1 *gin.RouterGroup, inspectionHandler *handlers.InspectionHandler, templateHandler *handlers.InspectionTemplateHandler, transactionHandler *handlers.TransactionHandler, exchangeRateHandler *handlers.ExchangeRateHandler, disputeHandler *handlers.DisputeHandler, ledgerHandler *handlers.LedgerHandler, db *storage.MongoDB, jwtManager *auth.JWTManager) {
+	adminRoutes := v1.Group("/admin")
+	adminRoutes.Use(middleware.AuthMiddleware(jwtManager), middleware.RequireAdmin(db.Collection("users")))
+	{
+		adminRoutes.PUT("/inspections/:id/inspector", inspectionHandler.AssignInspector)
+		adminRoutes.POST("/inspection-templates", templateHandler.SaveTemplate)
+		adminRoutes.DELETE("/inspection-templates/:key", templateHandler.RetireTemplate)
+		adminRoutes.PUT("/exchange-rates", exchangeRateHandler.UploadRates)
+		adminRoutes.POST("/transactions/:id/refund", transactionHandler.RefundTransaction)
+
+		// Dispute queue: review, then resolve for the buyer or the seller
+		adminRoutes.GET("/disputes", disputeHandler.ListDisputes)
+		adminRoutes.POST("/disputes/:id/review", disputeHandler.StartReview)
+		adminRoutes.POST("/disputes/:id/resolve", disputeHandler.ResolveDispute)
+
+		// Ledger reports for reconciliation
+		adminRoutes.GET("/ledger/trial-balance", ledgerHandler.GetTrialBalance)
+		adminRoutes.GET("/ledger/accounts/:account", ledgerHandler.GetAccountBalance)
+	}
+}
+
 // setupTransactionRoutes configures transaction routes
-func setupTransactionRoutes(v1 *gin.RouterGroup, transactionHandler *handlers.TransactionHandler, db *storage.MongoDB, jwtManager *auth.JWTManager) {
+func setupTransactionRoutes(v1 *gin.RouterGroup, transactionHandler *handlers.TransactionHandler, installmentHandler *handlers.InstallmentHandler, db *storage.MongoDB, jwtManager *auth.JWTManager) {
 	transactionRoutes := v1.Group("/transactions")
 	{
 		// Public routes (admin only)
@@ -151,8 +266,122 @@ func setupTransactionRoutes(v1 *gin.RouterGroup, transactionHandler *handlers.Tr
 		// Protected routes (authentication required)
 		transactionRoutes.POST("", middleware.AuthMiddleware(jwtManager), transactionHandler.CreateTransaction)
 		transactionRoutes.GET("/my", middleware.AuthMiddleware(jwtManager), transactionHandler.GetMyTransactions)
+		transactionRoutes.POST("/quote", middleware.AuthMiddleware(jwtManager), transactionHandler.QuoteTransaction)
 		transactionRoutes.PUT("/:id", middleware.AuthMiddleware(jwtManager), transactionHandler.UpdateTransaction)
 		transactionRoutes.POST("/:id/complete", middleware.AuthMiddleware(jwtManager), transactionHandler.CompleteTransaction)
 		transactionRoutes.POST("/:id/cancel", middleware.AuthMiddleware(jwtManager), transactionHandler.CancelTransaction)
+
+		// Reservation deposit that keeps the vehicle held for longer
+		transactionRoutes.POST("/:id/deposit", middleware.AuthMiddleware(jwtManager), transactionHandler.PayDeposit)
+
+		// Card and bank transfer payments through the payment provider
+		transactionRoutes.POST("/:id/payment-intent", middleware.AuthMiddleware(jwtManager), transactionHandler.CreatePaymentIntent)
+
+		// Escrow flow: buyer funds, seller delivers, buyer confirms or funds release after the dispute window
+		transactionRoutes.POST("/:id/fund", middleware.AuthMiddleware(jwtManager), transactionHandler.FundTransaction)
+		transactionRoutes.POST("/:id/deliver", middleware.AuthMiddleware(jwtManager), transactionHandler.MarkDelivered)
+		transactionRoutes.POST("/:id/confirm-delivery", middleware.AuthMiddleware(jwtManager), transactionHandler.ConfirmDelivery)
+
+		// Repayment plan of a financed purchase
+		transactionRoutes.GET("/:id/amortization", middleware.AuthMiddleware(jwtManager), transactionHandler.GetAmortization)
+		transactionRoutes.GET("/:id/installments", middleware.AuthMiddleware(jwtManager), installmentHandler.GetTransactionInstallments)
+
+		// Sale documents of a completed transaction
+		transactionRoutes.GET("/:id/invoice.pdf", middleware.AuthMiddleware(jwtManager), transactionHandler.GetInvoice)
+		transactionRoutes.GET("/:id/bill-of-sale.pdf", middleware.AuthMiddleware(jwtManager), transactionHandler.GetBillOfSale)
+	}
+}
+
+// setupInstallmentRoutes configures installment routes
+func setupInstallmentRoutes(v1 *gin.RouterGroup, installmentHandler *handlers.InstallmentHandler, db *storage.MongoDB, jwtManager *auth.JWTManager) {
+	installmentRoutes := v1.Group("/installments")
+	{
+		// Dealer receivables (admins can pass ?dealerId=)
+		installmentRoutes.GET("/receivables", middleware.AuthMiddleware(jwtManager), middleware.RequireAdminOrDealer(db.Collection("users")), installmentHandler.GetReceivablesSummary)
+
+		// Payments received from the buyer, recorded by the dealer or an admin
+		installmentRoutes.POST("/:id/payments", middleware.AuthMiddleware(jwtManager), installmentHandler.RecordPayment)
+	}
+}
+
+// setupOfferRoutes configures offer negotiation routes
+func setupOfferRoutes(v1 *gin.RouterGroup, offerHandler *handlers.OfferHandler, jwtManager *auth.JWTManager) {
+	// Buyers make offers on a listing; its owner sees every offer made on it
+	v1.POST("/vehicles/:id/offers", middleware.AuthMiddleware(jwtManager), offerHandler.MakeOffer)
+	v1.GET("/vehicles/:id/offers", middleware.AuthMiddleware(jwtManager), offerHandler.GetVehicleOffers)
+
+	offerRoutes := v1.Group("/offers")
+	offerRoutes.Use(middleware.AuthMiddleware(jwtManager))
+	{
+		offerRoutes.GET("/my", offerHandler.GetMyOffers)
+		offerRoutes.GET("/:id", offerHandler.GetOffer)
+
+		// The party whose turn it is accepts, rejects or counters the latest amount
+		offerRoutes.POST("/:id/counter", offerHandler.CounterOffer)
+		offerRoutes.POST("/:id/accept", offerHandler.AcceptOffer)
+		offerRoutes.POST("/:id/reject", offerHandler.RejectOffer)
+	}
+}
+
+// setupAuctionRoutes configures timed auction routes
+func setupAuctionRoutes(v1 *gin.RouterGroup, auctionHandler *handlers.AuctionHandler, db *storage.MongoDB, jwtManager *auth.JWTManager) {
+	// Dealers and admins list their vehicles by auction
+	v1.POST("/vehicles/:id/auction", middleware.AuthMiddleware(jwtManager), middleware.RequireAdminOrDealer(db.Collection("users")), auctionHandler.CreateAuction)
+
+	auctionRoutes := v1.Group("/auctions")
+	{
+		// Public routes
+		auctionRoutes.GET("", auctionHandler.ListAuctions)
+		auctionRoutes.GET("/:id", auctionHandler.GetAuction)
+		auctionRoutes.GET("/:id/bids", auctionHandler.GetBids)
+
+		// Protected routes (authentication required)
+		auctionRoutes.POST("/:id/bids", middleware.AuthMiddleware(jwtManager), auctionHandler.PlaceBid)
+		auctionRoutes.POST("/:id/cancel", middleware.AuthMiddleware(jwtManager), auctionHandler.CancelAuction)
+	}
+}
+
+// setupNotificationRoutes configures in-app notification routes
+func setupNotificationRoutes(v1 *gin.RouterGroup, notificationHandler *handlers.NotificationHandler, jwtManager *auth.JWTManager) {
+	notificationRoutes := v1.Group("/notifications")
+	notificationRoutes.Use(middleware.AuthMiddleware(jwtManager))
+	{
+		notificationRoutes.GET("", notificationHandler.GetNotifications)
+		notificationRoutes.POST("/:id/read", notificationHandler.MarkRead)
+	}
+}
+
+// setupDisputeRoutes configures transaction dispute routes
+func setupDisputeRoutes(v1 *gin.RouterGroup, disputeHandler *handlers.DisputeHandler, uploadHandler *handlers.UploadHandler, jwtManager *auth.JWTManager) {
+	// Buyers dispute their transactions; both parties see every dispute raised on one
+	v1.POST("/transactions/:id/disputes", middleware.AuthMiddleware(jwtManager), disputeHandler.OpenDispute)
+	v1.GET("/transactions/:id/disputes", middleware.AuthMiddleware(jwtManager), disputeHandler.GetTransactionDisputes)
+
+	disputeRoutes := v1.Group("/disputes")
+	disputeRoutes.Use(middleware.AuthMiddleware(jwtManager))
+	{
+		disputeRoutes.GET("/:id", disputeHandler.GetDispute)
+		disputeRoutes.POST("/:id/messages", disputeHandler.AddMessage)
+
+		// Evidence upload (buyer, seller or admin)
+		if uploadHandler != nil {
+			disputeRoutes.POST("/:id/evidence", uploadHandler.UploadDisputeEvidence)
+		}
+	}
+}
+
+// setupFinancingRoutes configures the public financing calculator
+func setupFinancingRoutes(v1 *gin.RouterGroup, transactionHandler *handlers.TransactionHandler) {
+	financingRoutes := v1.Group("/financing")
+	{
+		financingRoutes.POST("/quote", transactionHandler.QuoteFinancing)
+	}
+}
+
+// setupWebhookRoutes configures payment provider webhook routes
+func setupWebhookRoutes(v1 *gin.RouterGroup, webhookHandler *handlers.PaymentWebhookHandler) {
+	webhookRoutes := v1.Group("/webhooks")
+	{
+		webhookRoutes.POST("/payments/:provider", webhookHandler.HandleWebhook)
 	}
 }
@@ -0,0 +1,120 @@
+package seal
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Algorithm names the signature scheme used by Signer
+const Algorithm = "Ed25519"
+
+// Signer signs canonical JSON with an Ed25519 key
+type Signer struct {
+	privateKey ed25519.PrivateKey
+	keyID      string
+}
+
+// NewSigner creates a signer from a 32-byte Ed25519 seed
+func NewSigner(seed []byte) (*Signer, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key seed must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	fingerprint := sha256.Sum256(publicKey)
+
+	return &Signer{
+		privateKey: privateKey,
+		keyID:      hex.EncodeToString(fingerprint[:8]),
+	}, nil
+}
+
+// ParseSigner creates a signer from a base64-encoded 32-byte seed
+func ParseSigner(encoded string) (*Signer, error) {
+	seed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("signing key must be base64 encoded: %w", err)
+	}
+	return NewSigner(seed)
+}
+
+// GenerateSigner creates a signer with a random key
+func GenerateSigner() (*Signer, error) {
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return NewSigner(seed)
+}
+
+// KeyID identifies the signing key; it is a fingerprint of the public key
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// PublicKey returns the base64-encoded public key, for offline verification
+func (s *Signer) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.privateKey.Public().(ed25519.PublicKey))
+}
+
+// Sign returns the base64-encoded signature over the canonical JSON of v
+func (s *Signer) Sign(v interface{}) (string, error) {
+	message, err := CanonicalJSON(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(s.privateKey, message)), nil
+}
+
+// Verify reports whether signature is a valid signature over the canonical JSON of v
+func (s *Signer) Verify(v interface{}, signature string) bool {
+	raw, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	message, err := CanonicalJSON(v)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(s.privateKey.Public().(ed25519.PublicKey), message, raw)
+}
+
+// CanonicalJSON encodes v as compact JSON with object keys sorted at every level
+// Numbers keep their original representation
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Maps are encoded with sorted keys, so a round trip through a generic value sorts struct fields too
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected trailing JSON")
+	}
+
+	return json.Marshal(generic)
+}
+
+// Digest returns the hex-encoded SHA-256 of the canonical JSON of v
+func Digest(v interface{}) (string, error) {
+	canonical, err := CanonicalJSON(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
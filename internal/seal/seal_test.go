@@ -0,0 +1,74 @@
+package seal
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	type inner struct {
+		Zeta  int     `json:"zeta"`
+		Alpha float64 `json:"alpha"`
+	}
+	value := struct {
+		Name  string `json:"name"`
+		Inner inner  `json:"inner"`
+		Big   int64  `json:"big"`
+	}{"car", inner{Zeta: 1, Alpha: 2.5}, 9007199254740993}
+
+	canonical, err := CanonicalJSON(value)
+	require.NoError(t, err)
+	assert.Equal(t, `{"big":9007199254740993,"inner":{"alpha":2.5,"zeta":1},"name":"car"}`, string(canonical))
+}
+
+func TestDigest(t *testing.T) {
+	a, err := Digest(map[string]int{"a": 1, "b": 2})
+	require.NoError(t, err)
+	b, err := Digest(struct {
+		B int `json:"b"`
+		A int `json:"a"`
+	}{2, 1})
+	require.NoError(t, err)
+
+	assert.Equal(t, a, b, "field order does not change the digest")
+	assert.Len(t, a, 64)
+}
+
+func TestSigner_SignAndVerify(t *testing.T) {
+	signer, err := GenerateSigner()
+	require.NoError(t, err)
+
+	payload := map[string]string{"report": "good"}
+	signature, err := signer.Sign(payload)
+	require.NoError(t, err)
+
+	assert.True(t, signer.Verify(payload, signature))
+	assert.False(t, signer.Verify(map[string]string{"report": "poor"}, signature))
+	assert.False(t, signer.Verify(payload, "not-base64!"))
+
+	other, err := GenerateSigner()
+	require.NoError(t, err)
+	assert.False(t, other.Verify(payload, signature))
+	assert.NotEqual(t, signer.KeyID(), other.KeyID())
+}
+
+func TestParseSigner(t *testing.T) {
+	seed := make([]byte, 32)
+	seed[0] = 7
+
+	first, err := ParseSigner(base64.StdEncoding.EncodeToString(seed))
+	require.NoError(t, err)
+	second, err := NewSigner(seed)
+	require.NoError(t, err)
+	assert.Equal(t, first.PublicKey(), second.PublicKey(), "the same seed yields the same key")
+	assert.Len(t, first.KeyID(), 16)
+
+	_, err = ParseSigner("not base64")
+	assert.Error(t, err)
+
+	_, err = ParseSigner(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.Error(t, err)
+}
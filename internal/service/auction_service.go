@@ -0,0 +1,714 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+const (
+	// auctionCloseBatch caps how many ended auctions are closed per scheduler run
+	auctionCloseBatch = 100
+
+	// bidAttempts is how many times a bid is retried after losing a race with another bid
+	bidAttempts = 3
+)
+
+// errAuctionModified is returned when a bid keeps losing races with other bids
+var errAuctionModified = apperrors.NewConflictError("auction was modified concurrently, please retry")
+
+// AuctionService handles timed auctions of vehicles
+type AuctionService struct {
+	collection        *mongo.Collection
+	bidCollection     *mongo.Collection
+	vehicleCollection *mongo.Collection
+	transactions      *TransactionService // creates the winner's pending transaction
+	notifier          Notifier
+}
+
+// NewAuctionService creates a new auction service
+func NewAuctionService(db *mongo.Database, transactions *TransactionService, notifier Notifier) *AuctionService {
+	return &AuctionService{
+		collection:        db.Collection("auctions"),
+		bidCollection:     db.Collection("auction_bids"),
+		vehicleCollection: db.Collection("vehicles"),
+		transactions:      transactions,
+		notifier:          notifier,
+	}
+}
+
+// EnsureIndexes creates the index that keeps one open auction per vehicle and the indexes used by bidding and closing
+func (s *AuctionService) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "vehicleId", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": models.AuctionStatusOpen}).
+				SetName("idx_auctions_vehicle_open_unique"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "endsAt", Value: 1}},
+			Options: options.Index().SetName("idx_auctions_status_ends"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.bidCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "auctionId", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index().SetName("idx_auction_bids_auction_created"),
+	})
+	return err
+}
+
+// CreateAuction lists one of the seller's active vehicles by auction
+// Amounts are in the vehicle's listing currency
+func (s *AuctionService) CreateAuction(ctx context.Context, vehicleID string, req *models.CreateAuctionRequest, sellerID primitive.ObjectID) (*models.Auction, error) {
+	now := time.Now()
+	if err := req.Validate(now); err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(vehicleID)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid vehicle ID")
+	}
+
+	var vehicle models.Vehicle
+	if err := s.vehicleCollection.FindOne(ctx, bson.M{"_id": objectID, "ownerId": sellerID}).Decode(&vehicle); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError("vehicle not found")
+		}
+		return nil, err
+	}
+	if vehicle.Status != models.VehicleStatusActive {
+		return nil, apperrors.NewValidationError("vehicle is not available for sale")
+	}
+	if vehicle.ListingType == models.ListingTypeAuction {
+		return nil, apperrors.NewConflictError("vehicle already has an open auction")
+	}
+
+	currency := vehicle.Price.Currency()
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	startPrice, err := req.StartPrice.WithCurrency(currency)
+	if err != nil {
+		return nil, apperrors.NewValidationError("startPrice: " + err.Error())
+	}
+	bidIncrement, err := req.BidIncrement.WithCurrency(currency)
+	if err != nil {
+		return nil, apperrors.NewValidationError("bidIncrement: " + err.Error())
+	}
+	var reservePrice money.Money
+	if !req.ReservePrice.IsZero() {
+		if reservePrice, err = req.ReservePrice.WithCurrency(currency); err != nil {
+			return nil, apperrors.NewValidationError("reservePrice: " + err.Error())
+		}
+	}
+
+	auction := &models.Auction{
+		VehicleID:     vehicle.ID,
+		SellerID:      sellerID,
+		Status:        models.AuctionStatusOpen,
+		Currency:      currency,
+		StartPrice:    startPrice,
+		ReservePrice:  reservePrice,
+		BidIncrement:  bidIncrement,
+		PaymentMethod: req.PaymentMethod,
+		Escrow:        req.Escrow,
+		StartsAt:      now,
+		EndsAt:        req.EndsAt,
+		ExtendWithin:  req.ExtendWithin,
+		ExtendBy:      req.ExtendBy,
+		ReserveMet:    reservePrice.IsZero(),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if req.StartsAt != nil {
+		auction.StartsAt = *req.StartsAt
+	}
+	if auction.ExtendWithin == 0 {
+		auction.ExtendWithin = models.DefaultAuctionExtendWithin
+	}
+	if auction.ExtendBy == 0 {
+		auction.ExtendBy = models.DefaultAuctionExtendBy
+	}
+
+	result, err := s.collection.InsertOne(ctx, auction)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, apperrors.NewConflictError("vehicle already has an open auction")
+		}
+		return nil, err
+	}
+	auction.ID = result.InsertedID.(primitive.ObjectID)
+
+	// Switch the listing to auction mode, unless the vehicle changed since it was loaded
+	listed, err := s.vehicleCollection.UpdateOne(ctx,
+		bson.M{"_id": vehicle.ID, "status": models.VehicleStatusActive, "listingType": bson.M{"$ne": models.ListingTypeAuction}},
+		bson.M{"$set": bson.M{"listingType": models.ListingTypeAuction, "auctionId": auction.ID, "updatedAt": now}},
+	)
+	if err == nil && listed.MatchedCount == 0 {
+		err = apperrors.NewConflictError("vehicle is no longer available for auction")
+	}
+	if err != nil {
+		_, _ = s.collection.DeleteOne(ctx, bson.M{"_id": auction.ID})
+		return nil, err
+	}
+
+	return auction, nil
+}
+
+// GetAuction retrieves an auction by ID
+func (s *AuctionService) GetAuction(ctx context.Context, id string) (*models.Auction, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid auction ID")
+	}
+
+	var auction models.Auction
+	if err := s.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&auction); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError("auction not found")
+		}
+		return nil, err
+	}
+	return &auction, nil
+}
+
+// ListOpenAuctions lists open auctions, ending soonest first
+func (s *AuctionService) ListOpenAuctions(ctx context.Context) ([]models.Auction, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "endsAt", Value: 1}}).SetLimit(100)
+	cursor, err := s.collection.Find(ctx, bson.M{"status": models.AuctionStatusOpen}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	auctions := []models.Auction{}
+	if err := cursor.All(ctx, &auctions); err != nil {
+		return nil, err
+	}
+	return auctions, nil
+}
+
+// GetBids lists an auction's bids, newest first; bidders' maximums are never shown
+func (s *AuctionService) GetBids(ctx context.Context, id string) ([]models.AuctionBid, error) {
+	auction, err := s.GetAuction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := s.bidCollection.Find(ctx, bson.M{"auctionId": auction.ID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	bids := []models.AuctionBid{}
+	if err := cursor.All(ctx, &bids); err != nil {
+		return nil, err
+	}
+	return bids, nil
+}
+
+// PlaceBid places a proxy bid on an open auction
+// The bid only applies if no other bid changed the auction since it was read; on a race it is resolved again
+func (s *AuctionService) PlaceBid(ctx context.Context, id string, req *models.PlaceBidRequest, bidderID primitive.ObjectID) (*models.Auction, *models.AuctionBid, error) {
+	if err := req.Validate(); err != nil {
+		return nil, nil, apperrors.NewValidationError(err.Error())
+	}
+
+	for attempt := 0; attempt < bidAttempts; attempt++ {
+		auction, err := s.GetAuction(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		now := time.Now()
+		resolution, err := resolveBid(auction, bidderID, req.MaxAmount, now)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		filter := bson.M{
+			"_id":     auction.ID,
+			"status":  models.AuctionStatusOpen,
+			"version": auction.Version,
+			"endsAt":  bson.M{"$gt": now},
+		}
+		update := bson.M{
+			"$set": bson.M{
+				"currentPrice": resolution.price,
+				"leaderId":     resolution.leaderID,
+				"leaderMax":    resolution.leaderMax,
+				"reserveMet":   resolution.reserveMet,
+				"endsAt":       resolution.endsAt,
+				"updatedAt":    now,
+			},
+			"$inc": bson.M{"version": 1, "bidCount": 1},
+		}
+		result, err := s.collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return nil, nil, err
+		}
+		if result.MatchedCount == 0 {
+			continue
+		}
+
+		bid := &models.AuctionBid{
+			AuctionID: auction.ID,
+			BidderID:  bidderID,
+			MaxAmount: resolution.maxAmount,
+			Price:     resolution.price,
+			Leading:   resolution.leading,
+			CreatedAt: now,
+		}
+		inserted, err := s.bidCollection.InsertOne(ctx, bid)
+		if err != nil {
+			return nil, nil, err
+		}
+		bid.ID = inserted.InsertedID.(primitive.ObjectID)
+
+		if resolution.outbid != nil {
+			_ = s.notifier.Notify(ctx, models.Notification{
+				UserID:  *resolution.outbid,
+				Type:    models.NotificationTypeAuctionOutbid,
+				Title:   "You have been outbid",
+				Message: fmt.Sprintf("Another bidder has taken the lead at %s.", resolution.price),
+				Data:    map[string]string{"auctionId": auction.ID.Hex(), "vehicleId": auction.VehicleID.Hex()},
+			})
+		}
+
+		auction.CurrentPrice = resolution.price
+		auction.LeaderID = &resolution.leaderID
+		auction.LeaderMax = resolution.leaderMax
+		auction.ReserveMet = resolution.reserveMet
+		auction.EndsAt = resolution.endsAt
+		auction.BidCount++
+		auction.Version++
+		auction.UpdatedAt = now
+		return auction, bid, nil
+	}
+
+	return nil, nil, errAuctionModified
+}
+
+// CancelAuction withdraws an open auction that has no bids and returns the vehicle to fixed-price listing
+func (s *AuctionService) CancelAuction(ctx context.Context, id string, sellerID primitive.ObjectID) (*models.Auction, error) {
+	auction, err := s.GetAuction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if auction.SellerID != sellerID {
+		return nil, apperrors.NewNotFoundError("auction not found")
+	}
+	if auction.Status != models.AuctionStatusOpen {
+		return nil, apperrors.NewInvalidStateTransitionError(fmt.Sprintf("auction is already %s", auction.Status))
+	}
+	if auction.BidCount > 0 {
+		return nil, apperrors.NewInvalidStateTransitionError("auctions with bids cannot be cancelled")
+	}
+
+	now := time.Now()
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": auction.ID, "status": models.AuctionStatusOpen, "bidCount": 0},
+		bson.M{"$set": bson.M{"status": models.AuctionStatusCancelled, "closedAt": now, "updatedAt": now}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, errAuctionModified
+	}
+	if err := s.endListing(ctx, auction, now); err != nil {
+		return nil, err
+	}
+
+	auction.Status = models.AuctionStatusCancelled
+	auction.ClosedAt = &now
+	auction.UpdatedAt = now
+	return auction, nil
+}
+
+// CloseDueAuctions closes auctions whose end time has passed, creating a pending transaction for each winner
+// Sold auctions whose close stopped before the winner's transaction was recorded are finished
+// Returns how many auctions were closed or finished
+func (s *AuctionService) CloseDueAuctions(ctx context.Context, now time.Time) (int, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": models.AuctionStatusOpen, "endsAt": bson.M{"$lte": now}},
+		bson.M{"status": models.AuctionStatusClosed, "outcome": models.AuctionOutcomeSold, "transactionId": bson.M{"$exists": false}},
+	}}
+	opts := options.Find().SetSort(bson.D{{Key: "endsAt", Value: 1}}).SetLimit(auctionCloseBatch)
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var due []models.Auction
+	if err := cursor.All(ctx, &due); err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	var errs []error
+	for i := range due {
+		err := s.closeAuction(ctx, &due[i], now)
+		if err == errAuctionModified {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("auction %s: %w", due[i].ID.Hex(), err))
+			continue
+		}
+		closed++
+	}
+
+	return closed, errors.Join(errs...)
+}
+
+// closeAuction records an ended auction's outcome and creates the winner's transaction
+// The vehicle stays listed for auction until the winner's transaction reserves it, so no other sale can
+// take it in between. An auction whose winner's transaction is rejected is marked failed and its vehicle
+// is listed again
+func (s *AuctionService) closeAuction(ctx context.Context, auction *models.Auction, now time.Time) error {
+	closing := auction.Status == models.AuctionStatusOpen
+	if closing {
+		auction.Outcome = auctionOutcome(auction)
+		set := bson.M{
+			"status":    models.AuctionStatusClosed,
+			"outcome":   auction.Outcome,
+			"closedAt":  now,
+			"updatedAt": now,
+		}
+		if auction.Outcome == models.AuctionOutcomeSold {
+			auction.WinnerID = auction.LeaderID
+			set["winnerId"] = auction.LeaderID
+		}
+
+		// A bid that lands first extends or changes the auction, so it is closed on a later run instead
+		result, err := s.collection.UpdateOne(ctx,
+			bson.M{"_id": auction.ID, "status": models.AuctionStatusOpen, "version": auction.Version, "endsAt": bson.M{"$lte": now}},
+			bson.M{"$set": set},
+		)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return errAuctionModified
+		}
+		auction.Status = models.AuctionStatusClosed
+		auction.ClosedAt = &now
+
+		if auction.Outcome != models.AuctionOutcomeSold {
+			if err := s.endListing(ctx, auction, now); err != nil {
+				return err
+			}
+		}
+		_ = s.notifyClosed(ctx, auction)
+	}
+
+	if auction.Outcome != models.AuctionOutcomeSold || auction.TransactionID != nil {
+		return nil
+	}
+
+	transaction, err := s.winnerTransaction(ctx, auction, !closing)
+	if err != nil {
+		return s.failAuction(ctx, auction, err, now)
+	}
+
+	// The winner's transaction holds the vehicle now, so the auction listing can end. It ends before the
+	// transaction is recorded so a close that fails in between is retried
+	if err := s.endListing(ctx, auction, now); err != nil {
+		return err
+	}
+	if _, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": auction.ID},
+		bson.M{"$set": bson.M{"transactionId": transaction.ID, "updatedAt": now}},
+	); err != nil {
+		return err
+	}
+	auction.TransactionID = &transaction.ID
+
+	return s.notifier.Notify(ctx, models.Notification{
+		UserID:  *auction.WinnerID,
+		Type:    models.NotificationTypeAuctionWon,
+		Title:   "You won the auction",
+		Message: fmt.Sprintf("Your bid won at %s. A pending transaction has been created for the purchase.", auction.CurrentPrice),
+		Data: map[string]string{
+			"auctionId":     auction.ID.Hex(),
+			"vehicleId":     auction.VehicleID.Hex(),
+			"transactionId": transaction.ID.Hex(),
+		},
+	})
+}
+
+// winnerTransaction creates the pending transaction for the auction's winner
+// A retried close first looks for the transaction an earlier attempt created without recording it
+func (s *AuctionService) winnerTransaction(ctx context.Context, auction *models.Auction, retry bool) (*models.Transaction, error) {
+	if retry {
+		existing, err := s.transactions.findTransactions(ctx, bson.M{"auctionId": auction.ID}, options.Find().SetLimit(1))
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) > 0 {
+			return &existing[0], nil
+		}
+	}
+
+	return s.transactions.createTransaction(ctx, &models.CreateTransactionRequest{
+		VehicleID:     auction.VehicleID.Hex(),
+		BuyerID:       auction.WinnerID.Hex(),
+		Amount:        auction.CurrentPrice,
+		Currency:      auction.Currency,
+		PaymentMethod: auction.PaymentMethod,
+		Escrow:        auction.Escrow,
+		Notes:         fmt.Sprintf("Created from auction %s", auction.ID.Hex()),
+	}, auction.SellerID, &auction.ID)
+}
+
+// failAuction marks a sold auction whose winner's transaction could not be created as failed, so it is
+// not retried, and lists the vehicle again. Returns the creation error
+func (s *AuctionService) failAuction(ctx context.Context, auction *models.Auction, cause error, now time.Time) error {
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": auction.ID, "status": models.AuctionStatusClosed, "transactionId": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"status": models.AuctionStatusFailed, "closeError": cause.Error(), "updatedAt": now}},
+	)
+	if err != nil {
+		return errors.Join(cause, err)
+	}
+	if result.MatchedCount == 0 {
+		return errAuctionModified
+	}
+	auction.Status = models.AuctionStatusFailed
+	auction.CloseError = cause.Error()
+
+	if err := s.endListing(ctx, auction, now); err != nil {
+		return errors.Join(cause, err)
+	}
+	return cause
+}
+
+// endListing returns an auctioned vehicle to fixed-price listing
+func (s *AuctionService) endListing(ctx context.Context, auction *models.Auction, now time.Time) error {
+	_, err := s.vehicleCollection.UpdateOne(ctx,
+		bson.M{"_id": auction.VehicleID, "auctionId": auction.ID},
+		bson.M{"$unset": bson.M{"listingType": "", "auctionId": ""}, "$set": bson.M{"updatedAt": now}},
+	)
+	return err
+}
+
+// notifyClosed tells the seller how the auction ended and every bidder who did not win that it closed
+func (s *AuctionService) notifyClosed(ctx context.Context, auction *models.Auction) error {
+	data := map[string]string{"auctionId": auction.ID.Hex(), "vehicleId": auction.VehicleID.Hex()}
+
+	sellerMessage := map[string]string{
+		models.AuctionOutcomeSold:          fmt.Sprintf("Your auction closed with a winning bid of %s.", auction.CurrentPrice),
+		models.AuctionOutcomeReserveNotMet: fmt.Sprintf("Your auction closed at %s without reaching the reserve price.", auction.CurrentPrice),
+		models.AuctionOutcomeNoBids:        "Your auction closed without any bids.",
+	}[auction.Outcome]
+	notifications := []models.Notification{{
+		UserID:  auction.SellerID,
+		Type:    models.NotificationTypeAuctionClosed,
+		Title:   "Your auction has closed",
+		Message: sellerMessage,
+		Data:    data,
+	}}
+
+	bidders, err := s.bidCollection.Distinct(ctx, "bidderId", bson.M{"auctionId": auction.ID})
+	if err != nil {
+		return err
+	}
+	message := "The auction closed without a winner because the reserve price was not met."
+	if auction.Outcome == models.AuctionOutcomeSold {
+		message = fmt.Sprintf("The auction closed and another bidder won at %s.", auction.CurrentPrice)
+	}
+	for _, value := range bidders {
+		bidderID, ok := value.(primitive.ObjectID)
+		if !ok || (auction.WinnerID != nil && bidderID == *auction.WinnerID) {
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			UserID:  bidderID,
+			Type:    models.NotificationTypeAuctionLost,
+			Title:   "Auction closed",
+			Message: message,
+			Data:    data,
+		})
+	}
+
+	return s.notifier.Notify(ctx, notifications...)
+}
+
+// auctionOutcome decides how an ended auction closes
+func auctionOutcome(auction *models.Auction) string {
+	switch {
+	case auction.LeaderID == nil:
+		return models.AuctionOutcomeNoBids
+	case !auction.ReserveMet:
+		return models.AuctionOutcomeReserveNotMet
+	default:
+		return models.AuctionOutcomeSold
+	}
+}
+
+// bidResolution is an auction's state once a proxy bid has been resolved against the leader's maximum
+type bidResolution struct {
+	maxAmount  money.Money // the bid's maximum in the auction's currency
+	price      money.Money
+	leaderID   primitive.ObjectID
+	leaderMax  money.Money
+	reserveMet bool
+	endsAt     time.Time
+	leading    bool                // whether the bidder leads after the bid
+	outbid     *primitive.ObjectID // the previous leader, if they lost the lead
+}
+
+// resolveBid applies a proxy bid placed at now to the auction's state
+// The higher maximum leads at one increment above the other maximum, capped at its own maximum; on equal
+// maximums the earlier bid keeps the lead. A leading maximum that reaches the reserve lifts the price to it
+func resolveBid(auction *models.Auction, bidderID primitive.ObjectID, maxAmount money.Money, now time.Time) (bidResolution, error) {
+	if auction.Status != models.AuctionStatusOpen {
+		return bidResolution{}, apperrors.NewInvalidStateTransitionError(fmt.Sprintf("auction is %s", auction.Status))
+	}
+	if now.Before(auction.StartsAt) {
+		return bidResolution{}, apperrors.NewInvalidStateTransitionError("auction has not started yet")
+	}
+	if !now.Before(auction.EndsAt) {
+		return bidResolution{}, apperrors.NewInvalidStateTransitionError("auction has ended")
+	}
+	if bidderID == auction.SellerID {
+		return bidResolution{}, apperrors.NewValidationError("cannot bid on your own auction")
+	}
+
+	bid, err := maxAmount.WithCurrency(auction.Currency)
+	if err != nil {
+		return bidResolution{}, apperrors.NewValidationError("maxAmount: " + err.Error())
+	}
+
+	resolution := bidResolution{
+		maxAmount: bid,
+		price:     auction.CurrentPrice,
+		leaderMax: auction.LeaderMax,
+		endsAt:    auction.EndsAt,
+	}
+	switch {
+	case auction.LeaderID == nil:
+		if compareMoney(bid, auction.StartPrice) < 0 {
+			return bidResolution{}, apperrors.NewValidationError(fmt.Sprintf("maxAmount must be at least %s", auction.StartPrice))
+		}
+		resolution.leaderID = bidderID
+		resolution.leaderMax = bid
+		resolution.price = auction.StartPrice
+		resolution.leading = true
+
+	case *auction.LeaderID == bidderID:
+		// The leader raising their own maximum does not move the price
+		if compareMoney(bid, auction.LeaderMax) <= 0 {
+			return bidResolution{}, apperrors.NewValidationError(fmt.Sprintf("maxAmount must be more than your current maximum of %s", auction.LeaderMax))
+		}
+		resolution.leaderID = bidderID
+		resolution.leaderMax = bid
+		resolution.leading = true
+
+	default:
+		minimum := auction.MinimumBid()
+		if compareMoney(bid, minimum) < 0 {
+			return bidResolution{}, apperrors.NewValidationError(fmt.Sprintf("maxAmount must be at least %s", minimum))
+		}
+		if compareMoney(bid, auction.LeaderMax) > 0 {
+			previous := *auction.LeaderID
+			resolution.leaderID = bidderID
+			resolution.leaderMax = bid
+			resolution.price = minMoney(bid, addMoney(auction.LeaderMax, auction.BidIncrement))
+			resolution.leading = true
+			resolution.outbid = &previous
+		} else {
+			resolution.leaderID = *auction.LeaderID
+			resolution.price = minMoney(auction.LeaderMax, addMoney(bid, auction.BidIncrement))
+		}
+	}
+
+	if auction.ReservePrice.IsZero() {
+		resolution.reserveMet = true
+	} else if compareMoney(resolution.leaderMax, auction.ReservePrice) >= 0 {
+		resolution.price = maxMoney(resolution.price, auction.ReservePrice)
+		resolution.reserveMet = true
+	}
+
+	// A bid close to the end leaves other bidders time to respond
+	if remaining := auction.EndsAt.Sub(now); remaining <= time.Duration(auction.ExtendWithin)*time.Second {
+		if extended := now.Add(time.Duration(auction.ExtendBy) * time.Second); extended.After(resolution.endsAt) {
+			resolution.endsAt = extended
+		}
+	}
+
+	return resolution, nil
+}
+
+// compareMoney compares amounts already known to share a currency
+func compareMoney(a, b money.Money) int {
+	cmp, _ := a.Cmp(b)
+	return cmp
+}
+
+// addMoney adds amounts already known to share a currency
+func addMoney(a, b money.Money) money.Money {
+	sum, _ := a.Add(b)
+	return sum
+}
+
+// minMoney returns the smaller of two amounts in the same currency
+func minMoney(a, b money.Money) money.Money {
+	if compareMoney(a, b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// maxMoney returns the larger of two amounts in the same currency
+func maxMoney(a, b money.Money) money.Money {
+	if compareMoney(a, b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// AuctionCloser periodically closes auctions whose end time has passed
+type AuctionCloser struct {
+	auctions *AuctionService
+	interval time.Duration
+}
+
+// NewAuctionCloser creates a closer that checks for ended auctions every interval
+func NewAuctionCloser(auctions *AuctionService, interval time.Duration) *AuctionCloser {
+	return &AuctionCloser{
+		auctions: auctions,
+		interval: interval,
+	}
+}
+
+// Run closes ended auctions every interval until ctx is cancelled
+// Errors do not stop the loop; they are passed to onError and the auctions are retried next run
+func (c *AuctionCloser) Run(ctx context.Context, onError func(error)) {
+	runEvery(ctx, c.interval, func(ctx context.Context, now time.Time) error {
+		_, err := c.auctions.CloseDueAuctions(ctx, now)
+		return err
+	}, onError)
+}
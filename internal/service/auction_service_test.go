@@ -0,0 +1,159 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func usd(amount string) money.Money {
+	return money.MustParse(amount, "USD")
+}
+
+func TestResolveBid(t *testing.T) {
+	now := time.Now()
+	seller := primitive.NewObjectID()
+	alice := primitive.NewObjectID()
+	bob := primitive.NewObjectID()
+
+	newAuction := func() *models.Auction {
+		return &models.Auction{
+			SellerID:     seller,
+			Status:       models.AuctionStatusOpen,
+			Currency:     "USD",
+			StartPrice:   usd("10000"),
+			BidIncrement: usd("250"),
+			StartsAt:     now.Add(-time.Hour),
+			EndsAt:       now.Add(time.Hour),
+			ExtendWithin: models.DefaultAuctionExtendWithin,
+			ExtendBy:     models.DefaultAuctionExtendBy,
+		}
+	}
+	led := func(leader primitive.ObjectID, price, max string) *models.Auction {
+		auction := newAuction()
+		auction.LeaderID = &leader
+		auction.CurrentPrice = usd(price)
+		auction.LeaderMax = usd(max)
+		return auction
+	}
+
+	t.Run("first bid leads at the start price", func(t *testing.T) {
+		res, err := resolveBid(newAuction(), alice, usd("12000"), now)
+		require.NoError(t, err)
+		assert.True(t, res.leading)
+		assert.Equal(t, alice, res.leaderID)
+		assert.Equal(t, "10000.00", res.price.Decimal())
+		assert.True(t, res.reserveMet, "auctions without a reserve always meet it")
+	})
+
+	t.Run("first bid below the start price", func(t *testing.T) {
+		_, err := resolveBid(newAuction(), alice, usd("9000"), now)
+		assert.EqualError(t, err, "maxAmount must be at least 10000.00 USD")
+	})
+
+	t.Run("higher maximum takes the lead one increment above the old maximum", func(t *testing.T) {
+		res, err := resolveBid(led(alice, "10000", "12000"), bob, usd("15000"), now)
+		require.NoError(t, err)
+		assert.True(t, res.leading)
+		assert.Equal(t, bob, res.leaderID)
+		assert.Equal(t, "12250.00", res.price.Decimal())
+		require.NotNil(t, res.outbid)
+		assert.Equal(t, alice, *res.outbid)
+	})
+
+	t.Run("new leader's price is capped at their maximum", func(t *testing.T) {
+		res, err := resolveBid(led(alice, "10000", "12000"), bob, usd("12100"), now)
+		require.NoError(t, err)
+		assert.Equal(t, bob, res.leaderID)
+		assert.Equal(t, "12100.00", res.price.Decimal())
+	})
+
+	t.Run("lower maximum raises the leader's price", func(t *testing.T) {
+		res, err := resolveBid(led(alice, "10000", "12000"), bob, usd("11000"), now)
+		require.NoError(t, err)
+		assert.False(t, res.leading)
+		assert.Equal(t, alice, res.leaderID)
+		assert.Equal(t, "11250.00", res.price.Decimal())
+		assert.Nil(t, res.outbid)
+	})
+
+	t.Run("equal maximum keeps the earlier bid in the lead", func(t *testing.T) {
+		res, err := resolveBid(led(alice, "10000", "12000"), bob, usd("12000"), now)
+		require.NoError(t, err)
+		assert.False(t, res.leading)
+		assert.Equal(t, alice, res.leaderID)
+		assert.Equal(t, "12000.00", res.price.Decimal())
+	})
+
+	t.Run("bid below the minimum", func(t *testing.T) {
+		_, err := resolveBid(led(alice, "11000", "12000"), bob, usd("11100"), now)
+		assert.EqualError(t, err, "maxAmount must be at least 11250.00 USD")
+	})
+
+	t.Run("leader raising their maximum keeps the price", func(t *testing.T) {
+		res, err := resolveBid(led(alice, "11000", "12000"), alice, usd("20000"), now)
+		require.NoError(t, err)
+		assert.True(t, res.leading)
+		assert.Equal(t, "11000.00", res.price.Decimal())
+		assert.Equal(t, "20000.00", res.leaderMax.Decimal())
+
+		_, err = resolveBid(led(alice, "11000", "12000"), alice, usd("12000"), now)
+		assert.Error(t, err)
+	})
+
+	t.Run("maximum reaching the reserve lifts the price to it", func(t *testing.T) {
+		auction := newAuction()
+		auction.ReservePrice = usd("14000")
+
+		res, err := resolveBid(auction, alice, usd("13000"), now)
+		require.NoError(t, err)
+		assert.False(t, res.reserveMet)
+		assert.Equal(t, "10000.00", res.price.Decimal())
+
+		res, err = resolveBid(auction, alice, usd("15000"), now)
+		require.NoError(t, err)
+		assert.True(t, res.reserveMet)
+		assert.Equal(t, "14000.00", res.price.Decimal())
+	})
+
+	t.Run("late bid extends the auction", func(t *testing.T) {
+		auction := newAuction()
+		auction.EndsAt = now.Add(30 * time.Second)
+
+		res, err := resolveBid(auction, alice, usd("10000"), now)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(2*time.Minute), res.endsAt)
+
+		res, err = resolveBid(newAuction(), alice, usd("10000"), now)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(time.Hour), res.endsAt, "early bids leave the end alone")
+	})
+
+	t.Run("rejected bids", func(t *testing.T) {
+		_, err := resolveBid(newAuction(), seller, usd("12000"), now)
+		assert.EqualError(t, err, "cannot bid on your own auction")
+
+		_, err = resolveBid(newAuction(), alice, usd("12000"), now.Add(time.Hour))
+		assert.EqualError(t, err, "auction has ended")
+
+		_, err = resolveBid(newAuction(), alice, usd("12000"), now.Add(-2*time.Hour))
+		assert.EqualError(t, err, "auction has not started yet")
+
+		_, err = resolveBid(newAuction(), alice, money.MustParse("12000", "EUR"), now)
+		assert.Error(t, err)
+	})
+}
+
+func TestAuctionOutcome(t *testing.T) {
+	leader := primitive.NewObjectID()
+
+	assert.Equal(t, models.AuctionOutcomeNoBids, auctionOutcome(&models.Auction{}))
+	assert.Equal(t, models.AuctionOutcomeReserveNotMet, auctionOutcome(&models.Auction{LeaderID: &leader}))
+	assert.Equal(t, models.AuctionOutcomeSold, auctionOutcome(&models.Auction{LeaderID: &leader, ReserveMet: true}))
+}
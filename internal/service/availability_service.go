@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+// maxSlotSearchWindow bounds how far ahead free slots can be listed in one request
+const maxSlotSearchWindow = 31 * 24 * time.Hour
+
+// Slot booking errors
+var (
+	errSlotTaken       = apperrors.NewConflictError("inspector is already booked at this time")
+	errSlotUnavailable = apperrors.NewValidationError("requested time is not an available slot in the inspector's calendar")
+)
+
+// AvailabilityService manages inspector calendars and slot reservations
+type AvailabilityService struct {
+	collection            *mongo.Collection
+	reservationCollection *mongo.Collection
+	lockCollection        *mongo.Collection // one document per inspector, written by every reservation to serialize them
+}
+
+// NewAvailabilityService creates a new availability service
+func NewAvailabilityService(db *mongo.Database) *AvailabilityService {
+	return &AvailabilityService{
+		collection:            db.Collection("inspector_availability"),
+		reservationCollection: db.Collection("inspection_slots"),
+		lockCollection:        db.Collection("inspector_booking_locks"),
+	}
+}
+
+// EnsureIndexes creates the indexes used to find an inspector's overlapping reservations
+func (s *AvailabilityService) EnsureIndexes(ctx context.Context) error {
+	_, err := s.reservationCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "inspectorId", Value: 1}, {Key: "start", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("idx_inspection_slots_inspector_start_unique"),
+		},
+		{
+			Keys:    bson.D{{Key: "inspectorId", Value: 1}, {Key: "end", Value: 1}},
+			Options: options.Index().SetName("idx_inspection_slots_inspector_end"),
+		},
+		{
+			Keys:    bson.D{{Key: "inspectionId", Value: 1}},
+			Options: options.Index().SetName("idx_inspection_slots_inspectionid"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "inspectorId", Value: 1}},
+		Options: options.Index().SetUnique(true).SetName("idx_inspector_availability_inspector_unique"),
+	})
+	return err
+}
+
+// GetAvailability retrieves an inspector's calendar, falling back to the default one
+func (s *AvailabilityService) GetAvailability(ctx context.Context, inspectorID primitive.ObjectID) (*models.InspectorAvailability, error) {
+	var availability models.InspectorAvailability
+	err := s.collection.FindOne(ctx, bson.M{"inspectorId": inspectorID}).Decode(&availability)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.DefaultInspectorAvailability(inspectorID), nil
+		}
+		return nil, err
+	}
+
+	return &availability, nil
+}
+
+// SetAvailability replaces an inspector's calendar
+// Existing reservations are kept; the new calendar applies to future bookings, which must not overlap them
+func (s *AvailabilityService) SetAvailability(ctx context.Context, inspectorID primitive.ObjectID, req *models.SetAvailabilityRequest) (*models.InspectorAvailability, error) {
+	timeZone := req.TimeZone
+	if timeZone == "" {
+		timeZone = models.DefaultTimeZone
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"timeZone":      timeZone,
+			"workingHours":  req.WorkingHours,
+			"blackoutDates": req.BlackoutDates,
+			"slotMinutes":   req.SlotMinutes,
+			"updatedAt":     now,
+		},
+		"$setOnInsert": bson.M{
+			"inspectorId": inspectorID,
+			"createdAt":   now,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var availability models.InspectorAvailability
+	err := s.collection.FindOneAndUpdate(ctx, bson.M{"inspectorId": inspectorID}, update, opts).Decode(&availability)
+	if err != nil {
+		return nil, err
+	}
+
+	return &availability, nil
+}
+
+// FreeSlots lists an inspector's unbooked slots that start within [from, to)
+func (s *AvailabilityService) FreeSlots(ctx context.Context, inspectorID primitive.ObjectID, from, to time.Time) ([]models.InspectionSlot, error) {
+	if !to.After(from) {
+		return nil, apperrors.NewValidationError("to must be after from")
+	}
+	if to.Sub(from) > maxSlotSearchWindow {
+		return nil, apperrors.NewValidationError("slot search window cannot exceed 31 days")
+	}
+
+	availability, err := s.GetAvailability(ctx, inspectorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Slots starting before to can run past it, so reservations up to a slot later can overlap them
+	booked, err := s.bookedReservations(ctx, inspectorID, from, to.Add(slotDuration(availability)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Never offer slots that have already started
+	if now := time.Now(); from.Before(now) {
+		from = now
+	}
+
+	return computeFreeSlots(availability, from, to, booked), nil
+}
+
+// ReserveSlot atomically claims the slot starting at start for the inspection
+// The slot must not overlap another inspection's reservation, including ones booked on a different slot
+// grid before the inspector changed their calendar. Reserving a slot the inspection already holds is a no-op
+func (s *AvailabilityService) ReserveSlot(ctx context.Context, inspectorID primitive.ObjectID, start time.Time, inspectionID primitive.ObjectID) error {
+	availability, err := s.GetAvailability(ctx, inspectorID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkBookable(availability, start); err != nil {
+		return err
+	}
+
+	reservation := models.SlotReservation{
+		ID:           primitive.NewObjectID(),
+		InspectorID:  inspectorID,
+		InspectionID: inspectionID,
+		Start:        start.UTC(),
+		End:          start.UTC().Add(slotDuration(availability)),
+		CreatedAt:    time.Now(),
+	}
+
+	return withMongoTransaction(ctx, s.collection.Database().Client(), func(sc mongo.SessionContext) error {
+		// Every reservation for the inspector writes their lock, so concurrent ones conflict and the
+		// retried transaction sees the booking that committed first
+		if _, err := s.lockCollection.UpdateOne(sc,
+			bson.M{"_id": inspectorID},
+			bson.M{"$inc": bson.M{"version": 1}},
+			options.Update().SetUpsert(true),
+		); err != nil {
+			return err
+		}
+
+		err := s.reservationCollection.FindOne(sc, overlappingReservationFilter(&reservation)).Err()
+		if err == nil {
+			return errSlotTaken
+		}
+		if err != mongo.ErrNoDocuments {
+			return err
+		}
+
+		_, err = s.reservationCollection.UpdateOne(sc,
+			bson.M{"inspectorId": inspectorID, "start": reservation.Start, "inspectionId": inspectionID},
+			bson.M{"$setOnInsert": reservation},
+			options.Update().SetUpsert(true),
+		)
+		return err
+	})
+}
+
+// overlappingReservationFilter matches the inspector's reservations for other inspections that overlap
+// the reservation; an inspection being rescheduled may overlap its own old slot until it is released
+func overlappingReservationFilter(reservation *models.SlotReservation) bson.M {
+	return bson.M{
+		"inspectorId":  reservation.InspectorID,
+		"inspectionId": bson.M{"$ne": reservation.InspectionID},
+		"start":        bson.M{"$lt": reservation.End},
+		"end":          bson.M{"$gt": reservation.Start},
+	}
+}
+
+// ReleaseSlot frees the inspector's slot starting at start if the inspection holds it
+func (s *AvailabilityService) ReleaseSlot(ctx context.Context, inspectorID primitive.ObjectID, start time.Time, inspectionID primitive.ObjectID) error {
+	filter := bson.M{"inspectorId": inspectorID, "start": start.UTC(), "inspectionId": inspectionID}
+	_, err := s.reservationCollection.DeleteOne(ctx, filter)
+	return err
+}
+
+// ReleaseInspection frees every slot held by an inspection
+func (s *AvailabilityService) ReleaseInspection(ctx context.Context, inspectionID primitive.ObjectID) error {
+	_, err := s.reservationCollection.DeleteMany(ctx, bson.M{"inspectionId": inspectionID})
+	return err
+}
+
+// bookedReservations returns the inspector's reservations overlapping the window
+func (s *AvailabilityService) bookedReservations(ctx context.Context, inspectorID primitive.ObjectID, from, to time.Time) ([]models.SlotReservation, error) {
+	filter := bson.M{
+		"inspectorId": inspectorID,
+		"start":       bson.M{"$lt": to.UTC()},
+		"end":         bson.M{"$gt": from.UTC()},
+	}
+
+	cursor, err := s.reservationCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var reservations []models.SlotReservation
+	if err = cursor.All(ctx, &reservations); err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// slotDuration returns the configured slot length
+func slotDuration(availability *models.InspectorAvailability) time.Duration {
+	minutes := availability.SlotMinutes
+	if minutes <= 0 {
+		minutes = models.DefaultSlotMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// availabilityLocation resolves the calendar's time zone, defaulting to UTC
+func availabilityLocation(availability *models.InspectorAvailability) *time.Location {
+	if availability.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(availability.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// computeFreeSlots walks the working hours day by day and returns slots that
+// start within [from, to), fall outside blackouts and do not overlap a booking
+func computeFreeSlots(availability *models.InspectorAvailability, from, to time.Time, booked []models.SlotReservation) []models.InspectionSlot {
+	loc := availabilityLocation(availability)
+	slot := slotDuration(availability)
+	slots := []models.InspectionSlot{}
+
+	localFrom := from.In(loc)
+	day := time.Date(localFrom.Year(), localFrom.Month(), localFrom.Day(), 0, 0, 0, 0, loc)
+
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, wh := range availability.WorkingHours {
+			if wh.Weekday != day.Weekday() {
+				continue
+			}
+
+			startOffset, err := models.ParseClock(wh.Start)
+			if err != nil {
+				continue
+			}
+			endOffset, err := models.ParseClock(wh.End)
+			if err != nil {
+				continue
+			}
+
+			windowEnd := day.Add(endOffset)
+			for start := day.Add(startOffset); !start.Add(slot).After(windowEnd); start = start.Add(slot) {
+				if start.Before(from) || !start.Before(to) {
+					continue
+				}
+				if overlapsBooking(booked, start, start.Add(slot)) || inBlackout(availability.BlackoutDates, start, start.Add(slot)) {
+					continue
+				}
+				slots = append(slots, models.InspectionSlot{
+					InspectorID: availability.InspectorID,
+					Start:       start.UTC(),
+					End:         start.Add(slot).UTC(),
+				})
+			}
+		}
+	}
+
+	return slots
+}
+
+// checkBookable verifies that start is a slot boundary inside the inspector's working hours
+func checkBookable(availability *models.InspectorAvailability, start time.Time) error {
+	slots := computeFreeSlots(availability, start, start.Add(time.Second), nil)
+	for _, slot := range slots {
+		if slot.Start.Equal(start) {
+			return nil
+		}
+	}
+
+	return errSlotUnavailable
+}
+
+// isSlotUnavailable reports whether err means the inspector cannot take the booking
+func isSlotUnavailable(err error) bool {
+	return err == errSlotTaken || err == errSlotUnavailable
+}
+
+// inBlackout reports whether [start, end) overlaps any blackout period
+func inBlackout(blackouts []models.BlackoutPeriod, start, end time.Time) bool {
+	for _, b := range blackouts {
+		if start.Before(b.End) && end.After(b.Start) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlapsBooking reports whether [start, end) overlaps any of the reservations
+func overlapsBooking(booked []models.SlotReservation, start, end time.Time) bool {
+	for _, r := range booked {
+		if start.Before(r.End) && end.After(r.Start) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+func TestComputeFreeSlots(t *testing.T) {
+	availability := &models.InspectorAvailability{
+		InspectorID:  primitive.NewObjectID(),
+		TimeZone:     "UTC",
+		WorkingHours: []models.WorkingHours{{Weekday: time.Monday, Start: "09:00", End: "12:00"}},
+		SlotMinutes:  60,
+	}
+
+	// 2030-01-07 is a Monday
+	monday := time.Date(2030, 1, 7, 0, 0, 0, 0, time.UTC)
+	from, to := monday, monday.Add(48*time.Hour)
+
+	t.Run("working hours are split into slots", func(t *testing.T) {
+		slots := computeFreeSlots(availability, from, to, nil)
+		assert.Len(t, slots, 3)
+		assert.Equal(t, monday.Add(9*time.Hour), slots[0].Start)
+		assert.Equal(t, monday.Add(10*time.Hour), slots[0].End)
+		assert.Equal(t, availability.InspectorID, slots[0].InspectorID)
+	})
+
+	t.Run("booked slots are skipped", func(t *testing.T) {
+		booked := []models.SlotReservation{{Start: monday.Add(10 * time.Hour), End: monday.Add(11 * time.Hour)}}
+		slots := computeFreeSlots(availability, from, to, booked)
+		assert.Len(t, slots, 2)
+		assert.Equal(t, monday.Add(11*time.Hour), slots[1].Start)
+	})
+
+	t.Run("bookings on the old grid block overlapping slots after the slot length changes", func(t *testing.T) {
+		// Booked 10:00-11:00 while slots were an hour long; the inspector then switched to 45 minute slots
+		booked := []models.SlotReservation{{Start: monday.Add(10 * time.Hour), End: monday.Add(11 * time.Hour)}}
+		shorter := *availability
+		shorter.SlotMinutes = 45
+
+		slots := computeFreeSlots(&shorter, from, to, booked)
+		starts := []time.Time{}
+		for _, slot := range slots {
+			starts = append(starts, slot.Start)
+		}
+		// 09:45-10:30 and 10:30-11:15 overlap the booking; 09:00 and 11:15 do not
+		assert.Equal(t, []time.Time{monday.Add(9 * time.Hour), monday.Add(11*time.Hour + 15*time.Minute)}, starts)
+	})
+
+	t.Run("blackouts are skipped", func(t *testing.T) {
+		blackedOut := *availability
+		blackedOut.BlackoutDates = []models.BlackoutPeriod{{Start: monday.Add(9 * time.Hour), End: monday.Add(10*time.Hour + 30*time.Minute)}}
+		slots := computeFreeSlots(&blackedOut, from, to, nil)
+		assert.Len(t, slots, 1)
+		assert.Equal(t, monday.Add(11*time.Hour), slots[0].Start)
+	})
+
+	t.Run("working hours follow the inspector's time zone", func(t *testing.T) {
+		lagos := *availability
+		lagos.TimeZone = "Africa/Lagos" // UTC+1
+		slots := computeFreeSlots(&lagos, from, to, nil)
+		assert.Len(t, slots, 3)
+		assert.Equal(t, monday.Add(8*time.Hour), slots[0].Start)
+	})
+}
+
+func TestCheckBookable(t *testing.T) {
+	availability := models.DefaultInspectorAvailability(primitive.NewObjectID())
+	monday := time.Date(2030, 1, 7, 0, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, checkBookable(availability, monday.Add(9*time.Hour)))
+	assert.Equal(t, errSlotUnavailable, checkBookable(availability, monday.Add(9*time.Hour+30*time.Minute)))
+	assert.Equal(t, errSlotUnavailable, checkBookable(availability, monday.Add(18*time.Hour)))
+	assert.Equal(t, errSlotUnavailable, checkBookable(availability, monday.Add(-24*time.Hour+10*time.Hour))) // Sunday
+}
+
+func TestOverlappingReservationFilter(t *testing.T) {
+	monday := time.Date(2030, 1, 7, 0, 0, 0, 0, time.UTC)
+	// A 45 minute slot requested after the inspector moved off hourly slots
+	reservation := &models.SlotReservation{
+		InspectorID:  primitive.NewObjectID(),
+		InspectionID: primitive.NewObjectID(),
+		Start:        monday.Add(9*time.Hour + 45*time.Minute),
+		End:          monday.Add(10*time.Hour + 30*time.Minute),
+	}
+
+	assert.Equal(t, bson.M{
+		"inspectorId":  reservation.InspectorID,
+		"inspectionId": bson.M{"$ne": reservation.InspectionID},
+		"start":        bson.M{"$lt": reservation.End},
+		"end":          bson.M{"$gt": reservation.Start},
+	}, overlappingReservationFilter(reservation), "an hourly booking at 10:00 starts before 10:30 and ends after 09:45, so it clashes")
+
+	tests := []struct {
+		name     string
+		start    time.Duration
+		end      time.Duration
+		overlaps bool
+	}{
+		{"booked on the old hourly grid", 10 * time.Hour, 11 * time.Hour, true},
+		{"ends as the slot starts", 9 * time.Hour, 9*time.Hour + 45*time.Minute, false},
+		{"starts as the slot ends", 10*time.Hour + 30*time.Minute, 11*time.Hour + 30*time.Minute, false},
+		{"contains the slot", 9 * time.Hour, 11 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booked := []models.SlotReservation{{Start: monday.Add(tt.start), End: monday.Add(tt.end)}}
+			assert.Equal(t, tt.overlaps, overlapsBooking(booked, reservation.Start, reservation.End))
+		})
+	}
+}
@@ -0,0 +1,533 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+// disputePageSize caps how many disputes are listed at once
+const disputePageSize = 100
+
+// disputableStatuses are the transaction statuses a buyer can open a dispute in
+var disputableStatuses = []string{
+	models.TransactionStatusPending,
+	models.TransactionStatusFunded,
+	models.TransactionStatusDelivered,
+	models.TransactionStatusCompleted,
+}
+
+// unresolvedDisputeStatuses are the statuses in which a dispute still freezes its transaction
+var unresolvedDisputeStatuses = []string{models.DisputeStatusOpen, models.DisputeStatusUnderReview}
+
+var (
+	// errDisputeModified is returned when a conditional dispute update loses a race
+	errDisputeModified = apperrors.NewConflictError("dispute was modified concurrently, please retry")
+
+	// errNotDisputeParty is returned when someone other than the parties or an admin accesses a dispute
+	errNotDisputeParty = apperrors.NewAppError(apperrors.ErrCodeForbidden, "only the buyer, the seller and admins can access this dispute", http.StatusForbidden)
+)
+
+// disputeTransactions is what disputes need from the transaction service: reading the disputed
+// transaction and carrying a resolution out on it
+type disputeTransactions interface {
+	GetTransactionByID(ctx context.Context, id string) (*models.Transaction, error)
+	resolveActor(ctx context.Context, txn *models.Transaction, userID primitive.ObjectID) (TransactionActor, bool, error)
+	refund(ctx context.Context, transaction *models.Transaction, req *models.RefundTransactionRequest, actor TransactionActor) (*models.Transaction, error)
+	finishRefund(ctx context.Context, transaction *models.Transaction, refund *models.TransactionRefund, actor TransactionActor) (*models.Transaction, error)
+	cancel(ctx context.Context, transaction *models.Transaction, actor TransactionActor, notes string, forfeitDeposit bool) (*models.Transaction, error)
+	releaseEscrow(ctx context.Context, transaction *models.Transaction, actor TransactionActor, reason string) (*models.Transaction, error)
+	liftDisputeFreeze(ctx context.Context, transactionID, disputeID primitive.ObjectID) (*models.Transaction, error)
+}
+
+// DisputeService handles disputes raised by buyers against their transactions
+type DisputeService struct {
+	collection            *mongo.Collection
+	transactionCollection *mongo.Collection
+	userCollection        *mongo.Collection
+	transactions          disputeTransactions
+	notifier              Notifier
+}
+
+// NewDisputeService creates a new dispute service
+func NewDisputeService(db *mongo.Database, transactions *TransactionService, notifier Notifier) *DisputeService {
+	return &DisputeService{
+		collection:            db.Collection("disputes"),
+		transactionCollection: db.Collection("transactions"),
+		userCollection:        db.Collection("users"),
+		transactions:          transactions,
+		notifier:              notifier,
+	}
+}
+
+// EnsureIndexes creates the indexes used to list a transaction's disputes and the admin queue
+func (s *DisputeService) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "transactionId", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("idx_disputes_transaction_created"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: 1}},
+			Options: options.Index().SetName("idx_disputes_status_created"),
+		},
+	})
+	return err
+}
+
+// OpenDispute raises the buyer's dispute and freezes the transaction until an admin resolves it
+// Only one dispute can be open on a transaction at a time
+func (s *DisputeService) OpenDispute(ctx context.Context, transactionID string, req *models.OpenDisputeRequest, userID primitive.ObjectID) (*models.Dispute, error) {
+	if err := req.Validate(); err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+
+	transaction, err := s.transactions.GetTransactionByID(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.BuyerID != userID {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeForbidden, "only the buyer can open a dispute", http.StatusForbidden)
+	}
+	if !containsString(disputableStatuses, transaction.Status) {
+		return nil, apperrors.NewInvalidStateTransitionError(fmt.Sprintf("only pending, funded, delivered and completed transactions can be disputed; this one is %s", transaction.Status))
+	}
+	if transaction.OpenDisputeID != nil {
+		return nil, apperrors.NewConflictError("this transaction already has an open dispute")
+	}
+
+	now := time.Now()
+	dispute := models.Dispute{
+		ID:            primitive.NewObjectID(),
+		TransactionID: transaction.ID,
+		VehicleID:     transaction.VehicleID,
+		BuyerID:       transaction.BuyerID,
+		SellerID:      transaction.SellerID,
+		Status:        models.DisputeStatusOpen,
+		Reason:        req.Reason,
+		Description:   req.Description,
+		Evidence:      []models.DisputeEvidence{},
+		Messages:      []models.DisputeMessage{},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	// Freeze the transaction first, so a sale completed or cancelled since it was read is never disputed
+	result, err := s.transactionCollection.UpdateOne(ctx,
+		bson.M{"_id": transaction.ID, "status": transaction.Status, "openDisputeId": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"openDisputeId": dispute.ID, "updatedAt": now}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, errTransactionModified
+	}
+
+	if _, err := s.collection.InsertOne(ctx, dispute); err != nil {
+		// Nothing recorded the dispute, so lift the freeze
+		_, _ = s.transactions.liftDisputeFreeze(ctx, transaction.ID, dispute.ID)
+		return nil, err
+	}
+
+	_ = s.notifier.Notify(ctx, models.Notification{
+		UserID:  dispute.SellerID,
+		Type:    models.NotificationTypeDisputeOpened,
+		Title:   "A buyer disputed your sale",
+		Message: "The sale is on hold until an admin reviews the dispute.",
+		Data: map[string]string{
+			"disputeId":     dispute.ID.Hex(),
+			"transactionId": dispute.TransactionID.Hex(),
+		},
+	})
+
+	return &dispute, nil
+}
+
+// GetDispute retrieves a dispute for one of its parties or an admin
+func (s *DisputeService) GetDispute(ctx context.Context, id string, userID primitive.ObjectID) (*models.Dispute, error) {
+	dispute, err := s.getDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.roleOf(ctx, dispute, userID); err != nil {
+		return nil, err
+	}
+	return dispute, nil
+}
+
+// GetTransactionDisputes lists a transaction's disputes, newest first, for its parties or an admin
+func (s *DisputeService) GetTransactionDisputes(ctx context.Context, transactionID string, userID primitive.ObjectID) ([]models.Dispute, error) {
+	transaction, err := s.transactions.GetTransactionByID(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok, err := s.transactions.resolveActor(ctx, transaction, userID); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errNotDisputeParty
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	return s.findDisputes(ctx, bson.M{"transactionId": transaction.ID}, opts)
+}
+
+// ListDisputes lists disputes for admins, oldest first, optionally only those in one status
+func (s *DisputeService) ListDisputes(ctx context.Context, status string) ([]models.Dispute, error) {
+	filter := bson.M{}
+	if status != "" {
+		if !models.IsValidDisputeStatus(status) {
+			return nil, apperrors.NewValidationError("invalid status value")
+		}
+		filter["status"] = status
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}).SetLimit(disputePageSize)
+	return s.findDisputes(ctx, filter, opts)
+}
+
+// AddMessage posts a message to the dispute's thread while it is unresolved
+func (s *DisputeService) AddMessage(ctx context.Context, id string, req *models.DisputeMessageRequest, userID primitive.ObjectID) (*models.Dispute, error) {
+	if err := req.Validate(); err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+
+	dispute, err := s.getDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	role, err := s.roleOf(ctx, dispute, userID)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.IsResolved() {
+		return nil, apperrors.NewInvalidStateTransitionError("messages can only be posted while the dispute is unresolved")
+	}
+
+	now := time.Now()
+	message := models.DisputeMessage{
+		AuthorID:   userID,
+		AuthorRole: role,
+		Body:       req.Body,
+		CreatedAt:  now,
+	}
+	filter := bson.M{"_id": dispute.ID, "status": bson.M{"$in": unresolvedDisputeStatuses}}
+	update := bson.M{
+		"$push": bson.M{"messages": message},
+		"$set":  bson.M{"updatedAt": now},
+	}
+	return s.updateDispute(ctx, filter, update)
+}
+
+// CheckEvidenceUpload verifies that the user may attach count more files to the dispute
+// Called before uploading so that rejected requests never reach the media store
+func (s *DisputeService) CheckEvidenceUpload(ctx context.Context, id string, count int, userID primitive.ObjectID) error {
+	dispute, err := s.getDispute(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.checkEvidence(ctx, dispute, count, userID)
+}
+
+// AddEvidence attaches uploaded evidence to the dispute
+// The write only applies while the dispute is unresolved and still has room for the evidence
+func (s *DisputeService) AddEvidence(ctx context.Context, id string, evidence []models.DisputeEvidence, userID primitive.ObjectID) (*models.Dispute, error) {
+	dispute, err := s.getDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEvidence(ctx, dispute, len(evidence), userID); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{
+		"_id":    dispute.ID,
+		"status": bson.M{"$in": unresolvedDisputeStatuses},
+		fmt.Sprintf("evidence.%d", models.MaxDisputeEvidence-len(evidence)): bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$push": bson.M{"evidence": bson.M{"$each": evidence}},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+	return s.updateDispute(ctx, filter, update)
+}
+
+// checkEvidence enforces who may attach evidence to a dispute, and how much
+func (s *DisputeService) checkEvidence(ctx context.Context, dispute *models.Dispute, count int, userID primitive.ObjectID) error {
+	if _, err := s.roleOf(ctx, dispute, userID); err != nil {
+		return err
+	}
+	if dispute.IsResolved() {
+		return apperrors.NewInvalidStateTransitionError("evidence can only be attached while the dispute is unresolved")
+	}
+	if existing := len(dispute.Evidence); existing+count > models.MaxDisputeEvidence {
+		return apperrors.NewValidationError(fmt.Sprintf("maximum %d evidence files allowed per dispute (current: %d)", models.MaxDisputeEvidence, existing))
+	}
+	return nil
+}
+
+// StartReview moves an open dispute under review by the admin
+func (s *DisputeService) StartReview(ctx context.Context, id string, adminID primitive.ObjectID) (*models.Dispute, error) {
+	dispute, err := s.getDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Status != models.DisputeStatusOpen {
+		return nil, apperrors.NewInvalidStateTransitionError(fmt.Sprintf("only open disputes can be reviewed; this one is %s", dispute.Status))
+	}
+
+	now := time.Now()
+	filter := bson.M{"_id": dispute.ID, "status": models.DisputeStatusOpen}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     models.DisputeStatusUnderReview,
+			"reviewerId": adminID,
+			"reviewedAt": now,
+			"updatedAt":  now,
+		},
+	}
+	return s.updateDispute(ctx, filter, update)
+}
+
+// ResolveDispute decides the dispute for the buyer or the seller and lifts the transaction's freeze
+// Resolving for the buyer refunds a completed sale and cancels an unfinished one, returning any payment
+// or held funds. Resolving for the seller releases held escrow funds on a delivered sale and otherwise
+// lets the sale carry on. Returns the resolved dispute and the transaction as it was left
+func (s *DisputeService) ResolveDispute(ctx context.Context, id string, req *models.ResolveDisputeRequest, adminID primitive.ObjectID) (*models.Dispute, *models.Transaction, error) {
+	if err := req.Validate(); err != nil {
+		return nil, nil, apperrors.NewValidationError(err.Error())
+	}
+
+	dispute, err := s.getDispute(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dispute.IsResolved() {
+		return nil, nil, apperrors.NewInvalidStateTransitionError(fmt.Sprintf("the dispute is already %s", dispute.Status))
+	}
+
+	transaction, err := s.transactions.GetTransactionByID(ctx, dispute.TransactionID.Hex())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transaction, refundID, err := s.settleOutcome(ctx, dispute, transaction, req, adminID)
+	if err != nil {
+		return nil, nil, err
+	}
+	resolution := &models.DisputeResolution{
+		Outcome:    req.Outcome,
+		Notes:      req.Notes,
+		ResolvedBy: adminID,
+		RefundID:   refundID,
+	}
+
+	now := time.Now()
+	resolution.ResolvedAt = now
+	filter := bson.M{"_id": dispute.ID, "status": dispute.Status}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     req.Outcome,
+			"resolution": resolution,
+			"updatedAt":  now,
+		},
+	}
+	resolved, err := s.updateDispute(ctx, filter, update)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	notice := models.Notification{
+		Type:    models.NotificationTypeDisputeResolved,
+		Title:   "Your dispute was resolved",
+		Message: "An admin resolved the dispute for the " + resolvedParty(req.Outcome) + ".",
+		Data: map[string]string{
+			"disputeId":     resolved.ID.Hex(),
+			"transactionId": resolved.TransactionID.Hex(),
+		},
+	}
+	buyerNotice, sellerNotice := notice, notice
+	buyerNotice.UserID = resolved.BuyerID
+	sellerNotice.UserID = resolved.SellerID
+	_ = s.notifier.Notify(ctx, buyerNotice, sellerNotice)
+
+	return resolved, transaction, nil
+}
+
+// settleOutcome carries the decision out on the transaction unless an earlier attempt already did
+// Only resolving a dispute lifts its freeze, so a transaction this dispute no longer freezes had the
+// outcome applied by an earlier attempt that failed to record it; a refund that attempt claimed is
+// finished if it is still pending. Returns the transaction and the refund the dispute issued, if any
+func (s *DisputeService) settleOutcome(ctx context.Context, dispute *models.Dispute, transaction *models.Transaction, req *models.ResolveDisputeRequest, adminID primitive.ObjectID) (*models.Transaction, *primitive.ObjectID, error) {
+	var err error
+	if transaction.OpenDisputeID != nil && *transaction.OpenDisputeID == dispute.ID {
+		if transaction, err = s.applyOutcome(ctx, dispute, transaction, req, adminID); err != nil {
+			return nil, nil, err
+		}
+	} else if refund := disputeRefund(transaction, dispute.ID); refund != nil && refund.Status == models.RefundStatusPending {
+		actor := TransactionActor{ID: adminID, Role: TransactionRoleAdmin}
+		if transaction, err = s.transactions.finishRefund(ctx, transaction, refund, actor); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if refund := disputeRefund(transaction, dispute.ID); refund != nil {
+		return transaction, &refund.ID, nil
+	}
+	return transaction, nil, nil
+}
+
+// applyOutcome carries the decision out on the frozen transaction
+// Every path ends the freeze in the same write that changes the transaction
+func (s *DisputeService) applyOutcome(ctx context.Context, dispute *models.Dispute, transaction *models.Transaction, req *models.ResolveDisputeRequest, adminID primitive.ObjectID) (*models.Transaction, error) {
+	actor := TransactionActor{ID: adminID, Role: TransactionRoleAdmin, DisputeID: dispute.ID}
+	reason := fmt.Sprintf("dispute resolved for the %s: %s", resolvedParty(req.Outcome), req.Notes)
+
+	if req.Outcome == models.DisputeStatusResolvedBuyer {
+		if transaction.Status == models.TransactionStatusCompleted {
+			return s.transactions.refund(ctx, transaction, &models.RefundTransactionRequest{
+				Amount:          req.RefundAmount,
+				Reason:          reason,
+				Reference:       req.Reference,
+				RevertOwnership: req.RevertOwnership,
+			}, actor)
+		}
+		if !req.RefundAmount.IsZero() || req.Reference != "" || req.RevertOwnership {
+			return nil, apperrors.NewValidationError("refundAmount, reference and revertOwnership only apply to completed transactions; unfinished sales are cancelled and refunded in full")
+		}
+		return s.transactions.cancel(ctx, transaction, actor, reason, false)
+	}
+
+	if transaction.Status == models.TransactionStatusDelivered {
+		return s.transactions.releaseEscrow(ctx, transaction, actor, reason)
+	}
+	return s.transactions.liftDisputeFreeze(ctx, transaction.ID, dispute.ID)
+}
+
+// disputeRefund returns the refund issued by resolving the dispute, or nil if it issued none
+func disputeRefund(transaction *models.Transaction, disputeID primitive.ObjectID) *models.TransactionRefund {
+	for i := range transaction.Refunds {
+		if refund := &transaction.Refunds[i]; refund.DisputeID != nil && *refund.DisputeID == disputeID {
+			return refund
+		}
+	}
+	return nil
+}
+
+// liftDisputeFreeze lifts the dispute's freeze from the transaction, leaving it as it was
+func (s *TransactionService) liftDisputeFreeze(ctx context.Context, transactionID, disputeID primitive.ObjectID) (*models.Transaction, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var transaction models.Transaction
+	err := s.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": transactionID, "openDisputeId": disputeID},
+		bson.M{
+			"$unset": bson.M{"openDisputeId": ""},
+			"$set":   bson.M{"updatedAt": time.Now()},
+		},
+		opts,
+	).Decode(&transaction)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errTransactionModified
+		}
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// roleOf returns the user's role on the dispute
+// Returns errNotDisputeParty when the user is neither a party nor an admin
+func (s *DisputeService) roleOf(ctx context.Context, dispute *models.Dispute, userID primitive.ObjectID) (string, error) {
+	switch userID {
+	case dispute.BuyerID:
+		return models.DisputeRoleBuyer, nil
+	case dispute.SellerID:
+		return models.DisputeRoleSeller, nil
+	}
+
+	var user models.User
+	err := s.userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", errNotDisputeParty
+		}
+		return "", err
+	}
+	if user.Role != models.RoleAdmin {
+		return "", errNotDisputeParty
+	}
+	return models.DisputeRoleAdmin, nil
+}
+
+// getDispute loads a dispute by ID
+func (s *DisputeService) getDispute(ctx context.Context, id string) (*models.Dispute, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid dispute ID")
+	}
+
+	var dispute models.Dispute
+	if err := s.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&dispute); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError("dispute not found")
+		}
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+// updateDispute applies a conditional update and returns the updated dispute
+func (s *DisputeService) updateDispute(ctx context.Context, filter, update bson.M) (*models.Dispute, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.Dispute
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errDisputeModified
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// findDisputes decodes every dispute matching the filter
+func (s *DisputeService) findDisputes(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.Dispute, error) {
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	disputes := []models.Dispute{}
+	if err := cursor.All(ctx, &disputes); err != nil {
+		return nil, err
+	}
+	return disputes, nil
+}
+
+// disputeFreezeFilter matches the dispute freeze a transaction was read with
+// Conditional transaction writes use it so a dispute opened since the read is never bypassed
+func disputeFreezeFilter(transaction *models.Transaction) interface{} {
+	if transaction.OpenDisputeID == nil {
+		return bson.M{"$exists": false}
+	}
+	return *transaction.OpenDisputeID
+}
+
+// resolvedParty names the party a dispute outcome favours
+func resolvedParty(outcome string) string {
+	if outcome == models.DisputeStatusResolvedBuyer {
+		return "buyer"
+	}
+	return "seller"
+}
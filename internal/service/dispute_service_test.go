@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// fakeDisputeTransactions records how a resolution was carried out and returns the transaction
+// as the matching TransactionService method would leave it
+type fakeDisputeTransactions struct {
+	calls   []string
+	refunds []*models.RefundTransactionRequest
+	actors  []TransactionActor
+	forfeit bool
+}
+
+func (f *fakeDisputeTransactions) record(call string, actor TransactionActor) {
+	f.calls = append(f.calls, call)
+	f.actors = append(f.actors, actor)
+}
+
+func (f *fakeDisputeTransactions) GetTransactionByID(ctx context.Context, id string) (*models.Transaction, error) {
+	panic("not used when resolving")
+}
+
+func (f *fakeDisputeTransactions) resolveActor(ctx context.Context, txn *models.Transaction, userID primitive.ObjectID) (TransactionActor, bool, error) {
+	panic("not used when resolving")
+}
+
+func (f *fakeDisputeTransactions) refund(ctx context.Context, transaction *models.Transaction, req *models.RefundTransactionRequest, actor TransactionActor) (*models.Transaction, error) {
+	f.record("refund", actor)
+	f.refunds = append(f.refunds, req)
+	updated := *transaction
+	updated.OpenDisputeID = nil
+	disputeID := actor.DisputeID
+	updated.Refunds = append(updated.Refunds, models.TransactionRefund{
+		ID:        primitive.NewObjectID(),
+		Amount:    transaction.Amount,
+		Status:    models.RefundStatusCompleted,
+		DisputeID: &disputeID,
+	})
+	return &updated, nil
+}
+
+func (f *fakeDisputeTransactions) finishRefund(ctx context.Context, transaction *models.Transaction, refund *models.TransactionRefund, actor TransactionActor) (*models.Transaction, error) {
+	f.record("finishRefund", actor)
+	updated := *transaction
+	updated.Refunds = append([]models.TransactionRefund(nil), transaction.Refunds...)
+	for i := range updated.Refunds {
+		if updated.Refunds[i].ID == refund.ID {
+			updated.Refunds[i].Status = models.RefundStatusCompleted
+		}
+	}
+	return &updated, nil
+}
+
+func (f *fakeDisputeTransactions) cancel(ctx context.Context, transaction *models.Transaction, actor TransactionActor, notes string, forfeitDeposit bool) (*models.Transaction, error) {
+	f.record("cancel", actor)
+	f.forfeit = forfeitDeposit
+	updated := *transaction
+	updated.OpenDisputeID = nil
+	updated.Status = models.TransactionStatusCancelled
+	return &updated, nil
+}
+
+func (f *fakeDisputeTransactions) releaseEscrow(ctx context.Context, transaction *models.Transaction, actor TransactionActor, reason string) (*models.Transaction, error) {
+	f.record("releaseEscrow", actor)
+	updated := *transaction
+	updated.OpenDisputeID = nil
+	updated.Status = models.TransactionStatusCompleted
+	return &updated, nil
+}
+
+func (f *fakeDisputeTransactions) liftDisputeFreeze(ctx context.Context, transactionID, disputeID primitive.ObjectID) (*models.Transaction, error) {
+	f.record("liftDisputeFreeze", TransactionActor{})
+	return &models.Transaction{ID: transactionID}, nil
+}
+
+// disputedSale returns a dispute and the transaction it freezes, in the given status
+func disputedSale(status string) (*models.Dispute, *models.Transaction) {
+	transaction := completedSale(models.PaymentMethodCash)
+	transaction.Status = status
+	dispute := &models.Dispute{ID: primitive.NewObjectID(), TransactionID: transaction.ID}
+	transaction.OpenDisputeID = &dispute.ID
+	return dispute, transaction
+}
+
+func TestApplyOutcome(t *testing.T) {
+	adminID := primitive.NewObjectID()
+
+	tests := []struct {
+		name    string
+		status  string
+		req     models.ResolveDisputeRequest
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "buyer wins a completed sale",
+			status: models.TransactionStatusCompleted,
+			req:    models.ResolveDisputeRequest{Outcome: models.DisputeStatusResolvedBuyer, Notes: "not as described", Reference: "wire-1", RevertOwnership: true},
+			want:   "refund",
+		},
+		{
+			name:   "buyer wins an unfinished sale",
+			status: models.TransactionStatusDelivered,
+			req:    models.ResolveDisputeRequest{Outcome: models.DisputeStatusResolvedBuyer, Notes: "never arrived"},
+			want:   "cancel",
+		},
+		{
+			name:    "refund fields on an unfinished sale",
+			status:  models.TransactionStatusDelivered,
+			req:     models.ResolveDisputeRequest{Outcome: models.DisputeStatusResolvedBuyer, Notes: "never arrived", RefundAmount: money.MustParse("100", "USD")},
+			wantErr: true,
+		},
+		{
+			name:   "seller wins a delivered sale",
+			status: models.TransactionStatusDelivered,
+			req:    models.ResolveDisputeRequest{Outcome: models.DisputeStatusResolvedSeller, Notes: "arrived as described"},
+			want:   "releaseEscrow",
+		},
+		{
+			name:   "seller wins a completed sale",
+			status: models.TransactionStatusCompleted,
+			req:    models.ResolveDisputeRequest{Outcome: models.DisputeStatusResolvedSeller, Notes: "arrived as described"},
+			want:   "liftDisputeFreeze",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions := &fakeDisputeTransactions{}
+			s := &DisputeService{transactions: transactions}
+			dispute, transaction := disputedSale(tt.status)
+
+			_, err := s.applyOutcome(context.Background(), dispute, transaction, &tt.req, adminID)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Empty(t, transactions.calls, "a rejected resolution changes nothing")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{tt.want}, transactions.calls)
+
+			if actor := transactions.actors[0]; tt.want != "liftDisputeFreeze" {
+				assert.Equal(t, adminID, actor.ID)
+				assert.Equal(t, TransactionRoleAdmin, actor.Role)
+				assert.Equal(t, dispute.ID, actor.DisputeID, "the admin acts for the dispute so the freeze lets them through")
+			}
+			switch tt.want {
+			case "refund":
+				req := transactions.refunds[0]
+				assert.Equal(t, "wire-1", req.Reference)
+				assert.True(t, req.RevertOwnership)
+				assert.Equal(t, "dispute resolved for the buyer: not as described", req.Reason)
+			case "cancel":
+				assert.False(t, transactions.forfeit, "a buyer who wins the dispute keeps their deposit")
+			}
+		})
+	}
+}
+
+func TestSettleOutcome_RecordsTheDisputeRefund(t *testing.T) {
+	adminID := primitive.NewObjectID()
+	req := &models.ResolveDisputeRequest{Outcome: models.DisputeStatusResolvedBuyer, Notes: "not as described", Reference: "wire-1"}
+
+	transactions := &fakeDisputeTransactions{}
+	s := &DisputeService{transactions: transactions}
+	dispute, transaction := disputedSale(models.TransactionStatusCompleted)
+
+	updated, refundID, err := s.settleOutcome(context.Background(), dispute, transaction, req, adminID)
+	require.NoError(t, err)
+	require.NotNil(t, refundID)
+	assert.Equal(t, updated.Refunds[0].ID, *refundID)
+}
+
+func TestSettleOutcome_RetryFinishesThePendingRefund(t *testing.T) {
+	adminID := primitive.NewObjectID()
+	req := &models.ResolveDisputeRequest{Outcome: models.DisputeStatusResolvedBuyer, Notes: "not as described", Reference: "wire-1"}
+
+	// An earlier attempt claimed the refund, which lifted the freeze, and failed before finishing it
+	dispute, _ := disputedSale(models.TransactionStatusCompleted)
+	earlier := models.TransactionRefund{ID: primitive.NewObjectID(), Amount: money.MustParse("500", "USD"), Status: models.RefundStatusCompleted}
+	pending := models.TransactionRefund{ID: primitive.NewObjectID(), Amount: money.MustParse("25000", "USD"), Status: models.RefundStatusPending, DisputeID: &dispute.ID}
+	transaction := completedSale(models.PaymentMethodCash, earlier, pending)
+
+	transactions := &fakeDisputeTransactions{}
+	s := &DisputeService{transactions: transactions}
+
+	updated, refundID, err := s.settleOutcome(context.Background(), dispute, transaction, req, adminID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"finishRefund"}, transactions.calls, "the outcome is not applied twice")
+	require.NotNil(t, refundID)
+	assert.Equal(t, pending.ID, *refundID, "the dispute's refund is recorded, not the last one on the transaction")
+	assert.Equal(t, models.RefundStatusCompleted, updated.Refunds[1].Status)
+}
+
+func TestSettleOutcome_RetryAfterTheRefundFinished(t *testing.T) {
+	adminID := primitive.NewObjectID()
+	req := &models.ResolveDisputeRequest{Outcome: models.DisputeStatusResolvedBuyer, Notes: "not as described", Reference: "wire-1"}
+
+	dispute, _ := disputedSale(models.TransactionStatusCompleted)
+	refund := models.TransactionRefund{ID: primitive.NewObjectID(), Amount: money.MustParse("25000", "USD"), Status: models.RefundStatusCompleted, DisputeID: &dispute.ID}
+	transaction := completedSale(models.PaymentMethodCash, refund)
+	transaction.Status = models.TransactionStatusRefunded
+
+	transactions := &fakeDisputeTransactions{}
+	s := &DisputeService{transactions: transactions}
+
+	_, refundID, err := s.settleOutcome(context.Background(), dispute, transaction, req, adminID)
+	require.NoError(t, err)
+	assert.Empty(t, transactions.calls)
+	require.NotNil(t, refundID)
+	assert.Equal(t, refund.ID, *refundID)
+}
+
+func TestSettleOutcome_RetryWithoutARefund(t *testing.T) {
+	req := &models.ResolveDisputeRequest{Outcome: models.DisputeStatusResolvedSeller, Notes: "arrived as described"}
+
+	dispute, transaction := disputedSale(models.TransactionStatusCompleted)
+	transaction.OpenDisputeID = nil
+
+	transactions := &fakeDisputeTransactions{}
+	s := &DisputeService{transactions: transactions}
+
+	_, refundID, err := s.settleOutcome(context.Background(), dispute, transaction, req, primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.Empty(t, transactions.calls)
+	assert.Nil(t, refundID)
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+// ExchangeRateProvider supplies the table used to convert prices between currencies
+type ExchangeRateProvider interface {
+	Rates(ctx context.Context) (*models.ExchangeRateTable, error)
+}
+
+// errNoExchangeRates is returned when a conversion is needed but no rate table is configured
+var errNoExchangeRates = apperrors.NewValidationError("exchange rates are not available")
+
+// StaticExchangeRates serves a fixed rate table, such as one loaded from a file at startup
+type StaticExchangeRates struct {
+	table *models.ExchangeRateTable
+}
+
+// NewStaticExchangeRates creates a provider that always returns table
+func NewStaticExchangeRates(table *models.ExchangeRateTable) *StaticExchangeRates {
+	return &StaticExchangeRates{table: table}
+}
+
+// LoadExchangeRatesFile reads a rate table from a JSON file such as
+// {"base": "USD", "rates": {"NGN": 1520.25, "EUR": 0.92}, "source": "treasury"}
+// Returns nil if path is empty
+func LoadExchangeRatesFile(path string) (*StaticExchangeRates, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates: %w", err)
+	}
+
+	var table models.ExchangeRateTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("failed to parse exchange rates: %w", err)
+	}
+	if err := table.Validate(); err != nil {
+		return nil, err
+	}
+	if table.Source == "" {
+		table.Source = "file:" + path
+	}
+	if table.EffectiveAt.IsZero() {
+		if info, err := os.Stat(path); err == nil {
+			table.EffectiveAt = info.ModTime().UTC()
+		}
+	}
+
+	return NewStaticExchangeRates(&table), nil
+}
+
+// Rates returns the static table
+func (p *StaticExchangeRates) Rates(_ context.Context) (*models.ExchangeRateTable, error) {
+	if p == nil || p.table == nil {
+		return nil, errNoExchangeRates
+	}
+	table := *p.table
+	return &table, nil
+}
+
+// ExchangeRateService stores rate tables uploaded by admins and serves the one in effect
+// Until a table is uploaded, rates come from the fallback provider
+type ExchangeRateService struct {
+	collection *mongo.Collection
+	fallback   ExchangeRateProvider
+}
+
+// NewExchangeRateService creates a new exchange rate service; fallback may be nil
+func NewExchangeRateService(db *mongo.Database, fallback ExchangeRateProvider) *ExchangeRateService {
+	return &ExchangeRateService{
+		collection: db.Collection("exchange_rates"),
+		fallback:   fallback,
+	}
+}
+
+// EnsureIndexes creates the index used to find the table in effect
+func (s *ExchangeRateService) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "effectiveAt", Value: -1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index().SetName("idx_exchange_rates_effective"),
+	})
+	return err
+}
+
+// UploadRates stores a new rate table; it takes effect at its effective time
+func (s *ExchangeRateService) UploadRates(ctx context.Context, req *models.UploadExchangeRatesRequest, adminID primitive.ObjectID) (*models.ExchangeRateTable, error) {
+	if err := req.Validate(); err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+
+	now := time.Now()
+	table := &models.ExchangeRateTable{
+		Base:        req.Base,
+		Rates:       req.Rates,
+		Source:      strings.TrimSpace(req.Source),
+		EffectiveAt: now,
+		UploadedBy:  adminID,
+		CreatedAt:   now,
+	}
+	if req.EffectiveAt != nil {
+		table.EffectiveAt = *req.EffectiveAt
+	}
+	if table.Source == "" {
+		table.Source = "admin upload"
+	}
+
+	result, err := s.collection.InsertOne(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	table.ID = result.InsertedID.(primitive.ObjectID)
+	return table, nil
+}
+
+// Rates returns the most recent uploaded table already in effect, or the fallback's table
+func (s *ExchangeRateService) Rates(ctx context.Context) (*models.ExchangeRateTable, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "effectiveAt", Value: -1}, {Key: "createdAt", Value: -1}})
+	var table models.ExchangeRateTable
+	err := s.collection.FindOne(ctx, bson.M{"effectiveAt": bson.M{"$lte": time.Now()}}, opts).Decode(&table)
+	if err == nil {
+		return &table, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	if s.fallback == nil {
+		return nil, errNoExchangeRates
+	}
+	return s.fallback.Rates(ctx)
+}
+
+// exchangeRate looks up the rate between two currencies; a currency converts to itself without a table
+func exchangeRate(ctx context.Context, provider ExchangeRateProvider, from, to string) (models.ExchangeRate, error) {
+	if from == to {
+		return models.ExchangeRate{From: from, To: to, Rate: 1, Source: "identity", AsOf: time.Now()}, nil
+	}
+	if provider == nil {
+		return models.ExchangeRate{}, errNoExchangeRates
+	}
+
+	table, err := provider.Rates(ctx)
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+	rate, err := table.Rate(from, to)
+	if err != nil {
+		return models.ExchangeRate{}, apperrors.NewValidationError(err.Error())
+	}
+	return rate, nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func TestLoadExchangeRatesFile(t *testing.T) {
+	t.Run("empty path has no table", func(t *testing.T) {
+		rates, err := LoadExchangeRatesFile("")
+		require.NoError(t, err)
+		assert.Nil(t, rates)
+	})
+
+	t.Run("file provides the table", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rates.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"base":"USD","rates":{"NGN":1520.25,"EUR":0.92}}`), 0o600))
+
+		rates, err := LoadExchangeRatesFile(path)
+		require.NoError(t, err)
+		table, err := rates.Rates(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "USD", table.Base)
+		assert.Equal(t, 1520.25, table.Rates["NGN"])
+		assert.Equal(t, "file:"+path, table.Source)
+		assert.False(t, table.EffectiveAt.IsZero())
+	})
+
+	t.Run("invalid rates are rejected", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rates.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"base":"USD","rates":{"NGN":-1}}`), 0o600))
+
+		_, err := LoadExchangeRatesFile(path)
+		assert.Error(t, err)
+	})
+}
+
+func TestExchangeRate_IdentityWithoutTable(t *testing.T) {
+	rate, err := exchangeRate(context.Background(), nil, "NGN", "NGN")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, rate.Rate)
+
+	_, err = exchangeRate(context.Background(), nil, "NGN", "USD")
+	assert.ErrorIs(t, err, errNoExchangeRates)
+}
+
+func TestConvertedPriceFilter(t *testing.T) {
+	rates := &models.ExchangeRateTable{Base: "USD", Rates: map[string]float64{"NGN": 1500}}
+
+	clauses, err := convertedPriceFilter(rates, "USD", 10000, 20000)
+	require.NoError(t, err)
+	require.Len(t, clauses, 2)
+
+	assert.Equal(t, bson.M{"price.currency": "NGN", "price.amount": bson.M{
+		"$gte": money.MustParse("15000000", "NGN").Decimal128(),
+		"$lte": money.MustParse("30000000", "NGN").Decimal128(),
+	}}, clauses[0])
+	assert.Equal(t, bson.M{"price.currency": "USD", "price.amount": bson.M{
+		"$gte": money.MustParse("10000", "USD").Decimal128(),
+		"$lte": money.MustParse("20000", "USD").Decimal128(),
+	}}, clauses[1])
+
+	clauses, err = convertedPriceFilter(rates, "NGN", 0, 3000000)
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"price.currency": "USD", "price.amount": bson.M{
+		"$lte": money.MustParse("2000", "USD").Decimal128(),
+	}}, clauses[1], "only the bound that was given is converted")
+}
+
+func TestDisplayPrice(t *testing.T) {
+	rates := &models.ExchangeRateTable{Base: "USD", Rates: map[string]float64{"NGN": 1500}}
+
+	price := displayPrice(rates, money.MustParse("25000", "USD"), "NGN")
+	require.NotNil(t, price)
+	assert.Equal(t, money.MustParse("37500000", "NGN"), *price)
+
+	assert.Nil(t, displayPrice(rates, money.MustParse("25000", "GBP"), "NGN"), "prices the table cannot convert are not displayed")
+}
+
+func TestRequirePriceCurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   VehicleListQuery
+		wantErr bool
+	}{
+		{"no price filter or sort", VehicleListQuery{SortBy: "year"}, false},
+		{"minimum price without currency", VehicleListQuery{MinPrice: 5000}, true},
+		{"maximum price without currency", VehicleListQuery{MaxPrice: 20000}, true},
+		{"price sort without currency", VehicleListQuery{SortBy: "price"}, true},
+		{"price filter and sort with currency", VehicleListQuery{MinPrice: 5000, SortBy: "price", Currency: "USD"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := requirePriceCurrency(tt.query)
+			if tt.wantErr {
+				assert.Error(t, err, "prices in different currencies cannot be compared unconverted")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
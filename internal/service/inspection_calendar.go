@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Over-knight/Lujay-assesment/internal/calendar"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+// calendarLookback keeps recently past and cancelled inspections in the feed so clients see their final state
+const calendarLookback = 30 * 24 * time.Hour
+
+// calendarProdID identifies the feed producer (RFC 5545 PRODID)
+const calendarProdID = "-//LUJAY//Inspection Schedule//EN"
+
+// CalendarFeed builds the iCalendar feed for a user
+// It covers the inspections assigned to the user and the inspections on vehicles the user owns
+func (s *InspectionService) CalendarFeed(ctx context.Context, userID primitive.ObjectID) (*calendar.Calendar, error) {
+	assigned, err := s.GetInspectionsByInspector(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	owned, err := s.inspectionsOnOwnedVehicles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-calendarLookback)
+	seen := map[primitive.ObjectID]bool{}
+	inspections := []models.Inspection{}
+	for _, inspection := range append(assigned, owned...) {
+		if seen[inspection.ID] || inspection.ScheduledAt.IsZero() || inspection.ScheduledAt.Before(cutoff) {
+			continue
+		}
+		seen[inspection.ID] = true
+		inspections = append(inspections, inspection)
+	}
+
+	vehicles, err := s.vehiclesByID(ctx, inspections)
+	if err != nil {
+		return nil, err
+	}
+
+	durations := map[primitive.ObjectID]time.Duration{}
+	events := make([]calendar.Event, 0, len(inspections))
+	for _, inspection := range inspections {
+		duration, ok := durations[inspection.InspectorID]
+		if !ok {
+			duration = time.Duration(models.DefaultSlotMinutes) * time.Minute
+			if !inspection.InspectorID.IsZero() {
+				availability, err := s.availability.GetAvailability(ctx, inspection.InspectorID)
+				if err != nil {
+					return nil, err
+				}
+				duration = slotDuration(availability)
+			}
+			durations[inspection.InspectorID] = duration
+		}
+
+		vehicle := vehicles[inspection.VehicleID]
+		events = append(events, inspectionEvent(&inspection, vehicle, duration))
+	}
+
+	return &calendar.Calendar{
+		ProdID: calendarProdID,
+		Name:   "LUJAY inspections",
+		Events: events,
+	}, nil
+}
+
+// inspectionsOnOwnedVehicles retrieves the inspections on every vehicle the user owns
+func (s *InspectionService) inspectionsOnOwnedVehicles(ctx context.Context, ownerID primitive.ObjectID) ([]models.Inspection, error) {
+	vehicleIDs, err := s.vehicleCollection.Distinct(ctx, "_id", bson.M{"ownerId": ownerID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vehicleIDs) == 0 {
+		return []models.Inspection{}, nil
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "scheduledAt", Value: -1}})
+	cursor, err := s.collection.Find(ctx, bson.M{"vehicleId": bson.M{"$in": vehicleIDs}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var inspections []models.Inspection
+	if err = cursor.All(ctx, &inspections); err != nil {
+		return nil, err
+	}
+
+	return inspections, nil
+}
+
+// vehiclesByID loads the vehicles referenced by the inspections
+func (s *InspectionService) vehiclesByID(ctx context.Context, inspections []models.Inspection) (map[primitive.ObjectID]*models.Vehicle, error) {
+	vehicles := map[primitive.ObjectID]*models.Vehicle{}
+	if len(inspections) == 0 {
+		return vehicles, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(inspections))
+	for _, inspection := range inspections {
+		ids = append(ids, inspection.VehicleID)
+	}
+
+	cursor, err := s.vehicleCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var found []models.Vehicle
+	if err = cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	for i := range found {
+		vehicles[found[i].ID] = &found[i]
+	}
+
+	return vehicles, nil
+}
+
+// inspectionEvent maps an inspection to a calendar event
+// The UID is derived from the inspection ID and SEQUENCE from its transition log, so clients update events in place
+func inspectionEvent(inspection *models.Inspection, vehicle *models.Vehicle, duration time.Duration) calendar.Event {
+	summary := "Vehicle inspection"
+	location := ""
+	if vehicle != nil {
+		summary = fmt.Sprintf("Inspection: %d %s %s", vehicle.Year, vehicle.Make, vehicle.Model)
+		location = joinNonEmpty(", ", vehicle.Location.City, vehicle.Location.State, vehicle.Location.Country)
+	}
+
+	description := []string{"Status: " + inspection.Status}
+	if vehicle != nil {
+		description = append(description, fmt.Sprintf("Vehicle: %s %s (%d)", vehicle.Make, vehicle.Model, vehicle.Year))
+	}
+	if inspection.Notes != "" {
+		description = append(description, "Notes: "+inspection.Notes)
+	}
+
+	return calendar.Event{
+		UID:          "inspection-" + inspection.ID.Hex() + "@lujay",
+		Start:        inspection.ScheduledAt,
+		End:          inspection.ScheduledAt.Add(duration),
+		Summary:      summary,
+		Location:     location,
+		Description:  strings.Join(description, "\n"),
+		Status:       inspectionEventStatus(inspection.Status),
+		Sequence:     len(inspection.Transitions),
+		LastModified: inspection.UpdatedAt,
+	}
+}
+
+// inspectionEventStatus maps an inspection status to an RFC 5545 event status
+func inspectionEventStatus(status string) string {
+	switch status {
+	case models.InspectionStatusPending:
+		return calendar.StatusTentative
+	case models.InspectionStatusCancelled:
+		return calendar.StatusCancelled
+	default:
+		return calendar.StatusConfirmed
+	}
+}
+
+// joinNonEmpty joins the non-empty values with the separator
+func joinNonEmpty(sep string, values ...string) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, sep)
+}
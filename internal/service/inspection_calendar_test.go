@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/calendar"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+func TestInspectionEvent(t *testing.T) {
+	scheduledAt := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	inspection := &models.Inspection{
+		ID:          primitive.NewObjectID(),
+		Status:      models.InspectionStatusCancelled,
+		ScheduledAt: scheduledAt,
+		Notes:       "Bring the spare key",
+		Transitions: make([]models.InspectionTransition, 3),
+	}
+	vehicle := &models.Vehicle{
+		Make:     "Toyota",
+		Model:    "Camry",
+		Year:     2020,
+		Location: models.Location{City: "Ikeja", State: "Lagos", Country: "Nigeria"},
+	}
+
+	event := inspectionEvent(inspection, vehicle, time.Hour)
+
+	assert.Equal(t, "inspection-"+inspection.ID.Hex()+"@lujay", event.UID)
+	assert.Equal(t, scheduledAt.Add(time.Hour), event.End)
+	assert.Equal(t, "Inspection: 2020 Toyota Camry", event.Summary)
+	assert.Equal(t, "Ikeja, Lagos, Nigeria", event.Location)
+	assert.Equal(t, calendar.StatusCancelled, event.Status)
+	assert.Equal(t, 3, event.Sequence)
+	assert.Contains(t, event.Description, "Status: cancelled")
+	assert.Contains(t, event.Description, "Notes: Bring the spare key")
+}
+
+func TestInspectionEventStatus(t *testing.T) {
+	assert.Equal(t, calendar.StatusTentative, inspectionEventStatus(models.InspectionStatusPending))
+	assert.Equal(t, calendar.StatusConfirmed, inspectionEventStatus(models.InspectionStatusScheduled))
+	assert.Equal(t, calendar.StatusConfirmed, inspectionEventStatus(models.InspectionStatusCompleted))
+	assert.Equal(t, calendar.StatusCancelled, inspectionEventStatus(models.InspectionStatusCancelled))
+}
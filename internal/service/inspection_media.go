@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+// maxIssueMedia caps the evidence attached to a single issue
+const maxIssueMedia = 10
+
+// errNotAssignedInspector is returned when someone other than the assigned inspector attaches media
+var errNotAssignedInspector = apperrors.NewAppError(apperrors.ErrCodeForbidden, "only the assigned inspector can attach media", http.StatusForbidden)
+
+// MediaStore removes uploaded media
+type MediaStore interface {
+	DeleteImages(ctx context.Context, publicIDs []string) error
+}
+
+// CheckIssueMediaUpload verifies that the user may attach count more files to the issue
+// Called before uploading so that rejected requests never reach the media store
+func (s *InspectionService) CheckIssueMediaUpload(ctx context.Context, id string, index, count int, userID primitive.ObjectID) error {
+	inspection, err := s.GetInspectionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return checkIssueMedia(inspection, index, count, userID)
+}
+
+// AddIssueMedia attaches uploaded media to the issue at index
+// The write only applies if the inspection is unchanged since it was read, so a concurrent
+// report edit cannot shift the issue the media lands on
+func (s *InspectionService) AddIssueMedia(ctx context.Context, id string, index int, media []models.IssueMedia, userID primitive.ObjectID) (*models.Inspection, error) {
+	inspection, err := s.GetInspectionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIssueMedia(inspection, index, len(media), userID); err != nil {
+		return nil, err
+	}
+
+	publicIDs := make([]string, len(media))
+	for i, item := range media {
+		publicIDs[i] = item.PublicID
+	}
+
+	filter := bson.M{"_id": inspection.ID, "status": inspection.Status, "updatedAt": inspection.UpdatedAt}
+	update := bson.M{
+		"$push": bson.M{
+			fmt.Sprintf("report.issues.%d.media", index): bson.M{"$each": media},
+			"mediaPublicIds": bson.M{"$each": publicIDs},
+		},
+		"$set": bson.M{"updatedAt": time.Now()},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.Inspection
+	err = s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errInspectionModified
+		}
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// checkIssueMedia enforces who may attach media to an issue, and when
+func checkIssueMedia(inspection *models.Inspection, index, count int, userID primitive.ObjectID) error {
+	if inspection.InspectorID.IsZero() || inspection.InspectorID != userID {
+		return errNotAssignedInspector
+	}
+
+	if inspection.Status == models.InspectionStatusCompleted || inspection.Status == models.InspectionStatusCancelled {
+		return apperrors.NewInvalidStateTransitionError("media can only be attached before the inspection is completed")
+	}
+
+	if index < 0 || index >= len(inspection.Report.Issues) {
+		return apperrors.NewNotFoundError("issue not found")
+	}
+
+	if existing := len(inspection.Report.Issues[index].Media); existing+count > maxIssueMedia {
+		return apperrors.NewValidationError(fmt.Sprintf("maximum %d media files allowed per issue (current: %d)", maxIssueMedia, existing))
+	}
+
+	return nil
+}
+
+// reconcileIssueMedia keeps only media already uploaded to this inspection on a submitted report
+// Clients send issues back with their media; stored references replace what they submit, so media
+// cannot be forged or pointed elsewhere
+func reconcileIssueMedia(submitted *models.InspectionReport, stored *models.Inspection) {
+	uploaded := map[string]models.IssueMedia{}
+	for _, issue := range stored.Report.Issues {
+		for _, media := range issue.Media {
+			uploaded[media.PublicID] = media
+		}
+	}
+
+	issues := make([]models.InspectionIssue, len(submitted.Issues))
+	for i, issue := range submitted.Issues {
+		kept := []models.IssueMedia{}
+		for _, media := range issue.Media {
+			if original, ok := uploaded[media.PublicID]; ok {
+				kept = append(kept, original)
+			}
+		}
+		issue.Media = nil
+		if len(kept) > 0 {
+			issue.Media = kept
+		}
+		issues[i] = issue
+	}
+	if submitted.Issues != nil {
+		submitted.Issues = issues
+	}
+}
+
+// inspectionMediaIDs lists every uploaded file belonging to the inspection
+func inspectionMediaIDs(inspection *models.Inspection) []string {
+	seen := map[string]bool{}
+	ids := []string{}
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range inspection.MediaPublicIDs {
+		add(id)
+	}
+	for _, issue := range inspection.Report.Issues {
+		for _, media := range issue.Media {
+			add(media.PublicID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+func TestCheckIssueMedia(t *testing.T) {
+	inspectorID := primitive.NewObjectID()
+	fullIssue := models.InspectionIssue{Description: "Rust", Media: make([]models.IssueMedia, maxIssueMedia)}
+
+	inspection := func(status string) *models.Inspection {
+		return &models.Inspection{
+			InspectorID: inspectorID,
+			Status:      status,
+			Report: models.InspectionReport{Issues: []models.InspectionIssue{
+				{Description: "Dent"},
+				fullIssue,
+			}},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		inspection *models.Inspection
+		index      int
+		count      int
+		userID     primitive.ObjectID
+		wantCode   string
+	}{
+		{"assigned inspector in progress", inspection(models.InspectionStatusInProgress), 0, 2, inspectorID, ""},
+		{"other user", inspection(models.InspectionStatusInProgress), 0, 1, primitive.NewObjectID(), apperrors.ErrCodeForbidden},
+		{"unassigned inspection", &models.Inspection{Status: models.InspectionStatusPending}, 0, 1, primitive.NilObjectID, apperrors.ErrCodeForbidden},
+		{"completed", inspection(models.InspectionStatusCompleted), 0, 1, inspectorID, apperrors.ErrCodeInvalidStateTransition},
+		{"cancelled", inspection(models.InspectionStatusCancelled), 0, 1, inspectorID, apperrors.ErrCodeInvalidStateTransition},
+		{"index out of range", inspection(models.InspectionStatusInProgress), 2, 1, inspectorID, apperrors.ErrCodeNotFound},
+		{"negative index", inspection(models.InspectionStatusInProgress), -1, 1, inspectorID, apperrors.ErrCodeNotFound},
+		{"issue full", inspection(models.InspectionStatusInProgress), 1, 1, inspectorID, apperrors.ErrCodeValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkIssueMedia(tt.inspection, tt.index, tt.count, tt.userID)
+			if tt.wantCode == "" {
+				assert.NoError(t, err)
+				return
+			}
+			appErr, ok := err.(*apperrors.AppError)
+			if assert.True(t, ok, "expected an AppError, got %v", err) {
+				assert.Equal(t, tt.wantCode, appErr.Code)
+			}
+		})
+	}
+}
+
+func TestReconcileIssueMedia(t *testing.T) {
+	uploaded := models.IssueMedia{URL: "https://res.cloudinary.com/x/rust.jpg", PublicID: "rust"}
+	stored := &models.Inspection{Report: models.InspectionReport{Issues: []models.InspectionIssue{
+		{Description: "Rust", Media: []models.IssueMedia{uploaded}},
+	}}}
+
+	submitted := models.InspectionReport{Issues: []models.InspectionIssue{
+		{Description: "Dent"},
+		{Description: "Rust on sill", Media: []models.IssueMedia{
+			{URL: "https://elsewhere.example/fake.jpg", PublicID: "rust"},
+			{URL: "https://elsewhere.example/forged.jpg", PublicID: "forged"},
+		}},
+	}}
+
+	reconcileIssueMedia(&submitted, stored)
+
+	assert.Nil(t, submitted.Issues[0].Media)
+	assert.Equal(t, []models.IssueMedia{uploaded}, submitted.Issues[1].Media, "stored references win and unknown media is dropped")
+}
+
+func TestInspectionMediaIDs(t *testing.T) {
+	inspection := &models.Inspection{
+		MediaPublicIDs: []string{"a", "b"},
+		Report: models.InspectionReport{Issues: []models.InspectionIssue{
+			{Media: []models.IssueMedia{{PublicID: "b"}, {PublicID: "c"}}},
+		}},
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, inspectionMediaIDs(inspection))
+	assert.Empty(t, inspectionMediaIDs(&models.Inspection{}))
+}
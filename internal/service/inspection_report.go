@@ -0,0 +1,271 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for vehicle photos
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Over-knight/Lujay-assesment/internal/cache"
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+// errReportNotReady is returned when a report is requested before the inspection is completed
+var errReportNotReady = apperrors.NewConflictError("inspection report is only available for completed inspections")
+
+// Rendered reports are cached per inspection version
+const (
+	inspectionReportCacheTTL   = 24 * time.Hour
+	inspectionReportLocalLimit = 64 // reports kept in memory when Redis is unavailable
+	reportImageTimeout         = 10 * time.Second
+	reportImageMaxBytes        = 10 << 20
+)
+
+// ImageFetcher downloads an image by URL
+type ImageFetcher func(ctx context.Context, url string) ([]byte, error)
+
+// InspectionReportService renders completed inspections as PDF certificates
+type InspectionReportService struct {
+	inspections       *InspectionService
+	vehicleCollection *mongo.Collection
+	userCollection    *mongo.Collection
+	cache             *cache.RedisCache // optional
+	fetchImage        ImageFetcher
+
+	mu    sync.Mutex
+	local map[primitive.ObjectID]cachedReport
+}
+
+// cachedReport is an in-memory rendered report for one version of an inspection
+type cachedReport struct {
+	updatedAt time.Time
+	data      []byte
+}
+
+// NewInspectionReportService creates a new inspection report service
+// Rendered reports are cached in Redis when redisCache is set, and in memory otherwise
+func NewInspectionReportService(db *mongo.Database, inspections *InspectionService, redisCache *cache.RedisCache) *InspectionReportService {
+	client := &http.Client{Timeout: reportImageTimeout}
+	return &InspectionReportService{
+		inspections:       inspections,
+		vehicleCollection: db.Collection("vehicles"),
+		userCollection:    db.Collection("users"),
+		cache:             redisCache,
+		fetchImage: func(ctx context.Context, url string) ([]byte, error) {
+			return fetchImage(ctx, client, url)
+		},
+		local: map[primitive.ObjectID]cachedReport{},
+	}
+}
+
+// RenderReport returns the PDF certificate for a completed inspection
+// The bytes are cached against the inspection's UpdatedAt, so any change to the inspection renders a fresh copy
+func (s *InspectionReportService) RenderReport(ctx context.Context, id string) ([]byte, error) {
+	inspection, err := s.inspections.GetInspectionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inspection.Status != models.InspectionStatusCompleted {
+		return nil, errReportNotReady
+	}
+
+	if data, ok := s.cached(ctx, inspection); ok {
+		return data, nil
+	}
+
+	vehicle, err := s.reportVehicle(ctx, inspection.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	inspector, err := s.inspectorName(ctx, inspection.InspectorID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := renderInspectionReport(inspectionReportData{
+		Inspection:  inspection,
+		Vehicle:     vehicle,
+		Inspector:   inspector,
+		Thumbnails:  s.thumbnails(ctx, vehicle),
+		GeneratedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render inspection report: %w", err)
+	}
+
+	s.store(ctx, inspection, data)
+	return data, nil
+}
+
+// cached looks up a rendered report for the inspection's current version
+func (s *InspectionReportService) cached(ctx context.Context, inspection *models.Inspection) ([]byte, bool) {
+	if s.cache != nil {
+		var data []byte
+		if err := s.cache.Get(ctx, reportCacheKey(inspection), &data); err == nil && len(data) > 0 {
+			return data, true
+		}
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.local[inspection.ID]
+	if !ok || !entry.updatedAt.Equal(inspection.UpdatedAt) {
+		return nil, false
+	}
+	return entry.data, true
+}
+
+// store caches a rendered report; cache failures only cost a re-render
+func (s *InspectionReportService) store(ctx context.Context, inspection *models.Inspection, data []byte) {
+	if s.cache != nil {
+		_ = s.cache.Set(ctx, reportCacheKey(inspection), data, inspectionReportCacheTTL)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.local[inspection.ID]; !ok && len(s.local) >= inspectionReportLocalLimit {
+		// Evict an arbitrary entry to keep memory bounded
+		for id := range s.local {
+			delete(s.local, id)
+			break
+		}
+	}
+	s.local[inspection.ID] = cachedReport{updatedAt: inspection.UpdatedAt, data: data}
+}
+
+// reportCacheKey identifies one version of an inspection's report
+func reportCacheKey(inspection *models.Inspection) string {
+	return fmt.Sprintf("inspection_report:%s:%d", inspection.ID.Hex(), inspection.UpdatedAt.UnixNano())
+}
+
+// reportVehicle loads the inspected vehicle; a deleted vehicle leaves the report without vehicle details
+func (s *InspectionReportService) reportVehicle(ctx context.Context, vehicleID primitive.ObjectID) (*models.Vehicle, error) {
+	var vehicle models.Vehicle
+	err := s.vehicleCollection.FindOne(ctx, bson.M{"_id": vehicleID}).Decode(&vehicle)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &vehicle, nil
+}
+
+// inspectorName returns the display name of the inspector who completed the inspection
+func (s *InspectionReportService) inspectorName(ctx context.Context, inspectorID primitive.ObjectID) (string, error) {
+	if inspectorID.IsZero() {
+		return "", nil
+	}
+
+	var user models.User
+	opts := options.FindOne().SetProjection(bson.M{"firstName": 1, "lastName": 1})
+	err := s.userCollection.FindOne(ctx, bson.M{"_id": inspectorID}, opts).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", nil
+		}
+		return "", err
+	}
+	return joinNonEmpty(" ", user.FirstName, user.LastName), nil
+}
+
+// thumbnails downloads and shrinks the vehicle photos, primary image first
+// Photos that cannot be fetched or decoded are left out rather than failing the report
+func (s *InspectionReportService) thumbnails(ctx context.Context, vehicle *models.Vehicle) []image.Image {
+	if vehicle == nil {
+		return nil
+	}
+
+	images := append([]models.VehicleImage(nil), vehicle.Images...)
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].IsPrimary && !images[j].IsPrimary
+	})
+
+	thumbnails := []image.Image{}
+	for _, vehicleImage := range images {
+		if len(thumbnails) == reportThumbnailMax {
+			break
+		}
+		if vehicleImage.URL == "" {
+			continue
+		}
+
+		data, err := s.fetchImage(ctx, vehicleImage.URL)
+		if err != nil {
+			continue
+		}
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+		thumbnails = append(thumbnails, thumbnail(img, reportThumbnailPixel))
+	}
+	return thumbnails
+}
+
+// fetchImage downloads an image over HTTP(S)
+func fetchImage(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return nil, fmt.Errorf("unsupported image URL: %s", url)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image request returned %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, reportImageMaxBytes))
+}
+
+// thumbnail scales an image down so its longest side is at most maxSide pixels
+// Nearest-neighbour sampling is enough at certificate thumbnail sizes
+func thumbnail(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSide && height <= maxSide {
+		return img
+	}
+
+	scaledWidth, scaledHeight := maxSide, maxSide
+	if width > height {
+		scaledHeight = max(1, height*maxSide/width)
+	} else {
+		scaledWidth = max(1, width*maxSide/height)
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, scaledWidth, scaledHeight))
+	for y := 0; y < scaledHeight; y++ {
+		sourceY := bounds.Min.Y + y*height/scaledHeight
+		for x := 0; x < scaledWidth; x++ {
+			scaled.Set(x, y, img.At(bounds.Min.X+x*width/scaledWidth, sourceY))
+		}
+	}
+	return scaled
+}
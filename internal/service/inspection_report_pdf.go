@@ -0,0 +1,356 @@
+package service
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+	"github.com/Over-knight/Lujay-assesment/internal/pdf"
+)
+
+// Report layout, in points
+const (
+	reportMargin         = 40.0
+	reportContentWidth   = pdf.PageWidth - 2*reportMargin
+	reportFooterHeight   = 40.0
+	reportThumbnailWidth = 120.0
+	reportThumbnailGap   = 8.0
+	reportThumbnailMax   = 4   // vehicle photos shown on the certificate
+	reportThumbnailPixel = 320 // longest side of an embedded thumbnail
+)
+
+// Report palette
+var (
+	reportBrand    = pdf.RGB(17, 56, 99)
+	reportMuted    = pdf.RGB(110, 110, 110)
+	reportRule     = pdf.RGB(210, 210, 210)
+	reportBarTrack = pdf.RGB(232, 232, 232)
+	reportWarning  = pdf.RGB(180, 90, 0)
+)
+
+// severityOrder lists issue severities from most to least serious, with their heading colors
+var severityOrder = []struct {
+	severity string
+	title    string
+	color    pdf.Color
+}{
+	{"critical", "Critical", pdf.RGB(192, 32, 32)},
+	{"major", "Major", pdf.RGB(214, 120, 0)},
+	{"minor", "Minor", pdf.RGB(60, 120, 60)},
+}
+
+// inspectionReportData is everything the certificate shows
+type inspectionReportData struct {
+	Inspection  *models.Inspection
+	Vehicle     *models.Vehicle
+	Inspector   string
+	Thumbnails  []image.Image
+	GeneratedAt time.Time
+}
+
+// reportWriter lays out flowing content, starting new pages as needed
+type reportWriter struct {
+	doc   *pdf.Document
+	page  *pdf.Page
+	y     float64
+	label string // document name shown in the header band
+}
+
+// renderInspectionReport renders the inspection certificate PDF
+func renderInspectionReport(data inspectionReportData) ([]byte, error) {
+	w := &reportWriter{doc: pdf.New(), label: "Inspection Certificate"}
+	w.newPage()
+
+	inspection := data.Inspection
+	report := inspection.Report
+
+	w.vehicleSummary(data)
+	w.scores(&report)
+
+	w.section("Issues")
+	groups := groupIssuesBySeverity(report.Issues)
+	if len(report.Issues) == 0 {
+		w.text("No issues were recorded.", pdf.Helvetica, 10, reportMuted, 0)
+	}
+	for _, level := range severityOrder {
+		issues := groups[level.severity]
+		if len(issues) == 0 {
+			continue
+		}
+		w.ensureSpace(30)
+		w.text(fmt.Sprintf("%s (%d)", level.title, len(issues)), pdf.HelveticaBold, 11, level.color, 0)
+		for _, issue := range issues {
+			w.bullet(describeIssue(issue))
+		}
+		w.y += 4
+	}
+
+	w.section("Recommendations")
+	if len(report.Recommendations) == 0 {
+		w.text("No recommendations.", pdf.Helvetica, 10, reportMuted, 0)
+	}
+	for _, recommendation := range report.Recommendations {
+		w.bullet(recommendation)
+	}
+
+	w.section("Estimated repairs")
+	w.text(formatAmount(report.EstimatedRepairs), pdf.HelveticaBold, 14, pdf.Black, 0)
+
+	if len(data.Thumbnails) > 0 {
+		if err := w.thumbnails(data.Thumbnails); err != nil {
+			return nil, err
+		}
+	}
+
+	w.footers(fmt.Sprintf("Inspection %s  |  Generated %s", inspection.ID.Hex(), data.GeneratedAt.UTC().Format("2 Jan 2006 15:04 MST")))
+	return w.doc.Bytes()
+}
+
+// vehicleSummary draws the branded header and the vehicle and inspection details
+func (w *reportWriter) vehicleSummary(data inspectionReportData) {
+	inspection := data.Inspection
+
+	title := "Vehicle inspection certificate"
+	if data.Vehicle != nil {
+		title = fmt.Sprintf("%d %s %s", data.Vehicle.Year, data.Vehicle.Make, data.Vehicle.Model)
+	}
+	w.text(title, pdf.HelveticaBold, 18, pdf.Black, 0)
+	w.y += 4
+
+	details := [][2]string{
+		{"Inspection", inspection.ID.Hex()},
+	}
+	if inspection.CompletedAt != nil {
+		details = append(details, [2]string{"Completed", inspection.CompletedAt.UTC().Format("2 January 2006 15:04 MST")})
+	}
+	if inspection.Seal != nil {
+		details = append(details, [2]string{"Verification", inspection.Seal.Code})
+	}
+	if data.Inspector != "" {
+		details = append(details, [2]string{"Inspector", data.Inspector})
+	}
+	if inspection.Template != nil {
+		details = append(details, [2]string{"Checklist", fmt.Sprintf("%s v%d", inspection.Template.Key, inspection.Template.Version)})
+	}
+	if vehicle := data.Vehicle; vehicle != nil {
+		details = append(details, [2]string{"Mileage", fmt.Sprintf("%s km", formatThousands(int64(vehicle.Mileage)))})
+		if location := joinNonEmpty(", ", vehicle.Location.City, vehicle.Location.State, vehicle.Location.Country); location != "" {
+			details = append(details, [2]string{"Location", location})
+		}
+		if meta := joinNonEmpty(", ", vehicle.Meta.Color, vehicle.Meta.Transmission, vehicle.Meta.FuelType); meta != "" {
+			details = append(details, [2]string{"Specification", meta})
+		}
+	}
+
+	w.details(details)
+}
+
+// scores draws the overall condition and a bar per category score
+// Server-computed scores are shown when present, with any disagreement with the submitted values noted
+func (w *reportWriter) scores(report *models.InspectionReport) {
+	w.section("Condition")
+
+	condition := report.OverallCondition
+	mechanical, exterior, interior := report.MechanicalScore, report.ExteriorScore, report.InteriorScore
+	if computed := report.Computed; computed != nil {
+		condition = computed.OverallCondition
+		mechanical, exterior, interior = computed.MechanicalScore, computed.ExteriorScore, computed.InteriorScore
+	}
+
+	w.ensureSpace(30)
+	w.page.Text(reportMargin, w.y, pdf.HelveticaBold, 16, reportBrand, strings.ToUpper(condition))
+	if report.Computed != nil {
+		label := fmt.Sprintf("Overall score %d / 100", report.Computed.OverallScore)
+		w.page.Text(reportMargin+reportContentWidth-pdf.TextWidth(label, pdf.Helvetica, 10), w.y+4, pdf.Helvetica, 10, reportMuted, label)
+	}
+	w.y += 26
+
+	for _, score := range []struct {
+		label string
+		value int
+	}{
+		{"Mechanical", mechanical},
+		{"Exterior", exterior},
+		{"Interior", interior},
+	} {
+		w.ensureSpace(18)
+		w.page.Text(reportMargin, w.y, pdf.Helvetica, 10, pdf.Black, score.label)
+		barX, barWidth := reportMargin+90, reportContentWidth-130
+		w.page.Rect(barX, w.y+1, barWidth, 8, reportBarTrack)
+		w.page.Rect(barX, w.y+1, barWidth*float64(clampScore(score.value))/100, 8, reportBrand)
+		w.page.Text(barX+barWidth+10, w.y, pdf.HelveticaBold, 10, pdf.Black, fmt.Sprintf("%d", score.value))
+		w.y += 18
+	}
+
+	if report.Computed != nil && report.Computed.HasMismatch {
+		for _, mismatch := range report.Computed.Mismatches {
+			w.text(fmt.Sprintf("Inspector submitted %s %s; computed %s.", mismatch.Field, mismatch.Submitted, mismatch.Computed), pdf.Helvetica, 9, reportWarning, 0)
+		}
+	}
+}
+
+// thumbnails draws the vehicle photos in rows
+func (w *reportWriter) thumbnails(images []image.Image) error {
+	w.section("Photos")
+
+	x := reportMargin
+	rowHeight := 0.0
+	for _, img := range images {
+		registered, err := w.doc.AddImage(img)
+		if err != nil {
+			return err
+		}
+		height := reportThumbnailWidth * float64(registered.Height) / float64(registered.Width)
+
+		if x+reportThumbnailWidth > reportMargin+reportContentWidth {
+			w.y += rowHeight + reportThumbnailGap
+			x, rowHeight = reportMargin, 0
+		}
+		if w.ensureSpace(height) {
+			x, rowHeight = reportMargin, 0
+		}
+
+		w.page.Image(registered, x, w.y, reportThumbnailWidth, height)
+		x += reportThumbnailWidth + reportThumbnailGap
+		if height > rowHeight {
+			rowHeight = height
+		}
+	}
+	w.y += rowHeight
+	return nil
+}
+
+// footers stamps every page with the document reference and page number
+// They are drawn last so the page count is known
+func (w *reportWriter) footers(reference string) {
+	pages := w.doc.Pages()
+	for i, page := range pages {
+		top := pdf.PageHeight - reportMargin
+		page.Line(reportMargin, top, reportMargin+reportContentWidth, top, 0.5, reportRule)
+		page.Text(reportMargin, top+8, pdf.Helvetica, 8, reportMuted, reference)
+		number := fmt.Sprintf("Page %d of %d", i+1, len(pages))
+		page.Text(reportMargin+reportContentWidth-pdf.TextWidth(number, pdf.Helvetica, 8), top+8, pdf.Helvetica, 8, reportMuted, number)
+	}
+}
+
+// newPage starts a page with the branded header band
+func (w *reportWriter) newPage() {
+	w.page = w.doc.AddPage()
+	w.page.Rect(0, 0, pdf.PageWidth, 56, reportBrand)
+	w.page.Text(reportMargin, 18, pdf.HelveticaBold, 20, pdf.White, "LUJAY")
+	w.page.Text(pdf.PageWidth-reportMargin-pdf.TextWidth(w.label, pdf.Helvetica, 12), 23, pdf.Helvetica, 12, pdf.White, w.label)
+	w.y = 80
+}
+
+// ensureSpace starts a new page when height no longer fits; returns true if it did
+func (w *reportWriter) ensureSpace(height float64) bool {
+	if w.y+height <= pdf.PageHeight-reportMargin-reportFooterHeight {
+		return false
+	}
+	w.newPage()
+	return true
+}
+
+// section draws a section heading with a rule beneath it
+func (w *reportWriter) section(title string) {
+	w.y += 10
+	w.ensureSpace(40)
+	w.page.Text(reportMargin, w.y, pdf.HelveticaBold, 13, reportBrand, title)
+	w.y += 18
+	w.page.Line(reportMargin, w.y, reportMargin+reportContentWidth, w.y, 0.5, reportRule)
+	w.y += 8
+}
+
+// text draws wrapped text at the given indent
+func (w *reportWriter) text(text string, font pdf.Font, size float64, color pdf.Color, indent float64) {
+	lineHeight := size * 1.4
+	for _, line := range pdf.WrapText(text, font, size, reportContentWidth-indent) {
+		w.ensureSpace(lineHeight)
+		w.page.Text(reportMargin+indent, w.y, font, size, color, line)
+		w.y += lineHeight
+	}
+}
+
+// details draws label and value rows
+func (w *reportWriter) details(rows [][2]string) {
+	for _, row := range rows {
+		w.ensureSpace(16)
+		w.page.Text(reportMargin, w.y, pdf.Helvetica, 10, reportMuted, row[0])
+		w.page.Text(reportMargin+90, w.y, pdf.Helvetica, 10, pdf.Black, row[1])
+		w.y += 15
+	}
+}
+
+// bullet draws a wrapped bullet point
+func (w *reportWriter) bullet(text string) {
+	w.ensureSpace(14)
+	w.page.Text(reportMargin+6, w.y, pdf.Helvetica, 10, pdf.Black, "•")
+	w.text(text, pdf.Helvetica, 10, pdf.Black, 18)
+}
+
+// groupIssuesBySeverity buckets issues by severity, keeping their recorded order
+func groupIssuesBySeverity(issues []models.InspectionIssue) map[string][]models.InspectionIssue {
+	groups := map[string][]models.InspectionIssue{}
+	for _, issue := range issues {
+		severity := strings.ToLower(issue.Severity)
+		groups[severity] = append(groups[severity], issue)
+	}
+	return groups
+}
+
+// describeIssue formats an issue as a single line of text
+func describeIssue(issue models.InspectionIssue) string {
+	description := issue.Description
+	if issue.Category != "" {
+		description = strings.ToUpper(issue.Category[:1]) + issue.Category[1:] + ": " + description
+	}
+	if issue.Location != "" {
+		description += " (" + issue.Location + ")"
+	}
+	return description
+}
+
+// clampScore limits a score to the 0-100 range for drawing
+func clampScore(score int) int {
+	if score < 0 {
+		return 0
+	}
+	if score > 100 {
+		return 100
+	}
+	return score
+}
+
+// formatAmount formats an amount with thousands separators and the decimals of its currency
+func formatAmount(amount money.Money) string {
+	if stamped, err := amount.WithCurrency(money.DefaultCurrency); err == nil {
+		amount = stamped
+	}
+	whole, fraction, _ := strings.Cut(amount.Abs().Decimal(), ".")
+	units, _ := strconv.ParseInt(whole, 10, 64)
+	formatted := formatThousands(units)
+	if fraction != "" {
+		formatted += "." + fraction
+	}
+	if amount.IsNegative() {
+		return "-" + formatted
+	}
+	return formatted
+}
+
+// formatThousands formats a non-negative integer with comma separators
+func formatThousands(value int64) string {
+	digits := fmt.Sprintf("%d", value)
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func TestRenderInspectionReport(t *testing.T) {
+	completedAt := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+	issues := []models.InspectionIssue{}
+	for i := 0; i < 40; i++ {
+		issues = append(issues, models.InspectionIssue{Category: "body", Severity: "minor", Description: "Scratch on panel", Location: "Rear"})
+	}
+	issues = append(issues, models.InspectionIssue{Category: "mechanical", Severity: "critical", Description: "Brake failure"})
+
+	inspection := &models.Inspection{
+		ID:          primitive.NewObjectID(),
+		Status:      models.InspectionStatusCompleted,
+		CompletedAt: &completedAt,
+		Report: models.InspectionReport{
+			OverallCondition: "good",
+			MechanicalScore:  80,
+			ExteriorScore:    70,
+			InteriorScore:    90,
+			Issues:           issues,
+			Recommendations:  []string{"Replace brake lines"},
+			EstimatedRepairs: money.MustParse("125000.5", "USD"),
+			Computed:         &models.ComputedScores{OverallCondition: "poor", OverallScore: 40, HasMismatch: true},
+		},
+	}
+	vehicle := &models.Vehicle{Make: "Toyota", Model: "Corolla", Year: 2018, Mileage: 45210}
+
+	out, err := renderInspectionReport(inspectionReportData{
+		Inspection:  inspection,
+		Vehicle:     vehicle,
+		Inspector:   "Ada Obi",
+		Thumbnails:  []image.Image{image.NewRGBA(image.Rect(0, 0, 40, 30))},
+		GeneratedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-")))
+	assert.Contains(t, string(out), "/Count 2", "long issue lists flow onto a second page")
+	assert.Contains(t, string(out), "/Subtype /Image")
+}
+
+func TestGroupIssuesBySeverity(t *testing.T) {
+	groups := groupIssuesBySeverity([]models.InspectionIssue{
+		{Severity: "minor", Description: "a"},
+		{Severity: "Critical", Description: "b"},
+		{Severity: "minor", Description: "c"},
+	})
+
+	assert.Len(t, groups["critical"], 1)
+	require.Len(t, groups["minor"], 2)
+	assert.Equal(t, "a", groups["minor"][0].Description)
+	assert.Equal(t, "c", groups["minor"][1].Description)
+}
+
+func TestDescribeIssue(t *testing.T) {
+	assert.Equal(t, "Electrical: Dead battery (Engine bay)",
+		describeIssue(models.InspectionIssue{Category: "electrical", Description: "Dead battery", Location: "Engine bay"}))
+	assert.Equal(t, "Dent", describeIssue(models.InspectionIssue{Description: "Dent"}))
+}
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "0.00", formatAmount(money.Money{}))
+	assert.Equal(t, "999.99", formatAmount(money.MustParse("999.99", "USD")))
+	assert.Equal(t, "1,250,000.50", formatAmount(money.MustParse("1250000.5", "USD")))
+	assert.Equal(t, "1,250,000", formatAmount(money.MustParse("1250000", "JPY")))
+}
+
+func TestThumbnail(t *testing.T) {
+	wide := thumbnail(image.NewRGBA(image.Rect(0, 0, 1000, 500)), 320)
+	assert.Equal(t, image.Rect(0, 0, 320, 160), wide.Bounds())
+
+	tall := thumbnail(image.NewRGBA(image.Rect(0, 0, 300, 900)), 320)
+	assert.Equal(t, image.Rect(0, 0, 106, 320), tall.Bounds())
+
+	small := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	assert.Same(t, small, thumbnail(small, 320))
+}
+
+func TestInspectionReportService_Thumbnails(t *testing.T) {
+	var encoded bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, 640, 480))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	require.NoError(t, png.Encode(&encoded, img))
+
+	fetched := []string{}
+	s := &InspectionReportService{
+		fetchImage: func(ctx context.Context, url string) ([]byte, error) {
+			fetched = append(fetched, url)
+			if url == "https://img/broken" {
+				return nil, errors.New("timeout")
+			}
+			return encoded.Bytes(), nil
+		},
+	}
+
+	thumbnails := s.thumbnails(context.Background(), &models.Vehicle{Images: []models.VehicleImage{
+		{URL: "https://img/broken"},
+		{URL: "https://img/side"},
+		{URL: "https://img/front", IsPrimary: true},
+	}})
+
+	// The primary image is fetched first and failed downloads are skipped
+	assert.Equal(t, []string{"https://img/front", "https://img/broken", "https://img/side"}, fetched)
+	require.Len(t, thumbnails, 2)
+	assert.Equal(t, image.Rect(0, 0, 320, 240), thumbnails[0].Bounds())
+}
+
+func TestInspectionReportService_LocalCache(t *testing.T) {
+	s := &InspectionReportService{local: map[primitive.ObjectID]cachedReport{}}
+	inspection := &models.Inspection{ID: primitive.NewObjectID(), UpdatedAt: time.Now()}
+
+	s.store(context.Background(), inspection, []byte("v1"))
+	data, ok := s.cached(context.Background(), inspection)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("v1"), data)
+
+	// Updating the inspection invalidates the cached bytes
+	updated := *inspection
+	updated.UpdatedAt = inspection.UpdatedAt.Add(time.Second)
+	_, ok = s.cached(context.Background(), &updated)
+	assert.False(t, ok)
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+// Score categories reported on an inspection
+const (
+	ScoreCategoryMechanical = "mechanical"
+	ScoreCategoryExterior   = "exterior"
+	ScoreCategoryInterior   = "interior"
+)
+
+// ScoringConfig holds the tunable weights of the scoring engine
+type ScoringConfig struct {
+	// Points deducted from a category score per issue of each severity
+	SeverityPenalties map[string]float64 `json:"severityPenalties"`
+
+	// Maps issue categories (engine, paint, upholstery, ...) to a score category
+	CategoryMap     map[string]string `json:"categoryMap"`
+	DefaultCategory string            `json:"defaultCategory"` // used for unmapped issue categories
+
+	// Weight of each score category in the overall score
+	CategoryWeights map[string]float64 `json:"categoryWeights"`
+
+	// Minimum overall score for each condition grade; anything lower is poor
+	ConditionThresholds map[string]float64 `json:"conditionThresholds"`
+
+	// Best grade allowed when the report has a critical issue
+	CriticalConditionCap string `json:"criticalConditionCap"`
+
+	// Largest difference between a submitted and computed score that is not flagged
+	ScoreTolerance int `json:"scoreTolerance"`
+}
+
+// conditionRank orders condition grades from worst to best
+var conditionRank = map[string]int{"poor": 0, "fair": 1, "good": 2, "excellent": 3}
+
+// DefaultScoringConfig returns the weights used when no configuration file is provided
+func DefaultScoringConfig() ScoringConfig {
+	return ScoringConfig{
+		SeverityPenalties: map[string]float64{
+			"critical": 40,
+			"major":    15,
+			"minor":    5,
+		},
+		CategoryMap: map[string]string{
+			"mechanical":   ScoreCategoryMechanical,
+			"electrical":   ScoreCategoryMechanical,
+			"engine":       ScoreCategoryMechanical,
+			"transmission": ScoreCategoryMechanical,
+			"brakes":       ScoreCategoryMechanical,
+			"suspension":   ScoreCategoryMechanical,
+			"battery":      ScoreCategoryMechanical,
+			"body":         ScoreCategoryExterior,
+			"exterior":     ScoreCategoryExterior,
+			"paint":        ScoreCategoryExterior,
+			"tires":        ScoreCategoryExterior,
+			"glass":        ScoreCategoryExterior,
+			"lights":       ScoreCategoryExterior,
+			"interior":     ScoreCategoryInterior,
+			"upholstery":   ScoreCategoryInterior,
+			"dashboard":    ScoreCategoryInterior,
+		},
+		DefaultCategory: ScoreCategoryMechanical,
+		CategoryWeights: map[string]float64{
+			ScoreCategoryMechanical: 0.5,
+			ScoreCategoryExterior:   0.25,
+			ScoreCategoryInterior:   0.25,
+		},
+		ConditionThresholds: map[string]float64{
+			"excellent": 90,
+			"good":      75,
+			"fair":      50,
+		},
+		CriticalConditionCap: "poor",
+		ScoreTolerance:       10,
+	}
+}
+
+// LoadScoringConfig reads scoring weights from a JSON file
+// Fields missing from the file keep their default values
+func LoadScoringConfig(path string) (ScoringConfig, error) {
+	cfg := DefaultScoringConfig()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read scoring config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse scoring config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks that the configuration can produce meaningful scores
+func (c *ScoringConfig) Validate() error {
+	for severity, penalty := range c.SeverityPenalties {
+		if penalty < 0 {
+			return fmt.Errorf("penalty for %s issues cannot be negative", severity)
+		}
+	}
+
+	total := 0.0
+	for category, weight := range c.CategoryWeights {
+		if weight < 0 {
+			return fmt.Errorf("weight for %s cannot be negative", category)
+		}
+		total += weight
+	}
+	if total == 0 {
+		return fmt.Errorf("at least one category weight must be positive")
+	}
+
+	if _, ok := conditionRank[c.CriticalConditionCap]; c.CriticalConditionCap != "" && !ok {
+		return fmt.Errorf("invalid criticalConditionCap %q", c.CriticalConditionCap)
+	}
+
+	return nil
+}
+
+// ScoringEngine derives category scores and a condition grade from a report's issues
+type ScoringEngine struct {
+	config ScoringConfig
+}
+
+// NewScoringEngine creates a scoring engine with the given weights
+func NewScoringEngine(config ScoringConfig) *ScoringEngine {
+	return &ScoringEngine{config: config}
+}
+
+// Score computes the scores for a report and compares them with the inspector's submitted values
+func (e *ScoringEngine) Score(report *models.InspectionReport) *models.ComputedScores {
+	penalties := map[string]float64{}
+	hasCritical := false
+
+	for _, issue := range report.Issues {
+		category, ok := e.config.CategoryMap[strings.ToLower(issue.Category)]
+		if !ok {
+			category = e.config.DefaultCategory
+		}
+		penalties[category] += e.config.SeverityPenalties[issue.Severity]
+		if issue.Severity == "critical" {
+			hasCritical = true
+		}
+	}
+
+	computed := &models.ComputedScores{
+		MechanicalScore: categoryScore(penalties[ScoreCategoryMechanical]),
+		ExteriorScore:   categoryScore(penalties[ScoreCategoryExterior]),
+		InteriorScore:   categoryScore(penalties[ScoreCategoryInterior]),
+		ComputedAt:      time.Now(),
+	}
+
+	scores := map[string]int{
+		ScoreCategoryMechanical: computed.MechanicalScore,
+		ScoreCategoryExterior:   computed.ExteriorScore,
+		ScoreCategoryInterior:   computed.InteriorScore,
+	}
+
+	weighted, totalWeight := 0.0, 0.0
+	for category, weight := range e.config.CategoryWeights {
+		weighted += float64(scores[category]) * weight
+		totalWeight += weight
+	}
+	if totalWeight > 0 {
+		computed.OverallScore = int(math.Round(weighted / totalWeight))
+	}
+
+	computed.OverallCondition = e.condition(float64(computed.OverallScore))
+	if hasCritical && e.config.CriticalConditionCap != "" &&
+		conditionRank[computed.OverallCondition] > conditionRank[e.config.CriticalConditionCap] {
+		computed.OverallCondition = e.config.CriticalConditionCap
+	}
+
+	computed.Mismatches = e.mismatches(report, computed)
+	computed.HasMismatch = len(computed.Mismatches) > 0
+
+	return computed
+}
+
+// condition maps an overall score to the best grade whose threshold it meets
+func (e *ScoringEngine) condition(score float64) string {
+	best := "poor"
+	for grade, threshold := range e.config.ConditionThresholds {
+		if score >= threshold && conditionRank[grade] > conditionRank[best] {
+			best = grade
+		}
+	}
+	return best
+}
+
+// mismatches lists the submitted values that disagree with the computed ones
+func (e *ScoringEngine) mismatches(report *models.InspectionReport, computed *models.ComputedScores) []models.ScoreMismatch {
+	mismatches := []models.ScoreMismatch{}
+
+	if report.OverallCondition != computed.OverallCondition {
+		mismatches = append(mismatches, models.ScoreMismatch{
+			Field:     "overallCondition",
+			Submitted: report.OverallCondition,
+			Computed:  computed.OverallCondition,
+		})
+	}
+
+	fields := []struct {
+		name      string
+		submitted int
+		computed  int
+	}{
+		{"mechanicalScore", report.MechanicalScore, computed.MechanicalScore},
+		{"exteriorScore", report.ExteriorScore, computed.ExteriorScore},
+		{"interiorScore", report.InteriorScore, computed.InteriorScore},
+	}
+
+	for _, f := range fields {
+		diff := f.submitted - f.computed
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > e.config.ScoreTolerance {
+			mismatches = append(mismatches, models.ScoreMismatch{
+				Field:     f.name,
+				Submitted: strconv.Itoa(f.submitted),
+				Computed:  strconv.Itoa(f.computed),
+			})
+		}
+	}
+
+	return mismatches
+}
+
+// categoryScore converts accumulated penalty points into a 0-100 score
+func categoryScore(penalty float64) int {
+	return int(math.Round(math.Max(0, 100-penalty)))
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+func TestScoringEngine_Score(t *testing.T) {
+	engine := NewScoringEngine(DefaultScoringConfig())
+
+	t.Run("clean report is excellent", func(t *testing.T) {
+		report := &models.InspectionReport{OverallCondition: "excellent", MechanicalScore: 100, ExteriorScore: 98, InteriorScore: 95}
+		computed := engine.Score(report)
+
+		assert.Equal(t, 100, computed.OverallScore)
+		assert.Equal(t, "excellent", computed.OverallCondition)
+		assert.False(t, computed.HasMismatch)
+		assert.Empty(t, computed.Mismatches)
+	})
+
+	t.Run("penalties are applied per category", func(t *testing.T) {
+		report := &models.InspectionReport{
+			OverallCondition: "good",
+			MechanicalScore:  80,
+			ExteriorScore:    95,
+			InteriorScore:    100,
+			Issues: []models.InspectionIssue{
+				{Category: "engine", Severity: "major", Description: "Oil leak"},
+				{Category: "Brakes", Severity: "minor", Description: "Worn pads"},
+				{Category: "paint", Severity: "minor", Description: "Scratch"},
+			},
+		}
+		computed := engine.Score(report)
+
+		assert.Equal(t, 80, computed.MechanicalScore)
+		assert.Equal(t, 95, computed.ExteriorScore)
+		assert.Equal(t, 100, computed.InteriorScore)
+		// 0.5*80 + 0.25*95 + 0.25*100 = 88.75
+		assert.Equal(t, 89, computed.OverallScore)
+		assert.Equal(t, "good", computed.OverallCondition)
+		assert.False(t, computed.HasMismatch)
+	})
+
+	t.Run("critical issues cap the grade and flag a contradicting submission", func(t *testing.T) {
+		report := &models.InspectionReport{
+			OverallCondition: "excellent",
+			MechanicalScore:  95,
+			ExteriorScore:    95,
+			InteriorScore:    95,
+			Issues: []models.InspectionIssue{
+				{Category: "body", Severity: "critical", Description: "Frame damage"},
+			},
+		}
+		computed := engine.Score(report)
+
+		assert.Equal(t, "poor", computed.OverallCondition)
+		assert.True(t, computed.HasMismatch)
+		assert.Contains(t, computed.Mismatches, models.ScoreMismatch{Field: "overallCondition", Submitted: "excellent", Computed: "poor"})
+		assert.Contains(t, computed.Mismatches, models.ScoreMismatch{Field: "exteriorScore", Submitted: "95", Computed: "60"})
+	})
+
+	t.Run("unmapped categories use the default category", func(t *testing.T) {
+		report := &models.InspectionReport{
+			Issues: []models.InspectionIssue{{Category: "exhaust", Severity: "major", Description: "Rattle"}},
+		}
+		assert.Equal(t, 85, engine.Score(report).MechanicalScore)
+	})
+
+	t.Run("scores never go below zero", func(t *testing.T) {
+		issues := make([]models.InspectionIssue, 5)
+		for i := range issues {
+			issues[i] = models.InspectionIssue{Category: "interior", Severity: "critical", Description: "Damage"}
+		}
+		assert.Equal(t, 0, engine.Score(&models.InspectionReport{Issues: issues}).InteriorScore)
+	})
+}
+
+func TestLoadScoringConfig(t *testing.T) {
+	t.Run("empty path uses defaults", func(t *testing.T) {
+		cfg, err := LoadScoringConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultScoringConfig(), cfg)
+	})
+
+	t.Run("file overrides defaults", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "scoring.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"severityPenalties":{"critical":60,"major":20,"minor":5},"scoreTolerance":5}`), 0o600))
+
+		cfg, err := LoadScoringConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, 60.0, cfg.SeverityPenalties["critical"])
+		assert.Equal(t, 5, cfg.ScoreTolerance)
+		assert.Equal(t, 0.5, cfg.CategoryWeights[ScoreCategoryMechanical])
+	})
+
+	t.Run("invalid weights are rejected", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "scoring.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"criticalConditionCap":"terrible"}`), 0o600))
+
+		_, err := LoadScoringConfig(path)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/seal"
+)
+
+// errSealNotFound is returned when no inspection carries the verification code
+var errSealNotFound = apperrors.NewNotFoundError("verification code not found")
+
+// Verification codes avoid characters that are easily confused when read aloud or copied by hand
+const (
+	sealCodeAlphabet = "ABCDEFGHJKMNPQRSTVWXYZ23456789"
+	sealCodeLength   = 10
+)
+
+// Reasons a seal no longer holds
+const (
+	sealReasonUnknownKey = "inspection was sealed with a key this server no longer holds"
+	sealReasonSignature  = "seal signature does not match the signed summary"
+	sealReasonModified   = "inspection has been modified since it was sealed"
+)
+
+// EnsureIndexes creates the unique index on verification codes
+func (s *InspectionService) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "seal.code", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"seal.code": bson.M{"$exists": true}}),
+	})
+	return err
+}
+
+// sealInspection signs the inspection as it will be stored on completion
+func (s *InspectionService) sealInspection(inspection *models.Inspection, vehicle string, signedAt time.Time) (*models.InspectionSeal, error) {
+	code, err := newSealCode()
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := sealPayload(inspection)
+	if err != nil {
+		return nil, err
+	}
+	payload.Code = code
+	payload.Vehicle = vehicle
+	payload.SignedAt = sealTime(signedAt)
+
+	signature, err := s.signer.Sign(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.InspectionSeal{
+		Code:      code,
+		Payload:   payload,
+		Signature: signature,
+		KeyID:     s.signer.KeyID(),
+	}, nil
+}
+
+// VerifyInspection checks the seal of the inspection with the given verification code
+// The signature proves the summary came from this server; recomputing the summary from the stored
+// inspection proves nothing has changed since
+func (s *InspectionService) VerifyInspection(ctx context.Context, code string) (*models.InspectionVerification, error) {
+	var inspection models.Inspection
+	err := s.collection.FindOne(ctx, bson.M{"seal.code": normalizeSealCode(code)}).Decode(&inspection)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errSealNotFound
+		}
+		return nil, err
+	}
+
+	stored := inspection.Seal
+	result := &models.InspectionVerification{
+		Summary:   stored.Payload,
+		Signature: stored.Signature,
+		Algorithm: seal.Algorithm,
+		KeyID:     stored.KeyID,
+		PublicKey: s.signer.PublicKey(),
+	}
+	result.Reason = s.checkSeal(&inspection)
+	result.Valid = result.Reason == ""
+
+	return result, nil
+}
+
+// checkSeal returns why the inspection's seal no longer holds, or "" if it is intact
+func (s *InspectionService) checkSeal(inspection *models.Inspection) string {
+	stored := inspection.Seal
+	if stored.KeyID != s.signer.KeyID() {
+		return sealReasonUnknownKey
+	}
+	if !s.signer.Verify(stored.Payload, stored.Signature) {
+		return sealReasonSignature
+	}
+
+	if inspection.Status != models.InspectionStatusCompleted || stored.Code != stored.Payload.Code {
+		return sealReasonModified
+	}
+
+	expected, err := sealPayload(inspection)
+	if err != nil {
+		return sealReasonModified
+	}
+	expected.Code = stored.Payload.Code
+	expected.Vehicle = stored.Payload.Vehicle
+	expected.SignedAt = stored.Payload.SignedAt
+	if expected != stored.Payload {
+		return sealReasonModified
+	}
+
+	return ""
+}
+
+// sealPayload derives the signed summary fields from the inspection
+// Code, Vehicle and SignedAt are fixed at signing time and left for the caller to fill in
+func sealPayload(inspection *models.Inspection) (models.InspectionSealPayload, error) {
+	// Hash the report as it reads back from MongoDB, which drops sub-millisecond precision and time zones
+	raw, err := bson.Marshal(inspection.Report)
+	if err != nil {
+		return models.InspectionSealPayload{}, err
+	}
+	var report models.InspectionReport
+	if err := bson.Unmarshal(raw, &report); err != nil {
+		return models.InspectionSealPayload{}, err
+	}
+
+	digest, err := seal.Digest(sealedReport{
+		InspectionReport: report,
+		EstimatedRepairs: json.Number(strconv.FormatFloat(report.EstimatedRepairs.Float64(), 'f', -1, 64)),
+	})
+	if err != nil {
+		return models.InspectionSealPayload{}, err
+	}
+
+	payload := models.InspectionSealPayload{
+		InspectionID:     inspection.ID.Hex(),
+		VehicleID:        inspection.VehicleID.Hex(),
+		OverallCondition: report.OverallCondition,
+		ReportDigest:     digest,
+	}
+	if !inspection.InspectorID.IsZero() {
+		payload.InspectorID = inspection.InspectorID.Hex()
+	}
+	if inspection.CompletedAt != nil {
+		payload.CompletedAt = sealTime(*inspection.CompletedAt)
+	}
+	if report.Computed != nil {
+		payload.OverallCondition = report.Computed.OverallCondition
+		payload.OverallScore = report.Computed.OverallScore
+	}
+
+	return payload, nil
+}
+
+// sealedReport is the view of a report that is digested for its seal
+// Repairs are digested as the bare number reports held before amounts carried a currency, so earlier seals still verify
+type sealedReport struct {
+	models.InspectionReport
+	EstimatedRepairs json.Number `json:"estimatedRepairs"`
+}
+
+// sealTime formats a time the way it survives a MongoDB round trip
+func sealTime(t time.Time) string {
+	return t.UTC().Truncate(time.Millisecond).Format("2006-01-02T15:04:05.000Z")
+}
+
+// newSealCode generates a random verification code such as "K7QX3-M9TPA"
+func newSealCode() (string, error) {
+	var b strings.Builder
+	limit := big.NewInt(int64(len(sealCodeAlphabet)))
+	for i := 0; i < sealCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(sealCodeAlphabet[n.Int64()])
+	}
+	return normalizeSealCode(b.String()), nil
+}
+
+// normalizeSealCode canonicalizes a code typed by hand: upper case, with a single dash in the middle
+func normalizeSealCode(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(code) != sealCodeLength {
+		return code
+	}
+	return code[:sealCodeLength/2] + "-" + code[sealCodeLength/2:]
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+	"github.com/Over-knight/Lujay-assesment/internal/seal"
+)
+
+// sealedInspection returns a completed inspection sealed by s, after a BSON round trip like a stored document
+func sealedInspection(t *testing.T, s *InspectionService) *models.Inspection {
+	t.Helper()
+
+	// Sub-millisecond precision and a non-UTC zone are lost when stored
+	completedAt := time.Date(2025, 5, 1, 10, 30, 0, 123456789, time.FixedZone("WAT", 3600))
+	inspection := &models.Inspection{
+		ID:          primitive.NewObjectID(),
+		VehicleID:   primitive.NewObjectID(),
+		InspectorID: primitive.NewObjectID(),
+		Status:      models.InspectionStatusCompleted,
+		CompletedAt: &completedAt,
+		Report: models.InspectionReport{
+			OverallCondition: "good",
+			MechanicalScore:  80,
+			ExteriorScore:    85,
+			InteriorScore:    90,
+			EstimatedRepairs: money.MustParse("1200.75", "USD"),
+			Issues:           []models.InspectionIssue{{Category: "body", Severity: "minor", Description: "Dent"}},
+			Computed:         &models.ComputedScores{OverallCondition: "good", OverallScore: 84, ComputedAt: completedAt},
+		},
+	}
+
+	sealed, err := s.sealInspection(inspection, "2018 Toyota Corolla", completedAt)
+	require.NoError(t, err)
+	inspection.Seal = sealed
+
+	raw, err := bson.Marshal(inspection)
+	require.NoError(t, err)
+	var stored models.Inspection
+	require.NoError(t, bson.Unmarshal(raw, &stored))
+	return &stored
+}
+
+func newSealingService(t *testing.T) *InspectionService {
+	t.Helper()
+	signer, err := seal.GenerateSigner()
+	require.NoError(t, err)
+	return &InspectionService{signer: signer}
+}
+
+func TestCheckSeal_Intact(t *testing.T) {
+	s := newSealingService(t)
+	inspection := sealedInspection(t, s)
+
+	assert.Equal(t, "", s.checkSeal(inspection))
+	assert.Equal(t, "good", inspection.Seal.Payload.OverallCondition)
+	assert.Equal(t, 84, inspection.Seal.Payload.OverallScore)
+	assert.Equal(t, "2025-05-01T09:30:00.123Z", inspection.Seal.Payload.CompletedAt)
+	assert.Regexp(t, `^[A-Z2-9]{5}-[A-Z2-9]{5}$`, inspection.Seal.Code)
+}
+
+func TestCheckSeal_Broken(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(inspection *models.Inspection)
+		reason string
+	}{
+		{
+			name: "report edited",
+			modify: func(inspection *models.Inspection) {
+				inspection.Report.EstimatedRepairs = money.MustParse("100", "USD")
+			},
+			reason: sealReasonModified,
+		},
+		{
+			name:   "issue removed",
+			modify: func(inspection *models.Inspection) { inspection.Report.Issues = nil },
+			reason: sealReasonModified,
+		},
+		{
+			name:   "inspector changed",
+			modify: func(inspection *models.Inspection) { inspection.InspectorID = primitive.NewObjectID() },
+			reason: sealReasonModified,
+		},
+		{
+			name:   "status reverted",
+			modify: func(inspection *models.Inspection) { inspection.Status = models.InspectionStatusInProgress },
+			reason: sealReasonModified,
+		},
+		{
+			name: "summary edited to match",
+			modify: func(inspection *models.Inspection) {
+				inspection.Report.Computed.OverallCondition = "excellent"
+				inspection.Seal.Payload.OverallCondition = "excellent"
+			},
+			reason: sealReasonSignature,
+		},
+		{
+			name:   "signed by another key",
+			modify: func(inspection *models.Inspection) { inspection.Seal.KeyID = "0000000000000000" },
+			reason: sealReasonUnknownKey,
+		},
+	}
+
+	s := newSealingService(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inspection := sealedInspection(t, s)
+			tt.modify(inspection)
+			assert.Equal(t, tt.reason, s.checkSeal(inspection))
+		})
+	}
+}
+
+func TestSealedReport_DigestsRepairsAsNumber(t *testing.T) {
+	// Reports sealed before repairs carried a currency held a bare number; their digests must not change
+	canonical, err := seal.CanonicalJSON(sealedReport{
+		InspectionReport: models.InspectionReport{OverallCondition: "good", EstimatedRepairs: money.MustParse("1200.50", "USD")},
+		EstimatedRepairs: "1200.5",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(canonical), `"estimatedRepairs":1200.5`)
+	assert.NotContains(t, string(canonical), "USD")
+}
+
+func TestNormalizeSealCode(t *testing.T) {
+	assert.Equal(t, "K7QX3-M9TPA", normalizeSealCode("k7qx3m9tpa"))
+	assert.Equal(t, "K7QX3-M9TPA", normalizeSealCode(" K7QX3-M9TPA"))
+	assert.Equal(t, "SHORT", normalizeSealCode("short"))
+}
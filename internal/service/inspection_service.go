@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -10,38 +12,92 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
 	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+	"github.com/Over-knight/Lujay-assesment/internal/seal"
 )
 
+// errInspectionModified is returned when a conditional lifecycle update loses a race
+var errInspectionModified = apperrors.NewConflictError("inspection was modified concurrently, please retry")
+
+// defaultSlotSearchWindow is how far ahead slots are listed when no end time is given
+const defaultSlotSearchWindow = 7 * 24 * time.Hour
+
 // InspectionService handles inspection-related business logic
 type InspectionService struct {
-	collection *mongo.Collection
+	collection        *mongo.Collection
+	userCollection    *mongo.Collection
+	vehicleCollection *mongo.Collection
+	stateMachine      *InspectionStateMachine
+	assigner          *InspectorAssigner
+	availability      *AvailabilityService
+	templates         *InspectionTemplateService
+	scoring           *ScoringEngine
+	signer            *seal.Signer
+	media             MediaStore // optional; removes issue evidence with deleted inspections
 }
 
 // NewInspectionService creates a new inspection service
-func NewInspectionService(db *mongo.Database) *InspectionService {
+func NewInspectionService(db *mongo.Database, availability *AvailabilityService, templates *InspectionTemplateService, scoring *ScoringEngine, signer *seal.Signer, media MediaStore) *InspectionService {
 	return &InspectionService{
-		collection: db.Collection("inspections"),
+		collection:        db.Collection("inspections"),
+		userCollection:    db.Collection("users"),
+		vehicleCollection: db.Collection("vehicles"),
+		stateMachine:      NewInspectionStateMachine(),
+		assigner:          NewInspectorAssigner(db),
+		availability:      availability,
+		templates:         templates,
+		scoring:           scoring,
+		signer:            signer,
+		media:             media,
 	}
 }
 
-// CreateInspection creates a new inspection
-func (s *InspectionService) CreateInspection(ctx context.Context, req *models.CreateInspectionRequest, inspectorID primitive.ObjectID) (*models.Inspection, error) {
+// CreateInspection records an inspection request from a buyer or seller
+// The request starts pending and is auto-assigned to the best inspector serving the vehicle's location
+// who is free at the requested time
+func (s *InspectionService) CreateInspection(ctx context.Context, req *models.CreateInspectionRequest, requesterID primitive.ObjectID) (*models.Inspection, error) {
 	vehicleID, err := primitive.ObjectIDFromHex(req.VehicleID)
 	if err != nil {
 		return nil, errors.New("invalid vehicleId")
 	}
 
+	var vehicle models.Vehicle
+	err = s.vehicleCollection.FindOne(ctx, bson.M{"_id": vehicleID}).Decode(&vehicle)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("vehicle not found")
+		}
+		return nil, err
+	}
+
 	now := time.Now()
 	inspection := &models.Inspection{
 		VehicleID:   vehicleID,
-		InspectorID: inspectorID,
-		Status:      models.InspectionStatusScheduled,
+		RequestedBy: requesterID,
+		Status:      models.InspectionStatusPending,
 		ScheduledAt: req.ScheduledAt,
 		Notes:       req.Notes,
 		Report:      models.InspectionReport{},
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		Transitions: []models.InspectionTransition{
+			{
+				To:        models.InspectionStatusPending,
+				ActorID:   requesterID,
+				ActorRole: InspectionRoleRequester,
+				ChangedAt: now,
+			},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if req.TemplateKey != "" {
+		template, err := s.templates.GetActiveTemplate(ctx, req.TemplateKey)
+		if err != nil {
+			return nil, err
+		}
+		inspection.Template = template.Ref()
 	}
 
 	result, err := s.collection.InsertOne(ctx, inspection)
@@ -50,7 +106,106 @@ func (s *InspectionService) CreateInspection(ctx context.Context, req *models.Cr
 	}
 
 	inspection.ID = result.InsertedID.(primitive.ObjectID)
-	return inspection, nil
+
+	// Leave the request pending for an admin when nobody can take it at the requested time
+	actor := InspectionActor{Role: InspectionRoleSystem}
+	assigned, err := s.autoAssign(ctx, inspection, vehicle.Location, actor, "auto-assigned")
+	if err != nil {
+		return inspection, nil
+	}
+
+	return assigned, nil
+}
+
+// AssignInspector assigns or reassigns an inspection to an inspector on behalf of an admin
+// When no inspector is given the auto-assigner picks one by location and workload
+func (s *InspectionService) AssignInspector(ctx context.Context, id string, req *models.AssignInspectorRequest, adminID primitive.ObjectID) (*models.Inspection, error) {
+	inspection, err := s.GetInspectionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := InspectionActor{ID: adminID, Role: InspectionRoleAdmin}
+
+	if req.InspectorID == "" {
+		var vehicle models.Vehicle
+		err = s.vehicleCollection.FindOne(ctx, bson.M{"_id": inspection.VehicleID}).Decode(&vehicle)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, errors.New("vehicle not found")
+			}
+			return nil, err
+		}
+
+		return s.autoAssign(ctx, inspection, vehicle.Location, actor, req.Reason)
+	}
+
+	inspectorID, err := primitive.ObjectIDFromHex(req.InspectorID)
+	if err != nil {
+		return nil, errors.New("invalid inspectorId")
+	}
+
+	var inspector models.User
+	err = s.userCollection.FindOne(ctx, bson.M{"_id": inspectorID}).Decode(&inspector)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("inspector not found")
+		}
+		return nil, err
+	}
+	if inspector.Role != models.RoleInspector {
+		return nil, errors.New("user is not an inspector")
+	}
+
+	return s.assign(ctx, inspection, inspectorID, actor, models.AssignmentMethodManual, req.Reason)
+}
+
+// autoAssign assigns the inspection to the best-ranked inspector for the location who is free at its scheduled time
+func (s *InspectionService) autoAssign(ctx context.Context, inspection *models.Inspection, location models.Location, actor InspectionActor, reason string) (*models.Inspection, error) {
+	candidates, err := s.assigner.Candidates(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range candidates {
+		if candidate.Inspector.ID == inspection.InspectorID {
+			continue
+		}
+
+		assigned, err := s.assign(ctx, inspection, candidate.Inspector.ID, actor, models.AssignmentMethodAuto, reason)
+		if err == nil {
+			return assigned, nil
+		}
+		if !isSlotUnavailable(err) {
+			return nil, err
+		}
+	}
+
+	return nil, errors.New("no inspector available for this location")
+}
+
+// assign points the inspection at an inspector, books the inspector's slot and schedules it
+func (s *InspectionService) assign(ctx context.Context, inspection *models.Inspection, inspectorID primitive.ObjectID, actor InspectionActor, method, reason string) (*models.Inspection, error) {
+	candidate := *inspection
+	candidate.InspectorID = inspectorID
+
+	if reason == "" {
+		reason = "assigned to inspector " + inspectorID.Hex()
+	}
+
+	entry, err := s.stateMachine.Transition(&candidate, models.InspectionStatusScheduled, actor, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.M{
+		"status":           models.InspectionStatusScheduled,
+		"inspectorId":      inspectorID,
+		"assignedAt":       entry.ChangedAt,
+		"assignmentMethod": method,
+	}
+
+	return s.bookAndApply(ctx, inspection, inspectorID, candidate.ScheduledAt, set, entry)
 }
 
 // GetInspectionByID retrieves an inspection by ID
@@ -123,121 +278,360 @@ func (s *InspectionService) GetInspectionsByInspector(ctx context.Context, inspe
 	return inspections, nil
 }
 
-// UpdateInspection updates an inspection
-func (s *InspectionService) UpdateInspection(ctx context.Context, id string, req *models.UpdateInspectionRequest) (*models.Inspection, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+// GetInspectorQueue retrieves an inspector's active inspections, soonest first
+func (s *InspectionService) GetInspectorQueue(ctx context.Context, inspectorID primitive.ObjectID) ([]models.Inspection, error) {
+	filter := bson.M{
+		"inspectorId": inspectorID,
+		"status":      bson.M{"$in": activeInspectionStatuses},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "scheduledAt", Value: 1}})
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
 	if err != nil {
-		return nil, errors.New("invalid inspection ID")
+		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	update := bson.M{
-		"$set": bson.M{
-			"updatedAt": time.Now(),
-		},
+	var inspections []models.Inspection
+	if err = cursor.All(ctx, &inspections); err != nil {
+		return nil, err
 	}
 
+	if inspections == nil {
+		inspections = []models.Inspection{}
+	}
+
+	return inspections, nil
+}
+
+// UpdateInspection updates an inspection
+// Status and schedule changes go through the state machine; the report can only be drafted while in progress
+func (s *InspectionService) UpdateInspection(ctx context.Context, id string, req *models.UpdateInspectionRequest, userID primitive.ObjectID) (*models.Inspection, error) {
+	inspection, actor, err := s.getInspectionForActor(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	target := inspection.Status
 	if req.Status != "" {
-		update["$set"].(bson.M)["status"] = req.Status
+		target = req.Status
 	}
 
+	// Completion validates and seals the report, so it must use the dedicated endpoint
+	if target == models.InspectionStatusCompleted && inspection.Status != models.InspectionStatusCompleted {
+		return nil, apperrors.NewInvalidStateTransitionError("inspections can only be completed through the complete endpoint")
+	}
+
+	set := bson.M{}
+	candidate := *inspection
+
 	if req.ScheduledAt != nil {
-		update["$set"].(bson.M)["scheduledAt"] = *req.ScheduledAt
+		if target != models.InspectionStatusPending && target != models.InspectionStatusScheduled {
+			return nil, apperrors.NewInvalidStateTransitionError("scheduledAt can only be changed on pending or scheduled inspections")
+		}
+		candidate.ScheduledAt = *req.ScheduledAt
+		set["scheduledAt"] = *req.ScheduledAt
+	}
+
+	if req.TemplateKey != "" {
+		if target != models.InspectionStatusPending && target != models.InspectionStatusScheduled {
+			return nil, apperrors.NewInvalidStateTransitionError("the template can only be changed on pending or scheduled inspections")
+		}
+		template, err := s.templates.GetActiveTemplate(ctx, req.TemplateKey)
+		if err != nil {
+			return nil, err
+		}
+		set["template"] = template.Ref()
 	}
 
 	if req.Report != nil {
-		update["$set"].(bson.M)["report"] = req.Report
+		if actor.Role != InspectionRoleInspector && actor.Role != InspectionRoleAdmin {
+			return nil, errors.New("you are not authorized to modify this inspection")
+		}
+		if inspection.Status != models.InspectionStatusInProgress || target != models.InspectionStatusInProgress {
+			return nil, apperrors.NewInvalidStateTransitionError("the report can only be edited while the inspection is in progress")
+		}
+		// Drafts are never scored; scores are computed on completion
+		draft := *req.Report
+		draft.Computed = nil
+		if draft.EstimatedRepairs, err = draft.EstimatedRepairs.WithCurrency(money.DefaultCurrency); err != nil {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("estimatedRepairs: %v", err))
+		}
+		reconcileIssueMedia(&draft, inspection)
+		set["report"] = draft
 	}
 
 	if req.Notes != "" {
-		update["$set"].(bson.M)["notes"] = req.Notes
+		set["notes"] = req.Notes
 	}
 
-	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	var inspection models.Inspection
-	err = s.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update, opts).Decode(&inspection)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("inspection not found")
+	// A new status, or a new time on a scheduled inspection, is a lifecycle transition
+	var entry *models.InspectionTransition
+	if target != inspection.Status || (req.ScheduledAt != nil && target == models.InspectionStatusScheduled) {
+		entry, err = s.stateMachine.Transition(&candidate, target, actor, req.Notes)
+		if err != nil {
+			return nil, err
 		}
+		set["status"] = target
+	}
+
+	return s.bookAndApply(ctx, inspection, inspection.InspectorID, candidate.ScheduledAt, set, entry)
+}
+
+// StartInspection moves a scheduled inspection to in progress
+func (s *InspectionService) StartInspection(ctx context.Context, id string, userID primitive.ObjectID) (*models.Inspection, error) {
+	inspection, actor, err := s.getInspectionForActor(ctx, id, userID)
+	if err != nil {
 		return nil, err
 	}
 
-	return &inspection, nil
+	entry, err := s.stateMachine.Transition(inspection, models.InspectionStatusInProgress, actor, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.applyUpdate(ctx, inspection, bson.M{"status": models.InspectionStatusInProgress}, entry)
 }
 
-// CompleteInspection completes an inspection with a report
-func (s *InspectionService) CompleteInspection(ctx context.Context, id string, req *models.CompleteInspectionRequest) (*models.Inspection, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+// RescheduleInspection moves a pending or scheduled inspection to a new time
+func (s *InspectionService) RescheduleInspection(ctx context.Context, id string, req *models.RescheduleInspectionRequest, userID primitive.ObjectID) (*models.Inspection, error) {
+	inspection, actor, err := s.getInspectionForActor(ctx, id, userID)
 	if err != nil {
-		return nil, errors.New("invalid inspection ID")
+		return nil, err
 	}
 
-	now := time.Now()
-	update := bson.M{
-		"$set": bson.M{
-			"status":      models.InspectionStatusCompleted,
-			"report":      req.Report,
-			"completedAt": now,
-			"updatedAt":   now,
-		},
+	candidate := *inspection
+	candidate.ScheduledAt = req.ScheduledAt
+	entry, err := s.stateMachine.Transition(&candidate, models.InspectionStatusScheduled, actor, req.Reason)
+	if err != nil {
+		return nil, err
 	}
 
-	if req.Notes != "" {
-		update["$set"].(bson.M)["notes"] = req.Notes
+	set := bson.M{
+		"status":      models.InspectionStatusScheduled,
+		"scheduledAt": req.ScheduledAt,
 	}
 
-	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	var inspection models.Inspection
-	err = s.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update, opts).Decode(&inspection)
+	return s.bookAndApply(ctx, inspection, inspection.InspectorID, req.ScheduledAt, set, entry)
+}
+
+// CompleteInspection completes an in-progress inspection with a report
+// Only the assigned inspector or an admin may complete an inspection
+func (s *InspectionService) CompleteInspection(ctx context.Context, id string, req *models.CompleteInspectionRequest, userID primitive.ObjectID) (*models.Inspection, error) {
+	inspection, actor, err := s.getInspectionForActor(ctx, id, userID)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("inspection not found")
+		return nil, err
+	}
+
+	entry, err := s.stateMachine.Transition(inspection, models.InspectionStatusCompleted, actor, req.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate against the pinned version, not the template's current one
+	if inspection.Template != nil {
+		template, err := s.templates.GetTemplateByID(ctx, inspection.Template.ID.Hex())
+		if err != nil {
+			return nil, err
+		}
+		if err := template.ValidateAnswers(req.Report.Checklist); err != nil {
+			return nil, apperrors.NewValidationError(err.Error())
 		}
+	}
+
+	// Keep the inspector's values and store the engine's scores alongside them
+	report := req.Report
+	if report.EstimatedRepairs, err = report.EstimatedRepairs.WithCurrency(money.DefaultCurrency); err != nil {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("estimatedRepairs: %v", err))
+	}
+	reconcileIssueMedia(&report, inspection)
+	report.Computed = s.scoring.Score(&report)
+
+	// Seal the inspection exactly as it will be stored
+	completed := *inspection
+	completed.Status = models.InspectionStatusCompleted
+	completed.Report = report
+	completed.CompletedAt = &entry.ChangedAt
+
+	var vehicle models.Vehicle
+	description := ""
+	err = s.vehicleCollection.FindOne(ctx, bson.M{"_id": inspection.VehicleID}).Decode(&vehicle)
+	if err == nil {
+		description = fmt.Sprintf("%d %s %s", vehicle.Year, vehicle.Make, vehicle.Model)
+	} else if err != mongo.ErrNoDocuments {
 		return nil, err
 	}
 
-	return &inspection, nil
+	sealed, err := s.sealInspection(&completed, description, entry.ChangedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.M{
+		"status":      models.InspectionStatusCompleted,
+		"report":      report,
+		"completedAt": entry.ChangedAt,
+		"seal":        sealed,
+	}
+
+	if req.Notes != "" {
+		set["notes"] = req.Notes
+	}
+
+	return s.applyUpdate(ctx, inspection, set, entry)
 }
 
-// CancelInspection cancels an inspection
-func (s *InspectionService) CancelInspection(ctx context.Context, id string, notes string) (*models.Inspection, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+// CancelInspection cancels an inspection that has not been completed
+func (s *InspectionService) CancelInspection(ctx context.Context, id string, notes string, userID primitive.ObjectID) (*models.Inspection, error) {
+	inspection, actor, err := s.getInspectionForActor(ctx, id, userID)
 	if err != nil {
-		return nil, errors.New("invalid inspection ID")
+		return nil, err
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"status":    models.InspectionStatusCancelled,
-			"updatedAt": time.Now(),
-		},
+	entry, err := s.stateMachine.Transition(inspection, models.InspectionStatusCancelled, actor, notes)
+	if err != nil {
+		return nil, err
 	}
 
+	set := bson.M{"status": models.InspectionStatusCancelled}
+
 	if notes != "" {
-		update["$set"].(bson.M)["notes"] = notes
+		set["notes"] = notes
 	}
 
+	cancelled, err := s.applyUpdate(ctx, inspection, set, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	// Free the inspector's slot for other bookings
+	if err := s.availability.ReleaseInspection(ctx, inspection.ID); err != nil {
+		return nil, err
+	}
+
+	return cancelled, nil
+}
+
+// getInspectionForActor loads an inspection and resolves the caller's lifecycle role on it
+func (s *InspectionService) getInspectionForActor(ctx context.Context, id string, userID primitive.ObjectID) (*models.Inspection, InspectionActor, error) {
+	inspection, err := s.GetInspectionByID(ctx, id)
+	if err != nil {
+		return nil, InspectionActor{}, err
+	}
+
+	if !inspection.InspectorID.IsZero() && inspection.InspectorID == userID {
+		return inspection, InspectionActor{ID: userID, Role: InspectionRoleInspector}, nil
+	}
+
+	var user models.User
+	err = s.userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, InspectionActor{}, err
+	}
+
+	if err == nil && user.Role == models.RoleAdmin {
+		return inspection, InspectionActor{ID: userID, Role: InspectionRoleAdmin}, nil
+	}
+
+	if inspection.RequestedBy == userID {
+		return inspection, InspectionActor{ID: userID, Role: InspectionRoleRequester}, nil
+	}
+
+	return nil, InspectionActor{}, errors.New("you are not authorized to modify this inspection")
+}
+
+// bookAndApply reserves the inspector's slot at scheduledAt before applying the update, then frees the
+// slot the inspection held before; the new reservation is rolled back if the update fails
+func (s *InspectionService) bookAndApply(ctx context.Context, inspection *models.Inspection, inspectorID primitive.ObjectID, scheduledAt time.Time, set bson.M, entry *models.InspectionTransition) (*models.Inspection, error) {
+	if inspectorID.IsZero() || holdsSlot(inspection, inspectorID, scheduledAt) {
+		return s.applyUpdate(ctx, inspection, set, entry)
+	}
+
+	if err := s.availability.ReserveSlot(ctx, inspectorID, scheduledAt, inspection.ID); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.applyUpdate(ctx, inspection, set, entry)
+	if err != nil {
+		// A concurrent reschedule that won may have booked the same slot, which this inspection then still needs
+		var current models.Inspection
+		findErr := s.collection.FindOne(ctx, bson.M{"_id": inspection.ID}).Decode(&current)
+		if findErr != nil || !holdsSlot(&current, inspectorID, scheduledAt) {
+			_ = s.availability.ReleaseSlot(ctx, inspectorID, scheduledAt, inspection.ID)
+		}
+		return nil, err
+	}
+
+	if !inspection.InspectorID.IsZero() {
+		if err := s.availability.ReleaseSlot(ctx, inspection.InspectorID, inspection.ScheduledAt, inspection.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return updated, nil
+}
+
+// applyUpdate writes the changes only if the inspection is still in the status, and with the inspector
+// and time, it was read with, appending the transition to the lifecycle log when one was applied
+func (s *InspectionService) applyUpdate(ctx context.Context, inspection *models.Inspection, set bson.M, entry *models.InspectionTransition) (*models.Inspection, error) {
+	set["updatedAt"] = time.Now()
+	update := bson.M{"$set": set}
+
+	if entry != nil {
+		update["$push"] = bson.M{"transitions": entry}
+	}
+
+	filter := unchangedInspectionFilter(inspection)
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	var inspection models.Inspection
-	err = s.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update, opts).Decode(&inspection)
+	var updated models.Inspection
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("inspection not found")
+			return nil, errInspectionModified
 		}
 		return nil, err
 	}
 
-	return &inspection, nil
+	return &updated, nil
+}
+
+// unchangedInspectionFilter matches the inspection while it is still as it was read, so of two concurrent
+// reschedules or reassignments only one applies and the inspection keeps matching its reservation
+func unchangedInspectionFilter(inspection *models.Inspection) bson.M {
+	filter := bson.M{"_id": inspection.ID, "status": inspection.Status}
+	if inspection.InspectorID.IsZero() {
+		filter["inspectorId"] = bson.M{"$exists": false}
+	} else {
+		filter["inspectorId"] = inspection.InspectorID
+	}
+	if inspection.ScheduledAt.IsZero() {
+		filter["scheduledAt"] = bson.M{"$exists": false}
+	} else {
+		filter["scheduledAt"] = inspection.ScheduledAt
+	}
+	return filter
 }
 
-// DeleteInspection deletes an inspection
+// holdsSlot reports whether the inspection is booked with the inspector at scheduledAt
+func holdsSlot(inspection *models.Inspection, inspectorID primitive.ObjectID, scheduledAt time.Time) bool {
+	return inspection.InspectorID == inspectorID && inspection.ScheduledAt.Equal(scheduledAt)
+}
+
+// DeleteInspection deletes an inspection along with its uploaded issue media
 func (s *InspectionService) DeleteInspection(ctx context.Context, id string) error {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	inspection, err := s.GetInspectionByID(ctx, id)
 	if err != nil {
-		return errors.New("invalid inspection ID")
+		return err
+	}
+
+	// Media goes first: deleting it is idempotent, so a failure leaves the inspection in place to retry
+	if ids := inspectionMediaIDs(inspection); len(ids) > 0 && s.media != nil {
+		if err := s.media.DeleteImages(ctx, ids); err != nil {
+			return err
+		}
 	}
 
-	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": inspection.ID})
 	if err != nil {
 		return err
 	}
@@ -246,7 +640,53 @@ func (s *InspectionService) DeleteInspection(ctx context.Context, id string) err
 		return errors.New("inspection not found")
 	}
 
-	return nil
+	return s.availability.ReleaseInspection(ctx, inspection.ID)
+}
+
+// AvailableSlots lists the free slots of every inspector serving the vehicle's location
+// Slots are ordered by start time, then by how well the inspector matches the location
+func (s *InspectionService) AvailableSlots(ctx context.Context, vehicleID string, from, to time.Time) ([]models.InspectionSlot, error) {
+	objectID, err := primitive.ObjectIDFromHex(vehicleID)
+	if err != nil {
+		return nil, errors.New("invalid vehicleId")
+	}
+
+	var vehicle models.Vehicle
+	err = s.vehicleCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&vehicle)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("vehicle not found")
+		}
+		return nil, err
+	}
+
+	if from.IsZero() {
+		from = time.Now()
+	}
+	if to.IsZero() {
+		to = from.Add(defaultSlotSearchWindow)
+	}
+
+	candidates, err := s.assigner.Candidates(ctx, vehicle.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := []models.InspectionSlot{}
+	for _, candidate := range candidates {
+		free, err := s.availability.FreeSlots(ctx, candidate.Inspector.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, free...)
+	}
+
+	// Candidates are already ranked, so a stable sort keeps the best inspector first for each start time
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].Start.Before(slots[j].Start)
+	})
+
+	return slots, nil
 }
 
 // ListInspections retrieves inspections with filtering and pagination
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+func TestUnchangedInspectionFilter(t *testing.T) {
+	pending := &models.Inspection{ID: primitive.NewObjectID(), Status: models.InspectionStatusPending}
+	assert.Equal(t, bson.M{
+		"_id":         pending.ID,
+		"status":      models.InspectionStatusPending,
+		"inspectorId": bson.M{"$exists": false},
+		"scheduledAt": bson.M{"$exists": false},
+	}, unchangedInspectionFilter(pending), "an inspection assigned concurrently no longer matches")
+
+	scheduled := &models.Inspection{
+		ID:          primitive.NewObjectID(),
+		Status:      models.InspectionStatusScheduled,
+		InspectorID: primitive.NewObjectID(),
+		ScheduledAt: time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, bson.M{
+		"_id":         scheduled.ID,
+		"status":      models.InspectionStatusScheduled,
+		"inspectorId": scheduled.InspectorID,
+		"scheduledAt": scheduled.ScheduledAt,
+	}, unchangedInspectionFilter(scheduled), "of two concurrent reschedules only the first still matches")
+}
+
+func TestHoldsSlot(t *testing.T) {
+	inspectorID := primitive.NewObjectID()
+	scheduledAt := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	inspection := &models.Inspection{InspectorID: inspectorID, ScheduledAt: scheduledAt}
+
+	assert.True(t, holdsSlot(inspection, inspectorID, scheduledAt))
+	assert.True(t, holdsSlot(inspection, inspectorID, scheduledAt.In(time.FixedZone("WAT", 3600))))
+	assert.False(t, holdsSlot(inspection, inspectorID, scheduledAt.Add(time.Hour)), "rescheduled")
+	assert.False(t, holdsSlot(inspection, primitive.NewObjectID(), scheduledAt), "reassigned")
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+// Inspection actor roles used by the state machine
+const (
+	InspectionRoleInspector = "inspector"
+	InspectionRoleRequester = "requester"
+	InspectionRoleAdmin     = "admin"
+	InspectionRoleSystem    = "system" // automatic inspector assignment
+)
+
+// InspectionActor identifies who is requesting a lifecycle transition
+type InspectionActor struct {
+	ID   primitive.ObjectID
+	Role string // inspector, requester, admin, system
+}
+
+// InspectionGuard is a precondition that must hold before a transition is applied
+type InspectionGuard func(inspection *models.Inspection, actor InspectionActor) error
+
+// inspectionTransition describes who may move an inspection between two statuses
+type inspectionTransition struct {
+	roles  []string
+	guards []InspectionGuard
+}
+
+// InspectionStateMachine enforces the inspection lifecycle
+// pending -> scheduled -> in_progress -> completed, with cancellation and rescheduling along the way
+type InspectionStateMachine struct {
+	transitions map[string]map[string]inspectionTransition
+}
+
+// NewInspectionStateMachine creates the state machine with the default lifecycle
+func NewInspectionStateMachine() *InspectionStateMachine {
+	staff := []string{InspectionRoleInspector, InspectionRoleAdmin}
+	assigners := []string{InspectionRoleInspector, InspectionRoleAdmin, InspectionRoleSystem}
+	cancellers := []string{InspectionRoleInspector, InspectionRoleRequester, InspectionRoleAdmin}
+
+	return &InspectionStateMachine{
+		transitions: map[string]map[string]inspectionTransition{
+			models.InspectionStatusPending: {
+				models.InspectionStatusScheduled: {roles: assigners, guards: []InspectionGuard{requireScheduledTime, requireAssignedInspector}},
+				models.InspectionStatusCancelled: {roles: cancellers},
+			},
+			models.InspectionStatusScheduled: {
+				// A scheduled -> scheduled move is a reschedule or a reassignment
+				models.InspectionStatusScheduled:  {roles: staff, guards: []InspectionGuard{requireScheduledTime, requireAssignedInspector}},
+				models.InspectionStatusInProgress: {roles: staff},
+				models.InspectionStatusCancelled:  {roles: cancellers},
+			},
+			models.InspectionStatusInProgress: {
+				models.InspectionStatusCompleted: {roles: staff},
+				models.InspectionStatusCancelled: {roles: []string{InspectionRoleAdmin}},
+			},
+		},
+	}
+}
+
+// CanTransition reports whether the actor may move the inspection to the target status
+// Returns an INVALID_STATE_TRANSITION AppError when the move is not allowed
+func (m *InspectionStateMachine) CanTransition(inspection *models.Inspection, to string, actor InspectionActor) error {
+	if !models.IsValidInspectionStatus(to) {
+		return apperrors.NewInvalidStateTransitionError(fmt.Sprintf("unknown inspection status %q", to))
+	}
+
+	transition, ok := m.transitions[inspection.Status][to]
+	if !ok {
+		return apperrors.NewInvalidStateTransitionError(
+			fmt.Sprintf("cannot move inspection from %s to %s", inspection.Status, to),
+		)
+	}
+
+	if !containsString(transition.roles, actor.Role) {
+		return apperrors.NewInvalidStateTransitionError(
+			fmt.Sprintf("%s is not allowed to move inspection from %s to %s", actor.Role, inspection.Status, to),
+		)
+	}
+
+	for _, guard := range transition.guards {
+		if err := guard(inspection, actor); err != nil {
+			return apperrors.NewInvalidStateTransitionError(err.Error())
+		}
+	}
+
+	return nil
+}
+
+// Transition validates the move and returns the log entry to record for it
+func (m *InspectionStateMachine) Transition(inspection *models.Inspection, to string, actor InspectionActor, reason string) (*models.InspectionTransition, error) {
+	if err := m.CanTransition(inspection, to, actor); err != nil {
+		return nil, err
+	}
+
+	entry := &models.InspectionTransition{
+		From:      inspection.Status,
+		To:        to,
+		ActorID:   actor.ID,
+		ActorRole: actor.Role,
+		Reason:    reason,
+		ChangedAt: time.Now(),
+	}
+
+	if to == models.InspectionStatusScheduled {
+		scheduledAt := inspection.ScheduledAt
+		entry.ScheduledAt = &scheduledAt
+	}
+
+	return entry, nil
+}
+
+// requireAssignedInspector ensures an inspector has been assigned before an inspection is scheduled
+func requireAssignedInspector(inspection *models.Inspection, _ InspectionActor) error {
+	if inspection.InspectorID.IsZero() {
+		return errors.New("an inspector must be assigned to schedule an inspection")
+	}
+	return nil
+}
+
+// requireScheduledTime ensures an inspection has a future appointment before it is (re)scheduled
+func requireScheduledTime(inspection *models.Inspection, _ InspectionActor) error {
+	if inspection.ScheduledAt.IsZero() {
+		return errors.New("scheduledAt is required to schedule an inspection")
+	}
+	if inspection.ScheduledAt.Before(time.Now()) {
+		return errors.New("scheduledAt cannot be in the past")
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+func TestInspectionStateMachine_CanTransition(t *testing.T) {
+	machine := NewInspectionStateMachine()
+	future := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		status      string
+		scheduledAt time.Time
+		to          string
+		role        string
+		wantErr     bool
+	}{
+		{"inspector schedules pending", models.InspectionStatusPending, future, models.InspectionStatusScheduled, InspectionRoleInspector, false},
+		{"scheduling requires a time", models.InspectionStatusPending, time.Time{}, models.InspectionStatusScheduled, InspectionRoleInspector, true},
+		{"system auto-assigns pending", models.InspectionStatusPending, future, models.InspectionStatusScheduled, InspectionRoleSystem, false},
+		{"requester cannot schedule", models.InspectionStatusPending, future, models.InspectionStatusScheduled, InspectionRoleRequester, true},
+		{"requester cancels pending", models.InspectionStatusPending, future, models.InspectionStatusCancelled, InspectionRoleRequester, false},
+		{"requester cannot start", models.InspectionStatusScheduled, future, models.InspectionStatusInProgress, InspectionRoleRequester, true},
+		{"inspector reschedules", models.InspectionStatusScheduled, future, models.InspectionStatusScheduled, InspectionRoleInspector, false},
+		{"reschedule into the past", models.InspectionStatusScheduled, past, models.InspectionStatusScheduled, InspectionRoleAdmin, true},
+		{"inspector starts scheduled", models.InspectionStatusScheduled, future, models.InspectionStatusInProgress, InspectionRoleInspector, false},
+		{"scheduled cannot skip to completed", models.InspectionStatusScheduled, future, models.InspectionStatusCompleted, InspectionRoleInspector, true},
+		{"inspector completes in progress", models.InspectionStatusInProgress, past, models.InspectionStatusCompleted, InspectionRoleInspector, false},
+		{"admin completes in progress", models.InspectionStatusInProgress, past, models.InspectionStatusCompleted, InspectionRoleAdmin, false},
+		{"unknown role cannot complete", models.InspectionStatusInProgress, past, models.InspectionStatusCompleted, "buyer", true},
+		{"inspector cannot cancel in progress", models.InspectionStatusInProgress, past, models.InspectionStatusCancelled, InspectionRoleInspector, true},
+		{"admin cancels in progress", models.InspectionStatusInProgress, past, models.InspectionStatusCancelled, InspectionRoleAdmin, false},
+		{"cancelled cannot be completed", models.InspectionStatusCancelled, past, models.InspectionStatusCompleted, InspectionRoleAdmin, true},
+		{"completed cannot be cancelled", models.InspectionStatusCompleted, past, models.InspectionStatusCancelled, InspectionRoleAdmin, true},
+		{"completed cannot restart", models.InspectionStatusCompleted, past, models.InspectionStatusInProgress, InspectionRoleAdmin, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inspection := &models.Inspection{Status: tt.status, ScheduledAt: tt.scheduledAt, InspectorID: primitive.NewObjectID()}
+			actor := InspectionActor{ID: primitive.NewObjectID(), Role: tt.role}
+
+			err := machine.CanTransition(inspection, tt.to, actor)
+			if tt.wantErr {
+				assert.Error(t, err)
+				appErr, ok := err.(*apperrors.AppError)
+				if assert.True(t, ok) {
+					assert.Equal(t, apperrors.ErrCodeInvalidStateTransition, appErr.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInspectionStateMachine_TransitionRecordsSchedule(t *testing.T) {
+	machine := NewInspectionStateMachine()
+	future := time.Now().Add(48 * time.Hour)
+	actor := InspectionActor{ID: primitive.NewObjectID(), Role: InspectionRoleAdmin}
+	inspection := &models.Inspection{Status: models.InspectionStatusScheduled, ScheduledAt: future, InspectorID: primitive.NewObjectID()}
+
+	entry, err := machine.Transition(inspection, models.InspectionStatusScheduled, actor, "inspector unavailable")
+	assert.NoError(t, err)
+	assert.Equal(t, models.InspectionStatusScheduled, entry.From)
+	assert.Equal(t, models.InspectionStatusScheduled, entry.To)
+	assert.Equal(t, InspectionRoleAdmin, entry.ActorRole)
+	if assert.NotNil(t, entry.ScheduledAt) {
+		assert.True(t, entry.ScheduledAt.Equal(future))
+	}
+
+	inspection.Status = models.InspectionStatusInProgress
+	entry, err = machine.Transition(inspection, models.InspectionStatusCompleted, actor, "")
+	assert.NoError(t, err)
+	assert.Nil(t, entry.ScheduledAt)
+}
+
+func TestInspectionStateMachine_SchedulingRequiresInspector(t *testing.T) {
+	machine := NewInspectionStateMachine()
+	inspection := &models.Inspection{Status: models.InspectionStatusPending, ScheduledAt: time.Now().Add(time.Hour)}
+	actor := InspectionActor{ID: primitive.NewObjectID(), Role: InspectionRoleAdmin}
+
+	assert.Error(t, machine.CanTransition(inspection, models.InspectionStatusScheduled, actor))
+
+	inspection.InspectorID = primitive.NewObjectID()
+	assert.NoError(t, machine.CanTransition(inspection, models.InspectionStatusScheduled, actor))
+}
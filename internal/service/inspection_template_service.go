@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+// errTemplateNotFound is returned when no template matches the requested key or ID
+var errTemplateNotFound = apperrors.NewNotFoundError("inspection template not found")
+
+// InspectionTemplateService manages versioned inspection checklist templates
+type InspectionTemplateService struct {
+	collection *mongo.Collection
+}
+
+// NewInspectionTemplateService creates a new inspection template service
+func NewInspectionTemplateService(db *mongo.Database) *InspectionTemplateService {
+	return &InspectionTemplateService{
+		collection: db.Collection("inspection_templates"),
+	}
+}
+
+// EnsureIndexes creates the indexes that keep template versions unique
+func (s *InspectionTemplateService) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key", Value: 1}, {Key: "version", Value: -1}},
+			Options: options.Index().SetUnique(true).SetName("idx_inspection_templates_key_version_unique"),
+		},
+		{
+			Keys: bson.D{{Key: "key", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"active": true}).
+				SetName("idx_inspection_templates_active_key_unique"),
+		},
+	})
+	return err
+}
+
+// SaveTemplate creates a template, or publishes a new version when the key already exists
+// The previous version is retired but kept, so inspections pinned to it stay interpretable
+func (s *InspectionTemplateService) SaveTemplate(ctx context.Context, req *models.SaveInspectionTemplateRequest, adminID primitive.ObjectID) (*models.InspectionTemplate, error) {
+	latest, err := s.latestVersion(ctx, req.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &models.InspectionTemplate{
+		Key:          req.Key,
+		Version:      1,
+		Name:         req.Name,
+		Description:  req.Description,
+		VehicleTypes: req.VehicleTypes,
+		Sections:     req.Sections,
+		Active:       true,
+		CreatedBy:    adminID,
+		CreatedAt:    time.Now(),
+	}
+	if latest != nil {
+		template.Version = latest.Version + 1
+	}
+
+	session, err := s.collection.Database().Client().StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	err = mongo.WithSession(ctx, session, func(sc mongo.SessionContext) error {
+		if latest != nil && latest.Active {
+			_, err := s.collection.UpdateOne(sc, bson.M{"_id": latest.ID, "active": true}, bson.M{"$set": bson.M{"active": false}})
+			if err != nil {
+				return err
+			}
+		}
+
+		result, err := s.collection.InsertOne(sc, template)
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return apperrors.NewConflictError("template was modified concurrently, please retry")
+			}
+			return err
+		}
+
+		template.ID = result.InsertedID.(primitive.ObjectID)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// RetireTemplate deactivates a template key so it can no longer be chosen for new inspections
+func (s *InspectionTemplateService) RetireTemplate(ctx context.Context, key string) error {
+	result, err := s.collection.UpdateMany(ctx, bson.M{"key": key, "active": true}, bson.M{"$set": bson.M{"active": false}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errTemplateNotFound
+	}
+
+	return nil
+}
+
+// GetTemplateByID retrieves a specific template version
+func (s *InspectionTemplateService) GetTemplateByID(ctx context.Context, id string) (*models.InspectionTemplate, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid template ID")
+	}
+
+	var template models.InspectionTemplate
+	err = s.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&template)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errTemplateNotFound
+		}
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// GetActiveTemplate retrieves the current version of a template key
+func (s *InspectionTemplateService) GetActiveTemplate(ctx context.Context, key string) (*models.InspectionTemplate, error) {
+	var template models.InspectionTemplate
+	err := s.collection.FindOne(ctx, bson.M{"key": key, "active": true}).Decode(&template)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errTemplateNotFound
+		}
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// ListTemplates retrieves the active templates, or every version when includeRetired is set
+func (s *InspectionTemplateService) ListTemplates(ctx context.Context, includeRetired bool) ([]models.InspectionTemplate, error) {
+	filter := bson.M{"active": true}
+	if includeRetired {
+		filter = bson.M{}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "key", Value: 1}, {Key: "version", Value: -1}})
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var templates []models.InspectionTemplate
+	if err = cursor.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+
+	if templates == nil {
+		templates = []models.InspectionTemplate{}
+	}
+
+	return templates, nil
+}
+
+// latestVersion returns the highest version of a key, or nil if the key is new
+func (s *InspectionTemplateService) latestVersion(ctx context.Context, key string) (*models.InspectionTemplate, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+
+	var template models.InspectionTemplate
+	err := s.collection.FindOne(ctx, bson.M{"key": key}, opts).Decode(&template)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &template, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+// activeInspectionStatuses are the statuses that count toward an inspector's workload
+var activeInspectionStatuses = []string{
+	models.InspectionStatusScheduled,
+	models.InspectionStatusInProgress,
+}
+
+// InspectorCandidate is an inspector scored for a specific vehicle location
+type InspectorCandidate struct {
+	Inspector     models.User
+	LocationScore int   // 0 = no match, 1 = country, 2 = state, 3 = city
+	Workload      int64 // number of active inspections
+}
+
+// InspectorAssigner picks inspectors by location match and current workload
+type InspectorAssigner struct {
+	userCollection       *mongo.Collection
+	inspectionCollection *mongo.Collection
+}
+
+// NewInspectorAssigner creates a new inspector assigner
+func NewInspectorAssigner(db *mongo.Database) *InspectorAssigner {
+	return &InspectorAssigner{
+		userCollection:       db.Collection("users"),
+		inspectionCollection: db.Collection("inspections"),
+	}
+}
+
+// Candidates returns all inspectors serving the location, best match first
+func (a *InspectorAssigner) Candidates(ctx context.Context, location models.Location) ([]InspectorCandidate, error) {
+	cursor, err := a.userCollection.Find(ctx, bson.M{"role": models.RoleInspector})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var inspectors []models.User
+	if err = cursor.All(ctx, &inspectors); err != nil {
+		return nil, err
+	}
+
+	candidates := make([]InspectorCandidate, 0, len(inspectors))
+	ids := make([]primitive.ObjectID, 0, len(inspectors))
+	for _, inspector := range inspectors {
+		score := locationScore(inspector.Location, location)
+		if score == 0 {
+			continue
+		}
+		candidates = append(candidates, InspectorCandidate{Inspector: inspector, LocationScore: score})
+		ids = append(ids, inspector.ID)
+	}
+
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	workloads, err := a.Workloads(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range candidates {
+		candidates[i].Workload = workloads[candidates[i].Inspector.ID]
+	}
+
+	rankInspectorCandidates(candidates)
+	return candidates, nil
+}
+
+// Pick returns the best inspector for the location, or nil if nobody serves it
+func (a *InspectorAssigner) Pick(ctx context.Context, location models.Location) (*models.User, error) {
+	candidates, err := a.Candidates(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	return &candidates[0].Inspector, nil
+}
+
+// Workloads counts active inspections per inspector
+func (a *InspectorAssigner) Workloads(ctx context.Context, inspectorIDs []primitive.ObjectID) (map[primitive.ObjectID]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"inspectorId": bson.M{"$in": inspectorIDs},
+			"status":      bson.M{"$in": activeInspectionStatuses},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$inspectorId",
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cursor, err := a.inspectionCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int64              `bson:"count"`
+	}
+	if err = cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	workloads := make(map[primitive.ObjectID]int64, len(rows))
+	for _, row := range rows {
+		workloads[row.ID] = row.Count
+	}
+
+	return workloads, nil
+}
+
+// locationScore rates how closely an inspector's service area matches a vehicle location
+func locationScore(inspector *models.Location, vehicle models.Location) int {
+	if inspector == nil || !strings.EqualFold(inspector.Country, vehicle.Country) {
+		return 0
+	}
+	if !strings.EqualFold(inspector.State, vehicle.State) {
+		return 1
+	}
+	if !strings.EqualFold(inspector.City, vehicle.City) {
+		return 2
+	}
+	return 3
+}
+
+// rankInspectorCandidates orders candidates by best location match, then lightest workload
+// Ties are broken by ID so assignment is deterministic
+func rankInspectorCandidates(candidates []InspectorCandidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].LocationScore != candidates[j].LocationScore {
+			return candidates[i].LocationScore > candidates[j].LocationScore
+		}
+		if candidates[i].Workload != candidates[j].Workload {
+			return candidates[i].Workload < candidates[j].Workload
+		}
+		return candidates[i].Inspector.ID.Hex() < candidates[j].Inspector.ID.Hex()
+	})
+}
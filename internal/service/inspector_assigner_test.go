@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+func TestLocationScore(t *testing.T) {
+	vehicle := models.Location{City: "Lagos", State: "Lagos", Country: "Nigeria"}
+
+	tests := []struct {
+		name      string
+		inspector *models.Location
+		want      int
+	}{
+		{"same city", &models.Location{City: "lagos", State: "Lagos", Country: "NIGERIA"}, 3},
+		{"same state", &models.Location{City: "Ikeja", State: "Lagos", Country: "Nigeria"}, 2},
+		{"same country", &models.Location{City: "Abuja", State: "FCT", Country: "Nigeria"}, 1},
+		{"different country", &models.Location{City: "Accra", State: "Greater Accra", Country: "Ghana"}, 0},
+		{"no service area", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, locationScore(tt.inspector, vehicle))
+		})
+	}
+}
+
+func TestRankInspectorCandidates(t *testing.T) {
+	busyLocal := InspectorCandidate{Inspector: models.User{ID: primitive.NewObjectID()}, LocationScore: 3, Workload: 5}
+	idleLocal := InspectorCandidate{Inspector: models.User{ID: primitive.NewObjectID()}, LocationScore: 3, Workload: 1}
+	idleRegional := InspectorCandidate{Inspector: models.User{ID: primitive.NewObjectID()}, LocationScore: 2, Workload: 0}
+
+	candidates := []InspectorCandidate{idleRegional, busyLocal, idleLocal}
+	rankInspectorCandidates(candidates)
+
+	assert.Equal(t, idleLocal.Inspector.ID, candidates[0].Inspector.ID)
+	assert.Equal(t, busyLocal.Inspector.ID, candidates[1].Inspector.ID)
+	assert.Equal(t, idleRegional.Inspector.ID, candidates[2].Inspector.ID)
+}
@@ -0,0 +1,479 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/financing"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// InstallmentPolicy controls when unpaid installments become late or missed and what lateness costs
+type InstallmentPolicy struct {
+	GracePeriod    time.Duration // time after the due date before an installment is late
+	LateFeePercent float64       // late fee as a percentage of the scheduled payment
+	MissedAfter    time.Duration // time after the due date before a late installment is missed
+}
+
+// InstallmentService handles installment tracking for completed financing transactions
+type InstallmentService struct {
+	collection            *mongo.Collection
+	transactionCollection *mongo.Collection
+	userCollection        *mongo.Collection
+	policy                InstallmentPolicy
+}
+
+// errInstallmentModified is returned when a conditional installment update loses a race
+var errInstallmentModified = apperrors.NewConflictError("installment was modified concurrently, please retry")
+
+// NewInstallmentService creates a new installment service
+func NewInstallmentService(db *mongo.Database, policy InstallmentPolicy) *InstallmentService {
+	return &InstallmentService{
+		collection:            db.Collection("installments"),
+		transactionCollection: db.Collection("transactions"),
+		userCollection:        db.Collection("users"),
+		policy:                policy,
+	}
+}
+
+// EnsureIndexes creates the index that keeps each schedule position unique per transaction
+func (s *InstallmentService) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "transactionId", Value: 1}, {Key: "number", Value: 1}},
+		Options: options.Index().SetUnique(true).SetName("idx_installments_transaction_number_unique"),
+	})
+	return err
+}
+
+// GetInstallmentByID retrieves an installment by its ID
+func (s *InstallmentService) GetInstallmentByID(ctx context.Context, id string) (*models.Installment, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid installment ID")
+	}
+
+	var installment models.Installment
+	err = s.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&installment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("installment not found")
+		}
+		return nil, err
+	}
+
+	return &installment, nil
+}
+
+// GetTransactionInstallments lists the installments of a financing transaction in schedule order
+// Only the buyer, the seller and admins can see them
+func (s *InstallmentService) GetTransactionInstallments(ctx context.Context, transactionID string, userID primitive.ObjectID) ([]models.Installment, error) {
+	objectID, err := primitive.ObjectIDFromHex(transactionID)
+	if err != nil {
+		return nil, errors.New("invalid transaction ID")
+	}
+
+	var transaction models.Transaction
+	err = s.transactionCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&transaction)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("transaction not found")
+		}
+		return nil, err
+	}
+
+	if userID != transaction.SellerID && userID != transaction.BuyerID {
+		admin, err := s.isAdmin(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !admin {
+			return nil, errors.New("you are not authorized to view this transaction")
+		}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "number", Value: 1}})
+	cursor, err := s.collection.Find(ctx, bson.M{"transactionId": objectID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	installments := []models.Installment{}
+	if err = cursor.All(ctx, &installments); err != nil {
+		return nil, err
+	}
+
+	return installments, nil
+}
+
+// RecordPayment applies money received from the buyer to an installment
+// Only the dealer collecting the installment and admins can record payments
+func (s *InstallmentService) RecordPayment(ctx context.Context, id string, req *models.RecordInstallmentPaymentRequest, userID primitive.ObjectID) (*models.Installment, error) {
+	installment, err := s.GetInstallmentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID != installment.DealerID {
+		admin, err := s.isAdmin(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !admin {
+			return nil, errors.New("you are not authorized to record payments for this installment")
+		}
+	}
+
+	amount, err := req.Amount.WithCurrency(installment.Currency)
+	if err != nil {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("amount: %v", err))
+	}
+
+	amountPaid, status, err := applyInstallmentPayment(installment, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	paidAt := now
+	if req.PaidAt != nil {
+		paidAt = *req.PaidAt
+	}
+
+	set := bson.M{
+		"amountPaid": amountPaid,
+		"status":     status,
+		"updatedAt":  now,
+	}
+	if status == models.InstallmentStatusPaid {
+		set["paidAt"] = paidAt
+	}
+	update := bson.M{
+		"$set": set,
+		"$push": bson.M{"payments": models.InstallmentPayment{
+			Amount:     amount,
+			Reference:  req.Reference,
+			Notes:      req.Notes,
+			RecordedBy: userID,
+			PaidAt:     paidAt,
+		}},
+	}
+
+	// Only apply the payment if no other payment or overdue run changed the installment since it was read
+	filter := bson.M{"_id": installment.ID, "status": installment.Status, "amountPaid.amount": installment.AmountPaid.Decimal128()}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.Installment
+	err = s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errInstallmentModified
+		}
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// MarkOverdue charges late fees on installments past their grace period and flags long-unpaid ones as missed
+// Returns how many installments became late and how many became missed
+func (s *InstallmentService) MarkOverdue(ctx context.Context, now time.Time) (int64, int64, error) {
+	late, err := s.chargeLateFees(ctx, now)
+	if err != nil {
+		return late, 0, err
+	}
+
+	missedResult, err := s.collection.UpdateMany(ctx, bson.M{
+		"status":  models.InstallmentStatusLate,
+		"dueDate": bson.M{"$lt": now.Add(-s.policy.MissedAfter)},
+	}, bson.M{"$set": bson.M{
+		"status":    models.InstallmentStatusMissed,
+		"missedAt":  now,
+		"updatedAt": now,
+	}})
+	if err != nil {
+		return late, 0, err
+	}
+
+	return late, missedResult.ModifiedCount, nil
+}
+
+// chargeLateFees marks due installments past their grace period late and charges each its late fee
+// Fees are rounded half up to the installment currency's minor unit, so they are computed here rather than in an update pipeline
+func (s *InstallmentService) chargeLateFees(ctx context.Context, now time.Time) (int64, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{
+		"status":  models.InstallmentStatusDue,
+		"dueDate": bson.M{"$lt": now.Add(-s.policy.GracePeriod)},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var marked int64
+	for cursor.Next(ctx) {
+		var installment models.Installment
+		if err := cursor.Decode(&installment); err != nil {
+			return marked, err
+		}
+
+		lateFee, err := installment.Amount.Percent(s.policy.LateFeePercent, money.RoundHalfUp)
+		if err != nil {
+			return marked, err
+		}
+
+		// A payment recorded since the query keeps the installment due, and it is picked up on the next run
+		result, err := s.collection.UpdateOne(ctx, bson.M{
+			"_id":    installment.ID,
+			"status": models.InstallmentStatusDue,
+		}, bson.M{"$set": bson.M{
+			"status":    models.InstallmentStatusLate,
+			"lateFee":   lateFee,
+			"lateAt":    now,
+			"updatedAt": now,
+		}})
+		if err != nil {
+			return marked, err
+		}
+		marked += result.ModifiedCount
+	}
+
+	return marked, cursor.Err()
+}
+
+// GetReceivablesSummary totals the installments a dealer is owed and has collected
+func (s *InstallmentService) GetReceivablesSummary(ctx context.Context, dealerID primitive.ObjectID) (*models.ReceivablesSummary, error) {
+	// Amounts are stored as Decimal128, so the sums are exact
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"dealerId": dealerID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":          bson.M{"status": "$status", "currency": "$currency"},
+			"count":        bson.M{"$sum": 1},
+			"balance":      bson.M{"$sum": bson.M{"$subtract": bson.A{bson.M{"$add": bson.A{"$amount.amount", "$lateFee.amount"}}, "$amountPaid.amount"}}},
+			"lateFees":     bson.M{"$sum": "$lateFee.amount"},
+			"collected":    bson.M{"$sum": "$amountPaid.amount"},
+			"earliestDue":  bson.M{"$min": "$dueDate"},
+			"transactions": bson.M{"$addToSet": "$transactionId"},
+		}}},
+	}
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []receivablesRow
+	if err = cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	return summarizeReceivables(dealerID, rows)
+}
+
+// receivablesRow is one status and currency group of a dealer's installments
+type receivablesRow struct {
+	Group struct {
+		Status   string `bson:"status"`
+		Currency string `bson:"currency"`
+	} `bson:"_id"`
+	Count        int                  `bson:"count"`
+	Balance      primitive.Decimal128 `bson:"balance"`
+	LateFees     primitive.Decimal128 `bson:"lateFees"`
+	Collected    primitive.Decimal128 `bson:"collected"`
+	EarliestDue  time.Time            `bson:"earliestDue"`
+	Transactions []primitive.ObjectID `bson:"transactions"`
+}
+
+// summarizeReceivables combines the per-status groups into a dealer summary with totals per currency
+func summarizeReceivables(dealerID primitive.ObjectID, rows []receivablesRow) (*models.ReceivablesSummary, error) {
+	summary := &models.ReceivablesSummary{
+		DealerID:     dealerID,
+		Totals:       []models.ReceivablesTotals{},
+		StatusCounts: map[string]int{},
+	}
+
+	totals := map[string]*models.ReceivablesTotals{}
+	open := map[primitive.ObjectID]bool{}
+	for _, row := range rows {
+		currency := row.Group.Currency
+		total, ok := totals[currency]
+		if !ok {
+			total = &models.ReceivablesTotals{
+				Currency:    currency,
+				Outstanding: money.Zero(currency),
+				Overdue:     money.Zero(currency),
+				LateFees:    money.Zero(currency),
+				Collected:   money.Zero(currency),
+			}
+			totals[currency] = total
+		}
+
+		balance, err := money.FromDecimal128(row.Balance, currency)
+		if err != nil {
+			return nil, err
+		}
+		lateFees, err := money.FromDecimal128(row.LateFees, currency)
+		if err != nil {
+			return nil, err
+		}
+		collected, err := money.FromDecimal128(row.Collected, currency)
+		if err != nil {
+			return nil, err
+		}
+
+		summary.StatusCounts[row.Group.Status] += row.Count
+		total.LateFees, _ = total.LateFees.Add(lateFees)
+		total.Collected, _ = total.Collected.Add(collected)
+		if row.Group.Status == models.InstallmentStatusPaid || row.Group.Status == models.InstallmentStatusCancelled {
+			continue
+		}
+
+		total.Outstanding, _ = total.Outstanding.Add(balance)
+		for _, id := range row.Transactions {
+			open[id] = true
+		}
+
+		earliest := row.EarliestDue
+		switch row.Group.Status {
+		case models.InstallmentStatusDue:
+			if summary.NextDueDate == nil || earliest.Before(*summary.NextDueDate) {
+				summary.NextDueDate = &earliest
+			}
+		case models.InstallmentStatusLate, models.InstallmentStatusMissed:
+			total.Overdue, _ = total.Overdue.Add(balance)
+			if summary.OldestOverdueAt == nil || earliest.Before(*summary.OldestOverdueAt) {
+				summary.OldestOverdueAt = &earliest
+			}
+		}
+	}
+
+	for _, total := range totals {
+		summary.Totals = append(summary.Totals, *total)
+	}
+	sort.Slice(summary.Totals, func(i, j int) bool {
+		return summary.Totals[i].Currency < summary.Totals[j].Currency
+	})
+	summary.Transactions = len(open)
+	return summary, nil
+}
+
+// isAdmin reports whether the user has the admin role
+func (s *InstallmentService) isAdmin(ctx context.Context, userID primitive.ObjectID) (bool, error) {
+	var user models.User
+	err := s.userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, err
+	}
+	return user.Role == models.RoleAdmin, nil
+}
+
+// buildInstallments derives the monthly installments of a completed financing transaction
+// The first payment falls due one month after completion
+func buildInstallments(transaction *models.Transaction, completedAt time.Time) ([]interface{}, error) {
+	terms, err := transaction.PaymentDetails.FinancingTermsFor(transaction.Amount)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := financing.Amortize(terms)
+	if err != nil {
+		return nil, err
+	}
+
+	installments := make([]interface{}, 0, len(schedule.Periods))
+	for _, period := range schedule.Periods {
+		installments = append(installments, models.Installment{
+			TransactionID: transaction.ID,
+			VehicleID:     transaction.VehicleID,
+			DealerID:      transaction.SellerID,
+			BuyerID:       transaction.BuyerID,
+			Number:        period.Number,
+			DueDate:       addMonths(completedAt, period.Number),
+			Amount:        period.Payment,
+			Principal:     period.Principal,
+			Interest:      period.Interest,
+			Currency:      transaction.Currency,
+			Status:        models.InstallmentStatusDue,
+			LateFee:       money.Zero(transaction.Currency),
+			AmountPaid:    money.Zero(transaction.Currency),
+			CreatedAt:     completedAt,
+			UpdatedAt:     completedAt,
+		})
+	}
+
+	return installments, nil
+}
+
+// addMonths moves t forward by n calendar months, keeping the day of the month where it exists
+// A deal completed on January 31st falls due on the last day of February, not in early March
+func addMonths(t time.Time, n int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// applyInstallmentPayment returns the installment's amount paid and status once amount is received
+func applyInstallmentPayment(installment *models.Installment, amount money.Money) (money.Money, string, error) {
+	if !installment.IsOpen() {
+		return money.Money{}, "", apperrors.NewInvalidStateTransitionError(fmt.Sprintf("installment is already %s", installment.Status))
+	}
+
+	balance := installment.Balance()
+	cmp, err := amount.Cmp(balance)
+	if err != nil {
+		return money.Money{}, "", apperrors.NewValidationError(err.Error())
+	}
+	if cmp > 0 {
+		return money.Money{}, "", apperrors.NewValidationError(fmt.Sprintf("amount exceeds the outstanding balance of %s", balance))
+	}
+
+	amountPaid, err := installment.AmountPaid.Add(amount)
+	if err != nil {
+		return money.Money{}, "", apperrors.NewValidationError(err.Error())
+	}
+	if cmp == 0 {
+		return amountPaid, models.InstallmentStatusPaid, nil
+	}
+	return amountPaid, installment.Status, nil
+}
+
+// InstallmentOverdueJob periodically marks unpaid installments late or missed
+type InstallmentOverdueJob struct {
+	installments *InstallmentService
+	interval     time.Duration
+}
+
+// NewInstallmentOverdueJob creates a job that checks for overdue installments every interval
+func NewInstallmentOverdueJob(installments *InstallmentService, interval time.Duration) *InstallmentOverdueJob {
+	return &InstallmentOverdueJob{
+		installments: installments,
+		interval:     interval,
+	}
+}
+
+// Run marks overdue installments at startup and then every interval until ctx is cancelled
+// With a daily interval, a restart would otherwise postpone the check by up to a day
+func (j *InstallmentOverdueJob) Run(ctx context.Context, onError func(error)) {
+	markOverdue := func(ctx context.Context, now time.Time) error {
+		_, _, err := j.installments.MarkOverdue(ctx, now)
+		return err
+	}
+	runJob(ctx, markOverdue, onError)
+	runEvery(ctx, j.interval, markOverdue, onError)
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		name  string
+		start time.Time
+		n     int
+		want  time.Time
+	}{
+		{"mid month", time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC), 1, time.Date(2024, 4, 15, 10, 0, 0, 0, time.UTC)},
+		{"end of January into leap February", time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), 1, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"end of January into March keeps the day", time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), 2, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"across a year", time.Date(2024, 11, 30, 0, 0, 0, 0, time.UTC), 3, time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, addMonths(tt.start, tt.n))
+		})
+	}
+}
+
+func TestBuildInstallments(t *testing.T) {
+	txn := &models.Transaction{
+		ID:            primitive.NewObjectID(),
+		VehicleID:     primitive.NewObjectID(),
+		SellerID:      primitive.NewObjectID(),
+		BuyerID:       primitive.NewObjectID(),
+		Amount:        money.MustParse("30000", "USD"),
+		Currency:      "USD",
+		PaymentMethod: models.PaymentMethodFinancing,
+		PaymentDetails: models.PaymentDetails{
+			DownPayment:    money.MustParse("10000", "USD"),
+			FinancingTerms: 36,
+			InterestRate:   6,
+		},
+	}
+	completedAt := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	docs, err := buildInstallments(txn, completedAt)
+	require.NoError(t, err)
+	require.Len(t, docs, 36)
+
+	first := docs[0].(models.Installment)
+	assert.Equal(t, 1, first.Number)
+	assert.Equal(t, txn.SellerID, first.DealerID)
+	assert.Equal(t, txn.BuyerID, first.BuyerID)
+	assert.Equal(t, models.InstallmentStatusDue, first.Status)
+	assert.Equal(t, time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC), first.DueDate)
+	assert.Equal(t, money.MustParse("608.44", "USD"), first.Amount)
+	assert.Equal(t, money.MustParse("100", "USD"), first.Interest)
+	assert.Equal(t, money.Zero("USD"), first.AmountPaid)
+
+	// Principal is allocated to the cent, so the installments repay the financed amount exactly
+	total := money.Zero("USD")
+	for _, doc := range docs {
+		total, err = total.Add(doc.(models.Installment).Principal)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, money.MustParse("20000", "USD"), total)
+}
+
+func TestApplyInstallmentPayment(t *testing.T) {
+	tests := []struct {
+		name        string
+		installment models.Installment
+		amount      money.Money
+		wantPaid    money.Money
+		wantStatus  string
+		wantErr     bool
+	}{
+		{
+			name:        "full payment",
+			installment: models.Installment{Amount: money.MustParse("500", "USD"), Currency: "USD", Status: models.InstallmentStatusDue},
+			amount:      money.MustParse("500", "USD"),
+			wantPaid:    money.MustParse("500", "USD"),
+			wantStatus:  models.InstallmentStatusPaid,
+		},
+		{
+			name:        "partial payment keeps the status",
+			installment: models.Installment{Amount: money.MustParse("500", "USD"), Currency: "USD", Status: models.InstallmentStatusDue},
+			amount:      money.MustParse("200", "USD"),
+			wantPaid:    money.MustParse("200", "USD"),
+			wantStatus:  models.InstallmentStatusDue,
+		},
+		{
+			name:        "late installment needs the late fee too",
+			installment: models.Installment{Amount: money.MustParse("500", "USD"), LateFee: money.MustParse("25", "USD"), Currency: "USD", Status: models.InstallmentStatusLate},
+			amount:      money.MustParse("500", "USD"),
+			wantPaid:    money.MustParse("500", "USD"),
+			wantStatus:  models.InstallmentStatusLate,
+		},
+		{
+			name:        "paying off a missed installment",
+			installment: models.Installment{Amount: money.MustParse("500", "USD"), LateFee: money.MustParse("25", "USD"), AmountPaid: money.MustParse("300", "USD"), Currency: "USD", Status: models.InstallmentStatusMissed},
+			amount:      money.MustParse("225", "USD"),
+			wantPaid:    money.MustParse("525", "USD"),
+			wantStatus:  models.InstallmentStatusPaid,
+		},
+		{
+			name:        "overpayment",
+			installment: models.Installment{Amount: money.MustParse("500", "USD"), AmountPaid: money.MustParse("400", "USD"), Currency: "USD", Status: models.InstallmentStatusDue},
+			amount:      money.MustParse("100.01", "USD"),
+			wantErr:     true,
+		},
+		{
+			name:        "payment in another currency",
+			installment: models.Installment{Amount: money.MustParse("500", "USD"), Currency: "USD", Status: models.InstallmentStatusDue},
+			amount:      money.MustParse("500", "EUR"),
+			wantErr:     true,
+		},
+		{
+			name:        "already paid",
+			installment: models.Installment{Amount: money.MustParse("500", "USD"), AmountPaid: money.MustParse("500", "USD"), Currency: "USD", Status: models.InstallmentStatusPaid},
+			amount:      money.MustParse("1", "USD"),
+			wantErr:     true,
+		},
+		{
+			name:        "written off by a refund",
+			installment: models.Installment{Amount: money.MustParse("500", "USD"), Currency: "USD", Status: models.InstallmentStatusCancelled},
+			amount:      money.MustParse("500", "USD"),
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paid, status, err := applyInstallmentPayment(&tt.installment, tt.amount)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPaid, paid)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestSummarizeReceivables(t *testing.T) {
+	dealerID := primitive.NewObjectID()
+	shared := primitive.NewObjectID()
+	other := primitive.NewObjectID()
+	nextDue := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	oldestLate := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	oldestMissed := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	usd := func(amount string) primitive.Decimal128 { return money.MustParse(amount, "USD").Decimal128() }
+	row := func(status, currency string, count int, balance, lateFees, collected primitive.Decimal128, earliest time.Time, transactions ...primitive.ObjectID) receivablesRow {
+		r := receivablesRow{Count: count, Balance: balance, LateFees: lateFees, Collected: collected, EarliestDue: earliest, Transactions: transactions}
+		r.Group.Status = status
+		r.Group.Currency = currency
+		return r
+	}
+
+	summary, err := summarizeReceivables(dealerID, []receivablesRow{
+		row(models.InstallmentStatusPaid, "USD", 4, usd("0"), usd("25"), usd("2000"), time.Time{}, shared),
+		row(models.InstallmentStatusDue, "USD", 10, usd("5000"), usd("0"), usd("100.10"), nextDue, shared, other),
+		row(models.InstallmentStatusLate, "USD", 1, usd("525"), usd("25"), usd("0"), oldestLate, other),
+		row(models.InstallmentStatusMissed, "USD", 1, usd("525"), usd("25"), usd("0"), oldestMissed, shared),
+		row(models.InstallmentStatusDue, "NGN", 2, money.MustParse("80000", "NGN").Decimal128(), usd("0"), usd("0"), nextDue.AddDate(0, 1, 0), other),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, dealerID, summary.DealerID)
+	require.Len(t, summary.Totals, 2)
+	assert.Equal(t, "NGN", summary.Totals[0].Currency)
+	assert.Equal(t, money.MustParse("80000", "NGN"), summary.Totals[0].Outstanding)
+
+	dollars := summary.Totals[1]
+	assert.Equal(t, money.MustParse("6050", "USD"), dollars.Outstanding)
+	assert.Equal(t, money.MustParse("1050", "USD"), dollars.Overdue)
+	assert.Equal(t, money.MustParse("75", "USD"), dollars.LateFees)
+	assert.Equal(t, money.MustParse("2100.10", "USD"), dollars.Collected)
+	assert.Equal(t, 2, summary.Transactions)
+	assert.Equal(t, 12, summary.StatusCounts[models.InstallmentStatusDue])
+	require.NotNil(t, summary.NextDueDate)
+	assert.Equal(t, nextDue, *summary.NextDueDate)
+	require.NotNil(t, summary.OldestOverdueAt)
+	assert.Equal(t, oldestMissed, *summary.OldestOverdueAt)
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// jobFunc is one pass of a periodic job, run as of now
+type jobFunc func(ctx context.Context, now time.Time) error
+
+// runEvery runs the job every interval until ctx is cancelled
+// Errors do not stop the loop; they are passed to onError and the work is retried next run
+func runEvery(ctx context.Context, interval time.Duration, job jobFunc, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runJob(ctx, job, onError)
+		}
+	}
+}
+
+// runJob runs one pass of the job, passing its error to onError unless ctx was cancelled during the run
+func runJob(ctx context.Context, job jobFunc, onError func(error)) {
+	if err := job(ctx, time.Now()); err != nil && ctx.Err() == nil {
+		onError(err)
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunEvery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	failure := errors.New("sweep failed")
+
+	runs := 0
+	var reported []error
+	job := func(ctx context.Context, now time.Time) error {
+		runs++
+		if runs == 3 {
+			cancel()
+			return ctx.Err()
+		}
+		return failure
+	}
+
+	done := make(chan struct{})
+	go func() {
+		runEvery(ctx, time.Millisecond, job, func(err error) { reported = append(reported, err) })
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runEvery did not stop after ctx was cancelled")
+	}
+	assert.Equal(t, 3, runs, "errors do not stop the loop")
+	assert.Equal(t, []error{failure, failure}, reported, "errors from a cancelled run are dropped")
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// LedgerService reports on the ledger entries TransactionService posts
+type LedgerService struct {
+	collection *mongo.Collection
+}
+
+// NewLedgerService creates a new ledger service
+func NewLedgerService(db *mongo.Database) *LedgerService {
+	return &LedgerService{
+		collection: db.Collection("ledger_entries"),
+	}
+}
+
+// EnsureIndexes creates the index that posts each money movement once and the indexes used to
+// list a transaction's entries and to total accounts as of a date
+func (s *LedgerService) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("idx_ledger_entries_key_unique"),
+		},
+		{
+			Keys:    bson.D{{Key: "transactionId", Value: 1}, {Key: "createdAt", Value: 1}},
+			Options: options.Index().SetName("idx_ledger_entries_transaction_created"),
+		},
+		{
+			Keys:    bson.D{{Key: "postings.account", Value: 1}, {Key: "createdAt", Value: 1}},
+			Options: options.Index().SetName("idx_ledger_entries_account_created"),
+		},
+		{
+			Keys:    bson.D{{Key: "createdAt", Value: 1}},
+			Options: options.Index().SetName("idx_ledger_entries_created"),
+		},
+	})
+	return err
+}
+
+// AccountBalances returns the account's balance in each currency it has entries in, counting
+// entries created before asOf
+func (s *LedgerService) AccountBalances(ctx context.Context, account string, asOf time.Time) ([]models.LedgerAccountBalance, error) {
+	if _, ok := models.LedgerAccountType(account); !ok {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("unknown ledger account %q", account))
+	}
+
+	rows, err := s.totals(ctx, account, asOf)
+	if err != nil {
+		return nil, err
+	}
+	balances, _, err := summarizeLedger(rows)
+	return balances, err
+}
+
+// TrialBalance totals every account's debits and credits for entries created before asOf
+// Each entry balances, so total debits equal total credits in every currency unless the ledger is damaged
+func (s *LedgerService) TrialBalance(ctx context.Context, asOf time.Time) (*models.TrialBalance, error) {
+	rows, err := s.totals(ctx, "", asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, totals, err := summarizeLedger(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.TrialBalance{AsOf: asOf, Accounts: accounts, Totals: totals, Balanced: true}
+	for _, total := range totals {
+		report.Balanced = report.Balanced && total.Balanced
+	}
+	return report, nil
+}
+
+// totals sums the postings per account, currency and side; an empty account sums every account
+func (s *LedgerService) totals(ctx context.Context, account string, asOf time.Time) ([]ledgerRow, error) {
+	match := bson.M{"createdAt": bson.M{"$lt": asOf}}
+	if account != "" {
+		match["postings.account"] = account
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$unwind", Value: "$postings"}},
+	}
+	if account != "" {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"postings.account": account}}})
+	}
+
+	// Amounts are stored as Decimal128, so the sums are exact
+	pipeline = append(pipeline, bson.D{{Key: "$group", Value: bson.M{
+		"_id": bson.M{
+			"account":  "$postings.account",
+			"currency": "$postings.amount.currency",
+			"side":     "$postings.side",
+		},
+		"total": bson.M{"$sum": "$postings.amount.amount"},
+	}}})
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []ledgerRow
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ledgerRow is the total of one account's postings on one side in one currency
+type ledgerRow struct {
+	Group struct {
+		Account  string `bson:"account"`
+		Currency string `bson:"currency"`
+		Side     string `bson:"side"`
+	} `bson:"_id"`
+	Total primitive.Decimal128 `bson:"total"`
+}
+
+// summarizeLedger combines the per-side totals into account balances, in chart of accounts order,
+// and totals per currency
+func summarizeLedger(rows []ledgerRow) ([]models.LedgerAccountBalance, []models.TrialBalanceTotals, error) {
+	type key struct{ account, currency string }
+	accounts := map[key]*models.LedgerAccountBalance{}
+	totals := map[string]*models.TrialBalanceTotals{}
+
+	for _, row := range rows {
+		currency := row.Group.Currency
+		amount, err := money.FromDecimal128(row.Total, currency)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		k := key{row.Group.Account, currency}
+		balance, ok := accounts[k]
+		if !ok {
+			accountType, _ := models.LedgerAccountType(row.Group.Account)
+			balance = &models.LedgerAccountBalance{
+				Account:  row.Group.Account,
+				Type:     accountType,
+				Currency: currency,
+				Debits:   money.Zero(currency),
+				Credits:  money.Zero(currency),
+			}
+			accounts[k] = balance
+		}
+		total, ok := totals[currency]
+		if !ok {
+			total = &models.TrialBalanceTotals{Currency: currency, Debits: money.Zero(currency), Credits: money.Zero(currency)}
+			totals[currency] = total
+		}
+
+		switch row.Group.Side {
+		case models.LedgerDebit:
+			balance.Debits = addMoney(balance.Debits, amount)
+			total.Debits = addMoney(total.Debits, amount)
+		case models.LedgerCredit:
+			balance.Credits = addMoney(balance.Credits, amount)
+			total.Credits = addMoney(total.Credits, amount)
+		}
+	}
+
+	balances := make([]models.LedgerAccountBalance, 0, len(accounts))
+	for _, balance := range accounts {
+		if models.LedgerDebitNormal(balance.Account) {
+			balance.Balance, _ = balance.Debits.Sub(balance.Credits)
+		} else {
+			balance.Balance, _ = balance.Credits.Sub(balance.Debits)
+		}
+		balances = append(balances, *balance)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		a, b := balances[i], balances[j]
+		if a.Account != b.Account {
+			return ledgerAccountOrder(a.Account) < ledgerAccountOrder(b.Account)
+		}
+		return a.Currency < b.Currency
+	})
+
+	currencyTotals := make([]models.TrialBalanceTotals, 0, len(totals))
+	for _, total := range totals {
+		total.Balanced = compareMoney(total.Debits, total.Credits) == 0
+		currencyTotals = append(currencyTotals, *total)
+	}
+	sort.Slice(currencyTotals, func(i, j int) bool { return currencyTotals[i].Currency < currencyTotals[j].Currency })
+
+	return balances, currencyTotals, nil
+}
+
+// ledgerAccountOrder positions the account in the chart of accounts; unknown accounts sort last
+func ledgerAccountOrder(account string) int {
+	for i, known := range models.LedgerAccounts {
+		if known == account {
+			return i
+		}
+	}
+	return len(models.LedgerAccounts)
+}
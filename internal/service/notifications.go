@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+// notificationPageSize caps how many notifications are listed at once
+const notificationPageSize = 50
+
+// Notifier delivers notifications to users
+// Delivery is best effort: callers log failures instead of undoing the change they notify about
+type Notifier interface {
+	Notify(ctx context.Context, notifications ...models.Notification) error
+}
+
+// NotificationService stores notifications in each user's in-app inbox
+type NotificationService struct {
+	collection *mongo.Collection
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(db *mongo.Database) *NotificationService {
+	return &NotificationService{
+		collection: db.Collection("notifications"),
+	}
+}
+
+// EnsureIndexes creates the index used to list a user's inbox
+func (s *NotificationService) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index().SetName("idx_notifications_user_created"),
+	})
+	return err
+}
+
+// Notify adds notifications to their users' inboxes
+func (s *NotificationService) Notify(ctx context.Context, notifications ...models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	docs := make([]interface{}, len(notifications))
+	for i, notification := range notifications {
+		if notification.CreatedAt.IsZero() {
+			notification.CreatedAt = now
+		}
+		docs[i] = notification
+	}
+
+	_, err := s.collection.InsertMany(ctx, docs)
+	return err
+}
+
+// GetNotifications lists a user's most recent notifications, optionally only the unread ones
+func (s *NotificationService) GetNotifications(ctx context.Context, userID primitive.ObjectID, unreadOnly bool) ([]models.Notification, error) {
+	filter := bson.M{"userId": userID}
+	if unreadOnly {
+		filter["readAt"] = bson.M{"$exists": false}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(notificationPageSize)
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	notifications := []models.Notification{}
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// MarkRead marks one of the user's notifications as read
+func (s *NotificationService) MarkRead(ctx context.Context, id string, userID primitive.ObjectID) (*models.Notification, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid notification ID")
+	}
+
+	now := time.Now()
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var notification models.Notification
+	err = s.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID, "userId": userID},
+		bson.M{"$min": bson.M{"readAt": now}},
+		opts,
+	).Decode(&notification)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError("notification not found")
+		}
+		return nil, err
+	}
+	return &notification, nil
+}
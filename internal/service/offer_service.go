@@ -0,0 +1,418 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// errOfferModified is returned when a conditional offer update loses a race
+var errOfferModified = apperrors.NewConflictError("offer was modified concurrently, please retry")
+
+// OfferService handles offers and counter-offers on listed vehicles
+type OfferService struct {
+	collection        *mongo.Collection
+	vehicleCollection *mongo.Collection
+	transactions      *TransactionService // creates the pending transaction once an offer is accepted
+	ttl               time.Duration       // how long the other party has to respond to the latest amount
+}
+
+// NewOfferService creates a new offer service
+func NewOfferService(db *mongo.Database, transactions *TransactionService, ttl time.Duration) *OfferService {
+	return &OfferService{
+		collection:        db.Collection("offers"),
+		vehicleCollection: db.Collection("vehicles"),
+		transactions:      transactions,
+		ttl:               ttl,
+	}
+}
+
+// EnsureIndexes creates the index that keeps one open negotiation per buyer and vehicle
+func (s *OfferService) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "vehicleId", Value: 1}, {Key: "buyerId", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": models.OfferStatusOpen}).
+				SetName("idx_offers_vehicle_buyer_open_unique"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetName("idx_offers_status_expires"),
+		},
+	})
+	return err
+}
+
+// MakeOffer opens a negotiation between the buyer and the owner of an active vehicle
+func (s *OfferService) MakeOffer(ctx context.Context, vehicleID string, req *models.MakeOfferRequest, buyerID primitive.ObjectID) (*models.Offer, error) {
+	if err := req.Validate(); err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(vehicleID)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid vehicle ID")
+	}
+
+	var vehicle models.Vehicle
+	if err := s.vehicleCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&vehicle); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError("vehicle not found")
+		}
+		return nil, err
+	}
+	if vehicle.Status != models.VehicleStatusActive {
+		return nil, apperrors.NewValidationError("vehicle is not available for sale")
+	}
+	if vehicle.OwnerID == buyerID {
+		return nil, apperrors.NewValidationError("cannot make an offer on your own vehicle")
+	}
+	if vehicle.ListingType == models.ListingTypeAuction {
+		return nil, apperrors.NewValidationError("vehicle is being sold by auction; place a bid instead")
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = vehicle.Price.Currency()
+	}
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	amount, err := req.Amount.WithCurrency(currency)
+	if err != nil {
+		return nil, apperrors.NewValidationError("amount: " + err.Error())
+	}
+
+	now := time.Now()
+	offer := &models.Offer{
+		VehicleID:     vehicle.ID,
+		SellerID:      vehicle.OwnerID,
+		BuyerID:       buyerID,
+		Status:        models.OfferStatusOpen,
+		Amount:        amount,
+		Currency:      currency,
+		PaymentMethod: req.PaymentMethod,
+		Escrow:        req.Escrow,
+		AwaitingParty: models.OfferPartySeller,
+		Rounds: []models.OfferRound{
+			{Party: models.OfferPartyBuyer, ActorID: buyerID, Amount: amount, Message: req.Message, CreatedAt: now},
+		},
+		ExpiresAt: now.Add(s.ttl),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	result, err := s.collection.InsertOne(ctx, offer)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, apperrors.NewConflictError("you already have an open offer on this vehicle")
+		}
+		return nil, err
+	}
+
+	offer.ID = result.InsertedID.(primitive.ObjectID)
+	return offer, nil
+}
+
+// GetOffer retrieves an offer visible to its buyer or seller
+func (s *OfferService) GetOffer(ctx context.Context, id string, userID primitive.ObjectID) (*models.Offer, error) {
+	offer, err := s.getOffer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if offer.PartyOf(userID) == "" {
+		return nil, apperrors.NewNotFoundError("offer not found")
+	}
+	return offer, nil
+}
+
+// GetVehicleOffers lists every offer made on a vehicle, newest first; only the vehicle's owner can see them
+func (s *OfferService) GetVehicleOffers(ctx context.Context, vehicleID string, userID primitive.ObjectID) ([]models.Offer, error) {
+	objectID, err := primitive.ObjectIDFromHex(vehicleID)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid vehicle ID")
+	}
+
+	count, err := s.vehicleCollection.CountDocuments(ctx, bson.M{"_id": objectID, "ownerId": userID})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, apperrors.NewNotFoundError("vehicle not found")
+	}
+
+	return s.findOffers(ctx, bson.M{"vehicleId": objectID})
+}
+
+// GetMyOffers lists the offers the user made as a buyer or received as a seller, newest first
+func (s *OfferService) GetMyOffers(ctx context.Context, userID primitive.ObjectID) ([]models.Offer, error) {
+	return s.findOffers(ctx, bson.M{"$or": bson.A{bson.M{"buyerId": userID}, bson.M{"sellerId": userID}}})
+}
+
+// CounterOffer answers the latest amount with another one, handing the turn to the other party
+func (s *OfferService) CounterOffer(ctx context.Context, id string, req *models.CounterOfferRequest, userID primitive.ObjectID) (*models.Offer, error) {
+	if err := req.Validate(); err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+
+	offer, party, err := s.getOfferForTurn(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := req.Amount.WithCurrency(offer.Currency)
+	if err != nil {
+		return nil, apperrors.NewValidationError("amount: " + err.Error())
+	}
+	if cmp, err := amount.Cmp(offer.Amount); err == nil && cmp == 0 {
+		return nil, apperrors.NewValidationError("counter-offer must change the amount; accept the offer instead")
+	}
+
+	now := time.Now()
+	round := models.OfferRound{Party: party, ActorID: userID, Amount: amount, Message: req.Message, CreatedAt: now}
+	update := bson.M{
+		"$set": bson.M{
+			"amount":        amount,
+			"awaitingParty": otherOfferParty(party),
+			"expiresAt":     now.Add(s.ttl),
+			"updatedAt":     now,
+		},
+		"$push": bson.M{"rounds": round},
+	}
+	if err := s.applyTurn(ctx, offer, update); err != nil {
+		return nil, err
+	}
+
+	offer.Amount = amount
+	offer.AwaitingParty = otherOfferParty(party)
+	offer.ExpiresAt = now.Add(s.ttl)
+	offer.UpdatedAt = now
+	offer.Rounds = append(offer.Rounds, round)
+	return offer, nil
+}
+
+// RejectOffer closes the negotiation without a sale
+func (s *OfferService) RejectOffer(ctx context.Context, id string, req *models.RejectOfferRequest, userID primitive.ObjectID) (*models.Offer, error) {
+	offer, _, err := s.getOfferForTurn(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"$set":   bson.M{"status": models.OfferStatusRejected, "reason": req.Reason, "updatedAt": now},
+		"$unset": bson.M{"awaitingParty": ""},
+	}
+	if err := s.applyTurn(ctx, offer, update); err != nil {
+		return nil, err
+	}
+
+	offer.Status = models.OfferStatusRejected
+	offer.Reason = req.Reason
+	offer.AwaitingParty = ""
+	offer.UpdatedAt = now
+	return offer, nil
+}
+
+// AcceptOffer agrees to the latest amount and creates a pending transaction for it
+// Every other open offer on the vehicle is rejected
+func (s *OfferService) AcceptOffer(ctx context.Context, id string, userID primitive.ObjectID) (*models.Offer, *models.Transaction, error) {
+	offer, _, err := s.getOfferForTurn(ctx, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Claim the offer first so a concurrent counter-offer or second accept cannot also succeed
+	now := time.Now()
+	update := bson.M{
+		"$set":   bson.M{"status": models.OfferStatusAccepted, "updatedAt": now},
+		"$unset": bson.M{"awaitingParty": ""},
+	}
+	if err := s.applyTurn(ctx, offer, update); err != nil {
+		return nil, nil, err
+	}
+
+	transaction, err := s.transactions.CreateTransaction(ctx, &models.CreateTransactionRequest{
+		VehicleID:     offer.VehicleID.Hex(),
+		BuyerID:       offer.BuyerID.Hex(),
+		Amount:        offer.Amount,
+		Currency:      offer.Currency,
+		PaymentMethod: offer.PaymentMethod,
+		Escrow:        offer.Escrow,
+		Notes:         fmt.Sprintf("Created from accepted offer %s", offer.ID.Hex()),
+	}, offer.SellerID)
+	if err != nil {
+		// Reopen the offer so the parties can try again once the problem is resolved
+		_, _ = s.collection.UpdateOne(ctx,
+			bson.M{"_id": offer.ID, "status": models.OfferStatusAccepted, "transactionId": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"status": models.OfferStatusOpen, "awaitingParty": offer.AwaitingParty, "updatedAt": time.Now()}},
+		)
+		return nil, nil, err
+	}
+
+	if _, err := s.collection.UpdateOne(ctx, bson.M{"_id": offer.ID}, bson.M{"$set": bson.M{"transactionId": transaction.ID}}); err != nil {
+		return nil, nil, err
+	}
+	if _, err := s.collection.UpdateMany(ctx,
+		bson.M{"vehicleId": offer.VehicleID, "status": models.OfferStatusOpen, "_id": bson.M{"$ne": offer.ID}},
+		bson.M{
+			"$set":   bson.M{"status": models.OfferStatusRejected, "reason": "another offer was accepted", "updatedAt": now},
+			"$unset": bson.M{"awaitingParty": ""},
+		},
+	); err != nil {
+		return nil, nil, err
+	}
+
+	offer.Status = models.OfferStatusAccepted
+	offer.AwaitingParty = ""
+	offer.TransactionID = &transaction.ID
+	offer.UpdatedAt = now
+	return offer, transaction, nil
+}
+
+// ExpireOffers marks open offers whose TTL has lapsed as expired
+// Returns how many offers expired
+func (s *OfferService) ExpireOffers(ctx context.Context, now time.Time) (int64, error) {
+	result, err := s.collection.UpdateMany(ctx,
+		bson.M{"status": models.OfferStatusOpen, "expiresAt": bson.M{"$lte": now}},
+		bson.M{
+			"$set":   bson.M{"status": models.OfferStatusExpired, "updatedAt": now},
+			"$unset": bson.M{"awaitingParty": ""},
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// getOffer loads an offer by ID
+func (s *OfferService) getOffer(ctx context.Context, id string) (*models.Offer, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid offer ID")
+	}
+
+	var offer models.Offer
+	if err := s.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&offer); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError("offer not found")
+		}
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// getOfferForTurn loads an offer and checks that it is userID's turn to respond to it
+// An offer found past its TTL is marked expired
+func (s *OfferService) getOfferForTurn(ctx context.Context, id string, userID primitive.ObjectID) (*models.Offer, string, error) {
+	offer, err := s.GetOffer(ctx, id, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	party := offer.PartyOf(userID)
+	now := time.Now()
+	if err := checkOfferTurn(offer, party, now); err != nil {
+		if offer.IsExpired(now) {
+			_, _ = s.collection.UpdateOne(ctx,
+				bson.M{"_id": offer.ID, "status": models.OfferStatusOpen, "expiresAt": offer.ExpiresAt},
+				bson.M{"$set": bson.M{"status": models.OfferStatusExpired, "updatedAt": now}, "$unset": bson.M{"awaitingParty": ""}},
+			)
+		}
+		return nil, "", err
+	}
+	return offer, party, nil
+}
+
+// applyTurn updates an offer only if nobody responded to it since it was loaded
+func (s *OfferService) applyTurn(ctx context.Context, offer *models.Offer, update bson.M) error {
+	filter := bson.M{
+		"_id":           offer.ID,
+		"status":        models.OfferStatusOpen,
+		"awaitingParty": offer.AwaitingParty,
+		"rounds":        bson.M{"$size": len(offer.Rounds)},
+		"expiresAt":     bson.M{"$gt": time.Now()},
+	}
+	result, err := s.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errOfferModified
+	}
+	return nil
+}
+
+// findOffers lists offers matching filter, newest first
+func (s *OfferService) findOffers(ctx context.Context, filter bson.M) ([]models.Offer, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	offers := []models.Offer{}
+	if err := cursor.All(ctx, &offers); err != nil {
+		return nil, err
+	}
+	return offers, nil
+}
+
+// checkOfferTurn checks that party may accept, reject or counter the offer at now
+func checkOfferTurn(offer *models.Offer, party string, now time.Time) error {
+	if offer.Status != models.OfferStatusOpen {
+		return apperrors.NewInvalidStateTransitionError(fmt.Sprintf("offer is already %s", offer.Status))
+	}
+	if offer.IsExpired(now) {
+		return apperrors.NewInvalidStateTransitionError("offer has expired")
+	}
+	if party != offer.AwaitingParty {
+		return apperrors.NewInvalidStateTransitionError(fmt.Sprintf("waiting for the %s to respond", offer.AwaitingParty))
+	}
+	return nil
+}
+
+// otherOfferParty returns the party on the other side of the negotiation
+func otherOfferParty(party string) string {
+	if party == models.OfferPartyBuyer {
+		return models.OfferPartySeller
+	}
+	return models.OfferPartyBuyer
+}
+
+// OfferExpiryJob periodically expires offers nobody responded to in time
+type OfferExpiryJob struct {
+	offers   *OfferService
+	interval time.Duration
+}
+
+// NewOfferExpiryJob creates a job that checks for lapsed offers every interval
+func NewOfferExpiryJob(offers *OfferService, interval time.Duration) *OfferExpiryJob {
+	return &OfferExpiryJob{
+		offers:   offers,
+		interval: interval,
+	}
+}
+
+// Run expires lapsed offers every interval until ctx is cancelled
+// Errors do not stop the loop; they are passed to onError and the offers are retried next run
+func (j *OfferExpiryJob) Run(ctx context.Context, onError func(error)) {
+	runEvery(ctx, j.interval, func(ctx context.Context, now time.Time) error {
+		_, err := j.offers.ExpireOffers(ctx, now)
+		return err
+	}, onError)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+func TestCheckOfferTurn(t *testing.T) {
+	now := time.Now()
+	open := func() *models.Offer {
+		return &models.Offer{
+			Status:        models.OfferStatusOpen,
+			AwaitingParty: models.OfferPartySeller,
+			ExpiresAt:     now.Add(time.Hour),
+		}
+	}
+
+	assert.NoError(t, checkOfferTurn(open(), models.OfferPartySeller, now))
+
+	err := checkOfferTurn(open(), models.OfferPartyBuyer, now)
+	assert.EqualError(t, err, "waiting for the seller to respond")
+	var appErr *apperrors.AppError
+	assert.ErrorAs(t, err, &appErr)
+
+	assert.EqualError(t, checkOfferTurn(open(), models.OfferPartySeller, now.Add(time.Hour)), "offer has expired")
+
+	accepted := open()
+	accepted.Status = models.OfferStatusAccepted
+	assert.EqualError(t, checkOfferTurn(accepted, models.OfferPartySeller, now), "offer is already accepted")
+}
+
+func TestOtherOfferParty(t *testing.T) {
+	assert.Equal(t, models.OfferPartySeller, otherOfferParty(models.OfferPartyBuyer))
+	assert.Equal(t, models.OfferPartyBuyer, otherOfferParty(models.OfferPartySeller))
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// PricingRules breaks the price of a sale down into the lines the buyer pays
+// The first line is always the vehicle price; the lines sum to the transaction's amount
+type PricingRules interface {
+	Breakdown(ctx context.Context, sale PricingInput) ([]models.PriceLine, error)
+}
+
+// PricingInput is what a sale is priced from
+type PricingInput struct {
+	VehiclePrice money.Money     // agreed price in the transaction's currency
+	Location     models.Location // where the vehicle is sold, which decides the sales tax
+}
+
+// PricingRulesConfig holds the fees, sales tax and commission charged on a sale
+// Fixed fees are stated in Currency and converted to the transaction's currency at the rates in effect
+type PricingRulesConfig struct {
+	Currency         string      `json:"currency"`
+	DocumentationFee money.Money `json:"documentationFee"`
+	RegistrationFee  money.Money `json:"registrationFee"`
+
+	// Platform commission as a percentage of the vehicle price
+	CommissionPercent float64 `json:"commissionPercent"`
+
+	// Sales tax as a percentage of the vehicle price, where no jurisdiction matches
+	DefaultSalesTaxPercent float64 `json:"defaultSalesTaxPercent"`
+
+	// Jurisdictions with their own sales tax or registration fee; a state rule wins over its country's
+	Jurisdictions []PricingJurisdiction `json:"jurisdictions"`
+}
+
+// PricingJurisdiction overrides the defaults for a country, or a state within it
+type PricingJurisdiction struct {
+	Country         string       `json:"country"`
+	State           string       `json:"state"` // empty applies to the whole country
+	SalesTaxPercent float64      `json:"salesTaxPercent"`
+	RegistrationFee *money.Money `json:"registrationFee"` // the default registration fee when omitted
+}
+
+// DefaultPricingRulesConfig returns the rules used when no configuration file is provided
+// Nothing is charged on top of the vehicle price
+func DefaultPricingRulesConfig() PricingRulesConfig {
+	return PricingRulesConfig{Currency: money.DefaultCurrency}
+}
+
+// LoadPricingRulesConfig reads pricing rules from a JSON file such as
+// {"currency": "USD", "documentationFee": "150", "registrationFee": "85", "commissionPercent": 2.5,
+// "jurisdictions": [{"country": "Nigeria", "salesTaxPercent": 7.5}]}
+// Fields missing from the file keep their default values
+func LoadPricingRulesConfig(path string) (PricingRulesConfig, error) {
+	cfg := DefaultPricingRulesConfig()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read pricing rules: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse pricing rules: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks the rules and attaches the rules' currency to fees given as bare amounts
+func (c *PricingRulesConfig) Validate() error {
+	if !money.IsValidCurrency(c.Currency) {
+		return fmt.Errorf("currency %q is not a supported ISO 4217 code", c.Currency)
+	}
+
+	if err := c.fee("documentationFee", &c.DocumentationFee); err != nil {
+		return err
+	}
+	if err := c.fee("registrationFee", &c.RegistrationFee); err != nil {
+		return err
+	}
+	if err := validatePercent("commissionPercent", c.CommissionPercent); err != nil {
+		return err
+	}
+	if err := validatePercent("defaultSalesTaxPercent", c.DefaultSalesTaxPercent); err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	for i := range c.Jurisdictions {
+		jurisdiction := &c.Jurisdictions[i]
+		if strings.TrimSpace(jurisdiction.Country) == "" {
+			return fmt.Errorf("jurisdiction %d: country is required", i+1)
+		}
+		key := strings.ToLower(jurisdiction.Country) + "/" + strings.ToLower(jurisdiction.State)
+		if seen[key] {
+			return fmt.Errorf("jurisdiction %s is listed more than once", jurisdiction.name())
+		}
+		seen[key] = true
+
+		if err := validatePercent(jurisdiction.name()+" salesTaxPercent", jurisdiction.SalesTaxPercent); err != nil {
+			return err
+		}
+		if jurisdiction.RegistrationFee != nil {
+			if err := c.fee(jurisdiction.name()+" registrationFee", jurisdiction.RegistrationFee); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// fee validates a fixed fee and stamps it with the rules' currency
+func (c *PricingRulesConfig) fee(name string, fee *money.Money) error {
+	if fee.IsNegative() {
+		return fmt.Errorf("%s cannot be negative", name)
+	}
+	stamped, err := fee.WithCurrency(c.Currency)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	*fee = stamped
+	return nil
+}
+
+// validatePercent checks a percentage is between 0 and 100
+func validatePercent(name string, percent float64) error {
+	if percent < 0 || percent > 100 || math.IsNaN(percent) {
+		return fmt.Errorf("%s must be between 0 and 100", name)
+	}
+	return nil
+}
+
+// name describes the jurisdiction as it appears on a breakdown, such as "Lagos, Nigeria"
+func (j *PricingJurisdiction) name() string {
+	return joinNonEmpty(", ", strings.TrimSpace(j.State), strings.TrimSpace(j.Country))
+}
+
+// TablePricingRules prices sales from a PricingRulesConfig
+type TablePricingRules struct {
+	config PricingRulesConfig
+	rates  ExchangeRateProvider // converts fixed fees to the transaction's currency
+}
+
+// NewTablePricingRules creates pricing rules from a validated configuration; rates may be nil
+// when every transaction is in the configuration's currency
+func NewTablePricingRules(config PricingRulesConfig, rates ExchangeRateProvider) *TablePricingRules {
+	return &TablePricingRules{config: config, rates: rates}
+}
+
+// Breakdown prices the vehicle, its fees, its sales tax and the platform commission
+// Percentages apply to the vehicle price only; lines that come to zero are left out
+func (r *TablePricingRules) Breakdown(ctx context.Context, sale PricingInput) ([]models.PriceLine, error) {
+	price := sale.VehiclePrice
+	currency := price.Currency()
+	lines := []models.PriceLine{
+		{Kind: models.PriceLineVehicle, Description: "Vehicle price", Amount: price},
+	}
+
+	jurisdiction := r.jurisdiction(sale.Location)
+	registrationFee := r.config.RegistrationFee
+	taxPercent := r.config.DefaultSalesTaxPercent
+	if jurisdiction != nil {
+		taxPercent = jurisdiction.SalesTaxPercent
+		if jurisdiction.RegistrationFee != nil {
+			registrationFee = *jurisdiction.RegistrationFee
+		}
+	}
+
+	for _, fee := range []struct {
+		kind        string
+		description string
+		amount      money.Money
+	}{
+		{models.PriceLineDocumentationFee, "Documentation fee", r.config.DocumentationFee},
+		{models.PriceLineRegistrationFee, "Registration fee", registrationFee},
+	} {
+		if fee.amount.IsZero() {
+			continue
+		}
+		amount, err := r.convert(ctx, fee.amount, currency)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, models.PriceLine{Kind: fee.kind, Description: fee.description, Amount: amount})
+	}
+
+	tax, err := price.Percent(taxPercent, money.RoundHalfUp)
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+	if !tax.IsZero() {
+		line := models.PriceLine{Kind: models.PriceLineSalesTax, Description: "Sales tax", Amount: tax, Percent: taxPercent}
+		if jurisdiction != nil {
+			line.Jurisdiction = jurisdiction.name()
+		}
+		lines = append(lines, line)
+	}
+
+	commission, err := price.Percent(r.config.CommissionPercent, money.RoundHalfUp)
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+	if !commission.IsZero() {
+		lines = append(lines, models.PriceLine{
+			Kind:        models.PriceLineCommission,
+			Description: "Platform commission",
+			Amount:      commission,
+			Percent:     r.config.CommissionPercent,
+		})
+	}
+
+	return lines, nil
+}
+
+// jurisdiction returns the most specific rule matching the location, or nil if none does
+// Countries and states are matched case-insensitively
+func (r *TablePricingRules) jurisdiction(location models.Location) *PricingJurisdiction {
+	var country *PricingJurisdiction
+	for i := range r.config.Jurisdictions {
+		jurisdiction := &r.config.Jurisdictions[i]
+		if !strings.EqualFold(strings.TrimSpace(jurisdiction.Country), strings.TrimSpace(location.Country)) {
+			continue
+		}
+		switch {
+		case jurisdiction.State == "":
+			country = jurisdiction
+		case strings.EqualFold(strings.TrimSpace(jurisdiction.State), strings.TrimSpace(location.State)):
+			return jurisdiction
+		}
+	}
+	return country
+}
+
+// convert converts a fixed fee to the transaction's currency
+func (r *TablePricingRules) convert(ctx context.Context, fee money.Money, currency string) (money.Money, error) {
+	rate, err := exchangeRate(ctx, r.rates, fee.Currency(), currency)
+	if err != nil {
+		return money.Money{}, err
+	}
+	converted, err := rate.Convert(fee)
+	if err != nil {
+		return money.Money{}, apperrors.NewValidationError(err.Error())
+	}
+	return converted, nil
+}
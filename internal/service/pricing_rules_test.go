@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func testPricingRules(t *testing.T) *TablePricingRules {
+	t.Helper()
+	registration := money.MustParse("40", "")
+	cfg := PricingRulesConfig{
+		Currency:               "USD",
+		DocumentationFee:       money.MustParse("150", ""),
+		RegistrationFee:        money.MustParse("85", ""),
+		CommissionPercent:      2.5,
+		DefaultSalesTaxPercent: 5,
+		Jurisdictions: []PricingJurisdiction{
+			{Country: "Nigeria", SalesTaxPercent: 7.5},
+			{Country: "Nigeria", State: "Lagos", SalesTaxPercent: 8, RegistrationFee: &registration},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+
+	rates := NewStaticExchangeRates(&models.ExchangeRateTable{Base: "USD", Rates: map[string]float64{"NGN": 1500}})
+	return NewTablePricingRules(cfg, rates)
+}
+
+func TestTablePricingRules_Breakdown(t *testing.T) {
+	rules := testPricingRules(t)
+
+	lines, err := rules.Breakdown(context.Background(), PricingInput{
+		VehiclePrice: money.MustParse("10000", "USD"),
+		Location:     models.Location{City: "Ikeja", State: "lagos", Country: "nigeria"},
+	})
+	require.NoError(t, err)
+	require.Len(t, lines, 5)
+
+	assert.Equal(t, models.PriceLineVehicle, lines[0].Kind)
+	assert.Equal(t, money.MustParse("150", "USD"), lines[1].Amount)
+	assert.Equal(t, money.MustParse("40", "USD"), lines[2].Amount, "the state's registration fee wins")
+	assert.Equal(t, models.PriceLineSalesTax, lines[3].Kind)
+	assert.Equal(t, money.MustParse("800", "USD"), lines[3].Amount)
+	assert.Equal(t, "Lagos, Nigeria", lines[3].Jurisdiction)
+	assert.Equal(t, models.PriceLineCommission, lines[4].Kind)
+	assert.Equal(t, money.MustParse("250", "USD"), lines[4].Amount)
+
+	total, err := models.SumPriceLines(lines, "USD")
+	require.NoError(t, err)
+	assert.Equal(t, money.MustParse("11240", "USD"), total)
+}
+
+func TestTablePricingRules_Jurisdictions(t *testing.T) {
+	rules := testPricingRules(t)
+	price := money.MustParse("1000000", "NGN")
+
+	// Other states fall back to the country's rate, with fixed fees converted from the rules' currency
+	lines, err := rules.Breakdown(context.Background(), PricingInput{VehiclePrice: price, Location: models.Location{State: "Abuja", Country: "Nigeria"}})
+	require.NoError(t, err)
+	require.Len(t, lines, 5)
+	assert.Equal(t, money.MustParse("225000", "NGN"), lines[1].Amount)
+	assert.Equal(t, money.MustParse("75000", "NGN"), lines[3].Amount)
+	assert.Equal(t, "Nigeria", lines[3].Jurisdiction)
+
+	// Unlisted countries pay the default rate
+	lines, err = rules.Breakdown(context.Background(), PricingInput{VehiclePrice: price, Location: models.Location{Country: "Ghana"}})
+	require.NoError(t, err)
+	assert.Equal(t, money.MustParse("50000", "NGN"), lines[3].Amount)
+	assert.Empty(t, lines[3].Jurisdiction)
+}
+
+func TestTablePricingRules_DefaultChargesNothing(t *testing.T) {
+	rules := NewTablePricingRules(DefaultPricingRulesConfig(), nil)
+
+	lines, err := rules.Breakdown(context.Background(), PricingInput{VehiclePrice: money.MustParse("5000", "EUR")})
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.Equal(t, money.MustParse("5000", "EUR"), lines[0].Amount)
+}
+
+func TestLoadPricingRulesConfig(t *testing.T) {
+	t.Run("empty path uses defaults", func(t *testing.T) {
+		cfg, err := LoadPricingRulesConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultPricingRulesConfig(), cfg)
+	})
+
+	t.Run("file fees take the rules' currency", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pricing.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"currency":"NGN","documentationFee":"50000","jurisdictions":[{"country":"Nigeria","salesTaxPercent":7.5}]}`), 0o600))
+
+		cfg, err := LoadPricingRulesConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, money.MustParse("50000", "NGN"), cfg.DocumentationFee)
+		assert.Equal(t, 7.5, cfg.Jurisdictions[0].SalesTaxPercent)
+	})
+
+	for name, body := range map[string]string{
+		"unknown currency":       `{"currency":"XYZ"}`,
+		"negative fee":           `{"documentationFee":"-1"}`,
+		"percent over 100":       `{"commissionPercent":120}`,
+		"jurisdiction country":   `{"jurisdictions":[{"state":"Lagos","salesTaxPercent":5}]}`,
+		"duplicate jurisdiction": `{"jurisdictions":[{"country":"Nigeria"},{"country":"nigeria"}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pricing.json")
+			require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+
+			_, err := LoadPricingRulesConfig(path)
+			assert.Error(t, err)
+		})
+	}
+}
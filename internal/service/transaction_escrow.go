@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+// escrowReleaseBatch caps how many lapsed escrows are released per scheduler run
+const escrowReleaseBatch = 100
+
+// FundTransaction holds the buyer's payment for an escrow transaction
+func (s *TransactionService) FundTransaction(ctx context.Context, id string, req *models.FundTransactionRequest, userID primitive.ObjectID) (*models.Transaction, error) {
+	transaction, actor, err := s.getTransactionForActor(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	change, err := s.stateMachine.Transition(transaction, models.TransactionStatusFunded, actor, req.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := escrowFundedEntry(transaction, change.ChangedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, intent, err := s.holdPayment(ctx, transaction, holdPurposeEscrow, transaction.Amount, req.PaymentSource)
+	if err != nil {
+		return nil, err
+	}
+
+	now := change.ChangedAt
+	set := bson.M{
+		"escrow.status":                       models.EscrowStatusHeld,
+		"escrow.provider":                     provider.Name(),
+		"escrow.holdReference":                intent.ID,
+		"escrow.fundedAt":                     now,
+		"paymentDetails.transactionReference": intent.ID,
+		"paymentDetails.paidAt":               now,
+	}
+
+	updated, err := s.applyTransition(ctx, transaction, change, set, req.Notes, entry)
+	if err != nil {
+		// Nothing was recorded, so the hold is given back
+		_, _ = provider.Refund(ctx, intent.ID)
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// MarkDelivered records that the seller handed the vehicle over, starting the dispute window
+// Funds are released automatically once the window lapses unless the buyer confirms delivery sooner
+func (s *TransactionService) MarkDelivered(ctx context.Context, id string, notes string, userID primitive.ObjectID) (*models.Transaction, error) {
+	transaction, actor, err := s.getTransactionForActor(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.markDelivered(ctx, transaction, actor, notes)
+}
+
+// markDelivered moves the funded transaction to delivered and starts the dispute window
+func (s *TransactionService) markDelivered(ctx context.Context, transaction *models.Transaction, actor TransactionActor, notes string) (*models.Transaction, error) {
+	change, err := s.stateMachine.Transition(transaction, models.TransactionStatusDelivered, actor, notes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := change.ChangedAt
+	set := bson.M{
+		"escrow.deliveredAt": now,
+		"escrow.releaseAt":   now.Add(s.disputeWindow),
+	}
+
+	return s.applyTransition(ctx, transaction, change, set, notes, nil)
+}
+
+// ConfirmDelivery releases the held funds to the seller and transfers the vehicle to the buyer
+func (s *TransactionService) ConfirmDelivery(ctx context.Context, id string, notes string, userID primitive.ObjectID) (*models.Transaction, error) {
+	transaction, actor, err := s.getTransactionForActor(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.releaseEscrow(ctx, transaction, actor, notes)
+}
+
+// ReleaseDueEscrows releases the funds of delivered escrow transactions whose dispute window has lapsed
+// Returns how many were released; transactions another instance released first are skipped, and
+// disputed transactions wait for the dispute to be resolved
+func (s *TransactionService) ReleaseDueEscrows(ctx context.Context, now time.Time) (int, error) {
+	filter := bson.M{
+		"status":           models.TransactionStatusDelivered,
+		"escrow.releaseAt": bson.M{"$lte": now},
+		"openDisputeId":    bson.M{"$exists": false},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "escrow.releaseAt", Value: 1}}).SetLimit(escrowReleaseBatch)
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var due []models.Transaction
+	if err := cursor.All(ctx, &due); err != nil {
+		return 0, err
+	}
+
+	system := TransactionActor{Role: TransactionRoleSystem}
+	released := 0
+	var errs []error
+	for i := range due {
+		_, err := s.releaseEscrow(ctx, &due[i], system, "dispute window expired")
+		if err == errTransactionModified {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		released++
+	}
+
+	return released, errors.Join(errs...)
+}
+
+// releaseEscrow pays out the hold, then completes the transaction and transfers ownership
+// Capturing is idempotent at the provider, so if recording the completion fails it is safe to retry
+func (s *TransactionService) releaseEscrow(ctx context.Context, transaction *models.Transaction, actor TransactionActor, reason string) (*models.Transaction, error) {
+	change, err := s.stateMachine.Transition(transaction, models.TransactionStatusCompleted, actor, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.captureHold(ctx, transaction.Escrow.Provider, transaction.Escrow.HoldReference); err != nil {
+		return nil, err
+	}
+
+	now := change.ChangedAt
+	set := bson.M{
+		"completedAt":       now,
+		"escrow.status":     models.EscrowStatusReleased,
+		"escrow.releasedAt": now,
+	}
+	if reason != "" && actor.Role != TransactionRoleSystem {
+		set["notes"] = reason
+	}
+
+	return s.completeAndTransfer(ctx, transaction, change, set)
+}
+
+// applyTransition writes a status change if the transaction is still in the status it was read in and no
+// dispute was opened since. The ledger entry for any money the change moved is posted in the same MongoDB transaction
+func (s *TransactionService) applyTransition(ctx context.Context, transaction *models.Transaction, change *models.TransactionStatusChange, set bson.M, notes string, entry *models.LedgerEntry) (*models.Transaction, error) {
+	set["status"] = change.To
+	set["updatedAt"] = change.ChangedAt
+	if notes != "" {
+		set["notes"] = notes
+	}
+
+	update := bson.M{
+		"$set":  set,
+		"$push": bson.M{"statusHistory": change},
+	}
+
+	filter := bson.M{"_id": transaction.ID, "status": change.From, "openDisputeId": disputeFreezeFilter(transaction)}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.Transaction
+	err := s.withTransaction(ctx, func(sc mongo.SessionContext) error {
+		err := s.collection.FindOneAndUpdate(sc, filter, update, opts).Decode(&updated)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errTransactionModified
+			}
+			return err
+		}
+		return s.postLedger(sc, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// getTransactionForActor loads a transaction and resolves the caller's role on it
+func (s *TransactionService) getTransactionForActor(ctx context.Context, id string, userID primitive.ObjectID) (*models.Transaction, TransactionActor, error) {
+	transaction, err := s.GetTransactionByID(ctx, id)
+	if err != nil {
+		return nil, TransactionActor{}, err
+	}
+
+	actor, ok, err := s.resolveActor(ctx, transaction, userID)
+	if err != nil {
+		return nil, TransactionActor{}, err
+	}
+	if !ok {
+		return nil, TransactionActor{}, errors.New("you are not authorized to update this transaction")
+	}
+
+	return transaction, actor, nil
+}
+
+// paymentError wraps a provider failure for the caller
+func paymentError(err error) error {
+	return apperrors.NewPaymentError("payment provider error: " + err.Error())
+}
+
+// EscrowReleaser periodically releases escrow funds whose dispute window has lapsed
+type EscrowReleaser struct {
+	transactions *TransactionService
+	interval     time.Duration
+}
+
+// NewEscrowReleaser creates a releaser that checks for lapsed dispute windows every interval
+func NewEscrowReleaser(transactions *TransactionService, interval time.Duration) *EscrowReleaser {
+	return &EscrowReleaser{
+		transactions: transactions,
+		interval:     interval,
+	}
+}
+
+// Run releases due escrows every interval until ctx is cancelled
+// Errors do not stop the loop; they are passed to onError and the transactions are retried next run
+func (r *EscrowReleaser) Run(ctx context.Context, onError func(error)) {
+	runEvery(ctx, r.interval, func(ctx context.Context, now time.Time) error {
+		_, err := r.transactions.ReleaseDueEscrows(ctx, now)
+		return err
+	}, onError)
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+func TestMarkDelivered_DisputedSaleStaysFrozen(t *testing.T) {
+	// No collection is set, so the test fails if the transition reaches MongoDB
+	s := &TransactionService{stateMachine: NewTransactionStateMachine(), disputeWindow: 72 * time.Hour}
+	disputeID := primitive.NewObjectID()
+	transaction := &models.Transaction{
+		ID:            primitive.NewObjectID(),
+		Status:        models.TransactionStatusFunded,
+		Escrow:        &models.EscrowDetails{Status: models.EscrowStatusHeld},
+		OpenDisputeID: &disputeID,
+	}
+	seller := TransactionActor{ID: primitive.NewObjectID(), Role: TransactionRoleSeller}
+
+	_, err := s.markDelivered(context.Background(), transaction, seller, "handed over")
+	require.Error(t, err)
+	appErr, ok := err.(*apperrors.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperrors.ErrCodeInvalidStateTransition, appErr.Code)
+	assert.Nil(t, transaction.Escrow.ReleaseAt, "the release window does not start while the dispute is open")
+}
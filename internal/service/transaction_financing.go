@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/financing"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+// QuoteFinancing prices a financed purchase with the same engine CreateTransaction uses
+func (s *TransactionService) QuoteFinancing(req *models.FinancingQuoteRequest) (*financing.Schedule, error) {
+	terms, err := req.Terms()
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+	schedule, err := financing.Amortize(terms)
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+	return schedule, nil
+}
+
+// GetAmortizationSchedule rebuilds the repayment plan of a financed transaction from its stored terms
+// Only the buyer, the seller and admins can see it
+func (s *TransactionService) GetAmortizationSchedule(ctx context.Context, id string, userID primitive.ObjectID) (*financing.Schedule, error) {
+	transaction, err := s.GetTransactionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_, ok, err := s.resolveActor(ctx, transaction, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("you are not authorized to view this transaction")
+	}
+
+	if transaction.PaymentMethod != models.PaymentMethodFinancing {
+		return nil, apperrors.NewValidationError("transaction is not financed")
+	}
+
+	terms, err := transaction.PaymentDetails.FinancingTermsFor(transaction.Amount)
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+	schedule, err := financing.Amortize(terms)
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+	return schedule, nil
+}
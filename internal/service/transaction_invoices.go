@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+)
+
+// invoiceIssueAttempts caps how often issuing retries after another sale took the next number
+const invoiceIssueAttempts = 5
+
+var (
+	// errInvoiceNotReady is returned when sale documents are requested before the sale completed
+	errInvoiceNotReady = apperrors.NewConflictError("invoices and bills of sale are only available for completed transactions")
+
+	// errNotSaleParty is returned when someone other than the parties or an admin requests a sale document
+	errNotSaleParty = apperrors.NewAppError(apperrors.ErrCodeForbidden, "only the buyer, the seller and admins can download sale documents", http.StatusForbidden)
+)
+
+// RenderInvoice returns the invoice PDF of a completed transaction, issuing the invoice if needed
+func (s *TransactionService) RenderInvoice(ctx context.Context, id string, userID primitive.ObjectID) (*models.Invoice, []byte, error) {
+	invoice, err := s.invoiceForParty(ctx, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := renderInvoice(invoice)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render invoice: %w", err)
+	}
+	return invoice, data, nil
+}
+
+// RenderBillOfSale returns the bill of sale PDF of a completed transaction, issuing the invoice if needed
+func (s *TransactionService) RenderBillOfSale(ctx context.Context, id string, userID primitive.ObjectID) (*models.Invoice, []byte, error) {
+	invoice, err := s.invoiceForParty(ctx, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := renderBillOfSale(invoice)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render bill of sale: %w", err)
+	}
+	return invoice, data, nil
+}
+
+// invoiceForParty loads the transaction's invoice for one of its parties or an admin
+// Sales completed before invoicing existed, or whose invoice failed to issue on completion, are issued now
+func (s *TransactionService) invoiceForParty(ctx context.Context, id string, userID primitive.ObjectID) (*models.Invoice, error) {
+	transaction, err := s.GetTransactionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok, err := s.resolveActor(ctx, transaction, userID); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errNotSaleParty
+	}
+
+	// A refunded sale was completed first, so its documents still stand as the record of the sale
+	if transaction.Status != models.TransactionStatusCompleted && transaction.Status != models.TransactionStatusRefunded {
+		return nil, errInvoiceNotReady
+	}
+
+	return s.issueInvoice(ctx, transaction)
+}
+
+// issueInvoice returns the transaction's invoice, issuing it with the seller's next number the first time
+// A number is only used by inserting the invoice that holds it, and the unique seller and sequence
+// index rejects a number already taken, so numbers are never skipped or reused. The counter records the
+// last number issued; when a failure leaves it behind, the next issue moves it past the taken number
+func (s *TransactionService) issueInvoice(ctx context.Context, transaction *models.Transaction) (*models.Invoice, error) {
+	if existing, err := s.findInvoice(ctx, transaction.ID); err != nil || existing != nil {
+		return existing, err
+	}
+
+	snapshot, err := s.saleSnapshot(ctx, transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < invoiceIssueAttempts; attempt++ {
+		last, err := s.lastInvoiceSequence(ctx, transaction.SellerID)
+		if err != nil {
+			return nil, err
+		}
+
+		invoice := models.Invoice{
+			ID:            primitive.NewObjectID(),
+			TransactionID: transaction.ID,
+			SellerID:      transaction.SellerID,
+			Sequence:      last + 1,
+			Number:        models.FormatInvoiceNumber(last + 1),
+			Snapshot:      *snapshot,
+			IssuedAt:      time.Now(),
+		}
+		_, err = s.invoiceCollection.InsertOne(ctx, invoice)
+		if err == nil {
+			// The next issue repairs the counter if this fails
+			_ = s.advanceInvoiceCounter(ctx, transaction.SellerID, last)
+			return &invoice, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+
+		// Either this transaction's invoice was issued concurrently, or another sale took the number
+		if existing, err := s.findInvoice(ctx, transaction.ID); err != nil || existing != nil {
+			return existing, err
+		}
+		if err := s.advanceInvoiceCounter(ctx, transaction.SellerID, last); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, apperrors.NewConflictError("invoice numbering is busy, please retry")
+}
+
+// lastInvoiceSequence returns the last invoice number issued for the seller, 0 before their first
+func (s *TransactionService) lastInvoiceSequence(ctx context.Context, sellerID primitive.ObjectID) (int64, error) {
+	var counter models.InvoiceCounter
+	err := s.invoiceCounterCollection.FindOne(ctx, bson.M{"_id": sellerID}).Decode(&counter)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return counter.Sequence, nil
+}
+
+// advanceInvoiceCounter moves the seller's counter from one number to the next
+// The update only applies while the counter still holds from, so concurrent issues advance it once
+func (s *TransactionService) advanceInvoiceCounter(ctx context.Context, sellerID primitive.ObjectID, from int64) error {
+	_, err := s.invoiceCounterCollection.UpdateOne(ctx,
+		bson.M{"_id": sellerID, "sequence": from},
+		bson.M{"$set": bson.M{"sequence": from + 1}},
+		options.Update().SetUpsert(from == 0),
+	)
+	// Upserting a counter another issue created first collides on _id; that issue advanced it
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// findInvoice returns the transaction's invoice, or nil if none was issued
+func (s *TransactionService) findInvoice(ctx context.Context, transactionID primitive.ObjectID) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := s.invoiceCollection.FindOne(ctx, bson.M{"transactionId": transactionID}).Decode(&invoice)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// saleSnapshot captures the parties, the vehicle and the price of the sale
+func (s *TransactionService) saleSnapshot(ctx context.Context, transaction *models.Transaction) (*models.SaleSnapshot, error) {
+	var vehicle models.Vehicle
+	if err := s.vehicleCollection.FindOne(ctx, bson.M{"_id": transaction.VehicleID}).Decode(&vehicle); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("vehicle not found")
+		}
+		return nil, err
+	}
+
+	seller, err := s.saleParty(ctx, transaction.SellerID)
+	if err != nil {
+		return nil, err
+	}
+	buyer, err := s.saleParty(ctx, transaction.BuyerID)
+	if err != nil {
+		return nil, err
+	}
+
+	completedAt := transaction.UpdatedAt
+	if transaction.CompletedAt != nil {
+		completedAt = *transaction.CompletedAt
+	}
+
+	details := transaction.PaymentDetails
+	return &models.SaleSnapshot{
+		Seller: *seller,
+		Buyer:  *buyer,
+		Vehicle: models.SaleVehicle{
+			ID:           vehicle.ID,
+			Make:         vehicle.Make,
+			Model:        vehicle.Model,
+			Year:         vehicle.Year,
+			Mileage:      vehicle.Mileage,
+			Color:        vehicle.Meta.Color,
+			Transmission: vehicle.Meta.Transmission,
+			FuelType:     vehicle.Meta.FuelType,
+			Location:     joinNonEmpty(", ", vehicle.Location.City, vehicle.Location.State, vehicle.Location.Country),
+		},
+		Amount:           transaction.Amount,
+		Lines:            transaction.PriceLines,
+		Currency:         transaction.Currency,
+		PaymentMethod:    transaction.PaymentMethod,
+		PaymentReference: details.TransactionReference,
+		Escrow:           transaction.Escrow != nil,
+		DownPayment:      details.DownPayment,
+		FinancedAmount:   details.FinancedAmount,
+		MonthlyPayment:   details.MonthlyPayment,
+		FinancingTerms:   details.FinancingTerms,
+		InspectionID:     transaction.InspectionID,
+		CompletedAt:      completedAt,
+	}, nil
+}
+
+// saleParty loads the name and email a sale document shows for a user
+func (s *TransactionService) saleParty(ctx context.Context, userID primitive.ObjectID) (*models.SaleParty, error) {
+	var user models.User
+	if err := s.userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &models.SaleParty{
+		ID:    user.ID,
+		Name:  strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Email: user.Email,
+	}, nil
+}
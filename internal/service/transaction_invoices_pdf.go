@@ -0,0 +1,171 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+	"github.com/Over-knight/Lujay-assesment/internal/pdf"
+)
+
+// saleDateLayout formats dates on sale documents
+const saleDateLayout = "2 January 2006"
+
+// renderInvoice renders the invoice PDF from the invoice's snapshot
+// Nothing outside the invoice is read, so the same invoice always renders the same bytes
+func renderInvoice(invoice *models.Invoice) ([]byte, error) {
+	sale := invoice.Snapshot
+	w := &reportWriter{doc: pdf.New(), label: "Invoice"}
+	w.newPage()
+
+	w.text("Invoice "+invoice.Number, pdf.HelveticaBold, 18, pdf.Black, 0)
+	w.y += 4
+	details := [][2]string{
+		{"Invoice", invoice.Number},
+		{"Issued", invoice.IssuedAt.UTC().Format(saleDateLayout)},
+		{"Date of sale", sale.CompletedAt.UTC().Format(saleDateLayout)},
+		{"Transaction", invoice.TransactionID.Hex()},
+		{"Payment", describePaymentMethod(sale)},
+	}
+	if sale.PaymentReference != "" {
+		details = append(details, [2]string{"Reference", sale.PaymentReference})
+	}
+	w.details(details)
+
+	w.parties(sale)
+	w.saleVehicle(sale.Vehicle)
+
+	w.section("Charges")
+	vehicle := fmt.Sprintf("%d %s %s", sale.Vehicle.Year, sale.Vehicle.Make, sale.Vehicle.Model)
+	if len(sale.Lines) == 0 {
+		w.charge(vehicle, sale.Amount, sale.Currency, pdf.Helvetica)
+	}
+	for _, line := range sale.Lines {
+		w.charge(describePriceLine(line, vehicle), line.Amount, sale.Currency, pdf.Helvetica)
+	}
+	w.y += 2
+	w.page.Line(reportMargin, w.y, reportMargin+reportContentWidth, w.y, 0.5, reportRule)
+	w.y += 8
+	w.charge("Total", sale.Amount, sale.Currency, pdf.HelveticaBold)
+
+	if sale.PaymentMethod == models.PaymentMethodFinancing {
+		w.section("Financing")
+		w.charge("Down payment", sale.DownPayment, sale.Currency, pdf.Helvetica)
+		w.charge("Financed amount", sale.FinancedAmount, sale.Currency, pdf.Helvetica)
+		if sale.FinancingTerms > 0 {
+			w.y += 4
+			w.text(fmt.Sprintf("The financed amount is repaid in %d monthly installments of %s %s.",
+				sale.FinancingTerms, formatAmount(sale.MonthlyPayment), sale.Currency), pdf.Helvetica, 10, reportMuted, 0)
+		}
+	}
+
+	w.footers(fmt.Sprintf("Invoice %s  |  Issued %s", invoice.Number, invoice.IssuedAt.UTC().Format("2 Jan 2006 15:04 MST")))
+	return w.doc.Bytes()
+}
+
+// renderBillOfSale renders the bill of sale PDF from the invoice's snapshot
+func renderBillOfSale(invoice *models.Invoice) ([]byte, error) {
+	sale := invoice.Snapshot
+	w := &reportWriter{doc: pdf.New(), label: "Bill of Sale"}
+	w.newPage()
+
+	w.text("Bill of sale", pdf.HelveticaBold, 18, pdf.Black, 0)
+	w.y += 4
+	w.details([][2]string{
+		{"Date of sale", sale.CompletedAt.UTC().Format(saleDateLayout)},
+		{"Invoice", invoice.Number},
+		{"Transaction", invoice.TransactionID.Hex()},
+	})
+
+	w.y += 8
+	w.text(fmt.Sprintf("For the sum of %s %s, the seller named below sells and transfers ownership of the vehicle described below to the buyer named below, and acknowledges receipt of payment.",
+		formatAmount(sale.Amount), sale.Currency), pdf.Helvetica, 10, pdf.Black, 0)
+	w.text("The seller declares that they are the lawful owner of the vehicle and that it is free of any lien or claim not disclosed to the buyer.",
+		pdf.Helvetica, 10, pdf.Black, 0)
+
+	w.parties(sale)
+	w.saleVehicle(sale.Vehicle)
+
+	w.section("Price")
+	w.text(fmt.Sprintf("%s %s", formatAmount(sale.Amount), sale.Currency), pdf.HelveticaBold, 14, pdf.Black, 0)
+	w.text(describePaymentMethod(sale), pdf.Helvetica, 10, reportMuted, 0)
+
+	w.section("Signatures")
+	w.y += 24
+	w.ensureSpace(40)
+	half := (reportContentWidth - 40) / 2
+	for i, party := range []models.SaleParty{sale.Seller, sale.Buyer} {
+		x := reportMargin + float64(i)*(half+40)
+		role := "Seller"
+		if i == 1 {
+			role = "Buyer"
+		}
+		w.page.Line(x, w.y, x+half, w.y, 0.5, pdf.Black)
+		w.page.Text(x, w.y+6, pdf.Helvetica, 9, reportMuted, fmt.Sprintf("%s: %s", role, party.Name))
+		w.page.Text(x, w.y+20, pdf.Helvetica, 9, reportMuted, "Date:")
+	}
+	w.y += 34
+
+	w.footers(fmt.Sprintf("Bill of sale for invoice %s  |  Sold %s", invoice.Number, sale.CompletedAt.UTC().Format("2 Jan 2006")))
+	return w.doc.Bytes()
+}
+
+// parties draws the seller and the buyer
+func (w *reportWriter) parties(sale models.SaleSnapshot) {
+	w.section("Seller")
+	w.details([][2]string{{"Name", sale.Seller.Name}, {"Email", sale.Seller.Email}})
+	w.section("Buyer")
+	w.details([][2]string{{"Name", sale.Buyer.Name}, {"Email", sale.Buyer.Email}})
+}
+
+// saleVehicle draws the vehicle sold
+func (w *reportWriter) saleVehicle(vehicle models.SaleVehicle) {
+	w.section("Vehicle")
+	details := [][2]string{
+		{"Vehicle", fmt.Sprintf("%d %s %s", vehicle.Year, vehicle.Make, vehicle.Model)},
+		{"Listing", vehicle.ID.Hex()},
+		{"Mileage", fmt.Sprintf("%s km", formatThousands(int64(vehicle.Mileage)))},
+	}
+	if meta := joinNonEmpty(", ", vehicle.Color, vehicle.Transmission, vehicle.FuelType); meta != "" {
+		details = append(details, [2]string{"Specification", meta})
+	}
+	if vehicle.Location != "" {
+		details = append(details, [2]string{"Location", vehicle.Location})
+	}
+	w.details(details)
+}
+
+// charge draws a line item with its amount right-aligned
+func (w *reportWriter) charge(label string, amount money.Money, currency string, font pdf.Font) {
+	w.ensureSpace(16)
+	value := fmt.Sprintf("%s %s", formatAmount(amount), currency)
+	w.page.Text(reportMargin, w.y, font, 10, pdf.Black, label)
+	w.page.Text(reportMargin+reportContentWidth-pdf.TextWidth(value, font, 10), w.y, font, 10, pdf.Black, value)
+	w.y += 15
+}
+
+// describePriceLine labels a line of the sale's breakdown, naming the vehicle on its price line
+func describePriceLine(line models.PriceLine, vehicle string) string {
+	switch {
+	case line.Kind == models.PriceLineVehicle:
+		return vehicle
+	case line.Jurisdiction != "":
+		return fmt.Sprintf("%s, %s (%g%%)", line.Description, line.Jurisdiction, line.Percent)
+	case line.Percent != 0:
+		return fmt.Sprintf("%s (%g%%)", line.Description, line.Percent)
+	}
+	return line.Description
+}
+
+// describePaymentMethod describes how the sale was paid for
+func describePaymentMethod(sale models.SaleSnapshot) string {
+	method := strings.ReplaceAll(sale.PaymentMethod, "_", " ")
+	if method != "" {
+		method = strings.ToUpper(method[:1]) + method[1:]
+	}
+	if sale.Escrow {
+		method += ", held in escrow until delivery"
+	}
+	return method
+}
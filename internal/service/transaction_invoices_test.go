@@ -0,0 +1,79 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+func testInvoice(method string) *models.Invoice {
+	issuedAt := time.Date(2025, 6, 2, 9, 30, 0, 0, time.UTC)
+	return &models.Invoice{
+		ID:            primitive.NewObjectID(),
+		TransactionID: primitive.NewObjectID(),
+		SellerID:      primitive.NewObjectID(),
+		Sequence:      7,
+		Number:        models.FormatInvoiceNumber(7),
+		IssuedAt:      issuedAt,
+		Snapshot: models.SaleSnapshot{
+			Seller:           models.SaleParty{ID: primitive.NewObjectID(), Name: "Chidi Motors", Email: "sales@chidi.example"},
+			Buyer:            models.SaleParty{ID: primitive.NewObjectID(), Name: "Ada Obi", Email: "ada@example.com"},
+			Vehicle:          models.SaleVehicle{ID: primitive.NewObjectID(), Make: "Toyota", Model: "Corolla", Year: 2018, Mileage: 45210, Color: "Silver", Location: "Lagos, Nigeria"},
+			Amount:           money.MustParse("12500", "USD"),
+			Currency:         "USD",
+			PaymentMethod:    method,
+			PaymentReference: "PAY-123",
+			DownPayment:      money.MustParse("2500", "USD"),
+			FinancedAmount:   money.MustParse("10000", "USD"),
+			MonthlyPayment:   money.MustParse("455.5", "USD"),
+			FinancingTerms:   24,
+			CompletedAt:      issuedAt.Add(-time.Hour),
+		},
+	}
+}
+
+func TestRenderInvoice(t *testing.T) {
+	for _, method := range []string{models.PaymentMethodBankTransfer, models.PaymentMethodFinancing} {
+		invoice := testInvoice(method)
+
+		first, err := renderInvoice(invoice)
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(first, []byte("%PDF-")))
+
+		// Rendering only reads the snapshot, so downloading again gives the same document
+		second, err := renderInvoice(invoice)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+	}
+}
+
+func TestRenderBillOfSale(t *testing.T) {
+	invoice := testInvoice(models.PaymentMethodCash)
+
+	first, err := renderBillOfSale(invoice)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(first, []byte("%PDF-")))
+
+	second, err := renderBillOfSale(invoice)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestDescribePriceLine(t *testing.T) {
+	assert.Equal(t, "2018 Toyota Corolla", describePriceLine(models.PriceLine{Kind: models.PriceLineVehicle, Description: "Vehicle price"}, "2018 Toyota Corolla"))
+	assert.Equal(t, "Sales tax, Lagos, Nigeria (7.5%)", describePriceLine(models.PriceLine{Kind: models.PriceLineSalesTax, Description: "Sales tax", Percent: 7.5, Jurisdiction: "Lagos, Nigeria"}, ""))
+	assert.Equal(t, "Platform commission (2.5%)", describePriceLine(models.PriceLine{Kind: models.PriceLineCommission, Description: "Platform commission", Percent: 2.5}, ""))
+	assert.Equal(t, "Documentation fee", describePriceLine(models.PriceLine{Kind: models.PriceLineDocumentationFee, Description: "Documentation fee"}, ""))
+}
+
+func TestDescribePaymentMethod(t *testing.T) {
+	assert.Equal(t, "Bank transfer", describePaymentMethod(models.SaleSnapshot{PaymentMethod: models.PaymentMethodBankTransfer}))
+	assert.Equal(t, "Card, held in escrow until delivery", describePaymentMethod(models.SaleSnapshot{PaymentMethod: models.PaymentMethodCard, Escrow: true}))
+}
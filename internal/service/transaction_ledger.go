@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// errLedgerNotPosted wraps failures to build or post a ledger entry
+// Entries are built before anything is written and posted in the same MongoDB transaction as the change
+// they record, so when this is returned neither was written
+var errLedgerNotPosted = errors.New("failed to post ledger entry")
+
+// priceLineAccounts is the account credited with each line of a sale's price
+var priceLineAccounts = map[string]string{
+	models.PriceLineVehicle:          models.LedgerAccountSellerPayable,
+	models.PriceLineDocumentationFee: models.LedgerAccountFeeRevenue,
+	models.PriceLineRegistrationFee:  models.LedgerAccountFeeRevenue,
+	models.PriceLineSalesTax:         models.LedgerAccountSalesTaxPayable,
+	models.PriceLineCommission:       models.LedgerAccountCommissionRevenue,
+}
+
+// postLedger appends the entry to the ledger; nil entries post nothing
+// An entry whose key was already posted is left as it is, so retried settlements are recorded once. The
+// entry is upserted rather than inserted because a duplicate key error would abort the MongoDB transaction
+func (s *TransactionService) postLedger(ctx context.Context, entry *models.LedgerEntry) error {
+	if entry == nil {
+		return nil
+	}
+	_, err := s.ledgerCollection.UpdateOne(ctx,
+		bson.M{"key": entry.Key},
+		bson.M{"$setOnInsert": entry},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errLedgerNotPosted, err)
+	}
+	return nil
+}
+
+// ledgerEntryBuilder collects postings, merging repeated postings to the same account and side
+type ledgerEntryBuilder struct {
+	entry *models.LedgerEntry
+	err   error
+}
+
+// newLedgerEntry starts an entry for a movement on the transaction
+// The key is the transaction and event, plus the refund or other item the movement belongs to, if any
+func newLedgerEntry(transaction *models.Transaction, event, currency string, now time.Time, item ...primitive.ObjectID) *ledgerEntryBuilder {
+	key := transaction.ID.Hex() + ":" + event
+	for _, id := range item {
+		key += ":" + id.Hex()
+	}
+	return &ledgerEntryBuilder{entry: &models.LedgerEntry{
+		ID:            primitive.NewObjectID(),
+		Key:           key,
+		TransactionID: transaction.ID,
+		Event:         event,
+		Currency:      currency,
+		CreatedAt:     now,
+	}}
+}
+
+// transfer debits one account and credits another with the same amount; zero amounts post nothing
+func (b *ledgerEntryBuilder) transfer(debit, credit string, amount money.Money) *ledgerEntryBuilder {
+	b.post(models.LedgerDebit, debit, amount)
+	b.post(models.LedgerCredit, credit, amount)
+	return b
+}
+
+// post adds the amount to the account's posting on the side, creating it if needed
+func (b *ledgerEntryBuilder) post(side, account string, amount money.Money) {
+	if b.err != nil || amount.IsZero() {
+		return
+	}
+	for i := range b.entry.Postings {
+		posting := &b.entry.Postings[i]
+		if posting.Side == side && posting.Account == account {
+			posting.Amount, b.err = posting.Amount.Add(amount)
+			return
+		}
+	}
+	b.entry.Postings = append(b.entry.Postings, models.LedgerPosting{Account: account, Side: side, Amount: amount})
+}
+
+// memo describes the entry
+func (b *ledgerEntryBuilder) memo(memo string) *ledgerEntryBuilder {
+	b.entry.Memo = memo
+	return b
+}
+
+// build returns the validated entry, or nil when nothing moved
+func (b *ledgerEntryBuilder) build() (*models.LedgerEntry, error) {
+	if b.err != nil {
+		return nil, fmt.Errorf("%w: %v", errLedgerNotPosted, b.err)
+	}
+	if len(b.entry.Postings) == 0 {
+		return nil, nil
+	}
+	if err := b.entry.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errLedgerNotPosted, err)
+	}
+	return b.entry, nil
+}
+
+// escrowFundedEntry records the buyer's payment going into escrow
+func escrowFundedEntry(transaction *models.Transaction, now time.Time) (*models.LedgerEntry, error) {
+	return newLedgerEntry(transaction, models.LedgerEventEscrowFunded, transaction.Currency, now).
+		transfer(models.LedgerAccountEscrow, models.LedgerAccountBuyerFunds, transaction.Amount).
+		memo("buyer payment held in escrow").
+		build()
+}
+
+// escrowRefundedEntry records a cancelled sale's escrow hold going back to the buyer
+func escrowRefundedEntry(transaction *models.Transaction, now time.Time) (*models.LedgerEntry, error) {
+	return newLedgerEntry(transaction, models.LedgerEventEscrowRefunded, transaction.Currency, now).
+		transfer(models.LedgerAccountBuyerFunds, models.LedgerAccountEscrow, transaction.Amount).
+		memo("escrow hold refunded to the buyer").
+		build()
+}
+
+// depositEntry records the reservation deposit being held, refunded or forfeited to the seller
+func depositEntry(transaction *models.Transaction, event string, now time.Time) (*models.LedgerEntry, error) {
+	deposit := transaction.Hold.Deposit
+	b := newLedgerEntry(transaction, event, deposit.Currency(), now)
+	switch event {
+	case models.LedgerEventDepositHeld:
+		b.transfer(models.LedgerAccountEscrow, models.LedgerAccountBuyerFunds, deposit).memo("reservation deposit held")
+	case models.LedgerEventDepositRefunded:
+		b.transfer(models.LedgerAccountBuyerFunds, models.LedgerAccountEscrow, deposit).memo("reservation deposit refunded to the buyer")
+	case models.LedgerEventDepositForfeited:
+		b.transfer(models.LedgerAccountBuyerFunds, models.LedgerAccountSellerPayable, deposit).
+			transfer(models.LedgerAccountSellerPayable, models.LedgerAccountEscrow, deposit).
+			memo("reservation deposit forfeited and paid to the seller")
+	default:
+		return nil, fmt.Errorf("%w: %s is not a deposit event", errLedgerNotPosted, event)
+	}
+	return b.build()
+}
+
+// saleCompletedEntry splits the sale's price between the seller, the tax authorities and the platform's revenue
+// Escrow sales are paid from the buyer's held funds, and releasing the hold pays the seller their share
+// while the rest stays with the platform. Other sales are recorded as received by the platform, with
+// the seller's share owed to them
+func saleCompletedEntry(transaction *models.Transaction, now time.Time) (*models.LedgerEntry, error) {
+	lines := transaction.PriceLines
+	if len(lines) == 0 {
+		lines = []models.PriceLine{{Kind: models.PriceLineVehicle, Amount: transaction.Amount}}
+	}
+
+	escrowed := transaction.Escrow != nil
+	source := models.LedgerAccountCash
+	if escrowed {
+		source = models.LedgerAccountBuyerFunds
+	}
+
+	b := newLedgerEntry(transaction, models.LedgerEventSaleCompleted, transaction.Currency, now)
+	sellerShare, platformShare := money.Zero(transaction.Currency), money.Zero(transaction.Currency)
+	for _, line := range lines {
+		account, ok := priceLineAccounts[line.Kind]
+		if !ok {
+			return nil, fmt.Errorf("%w: no ledger account for price line %q", errLedgerNotPosted, line.Kind)
+		}
+		b.transfer(source, account, line.Amount)
+
+		var err error
+		if account == models.LedgerAccountSellerPayable {
+			sellerShare, err = sellerShare.Add(line.Amount)
+		} else {
+			platformShare, err = platformShare.Add(line.Amount)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errLedgerNotPosted, err)
+		}
+	}
+
+	if escrowed {
+		b.transfer(models.LedgerAccountSellerPayable, models.LedgerAccountEscrow, sellerShare).
+			transfer(models.LedgerAccountCash, models.LedgerAccountEscrow, platformShare).
+			memo("sale completed and escrow released to the seller")
+	} else {
+		b.memo("sale completed with " + transaction.PaymentMethod + " payment")
+	}
+	return b.build()
+}
+
+// refundEntry records money returned to the buyer after the sale completed
+func refundEntry(transaction *models.Transaction, refund *models.TransactionRefund) (*models.LedgerEntry, error) {
+	return newLedgerEntry(transaction, models.LedgerEventRefund, transaction.Currency, refund.CreatedAt, refund.ID).
+		transfer(models.LedgerAccountRefunds, models.LedgerAccountCash, refund.Amount).
+		memo(refund.Reason).
+		build()
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// postingsByAccount flattens an entry's postings for comparison, with credits negated
+func postingsByAccount(entry *models.LedgerEntry) map[string]string {
+	postings := map[string]string{}
+	for _, posting := range entry.Postings {
+		amount := posting.Amount
+		if posting.Side == models.LedgerCredit {
+			amount = amount.Neg()
+		}
+		postings[posting.Side+" "+posting.Account] = amount.Decimal()
+	}
+	return postings
+}
+
+func TestSaleCompletedEntry(t *testing.T) {
+	now := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	usd := func(amount string) money.Money { return money.MustParse(amount, "USD") }
+	sale := func(escrow bool) *models.Transaction {
+		transaction := &models.Transaction{
+			ID:            primitive.NewObjectID(),
+			Currency:      "USD",
+			PaymentMethod: models.PaymentMethodCard,
+			Amount:        usd("11085"),
+			PriceLines: []models.PriceLine{
+				{Kind: models.PriceLineVehicle, Amount: usd("10000")},
+				{Kind: models.PriceLineDocumentationFee, Amount: usd("150")},
+				{Kind: models.PriceLineRegistrationFee, Amount: usd("85")},
+				{Kind: models.PriceLineSalesTax, Amount: usd("600")},
+				{Kind: models.PriceLineCommission, Amount: usd("250")},
+			},
+		}
+		if escrow {
+			transaction.Escrow = &models.EscrowDetails{Status: models.EscrowStatusReleased}
+		}
+		return transaction
+	}
+
+	transaction := sale(false)
+	entry, err := saleCompletedEntry(transaction, now)
+	require.NoError(t, err)
+	require.NoError(t, entry.Validate())
+	assert.Equal(t, transaction.ID.Hex()+":sale_completed", entry.Key)
+	assert.Equal(t, map[string]string{
+		"debit cash":                "11085.00",
+		"credit seller_payable":     "-10000.00",
+		"credit fee_revenue":        "-235.00",
+		"credit sales_tax_payable":  "-600.00",
+		"credit commission_revenue": "-250.00",
+	}, postingsByAccount(entry))
+
+	// Releasing escrow pays the seller's share out of the hold and keeps the rest
+	entry, err = saleCompletedEntry(sale(true), now)
+	require.NoError(t, err)
+	require.NoError(t, entry.Validate())
+	assert.Equal(t, map[string]string{
+		"debit buyer_funds":         "11085.00",
+		"credit seller_payable":     "-10000.00",
+		"credit fee_revenue":        "-235.00",
+		"credit sales_tax_payable":  "-600.00",
+		"credit commission_revenue": "-250.00",
+		"debit seller_payable":      "10000.00",
+		"credit escrow":             "-11085.00",
+		"debit cash":                "1085.00",
+	}, postingsByAccount(entry))
+
+	// Sales from before price breakdowns are all vehicle price
+	legacy := &models.Transaction{ID: primitive.NewObjectID(), Currency: "USD", Amount: usd("9000")}
+	entry, err = saleCompletedEntry(legacy, now)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"debit cash":            "9000.00",
+		"credit seller_payable": "-9000.00",
+	}, postingsByAccount(entry))
+}
+
+func TestDepositEntry(t *testing.T) {
+	now := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		event    string
+		deposit  money.Money
+		postings map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "held",
+			event:    models.LedgerEventDepositHeld,
+			deposit:  money.MustParse("500", "USD"),
+			postings: map[string]string{"debit escrow": "500.00", "credit buyer_funds": "-500.00"},
+		},
+		{
+			name:     "refunded",
+			event:    models.LedgerEventDepositRefunded,
+			deposit:  money.MustParse("500", "USD"),
+			postings: map[string]string{"debit buyer_funds": "500.00", "credit escrow": "-500.00"},
+		},
+		{
+			name:    "forfeited",
+			event:   models.LedgerEventDepositForfeited,
+			deposit: money.MustParse("500", "USD"),
+			postings: map[string]string{
+				"debit buyer_funds":     "500.00",
+				"credit seller_payable": "-500.00",
+				"debit seller_payable":  "500.00",
+				"credit escrow":         "-500.00",
+			},
+		},
+		{
+			name:     "in the deposit's currency",
+			event:    models.LedgerEventDepositHeld,
+			deposit:  money.MustParse("750000", "NGN"),
+			postings: map[string]string{"debit escrow": "750000.00", "credit buyer_funds": "-750000.00"},
+		},
+		{
+			name:    "no deposit",
+			event:   models.LedgerEventDepositRefunded,
+			deposit: money.Zero("USD"),
+		},
+		{
+			name:    "not a deposit event",
+			event:   models.LedgerEventRefund,
+			deposit: money.MustParse("500", "USD"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := &models.Transaction{
+				ID:       primitive.NewObjectID(),
+				Currency: "USD",
+				Hold:     &models.ReservationHold{Deposit: tt.deposit},
+			}
+
+			entry, err := depositEntry(transaction, tt.event, now)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errLedgerNotPosted)
+				return
+			}
+			require.NoError(t, err)
+			if tt.postings == nil {
+				assert.Nil(t, entry, "nothing moved, so nothing is posted")
+				return
+			}
+			assert.Equal(t, tt.postings, postingsByAccount(entry))
+			assert.Equal(t, tt.deposit.Currency(), entry.Currency)
+			assert.Equal(t, transaction.ID.Hex()+":"+tt.event, entry.Key)
+		})
+	}
+}
+
+func TestRefundEntry(t *testing.T) {
+	transaction := &models.Transaction{ID: primitive.NewObjectID(), Currency: "USD"}
+	refund := &models.TransactionRefund{
+		ID:        primitive.NewObjectID(),
+		Amount:    money.MustParse("1200", "USD"),
+		Reason:    "damaged on delivery",
+		CreatedAt: time.Date(2026, 9, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	entry, err := refundEntry(transaction, refund)
+	require.NoError(t, err)
+	assert.Equal(t, transaction.ID.Hex()+":refund:"+refund.ID.Hex(), entry.Key, "each partial refund is posted once")
+	assert.Equal(t, refund.CreatedAt, entry.CreatedAt)
+	assert.Equal(t, "damaged on delivery", entry.Memo)
+	assert.Equal(t, map[string]string{"debit refunds": "1200.00", "credit cash": "-1200.00"}, postingsByAccount(entry))
+}
+
+func TestSummarizeLedger(t *testing.T) {
+	row := func(account, currency, side, total string) ledgerRow {
+		var r ledgerRow
+		r.Group.Account, r.Group.Currency, r.Group.Side = account, currency, side
+		r.Total, _ = primitive.ParseDecimal128(total)
+		return r
+	}
+
+	balances, totals, err := summarizeLedger([]ledgerRow{
+		row(models.LedgerAccountSellerPayable, "USD", models.LedgerCredit, "10000"),
+		row(models.LedgerAccountSellerPayable, "USD", models.LedgerDebit, "4000"),
+		row(models.LedgerAccountCash, "USD", models.LedgerDebit, "10250"),
+		row(models.LedgerAccountCommissionRevenue, "USD", models.LedgerCredit, "250"),
+		row(models.LedgerAccountCash, "USD", models.LedgerCredit, "4000"),
+		row(models.LedgerAccountCash, "NGN", models.LedgerDebit, "1500000"),
+		row(models.LedgerAccountSellerPayable, "NGN", models.LedgerCredit, "1400000"),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, balances, 5)
+	assert.Equal(t, models.LedgerAccountCash, balances[0].Account)
+	assert.Equal(t, "NGN", balances[0].Currency)
+	assert.Equal(t, money.MustParse("1500000", "NGN"), balances[0].Balance)
+	assert.Equal(t, "USD", balances[1].Currency)
+	assert.Equal(t, models.LedgerAccountTypeAsset, balances[1].Type)
+	assert.Equal(t, money.MustParse("6250", "USD"), balances[1].Balance)
+	assert.Equal(t, models.LedgerAccountSellerPayable, balances[3].Account)
+	assert.Equal(t, money.MustParse("6000", "USD"), balances[3].Balance, "liabilities grow with credits")
+	assert.Equal(t, models.LedgerAccountCommissionRevenue, balances[4].Account)
+
+	require.Len(t, totals, 2)
+	assert.Equal(t, "NGN", totals[0].Currency)
+	assert.False(t, totals[0].Balanced)
+	assert.Equal(t, "USD", totals[1].Currency)
+	assert.True(t, totals[1].Balanced)
+	assert.Equal(t, money.MustParse("14250", "USD"), totals[1].Debits)
+}
+
+func TestLedgerEntryBuilder_ValidatesBeforeAnythingIsWritten(t *testing.T) {
+	transaction := &models.Transaction{ID: primitive.NewObjectID(), Currency: "USD"}
+	now := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	// A refund in the wrong currency is caught when the entry is built, before the refund is recorded
+	_, err := newLedgerEntry(transaction, models.LedgerEventRefund, "USD", now).
+		transfer(models.LedgerAccountRefunds, models.LedgerAccountCash, money.MustParse("10", "EUR")).
+		build()
+	assert.ErrorIs(t, err, errLedgerNotPosted)
+
+	_, err = newLedgerEntry(transaction, models.LedgerEventRefund, "USD", now).
+		transfer(models.LedgerAccountRefunds, "suspense", money.MustParse("10", "USD")).
+		build()
+	assert.ErrorIs(t, err, errLedgerNotPosted)
+}
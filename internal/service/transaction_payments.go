@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+	"github.com/Over-knight/Lujay-assesment/internal/payments"
+)
+
+// Payment webhook outcomes
+const (
+	PaymentEventProcessed = "processed" // the event changed the transaction
+	PaymentEventIgnored   = "ignored"   // unknown intent, or a stale or repeated status
+	PaymentEventDuplicate = "duplicate" // the event was already processed
+)
+
+// EnsureIndexes creates the index that lets each webhook event be recorded only once, the index that
+// allows at most one pending transaction per vehicle and the index used to expire reservation holds
+func (s *TransactionService) EnsureIndexes(ctx context.Context) error {
+	_, err := s.eventCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "provider", Value: 1}, {Key: "eventId", Value: 1}},
+		Options: options.Index().SetUnique(true).SetName("idx_payment_events_provider_event_unique"),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "vehicleId", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": models.TransactionStatusPending}).
+				SetName("idx_transactions_vehicle_pending_unique"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "hold.expiresAt", Value: 1}},
+			Options: options.Index().SetName("idx_transactions_status_hold_expires"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Each sale gets one invoice, and each seller's numbers are used once
+	_, err = s.invoiceCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "transactionId", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("idx_invoices_transaction_unique"),
+		},
+		{
+			Keys:    bson.D{{Key: "sellerId", Value: 1}, {Key: "sequence", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("idx_invoices_seller_sequence_unique"),
+		},
+	})
+	return err
+}
+
+// CreatePaymentIntent starts the buyer's card or bank transfer payment with the default provider
+// While a payment is in flight the existing intent is returned; a new attempt is only made after a failure
+func (s *TransactionService) CreatePaymentIntent(ctx context.Context, id string, req *models.CreatePaymentIntentRequest, userID primitive.ObjectID) (*models.Transaction, *payments.Intent, error) {
+	transaction, actor, err := s.getTransactionForActor(ctx, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if actor.Role != TransactionRoleBuyer {
+		return nil, nil, errors.New("only the buyer can pay for this transaction")
+	}
+	if !transaction.RequiresProviderPayment() {
+		return nil, nil, apperrors.NewValidationError("only card and bank transfer transactions are paid through the payment provider")
+	}
+	if transaction.Status != models.TransactionStatusPending {
+		return nil, nil, apperrors.NewInvalidStateTransitionError("only pending transactions can be paid")
+	}
+
+	attempt := 1
+	if transaction.Payment != nil {
+		if transaction.Payment.Status != payments.StatusFailed {
+			provider, err := s.providers.Get(transaction.Payment.Provider)
+			if err != nil {
+				return nil, nil, err
+			}
+			intent, err := provider.Status(ctx, transaction.Payment.IntentID)
+			if err != nil {
+				return nil, nil, paymentError(err)
+			}
+			return transaction, intent, nil
+		}
+		attempt = transaction.Payment.Attempt + 1
+	}
+
+	provider := s.providers.Default()
+	intent, err := provider.CreateIntent(ctx, payments.IntentRequest{
+		Reference:      transaction.ID.Hex(),
+		Amount:         transaction.Amount,
+		Method:         transaction.PaymentMethod,
+		Source:         req.PaymentSource,
+		IdempotencyKey: fmt.Sprintf("%s-%d", transaction.ID.Hex(), attempt),
+	})
+	if err != nil {
+		return nil, nil, paymentError(err)
+	}
+
+	now := time.Now()
+	payment := models.ProviderPayment{
+		Provider:  provider.Name(),
+		IntentID:  intent.ID,
+		Status:    intent.Status,
+		Attempt:   attempt,
+		UpdatedAt: now,
+	}
+	set := bson.M{"updatedAt": now}
+	if payments.IsConfirmed(intent.Status) {
+		payment.ConfirmedAt = &now
+	}
+	if intent.Status == payments.StatusSucceeded {
+		set["paymentDetails.paidAt"] = now
+	}
+	set["payment"] = payment
+
+	// Only record the intent if no other attempt was recorded since the transaction was read
+	filter := bson.M{"_id": transaction.ID, "status": models.TransactionStatusPending}
+	if transaction.Payment == nil {
+		filter["payment"] = bson.M{"$exists": false}
+	} else {
+		filter["payment.intentId"] = transaction.Payment.IntentID
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.Transaction
+	err = s.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil, errTransactionModified
+		}
+		return nil, nil, err
+	}
+
+	// A declined payment is recorded so the buyer can retry with another source
+	if intent.Status == payments.StatusFailed {
+		return nil, nil, apperrors.NewPaymentError("payment was declined by the payment provider")
+	}
+
+	return &updated, intent, nil
+}
+
+// HandlePaymentEvent applies a verified provider webhook to the transaction paying through the intent
+// Redelivered events are reported as duplicates and stale statuses are ignored, so providers can retry freely
+func (s *TransactionService) HandlePaymentEvent(ctx context.Context, providerName string, event *payments.Event) (string, error) {
+	count, err := s.eventCollection.CountDocuments(ctx, bson.M{"provider": providerName, "eventId": event.ID})
+	if err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return PaymentEventDuplicate, nil
+	}
+
+	outcome, transactionID, err := s.applyPaymentEvent(ctx, providerName, event)
+	if err != nil {
+		return "", err
+	}
+
+	record := models.PaymentEvent{
+		Provider:      providerName,
+		EventID:       event.ID,
+		Type:          event.Type,
+		IntentID:      event.IntentID,
+		Status:        event.Status,
+		TransactionID: transactionID,
+		Outcome:       outcome,
+		ReceivedAt:    time.Now(),
+	}
+	if _, err := s.eventCollection.InsertOne(ctx, record); err != nil {
+		// A concurrent delivery recorded it first; the update it applied is the same one
+		if mongo.IsDuplicateKeyError(err) {
+			return PaymentEventDuplicate, nil
+		}
+		return "", err
+	}
+
+	return outcome, nil
+}
+
+// applyPaymentEvent moves the payment to the event's status if that is a forward move
+func (s *TransactionService) applyPaymentEvent(ctx context.Context, providerName string, event *payments.Event) (string, *primitive.ObjectID, error) {
+	var transaction models.Transaction
+	err := s.collection.FindOne(ctx, bson.M{"payment.provider": providerName, "payment.intentId": event.IntentID}).Decode(&transaction)
+	if err != nil {
+		// Earlier attempts and intents created outside this service have nothing to update
+		if err == mongo.ErrNoDocuments {
+			return PaymentEventIgnored, nil, nil
+		}
+		return "", nil, err
+	}
+
+	current := transaction.Payment.Status
+	if current == event.Status || !payments.CanTransition(current, event.Status) {
+		return PaymentEventIgnored, &transaction.ID, nil
+	}
+
+	now := time.Now()
+	set := bson.M{
+		"payment.status":    event.Status,
+		"payment.updatedAt": now,
+		"updatedAt":         now,
+	}
+	if payments.IsConfirmed(event.Status) && transaction.Payment.ConfirmedAt == nil {
+		set["payment.confirmedAt"] = now
+	}
+	if event.Status == payments.StatusSucceeded {
+		set["paymentDetails.paidAt"] = now
+	}
+	update := bson.M{"$set": set}
+
+	// Money returned to the buyer outside the platform calls off a sale that has not completed
+	// A disputed sale is left for the admin resolving the dispute, who sees the refunded payment
+	if event.Status == payments.StatusRefunded && transaction.Status == models.TransactionStatusPending && transaction.OpenDisputeID == nil {
+		system := TransactionActor{Role: TransactionRoleSystem}
+		change, err := s.stateMachine.Transition(&transaction, models.TransactionStatusCancelled, system, "payment refunded by the payment provider")
+		if err != nil {
+			return "", nil, err
+		}
+		set["status"] = models.TransactionStatusCancelled
+		set["cancelledAt"] = now
+		update["$push"] = bson.M{"statusHistory": change}
+	}
+
+	filter := bson.M{
+		"_id":              transaction.ID,
+		"status":           transaction.Status,
+		"openDisputeId":    disputeFreezeFilter(&transaction),
+		"payment.intentId": event.IntentID,
+		"payment.status":   current,
+	}
+	result, err := s.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return "", nil, err
+	}
+	if result.MatchedCount == 0 {
+		return "", nil, errTransactionModified
+	}
+
+	// The refunded buyer gets their deposit back and the vehicle is listed again; ExpireHolds retries this if it fails
+	if set["status"] == models.TransactionStatusCancelled {
+		transaction.Status = models.TransactionStatusCancelled
+		_ = s.settleHold(ctx, &transaction)
+	}
+
+	return PaymentEventProcessed, &transaction.ID, nil
+}
+
+// refreshPayment asks the provider for the latest status of a payment still being processed
+// This covers webhooks that were delayed or lost before the seller completes the sale
+func (s *TransactionService) refreshPayment(ctx context.Context, transaction *models.Transaction) error {
+	if !transaction.RequiresProviderPayment() || transaction.Payment == nil || transaction.Payment.Status != payments.StatusProcessing {
+		return nil
+	}
+
+	provider, err := s.providers.Get(transaction.Payment.Provider)
+	if err != nil {
+		return err
+	}
+	intent, err := provider.Status(ctx, transaction.Payment.IntentID)
+	if err != nil {
+		return paymentError(err)
+	}
+	if payments.CanTransition(transaction.Payment.Status, intent.Status) {
+		transaction.Payment.Status = intent.Status
+	}
+	return nil
+}
+
+// paymentCompletion returns the fields recording the provider payment as the sale completes
+// An authorized payment is only marked for capture, so no money moves unless the completion commits;
+// capturePayment collects it afterwards
+func paymentCompletion(transaction *models.Transaction, now time.Time) bson.M {
+	payment := transaction.Payment
+	if payment.Status == payments.StatusRequiresCapture {
+		return bson.M{"payment.captureDue": true, "payment.updatedAt": now}
+	}
+
+	set := bson.M{
+		"payment.status":    payments.StatusSucceeded,
+		"payment.updatedAt": now,
+	}
+	if payment.ConfirmedAt == nil {
+		set["payment.confirmedAt"] = now
+	}
+	return set
+}
+
+// capturePayment collects the authorized payment of a completed sale and records it as succeeded
+// Captures are idempotent at the provider, so one whose recording fails is retried by ExpireHolds
+func (s *TransactionService) capturePayment(ctx context.Context, transaction *models.Transaction) (*models.Transaction, error) {
+	payment := transaction.Payment
+	if payment == nil || !payment.CaptureDue {
+		return transaction, nil
+	}
+	if err := s.captureHold(ctx, payment.Provider, payment.IntentID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	set := bson.M{
+		"payment.status":    payments.StatusSucceeded,
+		"payment.updatedAt": now,
+		"updatedAt":         now,
+	}
+	if payment.ConfirmedAt == nil {
+		set["payment.confirmedAt"] = now
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var captured models.Transaction
+	err := s.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": transaction.ID, "payment.captureDue": true},
+		bson.M{"$set": set, "$unset": bson.M{"payment.captureDue": ""}},
+		opts,
+	).Decode(&captured)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errTransactionModified
+		}
+		return nil, err
+	}
+	return &captured, nil
+}
+
+// paymentRefundDue reports whether cancelling the transaction must refund its provider payment
+// A payment still processing cannot be refunded yet, so the cancellation has to wait for it to settle
+func paymentRefundDue(transaction *models.Transaction) (bool, error) {
+	payment := transaction.Payment
+	if !transaction.RequiresProviderPayment() || payment == nil {
+		return false, nil
+	}
+	if payment.Status == payments.StatusProcessing {
+		return false, apperrors.NewConflictError("the payment is still processing; cancel once it has settled")
+	}
+	return payments.IsConfirmed(payment.Status), nil
+}
+
+// Purposes of payments held with the provider, part of their idempotency keys
+const (
+	holdPurposeEscrow  = "escrow"
+	holdPurposeDeposit = "deposit"
+)
+
+// holdPayment authorizes the amount from the buyer's source with the default provider without collecting it
+// Escrow funds and deposits stay authorized until they are captured for the seller or refunded to the buyer.
+// Each call is a new attempt, so a buyer whose card was declined can retry with another source
+func (s *TransactionService) holdPayment(ctx context.Context, transaction *models.Transaction, purpose string, amount money.Money, source string) (payments.Provider, *payments.Intent, error) {
+	method := transaction.PaymentMethod
+	if !transaction.RequiresProviderPayment() {
+		method = models.PaymentMethodCard
+	}
+
+	provider := s.providers.Default()
+	intent, err := provider.CreateIntent(ctx, payments.IntentRequest{
+		Reference:      transaction.ID.Hex(),
+		Amount:         amount,
+		Method:         method,
+		Source:         source,
+		IdempotencyKey: fmt.Sprintf("%s-%s-%s", transaction.ID.Hex(), purpose, primitive.NewObjectID().Hex()),
+	})
+	if err != nil {
+		return nil, nil, paymentError(err)
+	}
+	if intent.Status == payments.StatusFailed {
+		return nil, nil, apperrors.NewPaymentError("payment was declined by the payment provider")
+	}
+	if !payments.IsConfirmed(intent.Status) {
+		// A hold that cannot be confirmed now is voided rather than left pending
+		_, _ = provider.Refund(ctx, intent.ID)
+		return nil, nil, apperrors.NewPaymentError(fmt.Sprintf("the payment provider could not hold the payment (status %s)", intent.Status))
+	}
+	return provider, intent, nil
+}
+
+// captureHold collects a held payment for the seller; capturing it again is a no-op
+func (s *TransactionService) captureHold(ctx context.Context, providerName, intentID string) error {
+	provider, err := s.providers.Get(providerName)
+	if err != nil {
+		return err
+	}
+	if _, err := provider.Capture(ctx, intentID); err != nil {
+		return paymentError(err)
+	}
+	return nil
+}
+
+// refundIntent returns a held or collected payment to the buyer; refunding it again is a no-op
+func (s *TransactionService) refundIntent(ctx context.Context, providerName, intentID string) error {
+	provider, err := s.providers.Get(providerName)
+	if err != nil {
+		return err
+	}
+	if _, err := provider.Refund(ctx, intentID); err != nil {
+		return paymentError(err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+	"github.com/Over-knight/Lujay-assesment/internal/payments"
+)
+
+// paymentsService returns a transaction service that only talks to a mock payment provider
+func paymentsService() (*TransactionService, *payments.MockProvider) {
+	provider := payments.NewMockProvider("test-secret", nil)
+	return &TransactionService{providers: payments.NewRegistry(provider)}, provider
+}
+
+func TestHoldPayment_CaptureForSeller(t *testing.T) {
+	s, mock := paymentsService()
+	ctx := context.Background()
+	transaction := &models.Transaction{ID: primitive.NewObjectID(), PaymentMethod: models.PaymentMethodCard}
+
+	provider, intent, err := s.holdPayment(ctx, transaction, holdPurposeEscrow, money.MustParse("25000", "USD"), "tok_visa")
+	require.NoError(t, err)
+	assert.Equal(t, payments.MockProviderName, provider.Name())
+	assert.Equal(t, payments.StatusRequiresCapture, intent.Status, "held funds are authorized, not collected")
+
+	require.NoError(t, s.captureHold(ctx, provider.Name(), intent.ID))
+	require.NoError(t, s.captureHold(ctx, provider.Name(), intent.ID), "retrying a capture that succeeded is safe")
+	status, err := mock.Status(ctx, intent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, payments.StatusSucceeded, status.Status)
+}
+
+func TestHoldPayment_RefundToBuyer(t *testing.T) {
+	s, mock := paymentsService()
+	ctx := context.Background()
+	transaction := &models.Transaction{ID: primitive.NewObjectID(), PaymentMethod: models.PaymentMethodCash}
+
+	// Deposits on sales paid outside the provider are held on the buyer's card
+	provider, intent, err := s.holdPayment(ctx, transaction, holdPurposeDeposit, money.MustParse("500", "USD"), "tok_visa")
+	require.NoError(t, err)
+	assert.Equal(t, models.PaymentMethodCard, intent.Method)
+
+	require.NoError(t, s.refundIntent(ctx, provider.Name(), intent.ID))
+	require.NoError(t, s.refundIntent(ctx, provider.Name(), intent.ID), "retrying a refund that succeeded is safe")
+	status, err := mock.Status(ctx, intent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, payments.StatusRefunded, status.Status)
+
+	assert.Error(t, s.captureHold(ctx, provider.Name(), intent.ID), "a refunded hold cannot be paid to the seller")
+}
+
+func TestHoldPayment_Declined(t *testing.T) {
+	s, _ := paymentsService()
+	ctx := context.Background()
+	transaction := &models.Transaction{ID: primitive.NewObjectID(), PaymentMethod: models.PaymentMethodCard}
+
+	_, _, err := s.holdPayment(ctx, transaction, holdPurposeEscrow, money.MustParse("100", "USD"), "decline_insufficient_funds")
+	assert.EqualError(t, err, "payment was declined by the payment provider")
+
+	// A new attempt with another source is a new intent, not the declined one replayed
+	_, intent, err := s.holdPayment(ctx, transaction, holdPurposeEscrow, money.MustParse("100", "USD"), "tok_visa")
+	require.NoError(t, err)
+	assert.Equal(t, payments.StatusRequiresCapture, intent.Status)
+}
+
+func TestHoldPayment_UnknownProvider(t *testing.T) {
+	s, _ := paymentsService()
+	assert.EqualError(t, s.captureHold(context.Background(), "fake", "fake_hold_1"), `unknown payment provider "fake"`)
+}
+
+func TestPaymentRefundDue(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		payment *models.ProviderPayment
+		want    bool
+		wantErr bool
+	}{
+		{"cash sale", models.PaymentMethodCash, nil, false, false},
+		{"card not paid yet", models.PaymentMethodCard, nil, false, false},
+		{"card authorized", models.PaymentMethodCard, &models.ProviderPayment{Status: payments.StatusRequiresCapture}, true, false},
+		{"bank transfer collected", models.PaymentMethodBankTransfer, &models.ProviderPayment{Status: payments.StatusSucceeded}, true, false},
+		{"card declined", models.PaymentMethodCard, &models.ProviderPayment{Status: payments.StatusFailed}, false, false},
+		{"already refunded", models.PaymentMethodCard, &models.ProviderPayment{Status: payments.StatusRefunded}, false, false},
+		{"bank transfer processing", models.PaymentMethodBankTransfer, &models.ProviderPayment{Status: payments.StatusProcessing}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due, err := paymentRefundDue(&models.Transaction{PaymentMethod: tt.method, Payment: tt.payment})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, due)
+		})
+	}
+}
+
+func TestPaymentCompletion(t *testing.T) {
+	now := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	confirmedAt := now.Add(-time.Hour)
+	card := func(status string, confirmed *time.Time) *models.Transaction {
+		return &models.Transaction{
+			PaymentMethod: models.PaymentMethodCard,
+			Payment:       &models.ProviderPayment{Provider: payments.MockProviderName, IntentID: "pi_1", Status: status, ConfirmedAt: confirmed},
+		}
+	}
+
+	assert.Equal(t, bson.M{
+		"payment.captureDue": true,
+		"payment.updatedAt":  now,
+	}, paymentCompletion(card(payments.StatusRequiresCapture, &confirmedAt), now), "authorized payments are captured after the completion commits")
+
+	assert.Equal(t, bson.M{
+		"payment.status":    payments.StatusSucceeded,
+		"payment.updatedAt": now,
+	}, paymentCompletion(card(payments.StatusSucceeded, &confirmedAt), now))
+
+	assert.Equal(t, bson.M{
+		"payment.status":      payments.StatusSucceeded,
+		"payment.updatedAt":   now,
+		"payment.confirmedAt": now,
+	}, paymentCompletion(card(payments.StatusSucceeded, nil), now))
+}
+
+func TestCapturePayment_NothingDue(t *testing.T) {
+	// No collection is set, so the test fails if anything is captured or written
+	s, _ := paymentsService()
+	transaction := &models.Transaction{
+		PaymentMethod: models.PaymentMethodCard,
+		Payment:       &models.ProviderPayment{Provider: payments.MockProviderName, IntentID: "pi_1", Status: payments.StatusSucceeded},
+	}
+
+	captured, err := s.capturePayment(context.Background(), transaction)
+	require.NoError(t, err)
+	assert.Same(t, transaction, captured)
+}
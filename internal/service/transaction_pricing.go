@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+)
+
+// QuoteTransaction previews the breakdown a transaction for the vehicle would be created with
+// Without an agreed amount the vehicle's listed price, converted to the quote's currency, is priced
+func (s *TransactionService) QuoteTransaction(ctx context.Context, req *models.TransactionQuoteRequest) (*models.TransactionQuote, error) {
+	if err := req.Validate(); err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+
+	vehicleID, _ := primitive.ObjectIDFromHex(req.VehicleID)
+	var vehicle models.Vehicle
+	if err := s.vehicleCollection.FindOne(ctx, bson.M{"_id": vehicleID}).Decode(&vehicle); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("vehicle not found")
+		}
+		return nil, err
+	}
+
+	price, _ := req.Amount.WithCurrency(req.Currency)
+	if price.IsZero() {
+		snapshot, err := s.exchangeRateSnapshot(ctx, vehicle.Price, req.Currency)
+		if err != nil {
+			return nil, err
+		}
+		price = snapshot.Converted
+	}
+
+	lines, total, err := s.priceSale(ctx, &vehicle, price)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TransactionQuote{
+		VehicleID: vehicle.ID,
+		Currency:  req.Currency,
+		Lines:     lines,
+		Total:     total,
+	}, nil
+}
+
+// priceSale breaks the vehicle price down into the lines the buyer pays and returns their total
+func (s *TransactionService) priceSale(ctx context.Context, vehicle *models.Vehicle, price money.Money) ([]models.PriceLine, money.Money, error) {
+	lines, err := s.pricing.Breakdown(ctx, PricingInput{VehiclePrice: price, Location: vehicle.Location})
+	if err != nil {
+		return nil, money.Money{}, err
+	}
+
+	total, err := models.SumPriceLines(lines, price.Currency())
+	if err != nil {
+		return nil, money.Money{}, fmt.Errorf("failed to total price breakdown: %w", err)
+	}
+	return lines, total, nil
+}
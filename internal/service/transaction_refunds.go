@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+	"github.com/Over-knight/Lujay-assesment/internal/payments"
+)
+
+// errOwnershipNotRevertible is returned when the buyer no longer holds the refunded vehicle
+var errOwnershipNotRevertible = apperrors.NewConflictError("the buyer no longer holds the vehicle as sold, so ownership cannot be reverted")
+
+// RefundTransaction refunds all or part of a completed transaction on an admin's behalf
+// A full refund of a provider payment is returned through the payment provider; anything else is paid
+// out outside the platform and recorded with the admin's payout reference. The refund line item, the
+// vehicle's return to the seller, the write-off of unpaid installments and the refund's ledger entry
+// are applied in MongoDB transactions
+func (s *TransactionService) RefundTransaction(ctx context.Context, id string, req *models.RefundTransactionRequest, adminID primitive.ObjectID) (*models.Transaction, error) {
+	if err := req.Validate(); err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+
+	transaction, err := s.GetTransactionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.refund(ctx, transaction, req, TransactionActor{ID: adminID, Role: TransactionRoleAdmin})
+}
+
+// refund issues the refund on the admin actor's behalf
+// The refund is claimed as pending before the provider is asked for the money, so a lost race or a
+// retry cannot pay the buyer twice. Retrying while a refund is pending completes that refund instead
+// of issuing another. A disputed transaction is only refunded by resolving its dispute, which the refund ends
+func (s *TransactionService) refund(ctx context.Context, transaction *models.Transaction, req *models.RefundTransactionRequest, actor TransactionActor) (*models.Transaction, error) {
+	if transaction.Status != models.TransactionStatusCompleted {
+		return nil, apperrors.NewInvalidStateTransitionError(fmt.Sprintf("only completed transactions can be refunded; this one is %s", transaction.Status))
+	}
+	if pending := transaction.PendingRefund(); pending != nil {
+		return s.finishRefund(ctx, transaction, pending, actor)
+	}
+	if err := requireNoOpenDispute(transaction, actor); err != nil {
+		return nil, apperrors.NewInvalidStateTransitionError(err.Error())
+	}
+	if transaction.Payment != nil && transaction.Payment.CaptureDue {
+		return nil, apperrors.NewConflictError("the payment is still being captured; refund once it has settled")
+	}
+
+	amount, full, err := refundAmount(transaction, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the vehicle can go back before any money moves; the claim re-checks it when reverting
+	if req.RevertOwnership {
+		count, err := s.vehicleCollection.CountDocuments(ctx, revertibleVehicleFilter(transaction))
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, errOwnershipNotRevertible
+		}
+	}
+	if full {
+		if _, err := s.stateMachine.Transition(transaction, models.TransactionStatusRefunded, actor, req.Reason); err != nil {
+			return nil, err
+		}
+	}
+
+	refund := models.TransactionRefund{
+		ID:                primitive.NewObjectID(),
+		Amount:            amount,
+		Reason:            req.Reason,
+		Reference:         req.Reference,
+		Status:            models.RefundStatusPending,
+		RevertedOwnership: req.RevertOwnership,
+		RefundedBy:        actor.ID,
+		CreatedAt:         time.Now(),
+	}
+	if actor.DisputeID != primitive.NilObjectID {
+		disputeID := actor.DisputeID
+		refund.DisputeID = &disputeID
+	}
+
+	// Providers only refund whole payments, so partial refunds are paid out by the admin
+	if s.refundsThroughProvider(transaction, full) {
+		refund.Provider = transaction.Payment.Provider
+	} else if refund.Reference == "" {
+		return nil, apperrors.NewValidationError("reference is required for refunds paid out outside the payment provider")
+	}
+
+	// Refunds are only ever appended, so the next slot being empty means no other refund was claimed since the read
+	filter := bson.M{
+		"_id":           transaction.ID,
+		"status":        models.TransactionStatusCompleted,
+		"openDisputeId": disputeFreezeFilter(transaction),
+		fmt.Sprintf("refunds.%d", len(transaction.Refunds)): bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set":  bson.M{"updatedAt": refund.CreatedAt},
+		"$push": bson.M{"refunds": refund},
+	}
+	if transaction.OpenDisputeID != nil {
+		update["$unset"] = bson.M{"openDisputeId": ""}
+	}
+
+	var claimed models.Transaction
+	err = s.withTransaction(ctx, func(sc mongo.SessionContext) error {
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err := s.collection.FindOneAndUpdate(sc, filter, update, opts).Decode(&claimed)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errTransactionModified
+			}
+			return err
+		}
+
+		if req.RevertOwnership {
+			return s.revertOwnership(sc, transaction, refund.CreatedAt)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.finishRefund(ctx, &claimed, &refund, actor)
+}
+
+// finishRefund returns the money of a claimed refund through the payment provider, if it pays it, and
+// records the refund as completed with its ledger entry. A full refund moves the transaction to refunded
+// and writes off a financed sale's unpaid installments
+// Provider refunds are idempotent, so a refund whose recording fails is safe to retry
+func (s *TransactionService) finishRefund(ctx context.Context, transaction *models.Transaction, refund *models.TransactionRefund, actor TransactionActor) (*models.Transaction, error) {
+	now := time.Now()
+	completed := *refund
+	completed.Status = models.RefundStatusCompleted
+	if completed.Provider != "" {
+		provider, err := s.providers.Get(completed.Provider)
+		if err != nil {
+			return nil, err
+		}
+		intent, err := provider.Refund(ctx, transaction.Payment.IntentID)
+		if err != nil {
+			return nil, paymentError(err)
+		}
+		completed.Reference = intent.ID
+	}
+
+	var change *models.TransactionStatusChange
+	if refundsInFull(transaction, &completed) {
+		var err error
+		// The claim ended any dispute, so the refund completes as the admin who finishes it
+		actor.DisputeID = primitive.NilObjectID
+		if change, err = s.stateMachine.Transition(transaction, models.TransactionStatusRefunded, actor, completed.Reason); err != nil {
+			return nil, err
+		}
+		change.ChangedAt = now
+	}
+
+	entry, err := refundEntry(transaction, &completed)
+	if err != nil {
+		return nil, err
+	}
+	filter, update, err := completeRefundUpdate(transaction, &completed, change, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated models.Transaction
+	err = s.withTransaction(ctx, func(sc mongo.SessionContext) error {
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err := s.collection.FindOneAndUpdate(sc, filter, update, opts).Decode(&updated)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errTransactionModified
+			}
+			return err
+		}
+
+		// Nothing more is owed on a financed sale that was refunded in full
+		if change != nil && transaction.PaymentMethod == models.PaymentMethodFinancing {
+			installments, writeOff := installmentWriteOff(transaction, now)
+			if _, err := s.installmentCollection.UpdateMany(sc, installments, writeOff); err != nil {
+				return err
+			}
+		}
+
+		return s.postLedger(sc, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// refundAmount resolves the amount the request refunds and whether it refunds everything still refundable
+// Omitting the amount refunds the whole balance; ownership can only be reverted by refunding it all
+func refundAmount(transaction *models.Transaction, req *models.RefundTransactionRequest) (money.Money, bool, error) {
+	refundable, err := transaction.RefundableAmount()
+	if err != nil {
+		return money.Money{}, false, err
+	}
+	amount := refundable
+	if !req.Amount.IsZero() {
+		amount, err = req.Amount.WithCurrency(transaction.Currency)
+		if err != nil {
+			return money.Money{}, false, apperrors.NewValidationError("amount: " + err.Error())
+		}
+		if compareMoney(amount, refundable) > 0 {
+			return money.Money{}, false, apperrors.NewValidationError(fmt.Sprintf("amount exceeds the refundable balance of %s", refundable))
+		}
+	}
+	if !amount.IsPositive() {
+		return money.Money{}, false, apperrors.NewValidationError("nothing is left to refund")
+	}
+
+	full := compareMoney(amount, refundable) == 0
+	if req.RevertOwnership && !full {
+		return money.Money{}, false, apperrors.NewValidationError("ownership can only be reverted by a full refund")
+	}
+	return amount, full, nil
+}
+
+// refundsInFull reports whether the refund returns everything not refunded before it
+func refundsInFull(transaction *models.Transaction, refund *models.TransactionRefund) bool {
+	refundable, err := transaction.RefundableAmount()
+	return err == nil && compareMoney(refund.Amount, refundable) == 0
+}
+
+// completeRefundUpdate returns the conditional update that records a claimed refund as completed
+// It only matches while the refund is still pending, so a refund finished concurrently is recorded once
+func completeRefundUpdate(transaction *models.Transaction, refund *models.TransactionRefund, change *models.TransactionStatusChange, now time.Time) (bson.M, bson.M, error) {
+	refundedAmount := refund.Amount
+	if !transaction.RefundedAmount.IsZero() {
+		var err error
+		if refundedAmount, err = transaction.RefundedAmount.Add(refund.Amount); err != nil {
+			return nil, nil, apperrors.NewValidationError(err.Error())
+		}
+	}
+
+	set := bson.M{
+		"refunds.$.status":    models.RefundStatusCompleted,
+		"refunds.$.reference": refund.Reference,
+		"refundedAmount":      refundedAmount,
+		"updatedAt":           now,
+	}
+	update := bson.M{"$set": set}
+	if refund.Provider != "" {
+		set["payment.status"] = payments.StatusRefunded
+		set["payment.updatedAt"] = now
+	}
+	if change != nil {
+		set["status"] = models.TransactionStatusRefunded
+		set["refundedAt"] = now
+		update["$push"] = bson.M{"statusHistory": change}
+	}
+
+	filter := bson.M{
+		"_id":     transaction.ID,
+		"status":  models.TransactionStatusCompleted,
+		"refunds": bson.M{"$elemMatch": bson.M{"_id": refund.ID, "status": models.RefundStatusPending}},
+	}
+	return filter, update, nil
+}
+
+// installmentWriteOff returns the filter and update that cancel a financed sale's unpaid installments
+// Paid installments are left as they are; the refund covers them
+func installmentWriteOff(transaction *models.Transaction, now time.Time) (bson.M, bson.M) {
+	filter := bson.M{
+		"transactionId": transaction.ID,
+		"status":        bson.M{"$in": []string{models.InstallmentStatusDue, models.InstallmentStatusLate, models.InstallmentStatusMissed}},
+	}
+	return filter, bson.M{"$set": bson.M{"status": models.InstallmentStatusCancelled, "updatedAt": now}}
+}
+
+// refundsThroughProvider reports whether the payment provider can return the money for the refund
+func (s *TransactionService) refundsThroughProvider(transaction *models.Transaction, full bool) bool {
+	return full &&
+		transaction.RefundedAmount.IsZero() &&
+		transaction.RequiresProviderPayment() &&
+		transaction.Payment != nil &&
+		(transaction.Payment.Status == payments.StatusSucceeded || transaction.Payment.Status == payments.StatusRefunded)
+}
+
+// revertOwnership gives the vehicle back to the seller and lists it again
+func (s *TransactionService) revertOwnership(ctx context.Context, transaction *models.Transaction, now time.Time) error {
+	update := bson.M{
+		"$set": bson.M{
+			"ownerId":   transaction.SellerID,
+			"status":    models.VehicleStatusActive,
+			"updatedAt": now,
+		},
+	}
+
+	result, err := s.vehicleCollection.UpdateOne(ctx, revertibleVehicleFilter(transaction), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errOwnershipNotRevertible
+	}
+	return nil
+}
+
+// revertibleVehicleFilter matches the transaction's vehicle while the buyer still owns it as sold,
+// so a vehicle the buyer has since relisted or resold is never taken back
+func revertibleVehicleFilter(transaction *models.Transaction) bson.M {
+	return bson.M{
+		"_id":     transaction.VehicleID,
+		"ownerId": transaction.BuyerID,
+		"status":  models.VehicleStatusSold,
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+	"github.com/Over-knight/Lujay-assesment/internal/payments"
+)
+
+func TestRefundsThroughProvider(t *testing.T) {
+	s := &TransactionService{}
+	card := func(status string) *models.Transaction {
+		return &models.Transaction{
+			PaymentMethod: models.PaymentMethodCard,
+			Amount:        money.MustParse("25000", "USD"),
+			Payment:       &models.ProviderPayment{Provider: payments.MockProviderName, IntentID: "pi_1", Status: status},
+		}
+	}
+
+	assert.True(t, s.refundsThroughProvider(card(payments.StatusSucceeded), true))
+	assert.True(t, s.refundsThroughProvider(card(payments.StatusRefunded), true), "retrying a recorded provider refund is safe")
+	assert.False(t, s.refundsThroughProvider(card(payments.StatusSucceeded), false), "providers only refund whole payments")
+
+	partlyRefunded := card(payments.StatusSucceeded)
+	partlyRefunded.RefundedAmount = money.MustParse("1000", "USD")
+	assert.False(t, s.refundsThroughProvider(partlyRefunded, true))
+
+	cash := &models.Transaction{PaymentMethod: models.PaymentMethodCash, Amount: money.MustParse("25000", "USD")}
+	assert.False(t, s.refundsThroughProvider(cash, true))
+}
+
+// completedSale returns a completed sale of 25000 USD with the given refunds already recorded
+func completedSale(method string, refunds ...models.TransactionRefund) *models.Transaction {
+	transaction := &models.Transaction{
+		ID:            primitive.NewObjectID(),
+		VehicleID:     primitive.NewObjectID(),
+		SellerID:      primitive.NewObjectID(),
+		BuyerID:       primitive.NewObjectID(),
+		Status:        models.TransactionStatusCompleted,
+		PaymentMethod: method,
+		Amount:        money.MustParse("25000", "USD"),
+		Currency:      "USD",
+		Refunds:       refunds,
+	}
+	for _, refund := range refunds {
+		transaction.RefundedAmount = addMoney(transaction.RefundedAmount, refund.Amount)
+	}
+	return transaction
+}
+
+func TestRefund_PartialThenFull(t *testing.T) {
+	now := time.Date(2026, 9, 5, 0, 0, 0, 0, time.UTC)
+	transaction := completedSale(models.PaymentMethodCash)
+
+	// A partial refund leaves the sale completed with the rest still refundable
+	amount, full, err := refundAmount(transaction, &models.RefundTransactionRequest{Amount: money.MustParse("1000", ""), Reason: "scratch", Reference: "PAYOUT-1"})
+	require.NoError(t, err)
+	assert.Equal(t, money.MustParse("1000", "USD"), amount)
+	assert.False(t, full)
+
+	partial := &models.TransactionRefund{ID: primitive.NewObjectID(), Amount: amount, Reference: "PAYOUT-1", Status: models.RefundStatusPending}
+	assert.False(t, refundsInFull(transaction, partial))
+	filter, update, err := completeRefundUpdate(transaction, partial, nil, now)
+	require.NoError(t, err)
+	set := update["$set"].(bson.M)
+	assert.Equal(t, money.MustParse("1000", "USD"), set["refundedAmount"])
+	assert.Equal(t, models.RefundStatusCompleted, set["refunds.$.status"])
+	assert.NotContains(t, set, "status")
+	assert.NotContains(t, update, "$push")
+	assert.Equal(t, bson.M{"$elemMatch": bson.M{"_id": partial.ID, "status": models.RefundStatusPending}}, filter["refunds"], "only a pending refund is completed")
+
+	// The next refund without an amount returns the rest and refunds the sale
+	partial.Status = models.RefundStatusCompleted
+	transaction = completedSale(models.PaymentMethodCash, *partial)
+	amount, full, err = refundAmount(transaction, &models.RefundTransactionRequest{Reason: "returned", Reference: "PAYOUT-2"})
+	require.NoError(t, err)
+	assert.Equal(t, money.MustParse("24000", "USD"), amount)
+	assert.True(t, full)
+
+	rest := &models.TransactionRefund{ID: primitive.NewObjectID(), Amount: amount, Reference: "PAYOUT-2", Status: models.RefundStatusPending}
+	assert.True(t, refundsInFull(transaction, rest))
+	change := &models.TransactionStatusChange{From: models.TransactionStatusCompleted, To: models.TransactionStatusRefunded, ChangedAt: now}
+	_, update, err = completeRefundUpdate(transaction, rest, change, now)
+	require.NoError(t, err)
+	set = update["$set"].(bson.M)
+	assert.Equal(t, money.MustParse("25000", "USD"), set["refundedAmount"])
+	assert.Equal(t, models.TransactionStatusRefunded, set["status"])
+	assert.Equal(t, now, set["refundedAt"])
+	assert.Equal(t, bson.M{"statusHistory": change}, update["$push"])
+
+	// Nothing more can be refunded than is left
+	_, _, err = refundAmount(transaction, &models.RefundTransactionRequest{Amount: money.MustParse("24000.01", ""), Reason: "too much"})
+	assert.EqualError(t, err, "amount exceeds the refundable balance of 24000.00 USD")
+
+	// The provider only refunds whole payments, so the rest of a partly refunded card sale is paid out
+	card := completedSale(models.PaymentMethodCard, *partial)
+	card.Payment = &models.ProviderPayment{Provider: payments.MockProviderName, IntentID: "pi_1", Status: payments.StatusSucceeded}
+	assert.False(t, (&TransactionService{}).refundsThroughProvider(card, true))
+}
+
+func TestRefund_ProviderRefundRecordsPayment(t *testing.T) {
+	now := time.Date(2026, 9, 5, 0, 0, 0, 0, time.UTC)
+	transaction := completedSale(models.PaymentMethodCard)
+	refund := &models.TransactionRefund{ID: primitive.NewObjectID(), Amount: transaction.Amount, Provider: payments.MockProviderName, Reference: "pi_1", Status: models.RefundStatusPending}
+
+	_, update, err := completeRefundUpdate(transaction, refund, nil, now)
+	require.NoError(t, err)
+	set := update["$set"].(bson.M)
+	assert.Equal(t, payments.StatusRefunded, set["payment.status"])
+	assert.Equal(t, "pi_1", set["refunds.$.reference"])
+}
+
+func TestRefund_RevertOwnershipPrecondition(t *testing.T) {
+	transaction := completedSale(models.PaymentMethodCash)
+
+	// Ownership only goes back to the seller with the whole sale refunded
+	_, _, err := refundAmount(transaction, &models.RefundTransactionRequest{Amount: money.MustParse("1000", ""), Reason: "partial", RevertOwnership: true})
+	assert.EqualError(t, err, "ownership can only be reverted by a full refund")
+
+	_, full, err := refundAmount(transaction, &models.RefundTransactionRequest{Reason: "returned", RevertOwnership: true})
+	require.NoError(t, err)
+	assert.True(t, full)
+
+	// ... and only while the buyer still holds the vehicle as sold, not once they relisted or resold it
+	assert.Equal(t, bson.M{
+		"_id":     transaction.VehicleID,
+		"ownerId": transaction.BuyerID,
+		"status":  models.VehicleStatusSold,
+	}, revertibleVehicleFilter(transaction))
+}
+
+func TestRefund_InstallmentWriteOff(t *testing.T) {
+	now := time.Date(2026, 9, 5, 0, 0, 0, 0, time.UTC)
+	transaction := completedSale(models.PaymentMethodFinancing)
+
+	filter, update := installmentWriteOff(transaction, now)
+	assert.Equal(t, transaction.ID, filter["transactionId"])
+	assert.Equal(t, bson.M{"$in": []string{models.InstallmentStatusDue, models.InstallmentStatusLate, models.InstallmentStatusMissed}}, filter["status"], "paid installments are kept")
+	assert.Equal(t, bson.M{"$set": bson.M{"status": models.InstallmentStatusCancelled, "updatedAt": now}}, update)
+}
@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+	"github.com/Over-knight/Lujay-assesment/internal/payments"
+)
+
+// holdExpiryBatch caps how many expired or unsettled holds are handled per job run
+const holdExpiryBatch = 100
+
+// errVehicleReserved is returned when another transaction already holds the vehicle
+var errVehicleReserved = apperrors.NewConflictError("vehicle is reserved for another transaction")
+
+// errVehicleAuctioned is returned when a vehicle being auctioned is sold outside its auction
+var errVehicleAuctioned = apperrors.NewConflictError("vehicle is being sold by auction")
+
+// ReservationPolicy controls how long a transaction keeps its vehicle reserved
+type ReservationPolicy struct {
+	HoldTTL        time.Duration // how long a new transaction holds the vehicle
+	DepositHoldTTL time.Duration // how long the hold lasts from the moment the buyer pays the deposit
+}
+
+// newHold starts the reservation hold of a transaction created at now
+func (s *TransactionService) newHold(deposit money.Money, now time.Time) *models.ReservationHold {
+	hold := &models.ReservationHold{ExpiresAt: now.Add(s.holds.HoldTTL)}
+	if deposit.IsPositive() {
+		hold.Deposit = deposit
+		hold.DepositStatus = models.DepositStatusAwaitingPayment
+	}
+	return hold
+}
+
+// reserveVehicle moves an active vehicle to reserved for the transaction
+// The update only applies while the vehicle is still active, so concurrent sales cannot both reserve it
+func (s *TransactionService) reserveVehicle(ctx context.Context, transaction *models.Transaction, now time.Time) error {
+	filter := reservableVehicleFilter(transaction)
+	update := bson.M{
+		"$set": bson.M{
+			"status": models.VehicleStatusReserved,
+			"reservation": models.VehicleReservation{
+				TransactionID: transaction.ID,
+				BuyerID:       transaction.BuyerID,
+				ExpiresAt:     transaction.Hold.ExpiresAt,
+				CreatedAt:     now,
+			},
+			"updatedAt": now,
+		},
+	}
+
+	result, err := s.vehicleCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errVehicleReserved
+	}
+	return nil
+}
+
+// reservableVehicleFilter matches the transaction's vehicle while its seller can still sell it
+// A vehicle being auctioned is only reservable by the transaction its own auction creates for the winner
+func reservableVehicleFilter(transaction *models.Transaction) bson.M {
+	filter := bson.M{
+		"_id":     transaction.VehicleID,
+		"ownerId": transaction.SellerID,
+		"status":  models.VehicleStatusActive,
+	}
+	if transaction.AuctionID != nil {
+		filter["$or"] = bson.A{
+			bson.M{"listingType": bson.M{"$ne": models.ListingTypeAuction}},
+			bson.M{"auctionId": *transaction.AuctionID},
+		}
+	} else {
+		filter["listingType"] = bson.M{"$ne": models.ListingTypeAuction}
+	}
+	return filter
+}
+
+// unreserveVehicle returns the vehicle to active if the transaction still holds it
+func (s *TransactionService) unreserveVehicle(ctx context.Context, transaction *models.Transaction, now time.Time) error {
+	filter := bson.M{
+		"_id":                       transaction.VehicleID,
+		"status":                    models.VehicleStatusReserved,
+		"reservation.transactionId": transaction.ID,
+	}
+	update := bson.M{
+		"$set":   bson.M{"status": models.VehicleStatusActive, "updatedAt": now},
+		"$unset": bson.M{"reservation": ""},
+	}
+	_, err := s.vehicleCollection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// PayDeposit holds the buyer's reservation deposit with the payment provider and extends the hold
+func (s *TransactionService) PayDeposit(ctx context.Context, id string, req *models.PayDepositRequest, userID primitive.ObjectID) (*models.Transaction, error) {
+	transaction, actor, err := s.getTransactionForActor(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Role != TransactionRoleBuyer {
+		return nil, errors.New("only the buyer can pay the deposit")
+	}
+
+	hold := transaction.Hold
+	if hold == nil || hold.Deposit.IsZero() {
+		return nil, apperrors.NewValidationError("this transaction has no deposit to pay")
+	}
+	if hold.DepositStatus != models.DepositStatusAwaitingPayment {
+		return nil, apperrors.NewInvalidStateTransitionError(fmt.Sprintf("the deposit is already %s", hold.DepositStatus))
+	}
+	if transaction.Status != models.TransactionStatusPending {
+		return nil, apperrors.NewInvalidStateTransitionError("deposits can only be paid on pending transactions")
+	}
+	now := time.Now()
+	if !now.Before(hold.ExpiresAt) {
+		return nil, apperrors.NewInvalidStateTransitionError("the reservation hold has expired")
+	}
+
+	entry, err := depositEntry(transaction, models.LedgerEventDepositHeld, now)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, intent, err := s.holdPayment(ctx, transaction, holdPurposeDeposit, hold.Deposit, req.PaymentSource)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := hold.ExpiresAt
+	if extended := now.Add(s.holds.DepositHoldTTL); extended.After(expiresAt) {
+		expiresAt = extended
+	}
+
+	// Only record the deposit if the hold is still unpaid and has not expired in the meantime
+	filter := bson.M{
+		"_id":                transaction.ID,
+		"status":             models.TransactionStatusPending,
+		"hold.depositStatus": models.DepositStatusAwaitingPayment,
+		"hold.expiresAt":     bson.M{"$gt": now},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"hold.depositStatus":    models.DepositStatusHeld,
+			"hold.depositProvider":  provider.Name(),
+			"hold.depositReference": intent.ID,
+			"hold.depositPaidAt":    now,
+			"hold.expiresAt":        expiresAt,
+			"updatedAt":             now,
+		},
+	}
+
+	// The deposit, the vehicle's reservation end and the ledger entry commit together
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated models.Transaction
+	err = s.withTransaction(ctx, func(sc mongo.SessionContext) error {
+		err := s.collection.FindOneAndUpdate(sc, filter, update, opts).Decode(&updated)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errTransactionModified
+			}
+			return err
+		}
+
+		// The vehicle shows when its reservation ends
+		_, err = s.vehicleCollection.UpdateOne(sc,
+			bson.M{"_id": updated.VehicleID, "reservation.transactionId": updated.ID},
+			bson.M{"$set": bson.M{"reservation.expiresAt": expiresAt}},
+		)
+		if err != nil {
+			return err
+		}
+
+		return s.postLedger(sc, entry)
+	})
+	if err != nil {
+		// Nothing was recorded, so the deposit is given back
+		_, _ = provider.Refund(ctx, intent.ID)
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// settleHold releases the vehicle and settles the deposit of a transaction that has ended
+// A held deposit is refunded unless the cancellation forfeited it to the seller. Provider calls are
+// idempotent, so a settlement that fails part way is retried by ExpireHolds
+func (s *TransactionService) settleHold(ctx context.Context, transaction *models.Transaction) error {
+	hold := transaction.Hold
+	if hold == nil || hold.ReleasedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	set := bson.M{"hold.releasedAt": now}
+	var entry *models.LedgerEntry
+	if hold.DepositStatus == models.DepositStatusHeld {
+		event, status := depositSettlement(transaction)
+		var err error
+		if entry, err = depositEntry(transaction, event, now); err != nil {
+			return err
+		}
+
+		if status == models.DepositStatusForfeited {
+			err = s.captureHold(ctx, hold.DepositProvider, hold.DepositReference)
+		} else {
+			err = s.refundIntent(ctx, hold.DepositProvider, hold.DepositReference)
+		}
+		if err != nil {
+			return err
+		}
+		set["hold.depositStatus"] = status
+	}
+
+	// Only the settlement that records the release reopens the vehicle and posts the deposit to the ledger
+	filter := bson.M{"_id": transaction.ID, "hold.releasedAt": bson.M{"$exists": false}}
+	return s.withTransaction(ctx, func(sc mongo.SessionContext) error {
+		result, err := s.collection.UpdateOne(sc, filter, bson.M{"$set": set})
+		if err != nil || result.MatchedCount == 0 {
+			return err
+		}
+
+		// Completed sales already marked the vehicle sold, so this only reopens cancelled ones
+		if err := s.unreserveVehicle(sc, transaction, now); err != nil {
+			return err
+		}
+		return s.postLedger(sc, entry)
+	})
+}
+
+// depositSettlement decides what happens to a held deposit once its transaction has ended, returning
+// the ledger event and the deposit's new status
+// Only a cancellation that forfeited the deposit pays it to the seller; everything else refunds the buyer
+func depositSettlement(transaction *models.Transaction) (event, status string) {
+	if transaction.Hold.ForfeitDeposit && transaction.Status == models.TransactionStatusCancelled {
+		return models.LedgerEventDepositForfeited, models.DepositStatusForfeited
+	}
+	return models.LedgerEventDepositRefunded, models.DepositStatusRefunded
+}
+
+// ExpireHolds cancels pending and failed transactions whose reservation hold has expired, finishes the
+// refunds of cancellations whose payment was not yet returned and the captures of completed sales whose
+// payment was not yet collected, and settles the holds of transactions that ended without their hold
+// being settled
+// Transactions with a payment still processing are left until it settles, and disputed transactions
+// until the dispute is resolved. Returns how many holds expired
+func (s *TransactionService) ExpireHolds(ctx context.Context, now time.Time) (int, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "hold.expiresAt", Value: 1}}).SetLimit(holdExpiryBatch)
+	expired, err := s.findTransactions(ctx, expiredHoldsFilter(now), opts)
+	if err != nil {
+		return 0, err
+	}
+
+	system := TransactionActor{Role: TransactionRoleSystem}
+	count := 0
+	var errs []error
+	for i := range expired {
+		_, err := s.cancel(ctx, &expired[i], system, "reservation hold expired", expiryForfeitsDeposit(&expired[i]))
+		if err == errTransactionModified {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		count++
+	}
+
+	refundsDue, err := s.findTransactions(ctx, bson.M{
+		"status": models.TransactionStatusCancelled,
+		"$or": bson.A{
+			bson.M{"escrow.status": models.EscrowStatusRefundPending},
+			bson.M{"payment.refundDue": true},
+		},
+	}, options.Find().SetLimit(holdExpiryBatch))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for i := range refundsDue {
+		if _, err := s.refundCancelled(ctx, &refundsDue[i]); err != nil && err != errTransactionModified {
+			errs = append(errs, err)
+		}
+	}
+
+	capturesDue, err := s.findTransactions(ctx, bson.M{
+		"status":             models.TransactionStatusCompleted,
+		"payment.captureDue": true,
+	}, options.Find().SetLimit(holdExpiryBatch))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for i := range capturesDue {
+		if _, err := s.capturePayment(ctx, &capturesDue[i]); err != nil && err != errTransactionModified {
+			errs = append(errs, err)
+		}
+	}
+
+	unsettled, err := s.findTransactions(ctx, bson.M{
+		"status":          bson.M{"$in": []string{models.TransactionStatusCompleted, models.TransactionStatusCancelled}},
+		"hold":            bson.M{"$exists": true},
+		"hold.releasedAt": bson.M{"$exists": false},
+	}, options.Find().SetLimit(holdExpiryBatch))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for i := range unsettled {
+		if err := s.settleHold(ctx, &unsettled[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return count, errors.Join(errs...)
+}
+
+// expiryForfeitsDeposit decides whether an expired hold pays the buyer's deposit to the seller
+// Only a buyer who defaulted forfeits it: a sale the seller or an admin marked failed, or one the buyer
+// already paid for through the provider or escrow, lapsed through no fault of the buyer
+func expiryForfeitsDeposit(transaction *models.Transaction) bool {
+	if transaction.Status == models.TransactionStatusFailed {
+		return false
+	}
+	if transaction.Payment != nil && payments.IsConfirmed(transaction.Payment.Status) {
+		return false
+	}
+	if transaction.Escrow != nil && transaction.Escrow.Status == models.EscrowStatusHeld {
+		return false
+	}
+	return true
+}
+
+// expiredHoldsFilter matches the pending and failed transactions whose hold expired by now, leaving
+// those with a payment still processing or an open dispute
+func expiredHoldsFilter(now time.Time) bson.M {
+	return bson.M{
+		"status":         bson.M{"$in": []string{models.TransactionStatusPending, models.TransactionStatusFailed}},
+		"hold.expiresAt": bson.M{"$lte": now},
+		"payment.status": bson.M{"$ne": payments.StatusProcessing},
+		"openDisputeId":  bson.M{"$exists": false},
+	}
+}
+
+// findTransactions decodes every transaction matching the filter
+func (s *TransactionService) findTransactions(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.Transaction, error) {
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var transactions []models.Transaction
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// HoldExpiryJob periodically cancels transactions whose reservation hold has expired
+type HoldExpiryJob struct {
+	transactions *TransactionService
+	interval     time.Duration
+}
+
+// NewHoldExpiryJob creates a job that checks for expired holds every interval
+func NewHoldExpiryJob(transactions *TransactionService, interval time.Duration) *HoldExpiryJob {
+	return &HoldExpiryJob{
+		transactions: transactions,
+		interval:     interval,
+	}
+}
+
+// Run expires holds every interval until ctx is cancelled
+// Errors do not stop the loop; they are passed to onError and the transactions are retried next run
+func (j *HoldExpiryJob) Run(ctx context.Context, onError func(error)) {
+	runEvery(ctx, j.interval, func(ctx context.Context, now time.Time) error {
+		_, err := j.transactions.ExpireHolds(ctx, now)
+		return err
+	}, onError)
+}
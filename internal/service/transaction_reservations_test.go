@@ -0,0 +1,232 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+	"github.com/Over-knight/Lujay-assesment/internal/payments"
+)
+
+func TestNewHold(t *testing.T) {
+	s := &TransactionService{holds: ReservationPolicy{HoldTTL: 48 * time.Hour, DepositHoldTTL: 7 * 24 * time.Hour}}
+	now := time.Now()
+
+	hold := s.newHold(money.Money{}, now)
+	assert.Equal(t, now.Add(48*time.Hour), hold.ExpiresAt)
+	assert.True(t, hold.Deposit.IsZero())
+	assert.Empty(t, hold.DepositStatus, "holds without a deposit have nothing to pay")
+
+	hold = s.newHold(money.MustParse("1000", "USD"), now)
+	assert.Equal(t, "1000.00", hold.Deposit.Decimal())
+	assert.Equal(t, models.DepositStatusAwaitingPayment, hold.DepositStatus)
+}
+
+func TestDepositSettlement(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		forfeit    bool
+		wantEvent  string
+		wantStatus string
+	}{
+		{"cancelled and forfeited", models.TransactionStatusCancelled, true, models.LedgerEventDepositForfeited, models.DepositStatusForfeited},
+		{"cancelled without forfeiting", models.TransactionStatusCancelled, false, models.LedgerEventDepositRefunded, models.DepositStatusRefunded},
+		{"completed", models.TransactionStatusCompleted, false, models.LedgerEventDepositRefunded, models.DepositStatusRefunded},
+		{"completed after a forfeit was decided", models.TransactionStatusCompleted, true, models.LedgerEventDepositRefunded, models.DepositStatusRefunded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := &models.Transaction{
+				Status: tt.status,
+				Hold:   &models.ReservationHold{Deposit: money.MustParse("500", "USD"), DepositStatus: models.DepositStatusHeld, ForfeitDeposit: tt.forfeit},
+			}
+
+			event, status := depositSettlement(transaction)
+			assert.Equal(t, tt.wantEvent, event)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestExpiryForfeitsDeposit(t *testing.T) {
+	tests := []struct {
+		name        string
+		transaction models.Transaction
+		wantEvent   string
+	}{
+		{"buyer never paid", models.Transaction{Status: models.TransactionStatusPending}, models.LedgerEventDepositForfeited},
+		{"buyer's payment was declined", models.Transaction{
+			Status:  models.TransactionStatusPending,
+			Payment: &models.ProviderPayment{Status: payments.StatusFailed},
+		}, models.LedgerEventDepositForfeited},
+		{"escrow never funded", models.Transaction{
+			Status: models.TransactionStatusPending,
+			Escrow: &models.EscrowDetails{Status: models.EscrowStatusAwaitingFunds},
+		}, models.LedgerEventDepositForfeited},
+		{"buyer's payment confirmed", models.Transaction{
+			Status:  models.TransactionStatusPending,
+			Payment: &models.ProviderPayment{Status: payments.StatusRequiresCapture},
+		}, models.LedgerEventDepositRefunded},
+		{"buyer's payment captured", models.Transaction{
+			Status:  models.TransactionStatusPending,
+			Payment: &models.ProviderPayment{Status: payments.StatusSucceeded},
+		}, models.LedgerEventDepositRefunded},
+		{"escrow funded", models.Transaction{
+			Status: models.TransactionStatusPending,
+			Escrow: &models.EscrowDetails{Status: models.EscrowStatusHeld},
+		}, models.LedgerEventDepositRefunded},
+		{"seller marked the sale failed", models.Transaction{Status: models.TransactionStatusFailed}, models.LedgerEventDepositRefunded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The expiry cancels the sale, then settles the deposit as the cancellation decided
+			transaction := tt.transaction
+			transaction.Hold = &models.ReservationHold{
+				Deposit:        money.MustParse("500", "USD"),
+				DepositStatus:  models.DepositStatusHeld,
+				ForfeitDeposit: expiryForfeitsDeposit(&tt.transaction),
+			}
+			transaction.Status = models.TransactionStatusCancelled
+
+			event, _ := depositSettlement(&transaction)
+			assert.Equal(t, tt.wantEvent, event)
+		})
+	}
+}
+
+// matchesFilter evaluates the equality, $in, $ne, $lte and $exists conditions ExpireHolds filters on
+// against the transaction as it is stored
+func matchesFilter(t *testing.T, filter bson.M, transaction *models.Transaction) bool {
+	raw, err := bson.Marshal(transaction)
+	require.NoError(t, err)
+	var doc bson.M
+	require.NoError(t, bson.Unmarshal(raw, &doc))
+
+	for path, condition := range filter {
+		var value interface{}
+		found := true
+		current := interface{}(doc)
+		for _, field := range strings.Split(path, ".") {
+			embedded, ok := current.(bson.M)
+			if !ok {
+				found = false
+				break
+			}
+			if current, ok = embedded[field]; !ok {
+				found = false
+				break
+			}
+		}
+		if found {
+			value = current
+		}
+
+		operators, ok := condition.(bson.M)
+		if !ok {
+			if !found || value != condition {
+				return false
+			}
+			continue
+		}
+		for operator, operand := range operators {
+			switch operator {
+			case "$exists":
+				if found != operand.(bool) {
+					return false
+				}
+			case "$ne":
+				if found && value == operand {
+					return false
+				}
+			case "$in":
+				in := false
+				for _, option := range operand.([]string) {
+					in = in || value == option
+				}
+				if !in {
+					return false
+				}
+			case "$lte":
+				stored, ok := value.(primitive.DateTime)
+				if !ok || stored.Time().After(operand.(time.Time)) {
+					return false
+				}
+			default:
+				t.Fatalf("matchesFilter does not support %s", operator)
+			}
+		}
+	}
+	return true
+}
+
+func TestExpiredHoldsFilter(t *testing.T) {
+	now := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	hold := func(expiresAt time.Time) *models.ReservationHold {
+		return &models.ReservationHold{ExpiresAt: expiresAt}
+	}
+	disputeID := primitive.NewObjectID()
+
+	tests := []struct {
+		name        string
+		transaction models.Transaction
+		want        bool
+	}{
+		{"pending past its hold", models.Transaction{Status: models.TransactionStatusPending, Hold: hold(now.Add(-time.Hour))}, true},
+		{"failed past its hold", models.Transaction{Status: models.TransactionStatusFailed, Hold: hold(now.Add(-time.Hour))}, true},
+		{"hold expiring now", models.Transaction{Status: models.TransactionStatusPending, Hold: hold(now)}, true},
+		{"declined payment", models.Transaction{
+			Status:  models.TransactionStatusFailed,
+			Hold:    hold(now.Add(-time.Hour)),
+			Payment: &models.ProviderPayment{Status: payments.StatusFailed},
+		}, true},
+		{"hold still running", models.Transaction{Status: models.TransactionStatusPending, Hold: hold(now.Add(time.Hour))}, false},
+		{"no hold", models.Transaction{Status: models.TransactionStatusPending}, false},
+		{"payment processing", models.Transaction{
+			Status:  models.TransactionStatusPending,
+			Hold:    hold(now.Add(-time.Hour)),
+			Payment: &models.ProviderPayment{Status: payments.StatusProcessing},
+		}, false},
+		{"disputed", models.Transaction{Status: models.TransactionStatusPending, Hold: hold(now.Add(-time.Hour)), OpenDisputeID: &disputeID}, false},
+		{"funded", models.Transaction{Status: models.TransactionStatusFunded, Hold: hold(now.Add(-time.Hour))}, false},
+		{"cancelled", models.Transaction{Status: models.TransactionStatusCancelled, Hold: hold(now.Add(-time.Hour))}, false},
+	}
+
+	filter := expiredHoldsFilter(now)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchesFilter(t, filter, &tt.transaction))
+		})
+	}
+}
+
+func TestReservableVehicleFilter(t *testing.T) {
+	transaction := &models.Transaction{VehicleID: primitive.NewObjectID(), SellerID: primitive.NewObjectID()}
+	assert.Equal(t, bson.M{
+		"_id":         transaction.VehicleID,
+		"ownerId":     transaction.SellerID,
+		"status":      models.VehicleStatusActive,
+		"listingType": bson.M{"$ne": models.ListingTypeAuction},
+	}, reservableVehicleFilter(transaction), "direct sales and accepted offers cannot take a vehicle mid-auction")
+
+	auctionID := primitive.NewObjectID()
+	transaction.AuctionID = &auctionID
+	assert.Equal(t, bson.M{
+		"_id":     transaction.VehicleID,
+		"ownerId": transaction.SellerID,
+		"status":  models.VehicleStatusActive,
+		"$or": bson.A{
+			bson.M{"listingType": bson.M{"$ne": models.ListingTypeAuction}},
+			bson.M{"auctionId": auctionID},
+		},
+	}, reservableVehicleFilter(transaction), "only the vehicle's own auction can sell it while it is auctioned")
+}
@@ -10,25 +10,66 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/financing"
 	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
+	"github.com/Over-knight/Lujay-assesment/internal/payments"
 )
 
 // TransactionService handles transaction-related business logic
 type TransactionService struct {
-	collection        *mongo.Collection
-	vehicleCollection *mongo.Collection
+	collection               *mongo.Collection
+	vehicleCollection        *mongo.Collection
+	userCollection           *mongo.Collection
+	eventCollection          *mongo.Collection // processed payment webhook events
+	installmentCollection    *mongo.Collection // installments of completed financing transactions
+	invoiceCollection        *mongo.Collection // invoices issued for completed sales
+	invoiceCounterCollection *mongo.Collection // last invoice number issued per seller
+	ledgerCollection         *mongo.Collection // double-entry postings for every money movement
+	stateMachine             *TransactionStateMachine
+	providers                *payments.Registry   // processes card and bank transfer payments, escrow funds and deposits
+	disputeWindow            time.Duration        // how long after delivery escrow funds are released automatically
+	rates                    ExchangeRateProvider // converts the vehicle's listed price for the rate snapshot
+	holds                    ReservationPolicy    // how long transactions keep their vehicle reserved
+	pricing                  PricingRules         // fees, sales tax and commission charged on top of the vehicle price
 }
 
+// errTransactionModified is returned when a conditional status update loses a race
+var errTransactionModified = apperrors.NewConflictError("transaction was modified concurrently, please retry")
+
 // NewTransactionService creates a new transaction service
-func NewTransactionService(db *mongo.Database) *TransactionService {
+// A nil pricing charges nothing on top of the vehicle price
+func NewTransactionService(db *mongo.Database, providers *payments.Registry, disputeWindow time.Duration, rates ExchangeRateProvider, holds ReservationPolicy, pricing PricingRules) *TransactionService {
+	if pricing == nil {
+		pricing = NewTablePricingRules(DefaultPricingRulesConfig(), rates)
+	}
 	return &TransactionService{
-		collection:        db.Collection("transactions"),
-		vehicleCollection: db.Collection("vehicles"),
+		collection:               db.Collection("transactions"),
+		vehicleCollection:        db.Collection("vehicles"),
+		userCollection:           db.Collection("users"),
+		eventCollection:          db.Collection("payment_events"),
+		installmentCollection:    db.Collection("installments"),
+		invoiceCollection:        db.Collection("invoices"),
+		invoiceCounterCollection: db.Collection("invoice_counters"),
+		ledgerCollection:         db.Collection("ledger_entries"),
+		stateMachine:             NewTransactionStateMachine(),
+		providers:                providers,
+		disputeWindow:            disputeWindow,
+		rates:                    rates,
+		holds:                    holds,
+		pricing:                  pricing,
 	}
 }
 
 // CreateTransaction creates a new transaction
 func (s *TransactionService) CreateTransaction(ctx context.Context, req *models.CreateTransactionRequest, sellerID primitive.ObjectID) (*models.Transaction, error) {
+	return s.createTransaction(ctx, req, sellerID, nil)
+}
+
+// createTransaction creates a new transaction, for the winner of the auction if auctionID is set
+// Vehicles being auctioned can only be sold by their own auction
+func (s *TransactionService) createTransaction(ctx context.Context, req *models.CreateTransactionRequest, sellerID primitive.ObjectID, auctionID *primitive.ObjectID) (*models.Transaction, error) {
 	vehicleID, err := primitive.ObjectIDFromHex(req.VehicleID)
 	if err != nil {
 		return nil, errors.New("invalid vehicleId")
@@ -54,29 +95,74 @@ func (s *TransactionService) CreateTransaction(ctx context.Context, req *models.
 	}
 
 	// Check if vehicle is active
+	if vehicle.Status == models.VehicleStatusReserved {
+		return nil, errVehicleReserved
+	}
 	if vehicle.Status != models.VehicleStatusActive {
 		return nil, errors.New("vehicle is not available for sale")
 	}
+	if vehicle.ListingType == models.ListingTypeAuction && (auctionID == nil || vehicle.AuctionID == nil || *vehicle.AuctionID != *auctionID) {
+		return nil, errVehicleAuctioned
+	}
 
 	// Check if buyer is not the seller
 	if buyerID == sellerID {
 		return nil, errors.New("cannot create transaction with yourself")
 	}
 
+	price, details, err := req.Amounts()
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+
+	// The request's amount is the vehicle price; the transaction's amount adds the fees and taxes on top
+	lines, amount, err := s.priceSale(ctx, &vehicle, price)
+	if err != nil {
+		return nil, err
+	}
+
+	deposit, err := req.Deposit.WithCurrency(req.Currency)
+	if err != nil {
+		return nil, apperrors.NewValidationError("deposit: " + err.Error())
+	}
+
+	snapshot, err := s.exchangeRateSnapshot(ctx, vehicle.Price, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	transaction := &models.Transaction{
+		ID:             primitive.NewObjectID(),
 		VehicleID:      vehicleID,
 		SellerID:       sellerID,
 		BuyerID:        buyerID,
 		Type:           models.TransactionTypeSale,
 		Status:         models.TransactionStatusPending,
-		Amount:         req.Amount,
+		Amount:         amount,
+		PriceLines:     lines,
 		Currency:       req.Currency,
 		PaymentMethod:  req.PaymentMethod,
-		PaymentDetails: req.PaymentDetails,
+		PaymentDetails: details,
+		ExchangeRate:   snapshot,
+		Hold:           s.newHold(deposit, now),
+		AuctionID:      auctionID,
 		Notes:          req.Notes,
-		CreatedAt:      now,
-		UpdatedAt:      now,
+		StatusHistory: []models.TransactionStatusChange{
+			{
+				To:        models.TransactionStatusPending,
+				ActorID:   sellerID,
+				ActorRole: TransactionRoleSeller,
+				ChangedAt: now,
+			},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// Escrow transactions wait for the buyer to fund them
+	if req.Escrow {
+		transaction.Escrow = &models.EscrowDetails{Status: models.EscrowStatusAwaitingFunds}
 	}
 
 	// Add inspection reference if provided
@@ -90,45 +176,67 @@ func (s *TransactionService) CreateTransaction(ctx context.Context, req *models.
 
 	// Calculate financing details if payment method is financing
 	if req.PaymentMethod == models.PaymentMethodFinancing {
-		s.calculateFinancingDetails(transaction)
+		if err := s.calculateFinancingDetails(transaction); err != nil {
+			return nil, err
+		}
 	}
 
-	result, err := s.collection.InsertOne(ctx, transaction)
-	if err != nil {
+	// Reserve the vehicle first so a concurrent sale of it fails instead of creating a second transaction
+	if err := s.reserveVehicle(ctx, transaction, now); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.collection.InsertOne(ctx, transaction); err != nil {
+		_ = s.unreserveVehicle(ctx, transaction, now)
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errVehicleReserved
+		}
 		return nil, err
 	}
 
-	transaction.ID = result.InsertedID.(primitive.ObjectID)
 	return transaction, nil
 }
 
-// calculateFinancingDetails calculates monthly payment and financed amount
-func (s *TransactionService) calculateFinancingDetails(txn *models.Transaction) {
-	txn.PaymentDetails.FinancedAmount = txn.Amount - txn.PaymentDetails.DownPayment
-
-	// Calculate monthly payment using simple interest
-	// Monthly payment = (Principal + Interest) / Number of months
-	principal := txn.PaymentDetails.FinancedAmount
-	monthlyInterestRate := txn.PaymentDetails.InterestRate / 100 / 12
-	months := float64(txn.PaymentDetails.FinancingTerms)
+// exchangeRateSnapshot captures the rate from the vehicle's listed price to the transaction's currency
+func (s *TransactionService) exchangeRateSnapshot(ctx context.Context, listingPrice money.Money, currency string) (*models.ExchangeRateSnapshot, error) {
+	if listingPrice.Currency() == "" {
+		listingPrice, _ = listingPrice.WithCurrency(money.DefaultCurrency)
+	}
 
-	if monthlyInterestRate > 0 {
-		// Using loan formula: M = P * [r(1+r)^n] / [(1+r)^n - 1]
-		monthlyPayment := principal * (monthlyInterestRate * pow(1+monthlyInterestRate, months)) / (pow(1+monthlyInterestRate, months) - 1)
-		txn.PaymentDetails.MonthlyPayment = monthlyPayment
-	} else {
-		// Zero interest
-		txn.PaymentDetails.MonthlyPayment = principal / months
+	rate, err := exchangeRate(ctx, s.rates, listingPrice.Currency(), currency)
+	if err != nil {
+		return nil, err
 	}
+	converted, err := rate.Convert(listingPrice)
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+
+	return &models.ExchangeRateSnapshot{
+		ExchangeRate: rate,
+		ListingPrice: listingPrice,
+		Converted:    converted,
+		CapturedAt:   time.Now(),
+	}, nil
 }
 
-// pow calculates x^n for financial calculations
-func pow(x, n float64) float64 {
-	result := 1.0
-	for i := 0; i < int(n); i++ {
-		result *= x
+// calculateFinancingDetails amortizes the financed amount and records the resulting payment terms
+func (s *TransactionService) calculateFinancingDetails(txn *models.Transaction) error {
+	terms, err := txn.PaymentDetails.FinancingTermsFor(txn.Amount)
+	if err != nil {
+		return apperrors.NewValidationError(err.Error())
+	}
+	schedule, err := financing.Amortize(terms)
+	if err != nil {
+		return apperrors.NewValidationError(err.Error())
 	}
-	return result
+
+	txn.PaymentDetails.FinancedAmount = schedule.Principal
+	txn.PaymentDetails.FinancingTerms = len(schedule.Periods)
+	txn.PaymentDetails.MonthlyPayment = schedule.Payment
+	txn.PaymentDetails.APR = schedule.APR
+	txn.PaymentDetails.TotalInterest = schedule.TotalInterest
+	return nil
 }
 
 // GetTransactionByID retrieves a transaction by ID
@@ -207,6 +315,7 @@ func (s *TransactionService) GetTransactionsByVehicle(ctx context.Context, vehic
 }
 
 // UpdateTransaction updates a transaction
+// Status changes are validated by the state machine; completion and escrow steps have dedicated methods
 func (s *TransactionService) UpdateTransaction(ctx context.Context, id string, req *models.UpdateTransactionRequest, userID primitive.ObjectID) (*models.Transaction, error) {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -223,32 +332,77 @@ func (s *TransactionService) UpdateTransaction(ctx context.Context, id string, r
 		return nil, err
 	}
 
-	if existingTxn.SellerID != userID && existingTxn.BuyerID != userID {
+	actor, ok, err := s.resolveActor(ctx, &existingTxn, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
 		return nil, errors.New("you are not authorized to update this transaction")
 	}
 
+	now := time.Now()
 	update := bson.M{
 		"$set": bson.M{
-			"updatedAt": time.Now(),
+			"updatedAt": now,
 		},
 	}
 
-	if req.Status != "" {
+	if req.Status != "" && req.Status != existingTxn.Status {
+		// Completion transfers vehicle ownership and must use the dedicated endpoint
+		if req.Status == models.TransactionStatusCompleted {
+			return nil, apperrors.NewInvalidStateTransitionError("transactions can only be completed through the complete endpoint")
+		}
+
+		// Refunds move money and may revert ownership, so they must use the admin refund endpoint
+		if req.Status == models.TransactionStatusRefunded {
+			return nil, apperrors.NewInvalidStateTransitionError("transactions can only be refunded through the refund endpoint")
+		}
+
+		// Cancelling refunds payments and releases the vehicle, so it must use the cancel endpoint
+		if req.Status == models.TransactionStatusCancelled {
+			return nil, apperrors.NewInvalidStateTransitionError("transactions can only be cancelled through the cancel endpoint")
+		}
+
+		// Escrow steps move money at the payment provider and must use their dedicated endpoints
+		if req.Status == models.TransactionStatusFunded || req.Status == models.TransactionStatusDelivered || existingTxn.Status == models.TransactionStatusFunded {
+			return nil, apperrors.NewInvalidStateTransitionError("escrow transactions can only change status through the fund, deliver, confirm-delivery and cancel endpoints")
+		}
+
+		// Failing or reopening a disputed sale would sidestep the dispute
+		if existingTxn.OpenDisputeID != nil {
+			return nil, apperrors.NewInvalidStateTransitionError("the transaction is frozen while its dispute is open")
+		}
+
+		change, err := s.stateMachine.Transition(&existingTxn, req.Status, actor, req.Notes)
+		if err != nil {
+			return nil, err
+		}
+
 		update["$set"].(bson.M)["status"] = req.Status
+		update["$push"] = bson.M{"statusHistory": change}
 	}
 
 	if req.PaymentDetails != nil {
-		update["$set"].(bson.M)["paymentDetails"] = req.PaymentDetails
+		details, err := req.PaymentDetails.WithCurrency(existingTxn.Currency)
+		if err != nil {
+			return nil, apperrors.NewValidationError(err.Error())
+		}
+		update["$set"].(bson.M)["paymentDetails"] = details
 	}
 
 	if req.Notes != "" {
 		update["$set"].(bson.M)["notes"] = req.Notes
 	}
 
+	// Only apply the update if the status has not changed and no dispute was opened since it was read
+	filter := bson.M{"_id": objectID, "status": existingTxn.Status, "openDisputeId": disputeFreezeFilter(&existingTxn)}
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	var transaction models.Transaction
-	err = s.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update, opts).Decode(&transaction)
+	err = s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&transaction)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errTransactionModified
+		}
 		return nil, err
 	}
 
@@ -272,69 +426,158 @@ func (s *TransactionService) CompleteTransaction(ctx context.Context, id string,
 		return nil, err
 	}
 
-	// Only seller can complete transaction
-	if transaction.SellerID != userID {
+	actor, ok, err := s.resolveActor(ctx, &transaction, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
 		return nil, errors.New("only the seller can complete this transaction")
 	}
 
-	// Check if transaction is pending
-	if transaction.Status != models.TransactionStatusPending {
-		return nil, errors.New("transaction is not pending")
+	// Card and bank transfer payments must be confirmed by the provider, not by the client
+	if err := s.refreshPayment(ctx, &transaction); err != nil {
+		return nil, err
 	}
 
-	// Start a session for transaction atomicity
-	session, err := s.collection.Database().Client().StartSession()
+	// Validate the transition against the state the transaction will have once paid
+	transaction.PaymentDetails.TransactionReference = req.TransactionReference
+	change, err := s.stateMachine.Transition(&transaction, models.TransactionStatusCompleted, actor, req.Notes)
 	if err != nil {
 		return nil, err
 	}
-	defer session.EndSession(ctx)
 
-	// Execute transaction
-	err = mongo.WithSession(ctx, session, func(sc mongo.SessionContext) error {
-		// Update transaction status
-		now := time.Now()
-		update := bson.M{
-			"$set": bson.M{
-				"status":                              models.TransactionStatusCompleted,
-				"completedAt":                         now,
-				"updatedAt":                           now,
-				"paymentDetails.transactionReference": req.TransactionReference,
-				"paymentDetails.paidAt":               now,
-			},
+	now := change.ChangedAt
+	set := bson.M{
+		"completedAt":                         now,
+		"paymentDetails.transactionReference": req.TransactionReference,
+		"paymentDetails.paidAt":               now,
+	}
+
+	// Authorized card payments are captured once the completion has committed
+	if transaction.RequiresProviderPayment() {
+		for k, v := range paymentCompletion(&transaction, now) {
+			set[k] = v
+		}
+		if transaction.PaymentDetails.PaidAt != nil {
+			delete(set, "paymentDetails.paidAt")
 		}
+	}
 
-		if req.Notes != "" {
-			update["$set"].(bson.M)["notes"] = req.Notes
+	if req.Notes != "" {
+		set["notes"] = req.Notes
+	}
+
+	completed, err := s.completeAndTransfer(ctx, &transaction, change, set)
+	if err != nil {
+		return nil, err
+	}
+
+	// The sale stands either way; ExpireHolds retries the capture if it fails
+	if captured, err := s.capturePayment(ctx, completed); err == nil {
+		completed = captured
+	}
+	return completed, nil
+}
+
+// withTransaction runs fn in a MongoDB transaction, so its writes all commit or none do
+// fn may run more than once when the server asks for a retry, so it must only write through sc
+// Transactions need MongoDB to run as a replica set
+func (s *TransactionService) withTransaction(ctx context.Context, fn func(sc mongo.SessionContext) error) error {
+	return withMongoTransaction(ctx, s.collection.Database().Client(), fn)
+}
+
+// withMongoTransaction runs fn in a MongoDB transaction on the client, retrying it as the server asks
+func withMongoTransaction(ctx context.Context, client *mongo.Client, fn func(sc mongo.SessionContext) error) error {
+	session, err := client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
+
+// completeAndTransfer marks the transaction completed and hands the vehicle to the buyer in one MongoDB transaction
+// Financing transactions get their installment schedule in the same transaction, and the sale is posted to the ledger
+// The update only applies if the transaction is still in the status the change moves it from and no
+// dispute was opened since it was read. A frozen transaction only gets here by resolving its dispute,
+// which the completion ends
+func (s *TransactionService) completeAndTransfer(ctx context.Context, transaction *models.Transaction, change *models.TransactionStatusChange, set bson.M) (*models.Transaction, error) {
+	now := change.ChangedAt
+
+	// Everything the completion writes is built first, so a failure here writes nothing
+	var installments []interface{}
+	if transaction.PaymentMethod == models.PaymentMethodFinancing {
+		var err error
+		if installments, err = buildInstallments(transaction, now); err != nil {
+			return nil, err
 		}
+	}
+	entry, err := saleCompletedEntry(transaction, now)
+	if err != nil {
+		return nil, err
+	}
+
+	set["status"] = models.TransactionStatusCompleted
+	set["updatedAt"] = now
+	update := bson.M{
+		"$set":  set,
+		"$push": bson.M{"statusHistory": change},
+	}
+	if transaction.OpenDisputeID != nil {
+		update["$unset"] = bson.M{"openDisputeId": ""}
+	}
+	filter := bson.M{"_id": transaction.ID, "status": change.From, "openDisputeId": disputeFreezeFilter(transaction)}
 
+	// The status change, ownership transfer, installment schedule and ledger entry commit together
+	var completed models.Transaction
+	err = s.withTransaction(ctx, func(sc mongo.SessionContext) error {
 		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-		err := s.collection.FindOneAndUpdate(sc, bson.M{"_id": objectID}, update, opts).Decode(&transaction)
+		err := s.collection.FindOneAndUpdate(sc, filter, update, opts).Decode(&completed)
 		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errTransactionModified
+			}
 			return err
 		}
 
-		// Update vehicle ownership and status
 		vehicleUpdate := bson.M{
 			"$set": bson.M{
-				"ownerId":   transaction.BuyerID,
+				"ownerId":   completed.BuyerID,
 				"status":    models.VehicleStatusSold,
 				"updatedAt": now,
 			},
+			"$unset": bson.M{"reservation": ""},
 		}
-
-		_, err = s.vehicleCollection.UpdateOne(sc, bson.M{"_id": transaction.VehicleID}, vehicleUpdate)
-		if err != nil {
+		if _, err := s.vehicleCollection.UpdateOne(sc, bson.M{"_id": completed.VehicleID}, vehicleUpdate); err != nil {
 			return err
 		}
 
-		return nil
-	})
+		// Financed sales start collecting monthly installments from the buyer
+		if len(installments) > 0 {
+			if _, err := s.installmentCollection.InsertMany(sc, installments); err != nil {
+				return err
+			}
+		}
 
+		return s.postLedger(sc, entry)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &transaction, nil
+	transaction = &completed
+
+	// The sale went through, so any deposit goes back to the buyer; ExpireHolds retries this if it fails
+	_ = s.settleHold(ctx, transaction)
+
+	// The invoice is issued on first download if this fails
+	_, _ = s.issueInvoice(ctx, transaction)
+
+	return transaction, nil
 }
 
 // CancelTransaction cancels a transaction
@@ -354,36 +597,165 @@ func (s *TransactionService) CancelTransaction(ctx context.Context, id string, n
 		return nil, err
 	}
 
-	// Both seller and buyer can cancel
-	if transaction.SellerID != userID && transaction.BuyerID != userID {
+	// Both parties and admins can cancel
+	actor, ok, err := s.resolveActor(ctx, &transaction, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
 		return nil, errors.New("you are not authorized to cancel this transaction")
 	}
 
-	// Can only cancel pending transactions
-	if transaction.Status != models.TransactionStatusPending {
-		return nil, errors.New("only pending transactions can be cancelled")
+	// A buyer who walks away forfeits their deposit to the seller
+	return s.cancel(ctx, &transaction, actor, notes, actor.Role == TransactionRoleBuyer)
+}
+
+// cancel marks the transaction cancelled, refunds any payment taken and releases its vehicle
+// The cancellation is recorded before any money moves, with the refunds it owes marked pending, so a
+// dispute opened or a status change made since the transaction was read leaves the payment untouched.
+// forfeitDeposit decides whether a held reservation deposit is paid to the seller instead of refunded
+func (s *TransactionService) cancel(ctx context.Context, transaction *models.Transaction, actor TransactionActor, notes string, forfeitDeposit bool) (*models.Transaction, error) {
+	change, err := s.stateMachine.Transition(transaction, models.TransactionStatusCancelled, actor, notes)
+	if err != nil {
+		return nil, err
+	}
+	refundDue, err := paymentRefundDue(transaction)
+	if err != nil {
+		return nil, err
 	}
 
-	now := time.Now()
+	now := change.ChangedAt
+	set := bson.M{
+		"status":      models.TransactionStatusCancelled,
+		"cancelledAt": now,
+		"updatedAt":   now,
+	}
 	update := bson.M{
-		"$set": bson.M{
-			"status":      models.TransactionStatusCancelled,
-			"cancelledAt": now,
-			"updatedAt":   now,
-		},
+		"$set":  set,
+		"$push": bson.M{"statusHistory": change},
 	}
 
-	if notes != "" {
-		update["$set"].(bson.M)["notes"] = notes
+	// Held escrow funds and confirmed card and bank transfer payments go back to the buyer
+	if transaction.Status == models.TransactionStatusFunded || transaction.Status == models.TransactionStatusDelivered {
+		set["escrow.status"] = models.EscrowStatusRefundPending
+	}
+	if refundDue {
+		set["payment.refundDue"] = true
 	}
 
+	if forfeitDeposit && transaction.Hold != nil {
+		set["hold.forfeitDeposit"] = true
+	}
+
+	if notes != "" && actor.Role != TransactionRoleSystem {
+		set["notes"] = notes
+	}
+
+	// A frozen transaction only gets here by resolving its dispute, which the cancellation ends
+	if transaction.OpenDisputeID != nil {
+		update["$unset"] = bson.M{"openDisputeId": ""}
+	}
+
+	filter := bson.M{"_id": transaction.ID, "status": transaction.Status, "openDisputeId": disputeFreezeFilter(transaction)}
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	err = s.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update, opts).Decode(&transaction)
+	var cancelled models.Transaction
+	err = s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&cancelled)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errTransactionModified
+		}
 		return nil, err
 	}
 
-	return &transaction, nil
+	// The cancellation stands either way; ExpireHolds retries the refunds and releasing the vehicle if these fail
+	if refunded, err := s.refundCancelled(ctx, &cancelled); err == nil {
+		cancelled = *refunded
+	}
+	_ = s.settleHold(ctx, &cancelled)
+
+	return &cancelled, nil
+}
+
+// refundCancelled returns the pending escrow hold and provider payment of a cancelled transaction to the
+// buyer and records them as refunded, posting the returned escrow to the ledger
+// Provider refunds are idempotent, so a refund whose recording fails is retried by ExpireHolds
+func (s *TransactionService) refundCancelled(ctx context.Context, transaction *models.Transaction) (*models.Transaction, error) {
+	escrowDue := transaction.Escrow != nil && transaction.Escrow.Status == models.EscrowStatusRefundPending
+	paymentDue := transaction.Payment != nil && transaction.Payment.RefundDue
+	if !escrowDue && !paymentDue {
+		return transaction, nil
+	}
+
+	now := time.Now()
+	filter := bson.M{"_id": transaction.ID, "status": models.TransactionStatusCancelled}
+	update := bson.M{"$set": bson.M{"updatedAt": now}}
+	var entry *models.LedgerEntry
+	if escrowDue {
+		var err error
+		if entry, err = escrowRefundedEntry(transaction, now); err != nil {
+			return nil, err
+		}
+		if err := s.refundIntent(ctx, transaction.Escrow.Provider, transaction.Escrow.HoldReference); err != nil {
+			return nil, err
+		}
+		filter["escrow.status"] = models.EscrowStatusRefundPending
+		update["$set"].(bson.M)["escrow.status"] = models.EscrowStatusRefunded
+		update["$set"].(bson.M)["escrow.refundedAt"] = now
+	}
+
+	// The ledger records provider payments when the sale completes, so these refunds post nothing
+	if paymentDue {
+		if err := s.refundIntent(ctx, transaction.Payment.Provider, transaction.Payment.IntentID); err != nil {
+			return nil, err
+		}
+		filter["payment.refundDue"] = true
+		update["$set"].(bson.M)["payment.status"] = payments.StatusRefunded
+		update["$set"].(bson.M)["payment.updatedAt"] = now
+		update["$unset"] = bson.M{"payment.refundDue": ""}
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var refunded models.Transaction
+	err := s.withTransaction(ctx, func(sc mongo.SessionContext) error {
+		err := s.collection.FindOneAndUpdate(sc, filter, update, opts).Decode(&refunded)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errTransactionModified
+			}
+			return err
+		}
+		return s.postLedger(sc, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &refunded, nil
+}
+
+// resolveActor determines the caller's role on the transaction
+// Returns false when the caller is neither a party to the transaction nor an admin
+func (s *TransactionService) resolveActor(ctx context.Context, txn *models.Transaction, userID primitive.ObjectID) (TransactionActor, bool, error) {
+	switch userID {
+	case txn.SellerID:
+		return TransactionActor{ID: userID, Role: TransactionRoleSeller}, true, nil
+	case txn.BuyerID:
+		return TransactionActor{ID: userID, Role: TransactionRoleBuyer}, true, nil
+	}
+
+	var user models.User
+	err := s.userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return TransactionActor{}, false, nil
+		}
+		return TransactionActor{}, false, err
+	}
+
+	if user.Role != models.RoleAdmin {
+		return TransactionActor{}, false, nil
+	}
+
+	return TransactionActor{ID: userID, Role: TransactionRoleAdmin}, true, nil
 }
 
 // ListTransactions retrieves transactions with filtering and pagination
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/payments"
+)
+
+// Transaction party roles used by the state machine
+const (
+	TransactionRoleSeller = "seller"
+	TransactionRoleBuyer  = "buyer"
+	TransactionRoleAdmin  = "admin"
+	TransactionRoleSystem = "system" // automatic escrow release, reservation hold expiry and payment provider webhooks
+)
+
+// TransactionActor identifies who is requesting a status transition
+type TransactionActor struct {
+	ID        primitive.ObjectID
+	Role      string             // seller, buyer, admin, system
+	DisputeID primitive.ObjectID // set when an admin acts on the transaction to resolve its open dispute
+}
+
+// TransactionGuard is a precondition that must hold before a transition is applied
+type TransactionGuard func(txn *models.Transaction, actor TransactionActor) error
+
+// transactionTransition describes who may move a transaction between two statuses
+type transactionTransition struct {
+	roles  []string
+	guards []TransactionGuard
+}
+
+// TransactionStateMachine enforces the allowed transaction status transitions
+type TransactionStateMachine struct {
+	transitions map[string]map[string]transactionTransition
+}
+
+// NewTransactionStateMachine creates the state machine with the default transition table
+// Escrow transactions go pending -> funded -> delivered -> completed instead of completing directly
+func NewTransactionStateMachine() *TransactionStateMachine {
+	return &TransactionStateMachine{
+		transitions: map[string]map[string]transactionTransition{
+			models.TransactionStatusPending: {
+				models.TransactionStatusCompleted: {
+					roles:  []string{TransactionRoleSeller, TransactionRoleAdmin},
+					guards: []TransactionGuard{requireNoOpenDispute, requireNoEscrow, requireTransactionReference, requireConfirmedPayment},
+				},
+				models.TransactionStatusFunded: {
+					roles:  []string{TransactionRoleBuyer},
+					guards: []TransactionGuard{requireNoOpenDispute, requireEscrow},
+				},
+				// The system cancels the sale when the payment provider reports the payment was refunded or the reservation hold expires
+				models.TransactionStatusCancelled: {
+					roles:  []string{TransactionRoleSeller, TransactionRoleBuyer, TransactionRoleAdmin, TransactionRoleSystem},
+					guards: []TransactionGuard{requireNoOpenDispute},
+				},
+				models.TransactionStatusFailed: {
+					roles: []string{TransactionRoleSeller, TransactionRoleAdmin},
+				},
+			},
+			models.TransactionStatusFunded: {
+				models.TransactionStatusDelivered: {
+					roles:  []string{TransactionRoleSeller, TransactionRoleAdmin},
+					guards: []TransactionGuard{requireNoOpenDispute},
+				},
+				// Cancelling after funding refunds the held payment
+				models.TransactionStatusCancelled: {
+					roles:  []string{TransactionRoleSeller, TransactionRoleBuyer, TransactionRoleAdmin},
+					guards: []TransactionGuard{requireNoOpenDispute},
+				},
+			},
+			// Admins refund completed sales in full; partial refunds leave the status alone
+			models.TransactionStatusCompleted: {
+				models.TransactionStatusRefunded: {
+					roles: []string{TransactionRoleAdmin},
+				},
+			},
+			models.TransactionStatusDelivered: {
+				// The buyer confirms delivery, or the dispute window lapses and funds are released automatically
+				models.TransactionStatusCompleted: {
+					roles:  []string{TransactionRoleBuyer, TransactionRoleAdmin, TransactionRoleSystem},
+					guards: []TransactionGuard{requireNoOpenDispute},
+				},
+				// A dispute resolved for the buyer after delivery cancels the sale and refunds the held funds
+				models.TransactionStatusCancelled: {
+					roles:  []string{TransactionRoleAdmin},
+					guards: []TransactionGuard{requireDisputeResolution},
+				},
+			},
+			models.TransactionStatusFailed: {
+				models.TransactionStatusPending: {
+					roles: []string{TransactionRoleAdmin},
+				},
+				// The system cancels failed sales whose reservation hold has expired
+				models.TransactionStatusCancelled: {
+					roles:  []string{TransactionRoleSeller, TransactionRoleBuyer, TransactionRoleAdmin, TransactionRoleSystem},
+					guards: []TransactionGuard{requireNoOpenDispute},
+				},
+			},
+		},
+	}
+}
+
+// CanTransition reports whether the actor may move the transaction to the target status
+// Returns an INVALID_STATE_TRANSITION AppError when the move is not allowed
+func (m *TransactionStateMachine) CanTransition(txn *models.Transaction, to string, actor TransactionActor) error {
+	if !models.IsValidTransactionStatus(to) {
+		return apperrors.NewInvalidStateTransitionError(fmt.Sprintf("unknown transaction status %q", to))
+	}
+
+	transition, ok := m.transitions[txn.Status][to]
+	if !ok {
+		return apperrors.NewInvalidStateTransitionError(
+			fmt.Sprintf("cannot move transaction from %s to %s", txn.Status, to),
+		)
+	}
+
+	if !containsString(transition.roles, actor.Role) {
+		return apperrors.NewInvalidStateTransitionError(
+			fmt.Sprintf("%s is not allowed to move transaction from %s to %s", actor.Role, txn.Status, to),
+		)
+	}
+
+	for _, guard := range transition.guards {
+		if err := guard(txn, actor); err != nil {
+			return apperrors.NewInvalidStateTransitionError(err.Error())
+		}
+	}
+
+	return nil
+}
+
+// Transition validates the move and returns the history entry to record for it
+func (m *TransactionStateMachine) Transition(txn *models.Transaction, to string, actor TransactionActor, reason string) (*models.TransactionStatusChange, error) {
+	if err := m.CanTransition(txn, to, actor); err != nil {
+		return nil, err
+	}
+
+	return &models.TransactionStatusChange{
+		From:      txn.Status,
+		To:        to,
+		ActorID:   actor.ID,
+		ActorRole: actor.Role,
+		Reason:    reason,
+		ChangedAt: time.Now(),
+	}, nil
+}
+
+// AllowedTransitions lists the statuses the actor may move the transaction to
+func (m *TransactionStateMachine) AllowedTransitions(txn *models.Transaction, actor TransactionActor) []string {
+	allowed := []string{}
+	for to := range m.transitions[txn.Status] {
+		if m.CanTransition(txn, to, actor) == nil {
+			allowed = append(allowed, to)
+		}
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+// requireTransactionReference ensures a payment reference exists before completion
+func requireTransactionReference(txn *models.Transaction, _ TransactionActor) error {
+	if txn.PaymentDetails.TransactionReference == "" {
+		return errors.New("a payment transaction reference is required to complete a transaction")
+	}
+	return nil
+}
+
+// requireConfirmedPayment ensures card and bank transfer payments were authorized by the payment provider
+func requireConfirmedPayment(txn *models.Transaction, _ TransactionActor) error {
+	if !txn.RequiresProviderPayment() {
+		return nil
+	}
+	if txn.Payment == nil || !payments.IsConfirmed(txn.Payment.Status) {
+		return errors.New("the payment has not been confirmed by the payment provider")
+	}
+	return nil
+}
+
+// requireEscrow ensures the transaction was created in escrow mode
+func requireEscrow(txn *models.Transaction, _ TransactionActor) error {
+	if txn.Escrow == nil {
+		return errors.New("only escrow transactions can be funded")
+	}
+	return nil
+}
+
+// requireNoEscrow ensures escrow transactions only complete by releasing their held funds
+func requireNoEscrow(txn *models.Transaction, _ TransactionActor) error {
+	if txn.Escrow != nil {
+		return errors.New("escrow transactions complete when the buyer confirms delivery")
+	}
+	return nil
+}
+
+// requireNoOpenDispute freezes a disputed transaction until an admin resolves the dispute
+// The admin resolving it is the only one who may complete or cancel it in the meantime
+func requireNoOpenDispute(txn *models.Transaction, actor TransactionActor) error {
+	if txn.OpenDisputeID == nil || actor.DisputeID == *txn.OpenDisputeID {
+		return nil
+	}
+	return errors.New("the transaction is frozen while its dispute is open")
+}
+
+// requireDisputeResolution ensures the move is made by an admin resolving the transaction's open dispute
+func requireDisputeResolution(txn *models.Transaction, actor TransactionActor) error {
+	if txn.OpenDisputeID == nil || actor.DisputeID != *txn.OpenDisputeID {
+		return errors.New("delivered transactions can only be cancelled by resolving a dispute for the buyer")
+	}
+	return nil
+}
+
+// containsString checks if a slice contains the given value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
+	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/payments"
+)
+
+func TestTransactionStateMachine_CanTransition(t *testing.T) {
+	machine := NewTransactionStateMachine()
+
+	tests := []struct {
+		name      string
+		status    string
+		reference string
+		to        string
+		role      string
+		wantErr   bool
+	}{
+		{"seller completes pending with reference", models.TransactionStatusPending, "REF-1", models.TransactionStatusCompleted, TransactionRoleSeller, false},
+		{"admin completes pending with reference", models.TransactionStatusPending, "REF-1", models.TransactionStatusCompleted, TransactionRoleAdmin, false},
+		{"buyer cannot complete", models.TransactionStatusPending, "REF-1", models.TransactionStatusCompleted, TransactionRoleBuyer, true},
+		{"completion requires reference", models.TransactionStatusPending, "", models.TransactionStatusCompleted, TransactionRoleSeller, true},
+		{"buyer cancels pending", models.TransactionStatusPending, "", models.TransactionStatusCancelled, TransactionRoleBuyer, false},
+		{"seller marks pending failed", models.TransactionStatusPending, "", models.TransactionStatusFailed, TransactionRoleSeller, false},
+		{"buyer cannot mark failed", models.TransactionStatusPending, "", models.TransactionStatusFailed, TransactionRoleBuyer, true},
+		{"cancelled cannot reopen", models.TransactionStatusCancelled, "", models.TransactionStatusPending, TransactionRoleAdmin, true},
+		{"cancelled cannot complete", models.TransactionStatusCancelled, "REF-1", models.TransactionStatusCompleted, TransactionRoleSeller, true},
+		{"admin refunds completed", models.TransactionStatusCompleted, "REF-1", models.TransactionStatusRefunded, TransactionRoleAdmin, false},
+		{"seller cannot refund", models.TransactionStatusCompleted, "REF-1", models.TransactionStatusRefunded, TransactionRoleSeller, true},
+		{"pending cannot be refunded", models.TransactionStatusPending, "", models.TransactionStatusRefunded, TransactionRoleAdmin, true},
+		{"completed cannot cancel", models.TransactionStatusCompleted, "REF-1", models.TransactionStatusCancelled, TransactionRoleAdmin, true},
+		{"admin retries failed", models.TransactionStatusFailed, "", models.TransactionStatusPending, TransactionRoleAdmin, false},
+		{"system cancels failed after hold expiry", models.TransactionStatusFailed, "", models.TransactionStatusCancelled, TransactionRoleSystem, false},
+		{"seller cannot retry failed", models.TransactionStatusFailed, "", models.TransactionStatusPending, TransactionRoleSeller, true},
+		{"unknown target status", models.TransactionStatusPending, "", "refunded_twice", TransactionRoleAdmin, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := &models.Transaction{
+				Status:         tt.status,
+				PaymentDetails: models.PaymentDetails{TransactionReference: tt.reference},
+			}
+			actor := TransactionActor{ID: primitive.NewObjectID(), Role: tt.role}
+
+			err := machine.CanTransition(txn, tt.to, actor)
+			if tt.wantErr {
+				assert.Error(t, err)
+				appErr, ok := err.(*apperrors.AppError)
+				if assert.True(t, ok) {
+					assert.Equal(t, apperrors.ErrCodeInvalidStateTransition, appErr.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTransactionStateMachine_EscrowTransitions(t *testing.T) {
+	machine := NewTransactionStateMachine()
+
+	tests := []struct {
+		name      string
+		status    string
+		escrow    bool
+		reference string
+		to        string
+		role      string
+		wantErr   bool
+	}{
+		{"buyer funds escrow", models.TransactionStatusPending, true, "", models.TransactionStatusFunded, TransactionRoleBuyer, false},
+		{"seller cannot fund", models.TransactionStatusPending, true, "", models.TransactionStatusFunded, TransactionRoleSeller, true},
+		{"direct transaction cannot be funded", models.TransactionStatusPending, false, "", models.TransactionStatusFunded, TransactionRoleBuyer, true},
+		{"escrow cannot complete directly", models.TransactionStatusPending, true, "REF-1", models.TransactionStatusCompleted, TransactionRoleSeller, true},
+		{"seller marks delivered", models.TransactionStatusFunded, true, "", models.TransactionStatusDelivered, TransactionRoleSeller, false},
+		{"buyer cannot mark delivered", models.TransactionStatusFunded, true, "", models.TransactionStatusDelivered, TransactionRoleBuyer, true},
+		{"buyer cancels funded", models.TransactionStatusFunded, true, "", models.TransactionStatusCancelled, TransactionRoleBuyer, false},
+		{"funded cannot complete", models.TransactionStatusFunded, true, "REF-1", models.TransactionStatusCompleted, TransactionRoleBuyer, true},
+		{"buyer confirms delivery", models.TransactionStatusDelivered, true, "", models.TransactionStatusCompleted, TransactionRoleBuyer, false},
+		{"system releases after window", models.TransactionStatusDelivered, true, "", models.TransactionStatusCompleted, TransactionRoleSystem, false},
+		{"seller cannot confirm delivery", models.TransactionStatusDelivered, true, "", models.TransactionStatusCompleted, TransactionRoleSeller, true},
+		{"delivered cannot cancel", models.TransactionStatusDelivered, true, "", models.TransactionStatusCancelled, TransactionRoleAdmin, true},
+		{"system cannot cancel funded", models.TransactionStatusFunded, true, "", models.TransactionStatusCancelled, TransactionRoleSystem, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := &models.Transaction{
+				Status:         tt.status,
+				PaymentDetails: models.PaymentDetails{TransactionReference: tt.reference},
+			}
+			if tt.escrow {
+				txn.Escrow = &models.EscrowDetails{Status: models.EscrowStatusAwaitingFunds}
+			}
+			actor := TransactionActor{ID: primitive.NewObjectID(), Role: tt.role}
+
+			err := machine.CanTransition(txn, tt.to, actor)
+			if tt.wantErr {
+				assert.Error(t, err)
+				appErr, ok := err.(*apperrors.AppError)
+				if assert.True(t, ok) {
+					assert.Equal(t, apperrors.ErrCodeInvalidStateTransition, appErr.Code)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTransactionStateMachine_ProviderPaymentGuard(t *testing.T) {
+	machine := NewTransactionStateMachine()
+	seller := TransactionActor{ID: primitive.NewObjectID(), Role: TransactionRoleSeller}
+
+	tests := []struct {
+		name    string
+		method  string
+		payment *models.ProviderPayment
+		wantErr bool
+	}{
+		{"cash needs no provider payment", models.PaymentMethodCash, nil, false},
+		{"card without payment", models.PaymentMethodCard, nil, true},
+		{"card authorized", models.PaymentMethodCard, &models.ProviderPayment{Status: payments.StatusRequiresCapture}, false},
+		{"card declined", models.PaymentMethodCard, &models.ProviderPayment{Status: payments.StatusFailed}, true},
+		{"bank transfer processing", models.PaymentMethodBankTransfer, &models.ProviderPayment{Status: payments.StatusProcessing}, true},
+		{"bank transfer settled", models.PaymentMethodBankTransfer, &models.ProviderPayment{Status: payments.StatusSucceeded}, false},
+		{"bank transfer refunded", models.PaymentMethodBankTransfer, &models.ProviderPayment{Status: payments.StatusRefunded}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := &models.Transaction{
+				Status:         models.TransactionStatusPending,
+				PaymentMethod:  tt.method,
+				PaymentDetails: models.PaymentDetails{TransactionReference: "REF-1"},
+				Payment:        tt.payment,
+			}
+
+			err := machine.CanTransition(txn, models.TransactionStatusCompleted, seller)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTransactionStateMachine_DisputeFreeze(t *testing.T) {
+	machine := NewTransactionStateMachine()
+	disputeID := primitive.NewObjectID()
+
+	tests := []struct {
+		name      string
+		status    string
+		escrow    bool
+		to        string
+		role      string
+		disputeID primitive.ObjectID
+		wantErr   bool
+	}{
+		{"seller cannot complete disputed sale", models.TransactionStatusPending, false, models.TransactionStatusCompleted, TransactionRoleSeller, primitive.NilObjectID, true},
+		{"buyer cannot cancel disputed sale", models.TransactionStatusPending, false, models.TransactionStatusCancelled, TransactionRoleBuyer, primitive.NilObjectID, true},
+		{"admin cannot cancel outside the resolution", models.TransactionStatusFunded, true, models.TransactionStatusCancelled, TransactionRoleAdmin, primitive.NilObjectID, true},
+		{"system cannot release disputed escrow", models.TransactionStatusDelivered, true, models.TransactionStatusCompleted, TransactionRoleSystem, primitive.NilObjectID, true},
+		{"seller cannot mark disputed sale delivered", models.TransactionStatusFunded, true, models.TransactionStatusDelivered, TransactionRoleSeller, primitive.NilObjectID, true},
+		{"buyer cannot fund disputed sale", models.TransactionStatusPending, true, models.TransactionStatusFunded, TransactionRoleBuyer, primitive.NilObjectID, true},
+		{"another dispute cannot lift the freeze", models.TransactionStatusPending, false, models.TransactionStatusCancelled, TransactionRoleAdmin, primitive.NewObjectID(), true},
+		{"resolution cancels pending", models.TransactionStatusPending, false, models.TransactionStatusCancelled, TransactionRoleAdmin, disputeID, false},
+		{"resolution releases delivered escrow", models.TransactionStatusDelivered, true, models.TransactionStatusCompleted, TransactionRoleAdmin, disputeID, false},
+		{"resolution cancels delivered escrow", models.TransactionStatusDelivered, true, models.TransactionStatusCancelled, TransactionRoleAdmin, disputeID, false},
+		{"seller cannot cancel delivered even for the dispute", models.TransactionStatusDelivered, true, models.TransactionStatusCancelled, TransactionRoleSeller, disputeID, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := &models.Transaction{
+				Status:         tt.status,
+				PaymentDetails: models.PaymentDetails{TransactionReference: "REF-1"},
+				OpenDisputeID:  &disputeID,
+			}
+			if tt.escrow {
+				txn.Escrow = &models.EscrowDetails{Status: models.EscrowStatusHeld}
+			}
+			actor := TransactionActor{ID: primitive.NewObjectID(), Role: tt.role, DisputeID: tt.disputeID}
+
+			err := machine.CanTransition(txn, tt.to, actor)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	// Delivered sales are only cancelled by a dispute resolution
+	txn := &models.Transaction{Status: models.TransactionStatusDelivered, Escrow: &models.EscrowDetails{Status: models.EscrowStatusHeld}}
+	admin := TransactionActor{ID: primitive.NewObjectID(), Role: TransactionRoleAdmin}
+	assert.Error(t, machine.CanTransition(txn, models.TransactionStatusCancelled, admin))
+	assert.NoError(t, machine.CanTransition(txn, models.TransactionStatusCompleted, admin))
+}
+
+func TestTransactionStateMachine_Transition(t *testing.T) {
+	machine := NewTransactionStateMachine()
+	actor := TransactionActor{ID: primitive.NewObjectID(), Role: TransactionRoleBuyer}
+	txn := &models.Transaction{Status: models.TransactionStatusPending}
+
+	change, err := machine.Transition(txn, models.TransactionStatusCancelled, actor, "changed my mind")
+	assert.NoError(t, err)
+	assert.Equal(t, models.TransactionStatusPending, change.From)
+	assert.Equal(t, models.TransactionStatusCancelled, change.To)
+	assert.Equal(t, actor.ID, change.ActorID)
+	assert.Equal(t, TransactionRoleBuyer, change.ActorRole)
+	assert.Equal(t, "changed my mind", change.Reason)
+	assert.False(t, change.ChangedAt.IsZero())
+}
+
+func TestTransactionStateMachine_AllowedTransitions(t *testing.T) {
+	machine := NewTransactionStateMachine()
+	txn := &models.Transaction{Status: models.TransactionStatusPending}
+
+	buyer := TransactionActor{ID: primitive.NewObjectID(), Role: TransactionRoleBuyer}
+	assert.Equal(t, []string{models.TransactionStatusCancelled}, machine.AllowedTransitions(txn, buyer))
+
+	seller := TransactionActor{ID: primitive.NewObjectID(), Role: TransactionRoleSeller}
+	assert.Equal(t, []string{models.TransactionStatusCancelled, models.TransactionStatusFailed}, machine.AllowedTransitions(txn, seller))
+
+	txn.Status = models.TransactionStatusCompleted
+	assert.Empty(t, machine.AllowedTransitions(txn, seller))
+}
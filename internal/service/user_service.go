@@ -64,6 +64,7 @@ func (s *UserService) Register(ctx context.Context, req models.RegisterRequest)
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		Role:      role,
+		Location:  req.Location,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -120,6 +121,37 @@ func (s *UserService) Login(ctx context.Context, req models.LoginRequest) (*mode
 	}, nil
 }
 
+// RotateCalendarToken issues a new calendar feed token for the user
+// Only the token's hash is stored, so the previous feed URL stops working immediately
+func (s *UserService) RotateCalendarToken(ctx context.Context, userID string) (string, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", errors.New("invalid user ID")
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", errors.New("failed to generate token")
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"calendarTokenHash": auth.HashOpaqueToken(token),
+			"updatedAt":         time.Now(),
+		},
+	}
+
+	result, err := s.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return "", err
+	}
+	if result.MatchedCount == 0 {
+		return "", errors.New("user not found")
+	}
+
+	return token, nil
+}
+
 // GetUserByID retrieves a user by their ID
 // ctx: Context for the operation
 // userID: The user's ID as a string
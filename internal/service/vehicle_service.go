@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"sort"
+	"strconv"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -10,19 +12,24 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	apperrors "github.com/Over-knight/Lujay-assesment/internal/errors"
 	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
 )
 
 // VehicleService handles vehicle-related business logic
 type VehicleService struct {
 	collection *mongo.Collection
+	rates      ExchangeRateProvider
 }
 
 // NewVehicleService creates a new vehicle service instance
 // collection: MongoDB collection for vehicles
-func NewVehicleService(collection *mongo.Collection) *VehicleService {
+// rates: Exchange rates used to list vehicles in another currency
+func NewVehicleService(collection *mongo.Collection, rates ExchangeRateProvider) *VehicleService {
 	return &VehicleService{
 		collection: collection,
+		rates:      rates,
 	}
 }
 
@@ -43,6 +50,11 @@ func (s *VehicleService) CreateVehicle(ctx context.Context, ownerID string, req
 		return nil, errors.New("invalid owner ID")
 	}
 
+	price, err := req.ListedPrice()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create vehicle object
 	vehicle := models.Vehicle{
 		ID:        primitive.NewObjectID(),
@@ -50,7 +62,7 @@ func (s *VehicleService) CreateVehicle(ctx context.Context, ownerID string, req
 		Make:      req.Make,
 		Model:     req.Model,
 		Year:      req.Year,
-		Price:     req.Price,
+		Price:     price,
 		Mileage:   req.Mileage,
 		Status:    models.VehicleStatusActive,
 		Location:  req.Location,
@@ -180,14 +192,32 @@ func (s *VehicleService) UpdateVehicle(ctx context.Context, vehicleID, ownerID s
 	if req.Year != 0 {
 		update["year"] = req.Year
 	}
-	if req.Price >= 0 {
-		update["price"] = req.Price
+	if !req.Price.IsZero() {
+		// A new price keeps the listing's currency unless the request relists the vehicle in another
+		currency := req.Currency
+		if currency == "" {
+			currency = existingVehicle.Price.Currency()
+		}
+		if currency == "" {
+			currency = money.DefaultCurrency
+		}
+		price, err := req.Price.WithCurrency(currency)
+		if err != nil {
+			return nil, apperrors.NewValidationError("price: " + err.Error())
+		}
+		update["price"] = price
 	}
 	if req.Mileage >= 0 {
 		update["mileage"] = req.Mileage
 	}
+	// Reserved vehicles change status only through their transaction
+	filter := bson.M{"_id": vehicleObjectID}
 	if req.Status != "" {
+		if existingVehicle.Status == models.VehicleStatusReserved {
+			return nil, errVehicleReserved
+		}
 		update["status"] = req.Status
+		filter["status"] = existingVehicle.Status
 	}
 	if req.Location != nil {
 		update["location"] = req.Location
@@ -200,14 +230,17 @@ func (s *VehicleService) UpdateVehicle(ctx context.Context, vehicleID, ownerID s
 	}
 
 	// Update vehicle
-	_, err = s.collection.UpdateOne(
+	result, err := s.collection.UpdateOne(
 		ctx,
-		bson.M{"_id": vehicleObjectID},
+		filter,
 		bson.M{"$set": update},
 	)
 	if err != nil {
 		return nil, errors.New("failed to update vehicle")
 	}
+	if result.MatchedCount == 0 {
+		return nil, errVehicleReserved
+	}
 
 	// Fetch and return updated vehicle
 	return s.GetVehicleByID(ctx, vehicleID)
@@ -230,12 +263,13 @@ func (s *VehicleService) DeleteVehicle(ctx context.Context, vehicleID, ownerID s
 		return errors.New("invalid owner ID")
 	}
 
-	// Update vehicle status to archived
+	// Update vehicle status to archived; reserved vehicles are released by their transaction first
 	result, err := s.collection.UpdateOne(
 		ctx,
 		bson.M{
 			"_id":     vehicleObjectID,
 			"ownerId": ownerObjectID,
+			"status":  bson.M{"$ne": models.VehicleStatusReserved},
 		},
 		bson.M{
 			"$set": bson.M{
@@ -249,6 +283,17 @@ func (s *VehicleService) DeleteVehicle(ctx context.Context, vehicleID, ownerID s
 	}
 
 	if result.MatchedCount == 0 {
+		reserved, err := s.collection.CountDocuments(ctx, bson.M{
+			"_id":     vehicleObjectID,
+			"ownerId": ownerObjectID,
+			"status":  models.VehicleStatusReserved,
+		})
+		if err != nil {
+			return err
+		}
+		if reserved > 0 {
+			return errVehicleReserved
+		}
 		return errors.New("vehicle not found or unauthorized")
 	}
 
@@ -268,6 +313,7 @@ type VehicleListQuery struct {
 	Status    string
 	SortBy    string // price, year, mileage, createdAt
 	SortOrder string // asc, desc
+	Currency  string // prices are filtered, sorted and displayed in this currency; required to filter or sort by price
 }
 
 // VehicleListResponse represents the paginated list response
@@ -277,6 +323,8 @@ type VehicleListResponse struct {
 	Page       int64            `json:"page"`
 	Limit      int64            `json:"limit"`
 	TotalPages int64            `json:"totalPages"`
+	Currency   string           `json:"currency,omitempty"`
+	RatesAsOf  *time.Time       `json:"ratesAsOf,omitempty"`
 }
 
 // ListVehicles retrieves vehicles with pagination, filtering, and sorting
@@ -297,16 +345,32 @@ func (s *VehicleService) ListVehicles(ctx context.Context, query VehicleListQuer
 		filter["status"] = query.Status
 	}
 
+	// Prices in other currencies are compared after conversion to the requested one
+	if err := requirePriceCurrency(query); err != nil {
+		return nil, err
+	}
+	var rates *models.ExchangeRateTable
+	if query.Currency != "" {
+		if s.rates == nil {
+			return nil, errNoExchangeRates
+		}
+		table, err := s.rates.Rates(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := table.Rate(query.Currency, query.Currency); err != nil {
+			return nil, apperrors.NewValidationError(err.Error())
+		}
+		rates = table
+	}
+
 	// Price range filter
 	if query.MinPrice > 0 || query.MaxPrice > 0 {
-		priceFilter := bson.M{}
-		if query.MinPrice > 0 {
-			priceFilter["$gte"] = query.MinPrice
+		priceFilter, err := convertedPriceFilter(rates, query.Currency, query.MinPrice, query.MaxPrice)
+		if err != nil {
+			return nil, err
 		}
-		if query.MaxPrice > 0 {
-			priceFilter["$lte"] = query.MaxPrice
-		}
-		filter["price"] = priceFilter
+		filter["$or"] = priceFilter
 	}
 
 	// Year range filter
@@ -341,8 +405,10 @@ func (s *VehicleService) ListVehicles(ctx context.Context, query VehicleListQuer
 
 	if query.SortBy != "" {
 		switch query.SortBy {
-		case "price", "year", "mileage", "createdAt":
+		case "year", "mileage", "createdAt":
 			sortField = query.SortBy
+		case "price":
+			sortField = "price.amount"
 		}
 	}
 
@@ -350,20 +416,31 @@ func (s *VehicleService) ListVehicles(ctx context.Context, query VehicleListQuer
 		sortOrder = 1
 	}
 
-	// Query options
-	opts := options.Find().
-		SetSkip(skip).
-		SetLimit(query.Limit).
-		SetSort(bson.D{{Key: sortField, Value: sortOrder}})
-
 	// Get total count
 	totalCount, err := s.collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	// Find vehicles
-	cursor, err := s.collection.Find(ctx, filter, opts)
+	// Find vehicles; sorting by a converted price needs the conversion done in an aggregation
+	var cursor *mongo.Cursor
+	if sortField == "price.amount" {
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: filter}},
+			{{Key: "$addFields", Value: bson.M{"convertedPrice": convertedPriceExpr(rates, query.Currency)}}},
+			{{Key: "$sort", Value: bson.D{{Key: "convertedPrice", Value: sortOrder}, {Key: "_id", Value: 1}}}},
+			{{Key: "$skip", Value: skip}},
+			{{Key: "$limit", Value: query.Limit}},
+			{{Key: "$project", Value: bson.M{"convertedPrice": 0}}},
+		}
+		cursor, err = s.collection.Aggregate(ctx, pipeline)
+	} else {
+		opts := options.Find().
+			SetSkip(skip).
+			SetLimit(query.Limit).
+			SetSort(bson.D{{Key: sortField, Value: sortOrder}})
+		cursor, err = s.collection.Find(ctx, filter, opts)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -380,6 +457,14 @@ func (s *VehicleService) ListVehicles(ctx context.Context, query VehicleListQuer
 		vehicles = []models.Vehicle{}
 	}
 
+	var ratesAsOf *time.Time
+	if rates != nil {
+		for i := range vehicles {
+			vehicles[i].DisplayPrice = displayPrice(rates, vehicles[i].Price, query.Currency)
+		}
+		ratesAsOf = &rates.EffectiveAt
+	}
+
 	// Calculate total pages
 	totalPages := totalCount / query.Limit
 	if totalCount%query.Limit > 0 {
@@ -392,5 +477,103 @@ func (s *VehicleService) ListVehicles(ctx context.Context, query VehicleListQuer
 		Page:       query.Page,
 		Limit:      query.Limit,
 		TotalPages: totalPages,
+		Currency:   query.Currency,
+		RatesAsOf:  ratesAsOf,
 	}, nil
 }
+
+// requirePriceCurrency rejects price filters and sorts without a currency
+// Vehicles are listed in different currencies, so their prices only compare once converted to one
+func requirePriceCurrency(query VehicleListQuery) error {
+	if query.Currency == "" && (query.MinPrice > 0 || query.MaxPrice > 0 || query.SortBy == "price") {
+		return apperrors.NewValidationError("currency is required to filter or sort by price")
+	}
+	return nil
+}
+
+// convertedPriceFilter matches prices between bounds given in currency, with one clause per currency in the table
+// Vehicles listed in a currency the table does not quote cannot be compared and never match
+func convertedPriceFilter(rates *models.ExchangeRateTable, currency string, minPrice, maxPrice float64) (bson.A, error) {
+	currencies := rates.Currencies()
+	sort.Strings(currencies)
+
+	clauses := bson.A{}
+	for _, listed := range currencies {
+		rate, err := rates.Rate(currency, listed)
+		if err != nil {
+			return nil, apperrors.NewValidationError(err.Error())
+		}
+
+		priceFilter := bson.M{}
+		if minPrice > 0 {
+			bound, err := convertedBound(rate, minPrice)
+			if err != nil {
+				return nil, err
+			}
+			priceFilter["$gte"] = bound
+		}
+		if maxPrice > 0 {
+			bound, err := convertedBound(rate, maxPrice)
+			if err != nil {
+				return nil, err
+			}
+			priceFilter["$lte"] = bound
+		}
+		clauses = append(clauses, bson.M{"price.currency": listed, "price.amount": priceFilter})
+	}
+	return clauses, nil
+}
+
+// convertedBound converts a price bound with rate into the Decimal128 prices in rate.To are stored as
+func convertedBound(rate models.ExchangeRate, amount float64) (primitive.Decimal128, error) {
+	bound, err := money.FromFloat(amount, rate.From, money.RoundHalfEven)
+	if err != nil {
+		return primitive.Decimal128{}, apperrors.NewValidationError(err.Error())
+	}
+	converted, err := rate.Convert(bound)
+	if err != nil {
+		return primitive.Decimal128{}, apperrors.NewValidationError(err.Error())
+	}
+	return converted.Decimal128(), nil
+}
+
+// convertedPriceExpr is an aggregation expression for a vehicle's price in currency
+// Prices in currencies the table does not quote convert to null and sort first
+func convertedPriceExpr(rates *models.ExchangeRateTable, currency string) bson.M {
+	currencies := rates.Currencies()
+	sort.Strings(currencies)
+
+	branches := bson.A{}
+	for _, listed := range currencies {
+		rate, err := rates.Rate(listed, currency)
+		if err != nil {
+			continue
+		}
+		factor, err := primitive.ParseDecimal128(strconv.FormatFloat(rate.Rate, 'f', -1, 64))
+		if err != nil {
+			continue
+		}
+		branches = append(branches, bson.M{
+			"case": bson.M{"$eq": bson.A{"$price.currency", listed}},
+			"then": factor,
+		})
+	}
+
+	return bson.M{"$multiply": bson.A{
+		"$price.amount",
+		bson.M{"$switch": bson.M{"branches": branches, "default": nil}},
+	}}
+}
+
+// displayPrice converts a listed price for display, or returns nil if the table has no rate for it
+func displayPrice(rates *models.ExchangeRateTable, price money.Money, currency string) *money.Money {
+	rate, err := rates.Rate(price.Currency(), currency)
+	if err != nil {
+		return nil
+	}
+	converted, err := rate.Convert(price)
+	if err != nil {
+		return nil
+	}
+	return &converted
+}
@@ -9,9 +9,14 @@ import (
 	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/admin"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 )
 
+// maxDeleteBatch is the most assets Cloudinary deletes in a single request
+const maxDeleteBatch = 100
+
 // CloudinaryUploader handles file uploads to Cloudinary
 type CloudinaryUploader struct {
 	cld    *cloudinary.Cloudinary
@@ -104,6 +109,26 @@ func (u *CloudinaryUploader) DeleteImage(ctx context.Context, publicID string) e
 	return nil
 }
 
+// DeleteImages deletes several images, in batches
+// Public IDs that no longer exist are ignored, so a failed cleanup can simply be retried
+func (u *CloudinaryUploader) DeleteImages(ctx context.Context, publicIDs []string) error {
+	for start := 0; start < len(publicIDs); start += maxDeleteBatch {
+		end := min(start+maxDeleteBatch, len(publicIDs))
+		result, err := u.cld.Admin.DeleteAssets(ctx, admin.DeleteAssetsParams{
+			AssetType:    api.Image,
+			DeliveryType: api.Upload,
+			PublicIDs:    publicIDs[start:end],
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete images: %w", err)
+		}
+		if result.Error.Message != "" {
+			return fmt.Errorf("failed to delete images: %s", result.Error.Message)
+		}
+	}
+	return nil
+}
+
 // UploadMultipleImages uploads multiple images
 func (u *CloudinaryUploader) UploadMultipleImages(ctx context.Context, files []multipart.File, filenames []string, folder string) ([]*UploadResult, error) {
 	if len(files) != len(filenames) {
@@ -46,6 +46,12 @@ func TestErrorResponses(t *testing.T) {
 			wantCode:   errors.ErrCodeConflict,
 			wantStatus: 409,
 		},
+		{
+			name:       "Invalid State Transition",
+			errorFunc:  func() *errors.AppError { return errors.NewInvalidStateTransitionError("cannot move transaction") },
+			wantCode:   errors.ErrCodeInvalidStateTransition,
+			wantStatus: 409,
+		},
 		{
 			name:       "Database Error",
 			errorFunc:  func() *errors.AppError { return errors.NewDatabaseError("db error") },
@@ -7,6 +7,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
 )
 
 // Integration tests for inspection functionality
@@ -22,7 +23,7 @@ func TestInspectionModelIntegration(t *testing.T) {
 			MechanicalScore:  88,
 			ExteriorScore:    92,
 			InteriorScore:    85,
-			EstimatedRepairs: 750.0,
+			EstimatedRepairs: money.MustParse("750", ""),
 			Issues: []models.InspectionIssue{
 				{
 					Category:    "mechanical",
@@ -7,6 +7,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
 )
 
 // Integration tests for transaction functionality
@@ -21,7 +22,7 @@ func TestTransactionModelIntegration(t *testing.T) {
 		cashReq := models.CreateTransactionRequest{
 			VehicleID:     vehicleID,
 			BuyerID:       buyerID,
-			Amount:        25000.0,
+			Amount:        money.MustParse("25000", ""),
 			Currency:      "USD",
 			PaymentMethod: models.PaymentMethodCash,
 			Notes:         "Full cash payment",
@@ -32,11 +33,11 @@ func TestTransactionModelIntegration(t *testing.T) {
 		financingReq := models.CreateTransactionRequest{
 			VehicleID:     vehicleID,
 			BuyerID:       buyerID,
-			Amount:        30000.0,
+			Amount:        money.MustParse("30000", ""),
 			Currency:      "USD",
 			PaymentMethod: models.PaymentMethodFinancing,
 			PaymentDetails: models.PaymentDetails{
-				DownPayment:    10000.0,
+				DownPayment:    money.MustParse("10000", ""),
 				FinancingTerms: 60,
 				InterestRate:   4.5,
 			},
@@ -48,7 +49,7 @@ func TestTransactionModelIntegration(t *testing.T) {
 		bankReq := models.CreateTransactionRequest{
 			VehicleID:     vehicleID,
 			BuyerID:       buyerID,
-			Amount:        25000.0,
+			Amount:        money.MustParse("25000", ""),
 			Currency:      "USD",
 			PaymentMethod: models.PaymentMethodBankTransfer,
 			PaymentDetails: models.PaymentDetails{
@@ -11,6 +11,7 @@ import (
 
 	"github.com/Over-knight/Lujay-assesment/internal/auth"
 	"github.com/Over-knight/Lujay-assesment/internal/models"
+	"github.com/Over-knight/Lujay-assesment/internal/money"
 	"github.com/Over-knight/Lujay-assesment/internal/service"
 )
 
@@ -77,7 +78,7 @@ func TestVehicleCreation(t *testing.T) {
 		Make:    "Toyota",
 		Model:   "Camry",
 		Year:    2020,
-		Price:   25000,
+		Price:   money.MustParse("25000", ""),
 		Mileage: 15000,
 		Location: models.Location{
 			City:    "New York",
@@ -115,7 +116,7 @@ func TestVehicleValidation(t *testing.T) {
 				Make:    "Honda",
 				Model:   "Accord",
 				Year:    2021,
-				Price:   28000,
+				Price:   money.MustParse("28000", ""),
 				Mileage: 5000,
 				Location: models.Location{
 					City:    "Los Angeles",
@@ -130,7 +131,7 @@ func TestVehicleValidation(t *testing.T) {
 			vehicle: models.CreateVehicleRequest{
 				Model:   "Accord",
 				Year:    2021,
-				Price:   28000,
+				Price:   money.MustParse("28000", ""),
 				Mileage: 5000,
 				Location: models.Location{
 					City:    "Los Angeles",
@@ -146,7 +147,7 @@ func TestVehicleValidation(t *testing.T) {
 				Make:    "Honda",
 				Model:   "Accord",
 				Year:    1899,
-				Price:   28000,
+				Price:   money.MustParse("28000", ""),
 				Mileage: 5000,
 				Location: models.Location{
 					City:    "Los Angeles",
@@ -162,7 +163,7 @@ func TestVehicleValidation(t *testing.T) {
 				Make:    "Honda",
 				Model:   "Accord",
 				Year:    2021,
-				Price:   -1000,
+				Price:   money.MustParse("-1000", ""),
 				Mileage: 5000,
 				Location: models.Location{
 					City:    "Los Angeles",
@@ -268,7 +269,7 @@ func TestVehicleOwnership(t *testing.T) {
 		Make:    "Tesla",
 		Model:   "Model 3",
 		Year:    2022,
-		Price:   45000,
+		Price:   money.MustParse("45000", ""),
 		Status:  models.VehicleStatusActive,
 	}
 